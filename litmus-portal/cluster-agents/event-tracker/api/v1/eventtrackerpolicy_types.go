@@ -28,38 +28,186 @@ type EventTrackerPolicySpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	// Foo is an example field of EventTrackerPolicy. Edit EventTrackerPolicy_types.go to remove/update
+	// ConditionType combines the conditions of the policy, "and" (default) or "or"
 	ConditionType string      `json:"condition_type,omitempty"`
 	Conditions    []Condition `json:"conditions,omitempty"`
+
+	// Groups are named condition groups, conditions refer to them to nest conditions
+	Groups []ConditionGroup `json:"groups,omitempty"`
+
+	// HistoryLimit is the number of evaluations kept in the status
+	// +kubebuilder:validation:Minimum=1
+	HistoryLimit *int32 `json:"history_limit,omitempty"`
+
+	// Source is what the policy is evaluated against: "resource" (default) for updates of resources, "event" for
+	// Kubernetes Events and "alert" for alerts received from Alertmanager. Event and alert policies trigger the
+	// workflows of WorkflowIDs
+	// +kubebuilder:validation:Enum=resource;event;alert
+	Source string `json:"source,omitempty"`
+
+	// Resource selects the kind of resource the policy is evaluated against. Policies without a
+	// resource are evaluated against Deployments, StatefulSets and DaemonSets
+	Resource *ResourceSelector `json:"resource,omitempty"`
+
+	// Namespaces limits the policy to resources, Kubernetes Events or alerts (by their namespace label) of the
+	// namespaces
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Selector limits the policy to resources, or alerts, whose labels match. Resource policies with namespaces or
+	// a selector are evaluated against the selected resources whether or not they carry the litmuschaos.io/gitops
+	// annotation. The selector isn't applied to Kubernetes Events
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// WorkflowIDs are the workflows triggered when the policy passes, the litmuschaos.io/workflow annotation of
+	// the resource is used when it is empty
+	WorkflowIDs []string `json:"workflow_ids,omitempty"`
+
+	// DiffMode decides which changes of a resource evaluate the policy. "last-applied" (default) compares the
+	// kubectl last-applied-configuration annotation, "live" compares the live object without its status and
+	// server managed metadata, so changes made by Helm, Argo CD, server-side apply or controllers are detected
+	// +kubebuilder:validation:Enum=last-applied;live
+	DiffMode string `json:"diff_mode,omitempty"`
+
+	// IgnorePaths are dot separated paths of fields whose changes are ignored, e.g. spec.replicas or
+	// metadata.annotations.deployment\.kubernetes\.io/revision. A * matches any key or list element
+	IgnorePaths []string `json:"ignore_paths,omitempty"`
+
+	// DebounceWindow delays the trigger of a workflow until no evaluation passed for the window, the passed
+	// evaluations within the window trigger the workflow once
+	DebounceWindow *metav1.Duration `json:"debounce_window,omitempty"`
+	// CooldownPeriod is the minimum time between two workflows triggered by the policy
+	CooldownPeriod *metav1.Duration `json:"cooldown_period,omitempty"`
+	// MaxConcurrentRuns is the maximum number of runs of a workflow in progress for the policy to trigger it
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrentRuns *int32 `json:"max_concurrent_runs,omitempty"`
+}
+
+// Sources of an EventTrackerPolicy
+const (
+	SourceResource = "resource"
+	SourceEvent    = "event"
+	SourceAlert    = "alert"
+)
+
+// Diff modes of an EventTrackerPolicy
+const (
+	DiffModeLastApplied = "last-applied"
+	DiffModeLive        = "live"
+)
+
+// ResourceSelector identifies a kind of resource by its group, version and kind. The event-tracker
+// needs RBAC permissions to list and watch the selected kind
+type ResourceSelector struct {
+	// Group is empty for the core API group
+	Group string `json:"group,omitempty"`
+	// Version is optional, any served version of the kind matches if it is empty
+	Version string `json:"version,omitempty"`
+	Kind    string `json:"kind"`
 }
 
+// Condition compares the value at the JMESPath Key of the resource with Value using Operator.
+// A condition referring to a Group is true when the group is true
 type Condition struct {
 	Key      string `json:"key,omitempty"`
 	Value    string `json:"value,omitempty"`
 	Operator string `json:"operator,omitempty"`
+
+	// Type is the type values are compared as: string, number, semver, duration or boolean.
+	// Values are compared as numbers if both are numeric and as strings otherwise when it is empty
+	Type string `json:"type,omitempty"`
+	// Values are the accepted values of the In operator
+	Values []string `json:"values,omitempty"`
+	// Group is the name of a condition group of the policy
+	Group string `json:"group,omitempty"`
 }
 
-// EventTrackerPolicyStatus defines the observed state of EventTrackerPolicy
-type EventTrackerPolicyStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+// ConditionGroup is a named set of conditions combined with its own ConditionType
+type ConditionGroup struct {
+	Name          string      `json:"name"`
+	ConditionType string      `json:"condition_type,omitempty"`
+	Conditions    []Condition `json:"conditions,omitempty"`
+}
+
+// DefaultHistoryLimit is the number of evaluations kept in the status when the spec doesn't set one
+const DefaultHistoryLimit = 20
+
+// Types of the conditions of an EventTrackerPolicy
+const (
+	// ConditionReady is true when the resource kind selected by the policy is watched
+	ConditionReady = "Ready"
+	// ConditionLastTriggered is true when the last workflow trigger of the policy succeeded
+	ConditionLastTriggered = "LastTriggered"
+)
+
+// PolicyEvaluation is the result of evaluating the policy against a change of a resource
+type PolicyEvaluation struct {
+	ID           string `json:"id,omitempty"`
 	TimeStamp    string `json:"time_stamp,omitempty"`
 	Resource     string `json:"resource,omitempty"`
 	ResourceName string `json:"resource_name,omitempty"`
 	Result       string `json:"result,omitempty"`
 	WorkflowID   string `json:"workflow_id,omitempty"`
 	IsTriggered  string `json:"is_triggered,omitempty"`
+	// SkipReason is set when the workflow was not triggered because of the debounce window, cooldown period or
+	// max concurrent runs of the policy
+	SkipReason string `json:"skip_reason,omitempty"`
+}
+
+// Reasons evaluations are skipped for
+const (
+	SkipReasonDebounced         = "Debounced"
+	SkipReasonCooldown          = "Cooldown"
+	SkipReasonMaxConcurrentRuns = "MaxConcurrentRuns"
+)
+
+// EventTrackerPolicyStatus defines the observed state of EventTrackerPolicy
+type EventTrackerPolicyStatus struct {
+	// Evaluations are the most recent evaluations of the policy, oldest first
+	Evaluations []PolicyEvaluation `json:"evaluations,omitempty"`
+	Conditions  []metav1.Condition `json:"conditions,omitempty"`
+
+	EvaluationCount int64 `json:"evaluation_count,omitempty"`
+	PassedCount     int64 `json:"passed_count,omitempty"`
+	TriggeredCount  int64 `json:"triggered_count,omitempty"`
+	SkippedCount    int64 `json:"skipped_count,omitempty"`
+
+	LastEvaluationTime *metav1.Time `json:"last_evaluation_time,omitempty"`
+	LastTriggerTime    *metav1.Time `json:"last_trigger_time,omitempty"`
+	ObservedGeneration int64        `json:"observed_generation,omitempty"`
+}
+
+// AddEvaluation records an evaluation, only the latest limit evaluations are kept
+func (in *EventTrackerPolicyStatus) AddEvaluation(evaluation PolicyEvaluation, limit int) {
+	in.Evaluations = append(in.Evaluations, evaluation)
+	if limit > 0 && len(in.Evaluations) > limit {
+		in.Evaluations = append([]PolicyEvaluation(nil), in.Evaluations[len(in.Evaluations)-limit:]...)
+	}
 }
 
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
 
 // EventTrackerPolicy is the Schema for the eventtrackerpolicies API
 type EventTrackerPolicy struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec     EventTrackerPolicySpec     `json:"spec,omitempty"`
-	Statuses []EventTrackerPolicyStatus `json:"statuses,omitempty"`
+	Spec   EventTrackerPolicySpec   `json:"spec,omitempty"`
+	Status EventTrackerPolicyStatus `json:"status,omitempty"`
+}
+
+// TriggerSource returns the source the policy is evaluated against
+func (in *EventTrackerPolicy) TriggerSource() string {
+	if in.Spec.Source == "" {
+		return SourceResource
+	}
+	return in.Spec.Source
+}
+
+// HistoryLimit returns the number of evaluations kept in the status
+func (in *EventTrackerPolicy) HistoryLimit() int {
+	if in.Spec.HistoryLimit != nil && *in.Spec.HistoryLimit > 0 {
+		return int(*in.Spec.HistoryLimit)
+	}
+	return DefaultHistoryLimit
 }
 
 // +kubebuilder:object:root=true
@@ -18,16 +18,20 @@ package controllers
 
 import (
 	"context"
-	"encoding/json"
 	"log"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	eventtrackerv1 "github.com/litmuschaos/litmus/litmus-portal/cluster-agents/event-tracker/api/v1"
+	"github.com/litmuschaos/litmus/litmus-portal/cluster-agents/event-tracker/pkg/notifier"
 	"github.com/litmuschaos/litmus/litmus-portal/cluster-agents/event-tracker/pkg/utils"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -35,65 +39,259 @@ import (
 // EventTrackerPolicyReconciler reconciles a EventTrackerPolicy object
 type EventTrackerPolicyReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
-}
-
-type Response struct {
-	Data struct {
-		GitopsNotifer string `json:"gitopsNotifer"`
-	} `json:"data"`
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+	Informers *utils.InformerManager
 }
 
 // +kubebuilder:rbac:groups=eventtracker.litmuschaos.io,resources=eventtrackerpolicies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=eventtracker.litmuschaos.io,resources=eventtrackerpolicies/status,verbs=get;update;patch
 
-const ConditionPassed = "ConditionPassed"
+const (
+	ConditionPassed    = "ConditionPassed"
+	watchRetryInterval = time.Minute
+)
+
+// Reasons of the conditions of an EventTrackerPolicy
+const (
+	ReasonWatching       = "Watching"
+	ReasonWatchFailed    = "WatchFailed"
+	ReasonTriggered      = "Triggered"
+	ReasonSkipped        = "Skipped"
+	ReasonGitOpsDisabled = "GitOpsDisabled"
+	ReasonTriggerFailed  = "TriggerFailed"
+)
+
+// triggerOutcome is the result of triggering the workflow of an evaluation, evaluations skipped by the
+// throttling of the policy have a skip reason
+type triggerOutcome struct {
+	response   *notifier.TriggerResponse
+	err        error
+	skipReason string
+}
 
 func (r *EventTrackerPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	_ = context.Background()
 	_ = r.Log.WithValues("eventtrackerpolicy", req.NamespacedName)
 
-	var mutex = &sync.Mutex{}
-	mutex.Lock()
-
+	var result ctrl.Result
 	var etp eventtrackerv1.EventTrackerPolicy
-	err := r.Client.Get(context.Background(), req.NamespacedName, &etp)
+	err := r.Client.Get(ctx, req.NamespacedName, &etp)
 	if errors.IsNotFound(err) {
 		log.Print(req.NamespacedName, " not found")
+		r.Informers.Unwatch(req.NamespacedName.String())
+		reportStatus(ctx, notifier.StatusReport{Name: req.Name, Deleted: true})
 		return ctrl.Result{}, nil
 	} else if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	for index, status := range etp.Statuses {
-		if string(status.Result) == ConditionPassed && strings.ToLower(status.IsTriggered) == "false" {
-			log.Print("ResourceName: " + status.ResourceName + "WorkflowID: " + status.WorkflowID)
-			response, err := utils.SendRequest(status.WorkflowID)
-			if err != nil {
-				return ctrl.Result{}, err
+	// the kind selected by the policy, or the events for event policies, must be watched for the policy to be audited
+	ready := metav1.Condition{Type: eventtrackerv1.ConditionReady, Status: metav1.ConditionTrue, Reason: ReasonWatching, Message: "the resources selected by the policy are watched"}
+	if etp.TriggerSource() == eventtrackerv1.SourceResource && etp.Spec.Resource != nil {
+		gvk := schema.GroupVersionKind{Group: etp.Spec.Resource.Group, Version: etp.Spec.Resource.Version, Kind: etp.Spec.Resource.Kind}
+		if err := r.Informers.Watch(req.NamespacedName.String(), gvk); err != nil {
+			log.Print("Failed to watch ", gvk.String(), " for ", req.NamespacedName, " : ", err)
+			ready.Status = metav1.ConditionFalse
+			ready.Reason = ReasonWatchFailed
+			ready.Message = "failed to watch " + gvk.String() + " : " + err.Error()
+			result = ctrl.Result{RequeueAfter: watchRetryInterval}
+		}
+	} else {
+		r.Informers.Unwatch(req.NamespacedName.String())
+		if etp.TriggerSource() == eventtrackerv1.SourceEvent {
+			r.Informers.WatchEvents()
+		}
+	}
+
+	outcomes, wait, err := r.triggerPending(ctx, &etp)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if wait > 0 && (result.RequeueAfter == 0 || wait < result.RequeueAfter) {
+		result.RequeueAfter = wait
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Client.Get(ctx, req.NamespacedName, &etp); err != nil {
+			return err
+		}
+		original := etp.DeepCopy()
+
+		for index, evaluation := range etp.Status.Evaluations {
+			outcome, ok := outcomes[evaluation.ID]
+			if !ok || evaluation.ID == "" {
+				continue
+			}
+			applyOutcome(&etp, index, outcome)
+		}
+
+		ready.ObservedGeneration = etp.Generation
+		meta.SetStatusCondition(&etp.Status.Conditions, ready)
+		etp.Status.ObservedGeneration = etp.Generation
+
+		return r.Status().Patch(ctx, &etp, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}))
+	})
+	if errors.IsNotFound(err) {
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	reportStatus(ctx, notifier.StatusReport{Name: etp.Name, Spec: etp.Spec, Status: etp.Status})
+
+	for _, outcome := range outcomes {
+		if outcome.err != nil {
+			return ctrl.Result{}, outcome.err
+		}
+	}
+
+	return result, nil
+}
+
+// triggerPending triggers the workflows of the passed evaluations which weren't triggered yet, once per evaluation.
+// The debounce window, cooldown period and max concurrent runs of the policy are enforced before triggering, the
+// returned duration is the time until a debounced workflow may be triggered
+func (r *EventTrackerPolicyReconciler) triggerPending(ctx context.Context, etp *eventtrackerv1.EventTrackerPolicy) (map[string]triggerOutcome, time.Duration, error) {
+	outcomes := map[string]triggerOutcome{}
+	var wait time.Duration
+
+	var workflowIDs []string
+	pending := map[string][]eventtrackerv1.PolicyEvaluation{}
+	for _, evaluation := range etp.Status.Evaluations {
+		if evaluation.Result != ConditionPassed || strings.ToLower(evaluation.IsTriggered) != "false" || evaluation.SkipReason != "" {
+			continue
+		}
+		if _, ok := pending[evaluation.WorkflowID]; !ok {
+			workflowIDs = append(workflowIDs, evaluation.WorkflowID)
+		}
+		pending[evaluation.WorkflowID] = append(pending[evaluation.WorkflowID], evaluation)
+	}
+	if len(workflowIDs) == 0 {
+		return outcomes, 0, nil
+	}
+
+	notifierClient, err := utils.NewNotifierClient()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now()
+	var lastTrigger time.Time
+	if etp.Status.LastTriggerTime != nil {
+		lastTrigger = etp.Status.LastTriggerTime.Time
+	}
+
+	for _, workflowID := range workflowIDs {
+		evaluations := pending[workflowID]
+
+		// only the latest evaluation within the debounce window triggers the workflow
+		if etp.Spec.DebounceWindow != nil && etp.Spec.DebounceWindow.Duration > 0 {
+			latest := evaluations[len(evaluations)-1]
+			if evaluatedAt, err := time.Parse(time.RFC850, latest.TimeStamp); err == nil {
+				if remaining := evaluatedAt.Add(etp.Spec.DebounceWindow.Duration).Sub(now); remaining > 0 {
+					if wait == 0 || remaining < wait {
+						wait = remaining
+					}
+					continue
+				}
 			}
-			log.Print(response)
+			for _, evaluation := range evaluations[:len(evaluations)-1] {
+				outcomes[evaluation.ID] = triggerOutcome{skipReason: eventtrackerv1.SkipReasonDebounced}
+			}
+			evaluations = evaluations[len(evaluations)-1:]
+		}
 
-			var res Response
-			json.Unmarshal([]byte(response), &res)
+		triggered := 0
+		for _, evaluation := range evaluations {
+			if etp.Spec.CooldownPeriod != nil && !lastTrigger.IsZero() && now.Sub(lastTrigger) < etp.Spec.CooldownPeriod.Duration {
+				log.Print("Skipping workflow ", workflowID, " of ", etp.Name, " : cooldown period")
+				outcomes[evaluation.ID] = triggerOutcome{skipReason: eventtrackerv1.SkipReasonCooldown}
+				continue
+			}
+
+			if etp.Spec.MaxConcurrentRuns != nil {
+				running, err := utils.RunningWorkflows(ctx, workflowID)
+				if err != nil {
+					outcomes[evaluation.ID] = triggerOutcome{err: err}
+					continue
+				}
+				if running+triggered >= int(*etp.Spec.MaxConcurrentRuns) {
+					log.Print("Skipping workflow ", workflowID, " of ", etp.Name, " : ", running, " runs in progress")
+					outcomes[evaluation.ID] = triggerOutcome{skipReason: eventtrackerv1.SkipReasonMaxConcurrentRuns}
+					continue
+				}
+			}
 
-			if res.Data.GitopsNotifer == "Gitops Disabled" {
-				etp.Statuses[index].IsTriggered = "false"
+			log.Print("ResourceName: " + evaluation.ResourceName + " WorkflowID: " + evaluation.WorkflowID)
+			response, err := notifierClient.TriggerWorkflow(ctx, evaluation.WorkflowID)
+			if err != nil {
+				log.Print("Failed to trigger workflow ", evaluation.WorkflowID, " : ", err)
 			} else {
-				etp.Statuses[index].IsTriggered = "true"
+				log.Print(response.Status + ": " + response.Message)
+				if response.Status != notifier.StatusGitOpsDisabled {
+					lastTrigger = now
+					triggered++
+				}
 			}
+			outcomes[evaluation.ID] = triggerOutcome{response: response, err: err}
 		}
 	}
 
-	err = r.Client.Update(context.Background(), &etp)
+	return outcomes, wait, nil
+}
+
+// reportStatus sends the policy to the server, so that its status is shown in the portal. Failed reports are only
+// logged, the policy is reported again on its next reconcile
+func reportStatus(ctx context.Context, report notifier.StatusReport) {
+	notifierClient, err := utils.NewNotifierClient()
+	if err == nil {
+		err = notifierClient.ReportStatus(ctx, report)
+	}
 	if err != nil {
-		return ctrl.Result{}, err
+		log.Print("Failed to report the status of ", report.Name, " : ", err)
+	}
+}
+
+// applyOutcome marks the evaluation as triggered or skipped and records the trigger in the LastTriggered condition.
+// Evaluations whose trigger failed or whose workflow has GitOps disabled are retried on the next reconcile
+func applyOutcome(etp *eventtrackerv1.EventTrackerPolicy, index int, outcome triggerOutcome) {
+	condition := metav1.Condition{
+		Type:               eventtrackerv1.ConditionLastTriggered,
+		ObservedGeneration: etp.Generation,
+	}
+
+	if outcome.skipReason != "" {
+		etp.Status.Evaluations[index].SkipReason = outcome.skipReason
+		etp.Status.SkippedCount++
+		return
 	}
 
-	defer mutex.Unlock()
+	switch {
+	case outcome.err != nil:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonTriggerFailed
+		condition.Message = outcome.err.Error()
+	case outcome.response.Status == notifier.StatusGitOpsDisabled:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonGitOpsDisabled
+		condition.Message = outcome.response.Message
+	default:
+		now := metav1.Now()
+		etp.Status.Evaluations[index].IsTriggered = "true"
+		etp.Status.TriggeredCount++
+		etp.Status.LastTriggerTime = &now
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = ReasonTriggered
+		if outcome.response.Status == notifier.StatusSkipped {
+			condition.Reason = ReasonSkipped
+		}
+		condition.Message = outcome.response.Message
+	}
+	if condition.Message == "" {
+		condition.Message = "workflow " + etp.Status.Evaluations[index].WorkflowID
+	}
 
-	return ctrl.Result{}, nil
+	meta.SetStatusCondition(&etp.Status.Conditions, condition)
 }
 
 func (r *EventTrackerPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
@@ -19,13 +19,13 @@ package main
 import (
 	"flag"
 	"log"
+	"net/http"
 	"os"
 	"time"
 
 	"github.com/litmuschaos/litmus/litmus-portal/cluster-agents/event-tracker/pkg/k8s"
 	"github.com/litmuschaos/litmus/litmus-portal/cluster-agents/event-tracker/pkg/utils"
 	"github.com/sirupsen/logrus"
-	"k8s.io/client-go/informers"
 
 	rt "runtime"
 
@@ -41,8 +41,9 @@ import (
 )
 
 var (
-	scheme   = runtime.NewScheme()
-	setupLog = ctrl.Log.WithName("setup")
+	scheme          = runtime.NewScheme()
+	setupLog        = ctrl.Log.WithName("setup")
+	informerManager *utils.InformerManager
 )
 
 func init() {
@@ -59,23 +60,32 @@ func init() {
 	_ = eventtrackerv1.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
 
-	clientset, err := k8s.K8sClient()
+	restConfig, err := k8s.GetKubeConfig()
 	if err != nil {
-		log.Print(err)
+		log.Fatal(err)
 	}
 
-	factory := informers.NewSharedInformerFactory(clientset, 30*time.Second)
+	informerManager, err = utils.NewInformerManager(restConfig, 30*time.Second)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	go utils.RunDeploymentInformer(factory)
-	go utils.RunDSInformer(factory)
-	go utils.RunStsInformer(factory)
+	// kinds selected by policies are watched once the policies are reconciled, the default resources are their own
+	// owners so that they are watched even without policies
+	for _, gvk := range utils.DefaultResources {
+		if err := informerManager.Watch(gvk.String(), gvk); err != nil {
+			log.Print(err)
+		}
+	}
 }
 
 func main() {
 
 	var metricsAddr string
+	var alertsAddr string
 	var enableLeaderElection bool
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	flag.StringVar(&alertsAddr, "alerts-addr", ":8081", "The address the Alertmanager webhook receiver binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -96,15 +106,32 @@ func main() {
 	}
 
 	if err = (&controllers.EventTrackerPolicyReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("EventTrackerPolicy"),
-		Scheme: mgr.GetScheme(),
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("EventTrackerPolicy"),
+		Scheme:    mgr.GetScheme(),
+		Informers: informerManager,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "EventTrackerPolicy")
 		os.Exit(1)
 	}
 	// +kubebuilder:scaffold:builder
 
+	// alert policies are evaluated against the alerts Alertmanager sends to the webhook receiver, the receiver
+	// only runs when its token is configured
+	if utils.AlertWebhookToken == "" {
+		setupLog.Info("alertmanager webhook receiver disabled, create the event-tracker-alert-token secret to enable it")
+	} else {
+		go func() {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/alerts", informerManager.AlertWebhookHandler)
+			setupLog.Info("starting alertmanager webhook receiver", "addr", alertsAddr)
+			if err := http.ListenAndServe(alertsAddr, mux); err != nil {
+				setupLog.Error(err, "problem running alertmanager webhook receiver")
+				os.Exit(1)
+			}
+		}()
+	}
+
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
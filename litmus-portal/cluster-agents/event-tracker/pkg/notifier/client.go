@@ -0,0 +1,185 @@
+// Package notifier is the client of the gitops trigger and policy status endpoints of the litmus server
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Statuses returned by the gitops trigger endpoint
+const (
+	StatusAccepted       = "accepted"
+	StatusTriggered      = "triggered"
+	StatusSkipped        = "skipped"
+	StatusGitOpsDisabled = "gitops_disabled"
+)
+
+const (
+	triggerPath        = "/gitops/trigger"
+	statusPath         = "/eventtracker/status"
+	defaultTimeout     = 10 * time.Second
+	defaultMaxRetries  = 3
+	defaultBackoff     = time.Second
+	maxResponseSize    = 1 << 16
+	graphqlQuerySuffix = "/query"
+)
+
+// TriggerRequest is the body of a gitops trigger request
+type TriggerRequest struct {
+	WorkflowID string `json:"workflow_id"`
+}
+
+// TriggerResponse is the body of a gitops trigger response
+type TriggerResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Error   string `json:"error"`
+}
+
+// StatusReport is the body of a policy status report, Spec and Status are the spec and status of the policy
+type StatusReport struct {
+	Name    string      `json:"name"`
+	Spec    interface{} `json:"spec,omitempty"`
+	Status  interface{} `json:"status,omitempty"`
+	Deleted bool        `json:"deleted"`
+}
+
+// StatusError is returned when the server rejects a request
+type StatusError struct {
+	Code    int
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return "request failed with status " + strconv.Itoa(e.Code) + " : " + e.Message
+}
+
+// Client sends gitops notifications to the litmus server, authenticated with the notifier token of the agent
+type Client struct {
+	URL        string
+	StatusURL  string
+	Token      string
+	HTTPClient *http.Client
+	MaxRetries int
+	Backoff    time.Duration
+}
+
+// NewClient returns a client for the server at serverAddr, which may be the address of the graphql endpoint
+func NewClient(serverAddr, token string) *Client {
+	return &Client{
+		URL:        TriggerURL(serverAddr),
+		StatusURL:  StatusURL(serverAddr),
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: defaultTimeout},
+		MaxRetries: defaultMaxRetries,
+		Backoff:    defaultBackoff,
+	}
+}
+
+// TriggerURL returns the address of the gitops trigger endpoint of the server
+func TriggerURL(serverAddr string) string {
+	serverAddr = strings.TrimSuffix(serverAddr, "/")
+	return strings.TrimSuffix(serverAddr, graphqlQuerySuffix) + triggerPath
+}
+
+// StatusURL returns the address of the policy status endpoint of the server
+func StatusURL(serverAddr string) string {
+	serverAddr = strings.TrimSuffix(serverAddr, "/")
+	return strings.TrimSuffix(serverAddr, graphqlQuerySuffix) + statusPath
+}
+
+// TriggerWorkflow asks the server to run the workflow, the server accepts the run before evaluating its steady state
+// gates. Network failures and server errors are retried with an exponential backoff, requests rejected by the server
+// are not
+func (c *Client) TriggerWorkflow(ctx context.Context, workflowID string) (*TriggerResponse, error) {
+	body, err := json.Marshal(TriggerRequest{WorkflowID: workflowID})
+	if err != nil {
+		return nil, err
+	}
+	return c.sendWithRetries(ctx, c.URL, body)
+}
+
+// ReportStatus sends the spec and status of a policy to the server, deleted policies are reported with Deleted set
+func (c *Client) ReportStatus(ctx context.Context, report StatusReport) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	_, err = c.sendWithRetries(ctx, c.StatusURL, body)
+	return err
+}
+
+func (c *Client) sendWithRetries(ctx context.Context, url string, body []byte) (*TriggerResponse, error) {
+	backoff := c.Backoff
+	for attempt := 0; ; attempt++ {
+		response, err := c.send(ctx, url, body)
+		if err == nil || !retryable(err) || attempt >= c.MaxRetries {
+			return response, err
+		}
+
+		logrus.WithError(err).Warn("request to ", url, " failed, retrying in ", backoff)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (c *Client) send(ctx context.Context, url string, body []byte) (*TriggerResponse, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+
+	var response TriggerResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		if !succeeded(resp.StatusCode) {
+			return nil, &StatusError{Code: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		}
+		return nil, errors.New("invalid response of " + url + " : " + err.Error())
+	}
+	if !succeeded(resp.StatusCode) {
+		return nil, &StatusError{Code: resp.StatusCode, Message: response.Error}
+	}
+	return &response, nil
+}
+
+// succeeded reports whether the server handled the request, triggered runs are accepted before they are dispatched
+func succeeded(code int) bool {
+	return code == http.StatusOK || code == http.StatusAccepted
+}
+
+// retryable reports whether a failed request may succeed when sent again
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code >= http.StatusInternalServerError || statusErr.Code == http.StatusTooManyRequests
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
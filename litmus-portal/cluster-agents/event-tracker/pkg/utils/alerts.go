@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	litmuschaosv1 "github.com/litmuschaos/litmus/litmus-portal/cluster-agents/event-tracker/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	alertStatusFiring   = "firing"
+	maxAlertPayloadSize = 1 << 20
+)
+
+// AlertWebhookToken authenticates Alertmanager with the webhook receiver, Alertmanager sends it through the
+// authorization of the http_config of the webhook. The receiver rejects every request when it isn't set
+var AlertWebhookToken = os.Getenv("ALERT_WEBHOOK_TOKEN")
+
+// AlertmanagerPayload is the body of an Alertmanager webhook notification
+type AlertmanagerPayload struct {
+	Version           string            `json:"version"`
+	GroupKey          string            `json:"groupKey"`
+	Status            string            `json:"status"`
+	Receiver          string            `json:"receiver"`
+	GroupLabels       map[string]string `json:"groupLabels"`
+	CommonLabels      map[string]string `json:"commonLabels"`
+	CommonAnnotations map[string]string `json:"commonAnnotations"`
+	ExternalURL       string            `json:"externalURL"`
+	Alerts            []Alert           `json:"alerts"`
+}
+
+// Alert is an alert of an Alertmanager notification, alert policies are evaluated against its JSON
+type Alert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     string            `json:"startsAt"`
+	EndsAt       string            `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// AlertWebhookHandler is an Alertmanager compatible webhook receiver, the firing alerts of a notification are
+// evaluated against the alert policies
+func (m *InformerManager) AlertWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if AlertWebhookToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(AlertWebhookToken)) != 1 {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var payload AlertmanagerPayload
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxAlertPayloadSize)).Decode(&payload); err != nil {
+		http.Error(w, "invalid alertmanager payload : "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	policies, err := m.policies.ByNamespace(AgentNamespace).List(labels.Everything())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for _, alert := range payload.Alerts {
+		if alert.Status != alertStatusFiring {
+			continue
+		}
+		if err := AlertAuditor(alert, policies); err != nil {
+			log.Print(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// AlertAuditor evaluates the alert policies against the alert and records the result in their statuses
+func AlertAuditor(alert Alert, policies []runtime.Object) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	var alertData interface{}
+	if err := json.Unmarshal(data, &alertData); err != nil {
+		return err
+	}
+
+	return auditPolicies(litmuschaosv1.SourceAlert, policies, func(etp litmuschaosv1.EventTrackerPolicy) ([]string, error) {
+		if len(etp.Spec.Namespaces) > 0 && !containsString(etp.Spec.Namespaces, alert.Labels["namespace"]) {
+			return nil, nil
+		}
+		if etp.Spec.Selector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(etp.Spec.Selector)
+			if err != nil {
+				return nil, err
+			}
+			if !selector.Matches(labels.Set(alert.Labels)) {
+				return nil, nil
+			}
+		}
+		return etp.Spec.WorkflowIDs, nil
+	}, "Alert", alert.Labels["alertname"], alertData, nil)
+}
@@ -0,0 +1,319 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmespath/go-jmespath"
+	litmuschaosv1 "github.com/litmuschaos/litmus/litmus-portal/cluster-agents/event-tracker/api/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// Operators of the policy conditions
+const (
+	OperatorEqualTo            = "EqualTo"
+	OperatorNotEqualTo         = "NotEqualTo"
+	OperatorLessThan           = "LessThan"
+	OperatorGreaterThan        = "GreaterThan"
+	OperatorLessThanEqualTo    = "LessThanEqualTo"
+	OperatorGreaterThanEqualTo = "GreaterThanEqualTo"
+	OperatorContains           = "Contains"
+	OperatorIn                 = "In"
+	OperatorRegex              = "Regex"
+	OperatorExists             = "Exists"
+	OperatorChanged            = "Changed"
+)
+
+// Types values of the policy conditions are compared as
+const (
+	TypeString   = "string"
+	TypeNumber   = "number"
+	TypeSemver   = "semver"
+	TypeDuration = "duration"
+	TypeBoolean  = "boolean"
+)
+
+// Condition types combining the conditions of a policy or group
+const (
+	ConditionTypeAnd = "and"
+	ConditionTypeOr  = "or"
+)
+
+// conditionEvaluator evaluates the conditions of a policy against the new and, for Changed, the old resource
+type conditionEvaluator struct {
+	groups   map[string]litmuschaosv1.ConditionGroup
+	visiting map[string]bool
+	newData  interface{}
+	oldData  interface{}
+}
+
+func conditionChecker(etp litmuschaosv1.EventTrackerPolicy, newData interface{}, oldData interface{}) bool {
+	evaluator := &conditionEvaluator{
+		groups:   map[string]litmuschaosv1.ConditionGroup{},
+		visiting: map[string]bool{},
+		newData:  newData,
+		oldData:  oldData,
+	}
+	for _, group := range etp.Spec.Groups {
+		evaluator.groups[group.Name] = group
+	}
+
+	result, err := evaluator.all(etp.Spec.ConditionType, etp.Spec.Conditions)
+	if err != nil {
+		log.Print("EventTrackerPolicy ", etp.Name, " : ", err)
+		return false
+	}
+	return result
+}
+
+// all combines the conditions, an empty list of conditions is never satisfied
+func (e *conditionEvaluator) all(conditionType string, conditions []litmuschaosv1.Condition) (bool, error) {
+	if len(conditions) == 0 {
+		return false, nil
+	}
+
+	switch strings.ToLower(conditionType) {
+	case "", ConditionTypeAnd:
+		for _, condition := range conditions {
+			result, err := e.condition(condition)
+			if err != nil || !result {
+				return false, err
+			}
+		}
+		return true, nil
+	case ConditionTypeOr:
+		for _, condition := range conditions {
+			result, err := e.condition(condition)
+			if err != nil || result {
+				return result, err
+			}
+		}
+		return false, nil
+	}
+	return false, errors.New("unknown condition type " + conditionType)
+}
+
+func (e *conditionEvaluator) condition(condition litmuschaosv1.Condition) (bool, error) {
+	if condition.Group != "" {
+		return e.group(condition.Group)
+	}
+
+	result, err := jmespath.Search(condition.Key, e.newData)
+	if err != nil {
+		return false, errors.New("invalid key " + condition.Key + " : " + err.Error())
+	}
+
+	switch condition.Operator {
+	case OperatorExists:
+		expected := true
+		if condition.Value != "" {
+			if expected, err = strconv.ParseBool(condition.Value); err != nil {
+				return false, errors.New("Exists expects a boolean value : " + err.Error())
+			}
+		}
+		return (result != nil) == expected, nil
+	case OperatorChanged:
+		if e.oldData == nil {
+			return false, nil
+		}
+		oldResult, err := jmespath.Search(condition.Key, e.oldData)
+		if err != nil {
+			return false, errors.New("invalid key " + condition.Key + " : " + err.Error())
+		}
+		return !reflect.DeepEqual(normalize(result), normalize(oldResult)), nil
+	case OperatorContains:
+		return contains(result, condition.Value), nil
+	case OperatorRegex:
+		re, err := regexp.Compile(condition.Value)
+		if err != nil {
+			return false, errors.New("invalid regex " + condition.Value + " : " + err.Error())
+		}
+		return re.MatchString(stringValue(result)), nil
+	case OperatorIn:
+		values := condition.Values
+		if len(values) == 0 && condition.Value != "" {
+			values = strings.Split(condition.Value, ",")
+		}
+		for _, value := range values {
+			equal, err := compare(condition.Type, OperatorEqualTo, stringValue(result), strings.TrimSpace(value))
+			if err != nil {
+				return false, err
+			}
+			if equal {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	// the comparison operators don't match missing values
+	if result == nil {
+		return false, nil
+	}
+	return compare(condition.Type, condition.Operator, stringValue(result), condition.Value)
+}
+
+// group evaluates a named condition group, groups referring to themselves are rejected
+func (e *conditionEvaluator) group(name string) (bool, error) {
+	group, ok := e.groups[name]
+	if !ok {
+		return false, errors.New("unknown condition group " + name)
+	}
+	if e.visiting[name] {
+		return false, errors.New("condition group " + name + " refers to itself")
+	}
+
+	e.visiting[name] = true
+	defer delete(e.visiting, name)
+	return e.all(group.ConditionType, group.Conditions)
+}
+
+// compare compares the actual value with the expected value as the given type
+func compare(valueType string, operator string, actual string, expected string) (bool, error) {
+	if valueType == "" {
+		valueType = TypeString
+		if isNumber(actual) && isNumber(expected) {
+			valueType = TypeNumber
+		}
+	}
+
+	var cmp int
+	switch strings.ToLower(valueType) {
+	case TypeString:
+		cmp = strings.Compare(actual, expected)
+	case TypeNumber:
+		a, err := strconv.ParseFloat(actual, 64)
+		if err != nil {
+			return false, errors.New(actual + " is not a number")
+		}
+		b, err := strconv.ParseFloat(expected, 64)
+		if err != nil {
+			return false, errors.New(expected + " is not a number")
+		}
+		cmp = compareFloat(a, b)
+	case TypeSemver:
+		a, err := version.ParseGeneric(actual)
+		if err != nil {
+			return false, errors.New(actual + " is not a version : " + err.Error())
+		}
+		if cmp, err = a.Compare(expected); err != nil {
+			return false, errors.New(expected + " is not a version : " + err.Error())
+		}
+	case TypeDuration:
+		a, err := time.ParseDuration(actual)
+		if err != nil {
+			return false, errors.New(actual + " is not a duration : " + err.Error())
+		}
+		b, err := time.ParseDuration(expected)
+		if err != nil {
+			return false, errors.New(expected + " is not a duration : " + err.Error())
+		}
+		cmp = compareFloat(float64(a), float64(b))
+	case TypeBoolean:
+		a, err := strconv.ParseBool(actual)
+		if err != nil {
+			return false, errors.New(actual + " is not a boolean")
+		}
+		b, err := strconv.ParseBool(expected)
+		if err != nil {
+			return false, errors.New(expected + " is not a boolean")
+		}
+		if operator != OperatorEqualTo && operator != OperatorNotEqualTo {
+			return false, errors.New("booleans only support the EqualTo and NotEqualTo operators")
+		}
+		cmp = 1
+		if a == b {
+			cmp = 0
+		}
+	default:
+		return false, errors.New("unknown type " + valueType)
+	}
+
+	switch operator {
+	case OperatorEqualTo:
+		return cmp == 0, nil
+	case OperatorNotEqualTo:
+		return cmp != 0, nil
+	case OperatorLessThan:
+		return cmp < 0, nil
+	case OperatorGreaterThan:
+		return cmp > 0, nil
+	case OperatorLessThanEqualTo:
+		return cmp <= 0, nil
+	case OperatorGreaterThanEqualTo:
+		return cmp >= 0, nil
+	}
+	return false, errors.New("unknown operator " + operator)
+}
+
+// contains reports whether a list holds the value, a map has the value as key or a string contains the value
+func contains(result interface{}, value string) bool {
+	switch res := result.(type) {
+	case []interface{}:
+		for _, item := range res {
+			if stringValue(item) == value {
+				return true
+			}
+		}
+		return false
+	case map[string]interface{}:
+		_, ok := res[value]
+		return ok
+	case nil:
+		return false
+	}
+	return strings.Contains(stringValue(result), value)
+}
+
+// stringValue returns the value of a JMESPath result as a string, lists and maps are JSON encoded
+func stringValue(result interface{}) string {
+	switch res := result.(type) {
+	case nil:
+		return ""
+	case string:
+		return res
+	case float64:
+		// numbers of unstructured objects are float64, large ones would otherwise be formatted with an exponent
+		return strconv.FormatFloat(res, 'f', -1, 64)
+	case []interface{}, map[string]interface{}:
+		data, err := json.Marshal(res)
+		if err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprintf("%v", result)
+}
+
+// normalize makes numbers of typed and unstructured objects comparable
+func normalize(result interface{}) interface{} {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return result
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return result
+	}
+	return normalized
+}
+
+func isNumber(value string) bool {
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil
+}
+
+func compareFloat(a, b float64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"testing"
+
+	litmuschaosv1 "github.com/litmuschaos/litmus/litmus-portal/cluster-agents/event-tracker/api/v1"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name      string
+		valueType string
+		operator  string
+		actual    string
+		expected  string
+		want      bool
+		wantErr   bool
+	}{
+		{name: "numbers are compared numerically", operator: OperatorLessThan, actual: "9", expected: "10", want: true},
+		{name: "numeric strings are not compared lexically", operator: OperatorLessThan, actual: "10", expected: "9", want: false},
+		{name: "strings are compared lexically", valueType: TypeString, operator: OperatorLessThan, actual: "10", expected: "9", want: true},
+		{name: "mixed values fall back to strings", operator: OperatorEqualTo, actual: "10", expected: "ten", want: false},
+		{name: "float equality", operator: OperatorEqualTo, actual: "1.50", expected: "1.5", want: true},
+		{name: "greater than or equal", operator: OperatorGreaterThanEqualTo, actual: "3", expected: "3", want: true},
+		{name: "not equal", operator: OperatorNotEqualTo, actual: "a", expected: "b", want: true},
+		{name: "invalid number", valueType: TypeNumber, operator: OperatorEqualTo, actual: "a", expected: "1", wantErr: true},
+		{name: "semver", valueType: TypeSemver, operator: OperatorLessThan, actual: "1.9.0", expected: "1.10.0", want: true},
+		{name: "semver with prefix", valueType: TypeSemver, operator: OperatorGreaterThan, actual: "v2.0.1", expected: "2.0.0", want: true},
+		{name: "invalid semver", valueType: TypeSemver, operator: OperatorLessThan, actual: "latest", expected: "1.0.0", wantErr: true},
+		{name: "duration", valueType: TypeDuration, operator: OperatorGreaterThan, actual: "90s", expected: "1m", want: true},
+		{name: "boolean", valueType: TypeBoolean, operator: OperatorEqualTo, actual: "True", expected: "true", want: true},
+		{name: "boolean ordering", valueType: TypeBoolean, operator: OperatorLessThan, actual: "false", expected: "true", wantErr: true},
+		{name: "unknown type", valueType: "date", operator: OperatorEqualTo, actual: "a", expected: "a", wantErr: true},
+		{name: "unknown operator", operator: "Like", actual: "a", expected: "a", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := compare(tt.valueType, tt.operator, tt.actual, tt.expected)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("compare() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("compare() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringValue(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{value: nil, want: ""},
+		{value: "text", want: "text"},
+		{value: float64(3), want: "3"},
+		{value: float64(1234567), want: "1234567"},
+		{value: 2.5, want: "2.5"},
+		{value: true, want: "true"},
+		{value: []interface{}{"a", float64(1)}, want: `["a",1]`},
+		{value: map[string]interface{}{"k": "v"}, want: `{"k":"v"}`},
+	}
+	for _, tt := range tests {
+		if got := stringValue(tt.value); got != tt.want {
+			t.Errorf("stringValue(%v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestConditionChecker(t *testing.T) {
+	newData := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{"app": "nginx"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": float64(10),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"image": "nginx:1.21"},
+					},
+				},
+			},
+		},
+	}
+	oldData := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": float64(9),
+		},
+	}
+	replicas := litmuschaosv1.Condition{Key: "spec.replicas", Operator: OperatorGreaterThan, Value: "9"}
+	nginx := litmuschaosv1.Condition{Key: "metadata.labels.app", Operator: OperatorEqualTo, Value: "nginx"}
+	missing := litmuschaosv1.Condition{Key: "metadata.labels.tier", Operator: OperatorEqualTo, Value: "web"}
+	invalid := litmuschaosv1.Condition{Key: "spec.replicas", Operator: OperatorLessThan, Value: "1.0", Type: TypeSemver}
+
+	tests := []struct {
+		name string
+		spec litmuschaosv1.EventTrackerPolicySpec
+		want bool
+	}{
+		{name: "no conditions", spec: litmuschaosv1.EventTrackerPolicySpec{}, want: false},
+		{name: "and", spec: litmuschaosv1.EventTrackerPolicySpec{Conditions: []litmuschaosv1.Condition{replicas, nginx}}, want: true},
+		{name: "and with a failing condition", spec: litmuschaosv1.EventTrackerPolicySpec{Conditions: []litmuschaosv1.Condition{replicas, missing}}, want: false},
+		{name: "or", spec: litmuschaosv1.EventTrackerPolicySpec{ConditionType: ConditionTypeOr, Conditions: []litmuschaosv1.Condition{missing, nginx}}, want: true},
+		{name: "or with an invalid condition", spec: litmuschaosv1.EventTrackerPolicySpec{ConditionType: ConditionTypeOr, Conditions: []litmuschaosv1.Condition{invalid, nginx}}, want: false},
+		{name: "unknown condition type", spec: litmuschaosv1.EventTrackerPolicySpec{ConditionType: "xor", Conditions: []litmuschaosv1.Condition{nginx}}, want: false},
+		{name: "changed", spec: litmuschaosv1.EventTrackerPolicySpec{Conditions: []litmuschaosv1.Condition{{Key: "spec.replicas", Operator: OperatorChanged}}}, want: true},
+		{name: "exists", spec: litmuschaosv1.EventTrackerPolicySpec{Conditions: []litmuschaosv1.Condition{{Key: "metadata.labels.tier", Operator: OperatorExists, Value: "false"}}}, want: true},
+		{name: "contains", spec: litmuschaosv1.EventTrackerPolicySpec{Conditions: []litmuschaosv1.Condition{{Key: "spec.template.spec.containers[0].image", Operator: OperatorContains, Value: "nginx"}}}, want: true},
+		{name: "regex", spec: litmuschaosv1.EventTrackerPolicySpec{Conditions: []litmuschaosv1.Condition{{Key: "spec.template.spec.containers[0].image", Operator: OperatorRegex, Value: `^nginx:1\.2[0-9]$`}}}, want: true},
+		{name: "in", spec: litmuschaosv1.EventTrackerPolicySpec{Conditions: []litmuschaosv1.Condition{{Key: "metadata.labels.app", Operator: OperatorIn, Values: []string{"redis", "nginx"}}}}, want: true},
+		{name: "missing values don't match comparisons", spec: litmuschaosv1.EventTrackerPolicySpec{Conditions: []litmuschaosv1.Condition{{Key: "spec.paused", Operator: OperatorNotEqualTo, Value: "true"}}}, want: false},
+		{
+			name: "group",
+			spec: litmuschaosv1.EventTrackerPolicySpec{
+				Conditions: []litmuschaosv1.Condition{replicas, {Group: "labels"}},
+				Groups:     []litmuschaosv1.ConditionGroup{{Name: "labels", ConditionType: ConditionTypeOr, Conditions: []litmuschaosv1.Condition{missing, nginx}}},
+			},
+			want: true,
+		},
+		{
+			name: "nested groups",
+			spec: litmuschaosv1.EventTrackerPolicySpec{
+				Conditions: []litmuschaosv1.Condition{{Group: "outer"}},
+				Groups: []litmuschaosv1.ConditionGroup{
+					{Name: "outer", Conditions: []litmuschaosv1.Condition{replicas, {Group: "inner"}}},
+					{Name: "inner", Conditions: []litmuschaosv1.Condition{missing}},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "unknown group",
+			spec: litmuschaosv1.EventTrackerPolicySpec{ConditionType: ConditionTypeOr, Conditions: []litmuschaosv1.Condition{{Group: "missing"}, nginx}},
+			want: false,
+		},
+		{
+			name: "recursive group",
+			spec: litmuschaosv1.EventTrackerPolicySpec{
+				Conditions: []litmuschaosv1.Condition{{Group: "loop"}},
+				Groups:     []litmuschaosv1.ConditionGroup{{Name: "loop", ConditionType: ConditionTypeOr, Conditions: []litmuschaosv1.Condition{{Group: "loop"}, nginx}}},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			etp := litmuschaosv1.EventTrackerPolicy{Spec: tt.spec}
+			etp.Name = tt.name
+			if got := conditionChecker(etp, newData, oldData); got != tt.want {
+				t.Errorf("conditionChecker() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	litmuschaosv1 "github.com/litmuschaos/litmus/litmus-portal/cluster-agents/event-tracker/api/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// liveIgnoredPaths are the fields the live diff always ignores, they change without the resource being changed
+var liveIgnoredPaths = [][]string{
+	{"status"},
+	{"metadata", "managedFields"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "generation"},
+	{"metadata", "selfLink"},
+	{"metadata", "uid"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "annotations", lastAppliedAnnotation},
+}
+
+// resourceChanged reports whether the resource changed in a way the policy is evaluated for
+func resourceChanged(etp litmuschaosv1.EventTrackerPolicy, oldObj *unstructured.Unstructured, newObj *unstructured.Unstructured) (bool, error) {
+	if oldObj == nil || newObj == nil {
+		return false, nil
+	}
+
+	var oldData, newData interface{}
+	switch etp.Spec.DiffMode {
+	case "", litmuschaosv1.DiffModeLastApplied:
+		oldManifest := oldObj.GetAnnotations()[lastAppliedAnnotation]
+		newManifest := newObj.GetAnnotations()[lastAppliedAnnotation]
+		if oldManifest == "" || newManifest == "" {
+			return false, nil
+		}
+		if err := json.Unmarshal([]byte(oldManifest), &oldData); err != nil {
+			return false, err
+		}
+		if err := json.Unmarshal([]byte(newManifest), &newData); err != nil {
+			return false, err
+		}
+	case litmuschaosv1.DiffModeLive:
+		oldData = runtime.DeepCopyJSON(oldObj.Object)
+		newData = runtime.DeepCopyJSON(newObj.Object)
+		for _, path := range liveIgnoredPaths {
+			removePath(oldData, path)
+			removePath(newData, path)
+		}
+	default:
+		return false, nil
+	}
+
+	for _, path := range etp.Spec.IgnorePaths {
+		fields := splitPath(path)
+		removePath(oldData, fields)
+		removePath(newData, fields)
+	}
+
+	return !reflect.DeepEqual(oldData, newData), nil
+}
+
+// splitPath splits a dot separated path, dots escaped with a backslash are part of the field name
+func splitPath(path string) []string {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+
+	var fields []string
+	var field strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && i+1 < len(path) && path[i+1] == '.':
+			field.WriteByte('.')
+			i++
+		case path[i] == '.':
+			fields = append(fields, field.String())
+			field.Reset()
+		default:
+			field.WriteByte(path[i])
+		}
+	}
+	return append(fields, field.String())
+}
+
+// removePath deletes the field at the path, list elements are selected by their index or a * matching any of them
+func removePath(data interface{}, path []string) {
+	if len(path) == 0 {
+		return
+	}
+
+	switch value := data.(type) {
+	case map[string]interface{}:
+		if path[0] == "*" {
+			for key := range value {
+				if len(path) == 1 {
+					delete(value, key)
+				} else {
+					removePath(value[key], path[1:])
+				}
+			}
+			return
+		}
+		if len(path) == 1 {
+			delete(value, path[0])
+			return
+		}
+		removePath(value[path[0]], path[1:])
+	case []interface{}:
+		// list elements can't be removed in place, whole lists are ignored through their parent
+		if len(path) == 1 {
+			return
+		}
+		if path[0] == "*" {
+			for _, item := range value {
+				removePath(item, path[1:])
+			}
+			return
+		}
+		if index, err := strconv.Atoi(path[0]); err == nil && index >= 0 && index < len(value) {
+			removePath(value[index], path[1:])
+		}
+	}
+}
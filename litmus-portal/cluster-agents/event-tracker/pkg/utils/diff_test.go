@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	litmuschaosv1 "github.com/litmuschaos/litmus/litmus-portal/cluster-agents/event-tracker/api/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSplitPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{path: "spec.replicas", want: []string{"spec", "replicas"}},
+		{path: "$.spec.replicas", want: []string{"spec", "replicas"}},
+		{path: ".status", want: []string{"status"}},
+		{path: `metadata.annotations.example\.com/hash`, want: []string{"metadata", "annotations", "example.com/hash"}},
+		{path: "spec.containers.*.image", want: []string{"spec", "containers", "*", "image"}},
+		{path: `a\b`, want: []string{`a\b`}},
+	}
+	for _, tt := range tests {
+		if got := splitPath(tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRemovePath(t *testing.T) {
+	newData := func() map[string]interface{} {
+		return map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{"a": "1", "b": "2"},
+			},
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "a", "image": "a:1"},
+					map[string]interface{}{"name": "b", "image": "b:1"},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name string
+		path []string
+		want map[string]interface{}
+	}{
+		{
+			name: "map field",
+			path: []string{"metadata", "annotations", "a"},
+			want: map[string]interface{}{
+				"metadata": map[string]interface{}{"annotations": map[string]interface{}{"b": "2"}},
+				"spec":     newData()["spec"],
+			},
+		},
+		{
+			name: "every map field",
+			path: []string{"metadata", "annotations", "*"},
+			want: map[string]interface{}{
+				"metadata": map[string]interface{}{"annotations": map[string]interface{}{}},
+				"spec":     newData()["spec"],
+			},
+		},
+		{
+			name: "list element by index",
+			path: []string{"spec", "containers", "1", "image"},
+			want: map[string]interface{}{
+				"metadata": newData()["metadata"],
+				"spec": map[string]interface{}{"containers": []interface{}{
+					map[string]interface{}{"name": "a", "image": "a:1"},
+					map[string]interface{}{"name": "b"},
+				}},
+			},
+		},
+		{
+			name: "every list element",
+			path: []string{"spec", "containers", "*", "image"},
+			want: map[string]interface{}{
+				"metadata": newData()["metadata"],
+				"spec": map[string]interface{}{"containers": []interface{}{
+					map[string]interface{}{"name": "a"},
+					map[string]interface{}{"name": "b"},
+				}},
+			},
+		},
+		{name: "list elements aren't removed", path: []string{"spec", "containers", "0"}, want: newData()},
+		{name: "index out of range", path: []string{"spec", "containers", "5", "image"}, want: newData()},
+		{name: "missing field", path: []string{"status", "replicas"}, want: newData()},
+		{name: "empty path", path: nil, want: newData()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := newData()
+			removePath(data, tt.path)
+			if !reflect.DeepEqual(data, tt.want) {
+				t.Errorf("removePath(%q) = %v, want %v", tt.path, data, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceChanged(t *testing.T) {
+	resource := func(replicas int64, image string, resourceVersion string, lastApplied string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":            "nginx",
+				"resourceVersion": resourceVersion,
+			},
+			"spec": map[string]interface{}{
+				"replicas": replicas,
+				"template": map[string]interface{}{"image": image},
+			},
+			"status": map[string]interface{}{"readyReplicas": replicas},
+		}}
+		if lastApplied != "" {
+			obj.SetAnnotations(map[string]string{lastAppliedAnnotation: lastApplied})
+		}
+		return obj
+	}
+	applied := `{"spec":{"replicas":1,"template":{"image":"nginx:1"}}}`
+	scaled := `{"spec":{"replicas":2,"template":{"image":"nginx:1"}}}`
+
+	tests := []struct {
+		name        string
+		diffMode    string
+		ignorePaths []string
+		oldObj      *unstructured.Unstructured
+		newObj      *unstructured.Unstructured
+		want        bool
+	}{
+		{name: "last applied unchanged", oldObj: resource(1, "nginx:1", "1", applied), newObj: resource(3, "nginx:1", "2", applied), want: false},
+		{name: "last applied changed", oldObj: resource(1, "nginx:1", "1", applied), newObj: resource(2, "nginx:1", "2", scaled), want: true},
+		{name: "last applied missing", oldObj: resource(1, "nginx:1", "1", ""), newObj: resource(2, "nginx:1", "2", ""), want: false},
+		{name: "last applied ignored path", ignorePaths: []string{"spec.replicas"}, oldObj: resource(1, "nginx:1", "1", applied), newObj: resource(2, "nginx:1", "2", scaled), want: false},
+		{name: "live status only", diffMode: litmuschaosv1.DiffModeLive, oldObj: resource(1, "nginx:1", "1", ""), newObj: func() *unstructured.Unstructured {
+			obj := resource(1, "nginx:1", "2", "")
+			obj.Object["status"] = map[string]interface{}{"readyReplicas": int64(0)}
+			return obj
+		}(), want: false},
+		{name: "live spec", diffMode: litmuschaosv1.DiffModeLive, oldObj: resource(1, "nginx:1", "1", ""), newObj: resource(1, "nginx:2", "2", ""), want: true},
+		{name: "live ignored path", diffMode: litmuschaosv1.DiffModeLive, ignorePaths: []string{"spec.template.image", "status"}, oldObj: resource(1, "nginx:1", "1", ""), newObj: resource(1, "nginx:2", "2", ""), want: false},
+		{name: "live last applied annotation", diffMode: litmuschaosv1.DiffModeLive, oldObj: resource(1, "nginx:1", "1", applied), newObj: resource(1, "nginx:1", "2", scaled), want: false},
+		{name: "unknown diff mode", diffMode: "Full", oldObj: resource(1, "nginx:1", "1", ""), newObj: resource(2, "nginx:2", "2", ""), want: false},
+		{name: "missing old object", newObj: resource(2, "nginx:1", "2", scaled), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			etp := litmuschaosv1.EventTrackerPolicy{Spec: litmuschaosv1.EventTrackerPolicySpec{DiffMode: tt.diffMode, IgnorePaths: tt.ignorePaths}}
+			got, err := resourceChanged(etp, tt.oldObj, tt.newObj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("resourceChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	litmuschaosv1 "github.com/litmuschaos/litmus/litmus-portal/cluster-agents/event-tracker/api/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+var eventResource = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "events"}
+
+// WatchEvents starts the informer of the Kubernetes Events evaluated by the event policies if it isn't running yet.
+// Events recorded before the informer started are ignored
+func (m *InformerManager) WatchEvents() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.watchingEvents {
+		return
+	}
+
+	started := time.Now()
+	informer := m.factory.ForResource(eventResource).Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		// When an event is recorded
+		AddFunc: func(obj interface{}) {
+			defer utilruntime.HandleCrash()
+			event, ok := obj.(*unstructured.Unstructured)
+			if !ok || eventTime(event).Before(started) {
+				return
+			}
+			m.handleEvent(event)
+		},
+		// When an event is recorded again, its count is increased
+		UpdateFunc: func(oldObj interface{}, newObj interface{}) {
+			defer utilruntime.HandleCrash()
+			oldEvent, ok := oldObj.(*unstructured.Unstructured)
+			if !ok {
+				return
+			}
+			newEvent, ok := newObj.(*unstructured.Unstructured)
+			if !ok || newEvent.GetResourceVersion() == oldEvent.GetResourceVersion() {
+				return
+			}
+			m.handleEvent(newEvent)
+		},
+	})
+	m.watchingEvents = true
+
+	go informer.Run(m.stopper)
+	go func() {
+		if !cache.WaitForCacheSync(m.stopper, informer.HasSynced) {
+			utilruntime.HandleError(fmt.Errorf("Timed out waiting for caches to sync"))
+			return
+		}
+		log.Print("Watching ", eventResource.String())
+	}()
+}
+
+func (m *InformerManager) handleEvent(event *unstructured.Unstructured) {
+	policies, err := m.policies.ByNamespace(AgentNamespace).List(labels.Everything())
+	if err != nil {
+		log.Print(err)
+		return
+	}
+
+	if err := EventAuditor(event, policies); err != nil {
+		log.Print(err)
+	}
+}
+
+// EventAuditor evaluates the event policies against the Kubernetes Event and records the result in their statuses
+func EventAuditor(event *unstructured.Unstructured, policies []runtime.Object) error {
+	return auditPolicies(litmuschaosv1.SourceEvent, policies, func(etp litmuschaosv1.EventTrackerPolicy) ([]string, error) {
+		if len(etp.Spec.Namespaces) > 0 && !containsString(etp.Spec.Namespaces, event.GetNamespace()) {
+			return nil, nil
+		}
+		return etp.Spec.WorkflowIDs, nil
+	}, event.GetKind(), event.GetName(), event.Object, nil)
+}
+
+// eventTime returns the last time the event was recorded
+func eventTime(event *unstructured.Unstructured) time.Time {
+	for _, field := range []string{"lastTimestamp", "eventTime", "firstTimestamp"} {
+		value, _, _ := unstructured.NestedString(event.Object, field)
+		if recorded, err := time.Parse(time.RFC3339, value); err == nil {
+			return recorded
+		}
+	}
+	return event.GetCreationTimestamp().Time
+}
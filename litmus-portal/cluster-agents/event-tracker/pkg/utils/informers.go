@@ -1,180 +1,209 @@
 package utils
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
-	"reflect"
+	"sync"
+	"time"
 
-	v1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/client-go/informers"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/cache"
 )
 
-// K8s informer watching for all the deployment changes
-func RunDeploymentInformer(factory informers.SharedInformerFactory) {
-	deploymentInformer := factory.Apps().V1().Deployments().Informer()
+// DefaultResources are watched from startup, policies without a resource selector are evaluated against them
+var DefaultResources = []schema.GroupVersionKind{
+	{Group: "apps", Version: "v1", Kind: "Deployment"},
+	{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+	{Group: "apps", Version: "v1", Kind: "DaemonSet"},
+}
 
-	stopper := make(chan struct{})
-	defer close(stopper)
+// InformerManager runs a dynamic informer for every kind referenced by an EventTrackerPolicy
+type InformerManager struct {
+	client    dynamic.Interface
+	namespace string
+	resync    time.Duration
+	factory   dynamicinformer.DynamicSharedInformerFactory
+	policies  cache.GenericLister
+	mapper    *restmapper.DeferredDiscoveryRESTMapper
+	stopper   chan struct{}
+	mutex     sync.Mutex
+	watches   map[schema.GroupVersionResource]*watch
+	owners    map[string]schema.GroupVersionResource
+
+	watchingEvents bool
+}
 
-	defer runtime.HandleCrash()
+// watch is the informer of a resource, it is stopped once none of its owners needs it
+type watch struct {
+	owners  int
+	stopper chan struct{}
+}
 
-	// label change -->ver (2) --> policy-replicas=1
-	deploymentInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		// When a resource gets updated
-		UpdateFunc: func(oldObj interface{}, newObj interface{}) {
-			depNewObj := newObj.(*v1.Deployment)
-			depOldObj := oldObj.(*v1.Deployment)
-
-			oldManifest := depOldObj.GetAnnotations()["kubectl.kubernetes.io/last-applied-configuration"]
-			newManifest := depNewObj.GetAnnotations()["kubectl.kubernetes.io/last-applied-configuration"]
-
-			if oldManifest != "" && newManifest != "" {
-				var oldDep v1.Deployment
-				err := json.Unmarshal([]byte(oldManifest), &oldDep)
-				if err != nil {
-					log.Print(err)
-					return
-				}
-
-				var newDep v1.Deployment
-				err = json.Unmarshal([]byte(newManifest), &newDep)
-				if err != nil {
-					log.Print(err)
-					return
-				}
-
-				if depNewObj.GetResourceVersion() != depOldObj.GetResourceVersion() && !reflect.DeepEqual(newDep, oldDep) {
-					var worflowid = depNewObj.GetAnnotations()["litmuschaos.io/workflow"]
-					if depNewObj.GetAnnotations()["litmuschaos.io/gitops"] == "true" && worflowid != "" {
-						log.Print("EventType: Update \n GitOps Notification for workflowID: %s, ResourceType: %s, ResourceName: %s, ResourceNamespace: %s", worflowid, "Deployment", depNewObj.Name, depNewObj.Namespace)
-						err := PolicyAuditor("Deployment", depNewObj, worflowid)
-						if err != nil {
-							log.Print(err)
-							return
-						}
-					}
-				}
-			}
-		},
-	})
+// NewInformerManager returns an informer manager, informers are limited to the agent namespace for namespaced agents
+func NewInformerManager(restConfig *rest.Config, resync time.Duration) (*InformerManager, error) {
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
 
-	deploymentInformer.Run(stopper)
-	if !cache.WaitForCacheSync(stopper, deploymentInformer.HasSynced) {
-		runtime.HandleError(fmt.Errorf("Timed out waiting for caches to sync"))
-		return
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, err
 	}
-}
 
-// K8s informer watching for all the Statefullset changes
-func RunStsInformer(factory informers.SharedInformerFactory) {
-	stsInformer := factory.Apps().V1().StatefulSets().Informer()
+	namespace := ""
+	if AgentScope == "namespace" {
+		namespace = AgentNamespace
+	}
 
+	// the policies are cached so that updates of resources are audited without listing them from the API server
+	policyInformer := dynamicinformer.NewFilteredDynamicInformer(dynamicClient, EventTrackerPolicyResource, AgentNamespace, resync, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, nil)
 	stopper := make(chan struct{})
-	defer close(stopper)
+	go policyInformer.Informer().Run(stopper)
+
+	return &InformerManager{
+		client:    dynamicClient,
+		namespace: namespace,
+		resync:    resync,
+		factory:   dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicClient, resync, namespace, nil),
+		policies:  policyInformer.Lister(),
+		mapper:    restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient)),
+		stopper:   stopper,
+		watches:   map[schema.GroupVersionResource]*watch{},
+		owners:    map[string]schema.GroupVersionResource{},
+	}, nil
+}
+
+// Watch watches the kind on behalf of the owner, e.g. a policy, and starts its informer if it isn't watched yet.
+// An owner watches a single kind, the kind it watched before is released
+func (m *InformerManager) Watch(owner string, gvk schema.GroupVersionKind) error {
+	gvr, err := m.resourceFor(gvk)
+	if err != nil {
+		return err
+	}
 
-	defer runtime.HandleCrash()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if current, ok := m.owners[owner]; ok {
+		if current == gvr {
+			return nil
+		}
+		m.release(owner)
+	}
+	m.owners[owner] = gvr
+	if w, ok := m.watches[gvr]; ok {
+		w.owners++
+		return nil
+	}
 
-	stsInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+	w := &watch{owners: 1, stopper: make(chan struct{})}
+	m.watches[gvr] = w
+	informer := dynamicinformer.NewFilteredDynamicInformer(m.client, gvr, m.namespace, m.resync, cache.Indexers{}, nil).Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		// When a resource gets updated
 		UpdateFunc: func(oldObj interface{}, newObj interface{}) {
-			stsNewObj := newObj.(*v1.StatefulSet)
-			stsOldObj := oldObj.(*v1.StatefulSet)
-
-			oldManifest := stsOldObj.GetAnnotations()["kubectl.kubernetes.io/last-applied-configuration"]
-			newManifest := stsNewObj.GetAnnotations()["kubectl.kubernetes.io/last-applied-configuration"]
-
-			if oldManifest != "" && newManifest != "" {
-				var oldSts v1.StatefulSet
-				err := json.Unmarshal([]byte(oldManifest), &oldSts)
-				if err != nil {
-					log.Print(err)
-					return
-				}
-
-				var newSts v1.StatefulSet
-				err = json.Unmarshal([]byte(newManifest), &newSts)
-				if err != nil {
-					log.Print(err)
-					return
-				}
-
-				if stsNewObj.GetResourceVersion() != stsOldObj.GetResourceVersion() && !reflect.DeepEqual(newSts, oldSts) {
-					var worflowid = stsNewObj.GetAnnotations()["litmuschaos.io/workflow"]
-					if stsNewObj.GetAnnotations()["litmuschaos.io/gitops"] == "true" && worflowid != "" {
-						log.Print("EventType: Update \n GitOps Notification for workflowID: %s, ResourceType: %s, ResourceName: %s, ResourceNamespace: %s", worflowid, "StateFulSet", stsNewObj.Name, stsNewObj.Namespace)
-						err := PolicyAuditor("StateFulSet", stsNewObj, worflowid)
-						if err != nil {
-							log.Print(err)
-							return
-						}
-					}
-
-				}
-			}
+			defer runtime.HandleCrash()
+			m.handleUpdate(oldObj, newObj)
 		},
 	})
 
-	stsInformer.Run(stopper)
-	if !cache.WaitForCacheSync(stopper, stsInformer.HasSynced) {
-		runtime.HandleError(fmt.Errorf("Timed out waiting for caches to sync"))
+	go informer.Run(w.stopper)
+	go func() {
+		if !cache.WaitForCacheSync(w.stopper, informer.HasSynced) {
+			runtime.HandleError(fmt.Errorf("Timed out waiting for caches to sync"))
+			return
+		}
+		log.Print("Watching ", gvr.String())
+	}()
+	return nil
+}
+
+// Unwatch releases the kind watched by the owner, its informer is stopped when no other owner watches it
+func (m *InformerManager) Unwatch(owner string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.release(owner)
+}
+
+// release drops the watch of the owner, the mutex must be held by the caller
+func (m *InformerManager) release(owner string) {
+	gvr, ok := m.owners[owner]
+	if !ok {
+		return
+	}
+	delete(m.owners, owner)
+
+	w := m.watches[gvr]
+	if w.owners--; w.owners > 0 {
 		return
 	}
+	delete(m.watches, gvr)
+	close(w.stopper)
+	log.Print("Stopped watching ", gvr.String())
 }
 
-// K8s informer watching for all the daemonset changes
-func RunDSInformer(factory informers.SharedInformerFactory) {
-	dsInformer := factory.Apps().V1().DaemonSets().Informer()
+// Stop stops all the informers
+func (m *InformerManager) Stop() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for gvr, w := range m.watches {
+		close(w.stopper)
+		delete(m.watches, gvr)
+	}
+	m.owners = map[string]schema.GroupVersionResource{}
+	close(m.stopper)
+}
 
-	stopper := make(chan struct{})
-	defer close(stopper)
+// resourceFor resolves the resource of a kind, the discovery cache is refreshed once for kinds of new CRDs
+func (m *InformerManager) resourceFor(gvk schema.GroupVersionKind) (schema.GroupVersionResource, error) {
+	var versions []string
+	if gvk.Version != "" {
+		versions = append(versions, gvk.Version)
+	}
 
-	defer runtime.HandleCrash()
+	mapping, err := m.mapper.RESTMapping(gvk.GroupKind(), versions...)
+	if err != nil {
+		m.mapper.Reset()
+		mapping, err = m.mapper.RESTMapping(gvk.GroupKind(), versions...)
+	}
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	return mapping.Resource, nil
+}
 
-	dsInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		// When a resource gets updated
-		UpdateFunc: func(oldObj interface{}, newObj interface{}) {
-			dsNewObj := newObj.(*v1.DaemonSet)
-			dsOldObj := newObj.(*v1.DaemonSet)
-
-			oldManifest := dsOldObj.GetAnnotations()["kubectl.kubernetes.io/last-applied-configuration"]
-			newManifest := dsNewObj.GetAnnotations()["kubectl.kubernetes.io/last-applied-configuration"]
-
-			if oldManifest != "" && newManifest != "" {
-				var oldDm v1.StatefulSet
-				err := json.Unmarshal([]byte(oldManifest), &oldDm)
-				if err != nil {
-					log.Print(err)
-					return
-				}
-
-				var newDm v1.StatefulSet
-				err = json.Unmarshal([]byte(newManifest), &newDm)
-				if err != nil {
-					log.Print(err)
-					return
-				}
-
-				if dsNewObj.GetResourceVersion() != dsOldObj.GetResourceVersion() && !reflect.DeepEqual(newDm, oldDm) {
-					var worflowid = dsNewObj.GetAnnotations()["litmuschaos.io/workflow"]
-					if dsNewObj.GetAnnotations()["litmuschaos.io/gitops"] == "true" && worflowid != "" {
-						log.Print("EventType: Update \n GitOps Notification for workflowID: %s, ResourceType: %s, ResourceName: %s, ResourceNamespace: %s", worflowid, "DaemonSet", dsNewObj.Name, dsNewObj.Namespace)
-						err := PolicyAuditor("DaemonSet", dsNewObj, worflowid)
-						if err != nil {
-							log.Print(err)
-							return
-						}
-					}
-				}
-			}
-		},
-	})
+// handleUpdate audits the policies for updated resources, each policy decides whether it targets the resource and
+// whether the change is relevant through its selectors and diff mode
+func (m *InformerManager) handleUpdate(oldObj interface{}, newObj interface{}) {
+	newRes, ok := newObj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	oldRes, ok := oldObj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
 
-	dsInformer.Run(stopper)
-	if !cache.WaitForCacheSync(stopper, dsInformer.HasSynced) {
-		runtime.HandleError(fmt.Errorf("Timed out waiting for caches to sync"))
+	if newRes.GetResourceVersion() == oldRes.GetResourceVersion() {
 		return
 	}
+
+	policies, err := m.policies.ByNamespace(AgentNamespace).List(labels.Everything())
+	if err != nil {
+		log.Print(err)
+		return
+	}
+
+	if err := PolicyAuditor(newRes.GroupVersionKind(), newRes, oldRes, policies); err != nil {
+		log.Print(err)
+	}
 }
@@ -1,253 +1,300 @@
 package utils
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
-	"io/ioutil"
 
-	"github.com/jmespath/go-jmespath"
 	litmuschaosv1 "github.com/litmuschaos/litmus/litmus-portal/cluster-agents/event-tracker/api/v1"
 	"github.com/litmuschaos/litmus/litmus-portal/cluster-agents/event-tracker/pkg/k8s"
-	v1 "k8s.io/api/apps/v1"
+	"github.com/litmuschaos/litmus/litmus-portal/cluster-agents/event-tracker/pkg/notifier"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
 
 	"log"
-	"net/http"
 	"os"
 	"strings"
 	"time"
 
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
 )
 
 var (
+	// EventTrackerPolicyResource is the resource of the EventTrackerPolicies
+	EventTrackerPolicyResource = schema.GroupVersionResource{Group: "eventtracker.litmuschaos.io", Version: "v1", Resource: "eventtrackerpolicies"}
+
 	AgentNamespace = os.Getenv("AGENT_NAMESPACE")
+	AgentScope     = os.Getenv("AGENT_SCOPE")
 )
 
 const (
-	ExternAgentConfigName = "agent-config"
-	ConditionPassed       = "ConditionPassed"
-	ConditionFailed       = "ConditionFailed"
+	ExternAgentConfigName   = "agent-config"
+	NotifierTokenSecretName = "gitops-notifier-token"
+	NotifierTokenKey        = "TOKEN"
+	ConditionPassed         = "ConditionPassed"
+	ConditionFailed         = "ConditionFailed"
 )
 
-func cases(key string, value string, operator string) bool {
-	switch operator {
-	case "EqualTo":
-		return key == value
-	case "NotEqualTo":
-		return key != value
-	case "LessThan":
-		return key < value
-	case "GreaterThan":
-		return key > value
-	case "GreaterThanEqualTo":
-		return key >= value
-	case "LessThanEqualTo":
-		return key <= value
+// policyMatches reports whether the policy is evaluated against resources of the kind
+func policyMatches(etp litmuschaosv1.EventTrackerPolicy, gvk schema.GroupVersionKind) bool {
+	if etp.Spec.Resource == nil {
+		for _, resource := range DefaultResources {
+			if resource.GroupKind() == gvk.GroupKind() {
+				return true
+			}
+		}
+		return false
 	}
 
-	return false
+	selector := etp.Spec.Resource
+	if selector.Group != gvk.Group || !strings.EqualFold(selector.Kind, gvk.Kind) {
+		return false
+	}
+	return selector.Version == "" || selector.Version == gvk.Version
 }
 
-func conditionChecker(etp litmuschaosv1.EventTrackerPolicy, data interface{}) bool {
-	final_result := false
-	if etp.Spec.ConditionType == "and" {
-		for _, condition := range etp.Spec.Conditions {
-			result, err := jmespath.Search(condition.Key, data)
-			if err != nil {
-				log.Print(err)
-				return false
-			}
+// policyWorkflows returns the workflows the policy triggers for the resource, no workflows are returned for resources
+// the policy doesn't target. Policies without namespaces or a selector target resources annotated for GitOps
+func policyWorkflows(etp litmuschaosv1.EventTrackerPolicy, obj *unstructured.Unstructured) ([]string, error) {
+	annotations := obj.GetAnnotations()
+	annotatedWorkflow := ""
+	if annotations["litmuschaos.io/gitops"] == "true" {
+		annotatedWorkflow = annotations["litmuschaos.io/workflow"]
+	}
 
-			str := fmt.Sprintf("%v", result)
-			if val := cases(str, condition.Value, condition.Operator); !val {
-				final_result = val
-				break
-			} else if val {
-				final_result = true
-			}
+	if len(etp.Spec.Namespaces) == 0 && etp.Spec.Selector == nil {
+		if annotatedWorkflow == "" {
+			return nil, nil
 		}
-	} else if etp.Spec.ConditionType == "or" {
-		for _, condition := range etp.Spec.Conditions {
-			result, err := jmespath.Search(condition.Key, data)
+	} else {
+		if len(etp.Spec.Namespaces) > 0 && !containsString(etp.Spec.Namespaces, obj.GetNamespace()) {
+			return nil, nil
+		}
+		if etp.Spec.Selector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(etp.Spec.Selector)
 			if err != nil {
-				log.Print(err)
+				return nil, errors.New("invalid selector : " + err.Error())
 			}
-
-			str := fmt.Sprintf("%v", result)
-			if val := cases(str, condition.Value, condition.Operator); val {
-				final_result = val
+			if !selector.Matches(labels.Set(obj.GetLabels())) {
+				return nil, nil
 			}
 		}
 	}
 
-	return final_result
+	if len(etp.Spec.WorkflowIDs) > 0 {
+		return etp.Spec.WorkflowIDs, nil
+	}
+	if annotatedWorkflow != "" {
+		return []string{annotatedWorkflow}, nil
+	}
+	return nil, nil
 }
 
-func PolicyAuditor(resourceType string, obj interface{}, workflowid string) error {
-	restConfig, err := k8s.GetKubeConfig()
-	if err != nil {
-		return err
+// PolicyAuditor evaluates the resource policies targeting the resource whose change they detect and records the
+// result in their statuses, the old object is used for the diff and by the Changed operator
+func PolicyAuditor(gvk schema.GroupVersionKind, obj *unstructured.Unstructured, oldObj *unstructured.Unstructured, policies []runtime.Object) error {
+	var oldData interface{}
+	if oldObj != nil {
+		oldData = oldObj.Object
 	}
 
-	clientSet, err := dynamic.NewForConfig(restConfig)
-	if err != nil {
-		return err
-	}
+	return auditPolicies(litmuschaosv1.SourceResource, policies, func(etp litmuschaosv1.EventTrackerPolicy) ([]string, error) {
+		if !policyMatches(etp, gvk) {
+			return nil, nil
+		}
 
-	deploymentRes := schema.GroupVersionResource{Group: "eventtracker.litmuschaos.io", Version: "v1", Resource: "eventtrackerpolicies"}
-	deploymentConfigList, err := clientSet.Resource(deploymentRes).Namespace(AgentNamespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return err
-	}
+		workflowIDs, err := policyWorkflows(etp, obj)
+		if err != nil || len(workflowIDs) == 0 {
+			return nil, err
+		}
 
-	for _, ep := range deploymentConfigList.Items {
+		changed, err := resourceChanged(etp, oldObj, obj)
+		if err != nil || !changed {
+			return nil, err
+		}
+		return workflowIDs, nil
+	}, gvk.Kind, obj.GetName(), obj.Object, oldData)
+}
 
-		eventTrackerPolicy, err := clientSet.Resource(deploymentRes).Namespace(AgentNamespace).Get(context.TODO(), ep.GetName(), metav1.GetOptions{})
+// auditPolicies evaluates the policies of the source against the data, target returns the workflows a policy
+// triggers or none when the policy doesn't apply. Only the passed evaluations of events and alerts are recorded
+func auditPolicies(source string, policies []runtime.Object, target func(etp litmuschaosv1.EventTrackerPolicy) ([]string, error), kind, name string, newData interface{}, oldData interface{}) error {
+	var clientSet dynamic.Interface
+	for _, policy := range policies {
+		eventTrackerPolicy, ok := policy.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		etp, err := toEventTrackerPolicy(eventTrackerPolicy)
 		if err != nil {
 			return err
 		}
 
-		var etp litmuschaosv1.EventTrackerPolicy
-		data, err := json.Marshal(eventTrackerPolicy.Object)
-		if err != nil {
-			return err
+		if etp.TriggerSource() != source {
+			continue
 		}
 
-		err = json.Unmarshal(data, &etp)
+		workflowIDs, err := target(etp)
 		if err != nil {
-			return err
+			log.Print("EventTrackerPolicy ", etp.Name, " : ", err)
+			continue
+		} else if len(workflowIDs) == 0 {
+			continue
 		}
 
-		var dataInterface interface{}
-		var resourceName string
-		expr := strings.ToLower(resourceType)
-		switch expr {
-		case "deployment":
-			deps := obj.(*v1.Deployment)
-			resourceName = deps.GetName()
-			mar, err := json.Marshal(deps)
-			if err != nil {
-				return err
-			}
+		result := ConditionFailed
+		if conditionChecker(etp, newData, oldData) {
+			result = ConditionPassed
+		} else if source != litmuschaosv1.SourceResource {
+			// events and alerts are too frequent to record their failed evaluations, each one is a status update
+			continue
+		}
+
+		var evaluations []litmuschaosv1.PolicyEvaluation
+		for _, workflowID := range workflowIDs {
+			log.Printf("GitOps Notification for workflowID: %s, Source: %s, ResourceType: %s, ResourceName: %s, EventTrackerPolicy: %s", workflowID, source, kind, name, etp.Name)
+			evaluations = append(evaluations, litmuschaosv1.PolicyEvaluation{
+				ID:           string(uuid.NewUUID()),
+				TimeStamp:    time.Now().Format(time.RFC850),
+				Resource:     kind,
+				ResourceName: name,
+				Result:       result,
+				WorkflowID:   workflowID,
+				IsTriggered:  "false",
+			})
+		}
 
-			err = json.Unmarshal(mar, &dataInterface)
-		case "statefulset":
-			sts := obj.(*v1.StatefulSet)
-			resourceName = sts.GetName()
-			mar, err := json.Marshal(sts)
+		if clientSet == nil {
+			restConfig, err := k8s.GetKubeConfig()
 			if err != nil {
 				return err
 			}
-
-			err = json.Unmarshal(mar, &dataInterface)
-		case "daemonset":
-			sts := obj.(*v1.DaemonSet)
-			resourceName = sts.GetName()
-			mar, err := json.Marshal(sts)
-			if err != nil {
+			if clientSet, err = dynamic.NewForConfig(restConfig); err != nil {
 				return err
 			}
-
-			err = json.Unmarshal(mar, &dataInterface)
-		default:
-			return errors.New("Resource not supported")
 		}
 
-		check := conditionChecker(etp, dataInterface)
-		var result string
-		if check == true {
-			result = ConditionPassed
-		} else if check == false {
-			result = ConditionFailed
+		err = recordEvaluations(clientSet, etp.Name, evaluations)
+		if err != nil {
+			return err
 		}
 
-		etp.Statuses = append(etp.Statuses, litmuschaosv1.EventTrackerPolicyStatus{
-			TimeStamp:    time.Now().Format(time.RFC850),
-			Resource:     resourceType,
-			ResourceName: resourceName,
-			Result:       result,
-			WorkflowID:   workflowid,
-			IsTriggered:  "false",
-		})
+		log.Print("EventTrackerPolicy ", etp.Name, " status updated")
+	}
 
-		// Updating EventTrackerPolicy
-		var unstruc unstructured.Unstructured
-		data, err = json.Marshal(etp)
+	return nil
+}
+
+// recordEvaluations adds the evaluations to the status of the policy. The patch carries the resource version it was
+// computed from, so concurrent status updates conflict and the evaluations are recorded again on the latest status
+func recordEvaluations(clientSet dynamic.Interface, name string, evaluations []litmuschaosv1.PolicyEvaluation) error {
+	policies := clientSet.Resource(EventTrackerPolicyResource).Namespace(AgentNamespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		obj, err := policies.Get(context.TODO(), name, metav1.GetOptions{})
 		if err != nil {
 			return err
 		}
 
-		err = json.Unmarshal(data, &unstruc)
+		etp, err := toEventTrackerPolicy(obj)
 		if err != nil {
 			return err
 		}
 
-		_, err = clientSet.Resource(deploymentRes).Namespace(AgentNamespace).Update(context.TODO(), &unstruc, metav1.UpdateOptions{})
+		now := metav1.Now()
+		for _, evaluation := range evaluations {
+			etp.Status.AddEvaluation(evaluation, etp.HistoryLimit())
+			etp.Status.EvaluationCount++
+			if evaluation.Result == ConditionPassed {
+				etp.Status.PassedCount++
+			}
+		}
+		etp.Status.LastEvaluationTime = &now
+
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{"resourceVersion": etp.ResourceVersion},
+			"status":   etp.Status,
+		})
 		if err != nil {
 			return err
 		}
 
-		log.Print("EventTrackerPolicy updated")
+		_, err = policies.Patch(context.TODO(), name, types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+		return err
+	})
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
 	}
+	return false
+}
 
-	return nil
+func toEventTrackerPolicy(obj *unstructured.Unstructured) (litmuschaosv1.EventTrackerPolicy, error) {
+	var etp litmuschaosv1.EventTrackerPolicy
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &etp)
+	return etp, err
 }
 
-func getAgentConfigMapData() (string, string, string, error) {
+func getServerAddr() (string, error) {
 	clientset, err := k8s.K8sClient()
 	if err != nil {
-		return "", "", "", err
+		return "", err
 	}
 
 	getCM, err := clientset.CoreV1().ConfigMaps(AgentNamespace).Get(context.TODO(), ExternAgentConfigName, metav1.GetOptions{})
 	if k8sErrors.IsNotFound(err) {
-		return "", "", "", errors.New(ExternAgentConfigName + " configmap not found")
-	} else if getCM.Data["IS_CLUSTER_CONFIRMED"] == "true" {
-		return getCM.Data["ACCESS_KEY"], getCM.Data["CLUSTER_ID"], getCM.Data["SERVER_ADDR"], nil
+		return "", errors.New(ExternAgentConfigName + " configmap not found")
 	} else if err != nil {
-		return "", "", "", err
+		return "", err
+	} else if getCM.Data["IS_CLUSTER_CONFIRMED"] != "true" {
+		return "", errors.New("cluster is not confirmed yet")
 	}
 
-	return "", "", "", nil
+	return getCM.Data["SERVER_ADDR"], nil
 }
 
-// Function to send request to litmus graphql server
-func SendRequest(workflowID string) (string, error) {
-	accessKey, clusterID, serverAddr, err := getAgentConfigMapData()
+func getNotifierToken() (string, error) {
+	clientset, err := k8s.K8sClient()
 	if err != nil {
 		return "", err
 	}
 
-	payload := `{"query": "mutation { gitopsNotifer(clusterInfo: { cluster_id: \"` + clusterID + `\", access_key: \"` + accessKey + `\"}, workflow_id: \"` + workflowID + `\")\n}"}`
-	req, err := http.NewRequest("POST", serverAddr, bytes.NewBuffer([]byte(payload)))
-	if err != nil {
+	secret, err := clientset.CoreV1().Secrets(AgentNamespace).Get(context.TODO(), NotifierTokenSecretName, metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		return "", errors.New(NotifierTokenSecretName + " secret not found, the token is issued once the agent connects to the server")
+	} else if err != nil {
 		return "", err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
+	token := string(secret.Data[NotifierTokenKey])
+	if token == "" {
+		return "", errors.New(NotifierTokenKey + " missing in " + NotifierTokenSecretName + " secret")
 	}
+	return token, nil
+}
 
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return "URL is not reachable or Bad request", nil
+// NewNotifierClient returns a client of the gitops trigger endpoint, the token is read on every call so that
+// rotated tokens are picked up
+func NewNotifierClient() (*notifier.Client, error) {
+	serverAddr, err := getServerAddr()
+	if err != nil {
+		return nil, err
 	}
 
-	body, err := ioutil.ReadAll(resp.Body)
+	token, err := getNotifierToken()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return string(body), nil
+	return notifier.NewClient(serverAddr, token), nil
 }
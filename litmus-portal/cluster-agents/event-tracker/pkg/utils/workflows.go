@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/litmuschaos/litmus/litmus-portal/cluster-agents/event-tracker/pkg/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var workflowResource = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "workflows"}
+
+// RunningWorkflows returns the number of runs of the workflow which haven't completed yet
+func RunningWorkflows(ctx context.Context, workflowID string) (int, error) {
+	restConfig, err := k8s.GetKubeConfig()
+	if err != nil {
+		return 0, err
+	}
+
+	clientSet, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return 0, err
+	}
+
+	runs, err := clientSet.Resource(workflowResource).Namespace(AgentNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "workflow_id=" + workflowID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	running := 0
+	for _, run := range runs.Items {
+		phase, _, _ := unstructured.NestedString(run.Object, "status", "phase")
+		switch phase {
+		case "Succeeded", "Failed", "Error":
+		default:
+			running++
+		}
+	}
+	return running, nil
+}
@@ -2,6 +2,8 @@ package k8s
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strings"
@@ -28,6 +30,10 @@ import (
 const (
 	ExternAgentConfigName = "agent-config"
 	LiveCheckMaxTries     = 6
+
+	// NotifierTokenSecretName is the secret holding the token used by the event-tracker for gitops notifications
+	NotifierTokenSecretName = "gitops-notifier-token"
+	NotifierTokenKey        = "TOKEN"
 )
 
 type AgentComponents struct {
@@ -172,6 +178,66 @@ func ClusterRegister(clusterData map[string]string) (bool, error) {
 	return true, nil
 }
 
+// NotifierTokenHash returns the hash of the gitops notifier token held by the agent, empty when it has none
+func NotifierTokenHash() string {
+	clientset, err := GetGenericK8sClient()
+	if err != nil {
+		logrus.WithError(err).Error("failed to read " + NotifierTokenSecretName)
+		return ""
+	}
+
+	secret, err := clientset.CoreV1().Secrets(AgentNamespace).Get(NotifierTokenSecretName, metav1.GetOptions{})
+	if err != nil {
+		if !k8s_errors.IsNotFound(err) {
+			logrus.WithError(err).Error("failed to read " + NotifierTokenSecretName)
+		}
+		return ""
+	}
+
+	token := secret.Data[NotifierTokenKey]
+	if len(token) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(token)
+	return hex.EncodeToString(sum[:])
+}
+
+// SaveNotifierToken stores the gitops notifier token issued by the server for the event-tracker
+func SaveNotifierToken(token string) error {
+	clientset, err := GetGenericK8sClient()
+	if err != nil {
+		return err
+	}
+
+	secret, err := clientset.CoreV1().Secrets(AgentNamespace).Get(NotifierTokenSecretName, metav1.GetOptions{})
+	if k8s_errors.IsNotFound(err) {
+		_, err = clientset.CoreV1().Secrets(AgentNamespace).Create(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: NotifierTokenSecretName,
+			},
+			Type:       corev1.SecretTypeOpaque,
+			StringData: map[string]string{NotifierTokenKey: token},
+		})
+		if err != nil {
+			return err
+		}
+
+		logrus.Info(NotifierTokenSecretName + " has been created")
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	secret.StringData = map[string]string{NotifierTokenKey: token}
+	_, err = clientset.CoreV1().Secrets(AgentNamespace).Update(secret)
+	if err != nil {
+		return err
+	}
+
+	logrus.Info(NotifierTokenSecretName + " has been updated")
+	return nil
+}
+
 func applyRequest(requestType string, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
 	if requestType == "create" {
 		response, err := dr.Create(obj, metav1.CreateOptions{})
@@ -0,0 +1,152 @@
+package k8s
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/litmuschaos/litmus/litmus-portal/cluster-agents/subscriber/pkg/graphql"
+	"github.com/litmuschaos/litmus/litmus-portal/cluster-agents/subscriber/pkg/types"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// CheckReadiness runs the readiness checks, it returns the reason of the first resource which isn't ready
+func CheckReadiness(checks []types.ReadinessCheck) (string, error) {
+	clientset, err := GetGenericK8sClient()
+	if err != nil {
+		return "", err
+	}
+
+	for _, check := range checks {
+		reason, err := checkReadiness(clientset, check)
+		if err != nil {
+			return "", errors.New("readiness check of " + check.Kind + " in " + check.Namespace + " failed: " + err.Error())
+		}
+		if reason != "" {
+			return reason, nil
+		}
+	}
+	return "", nil
+}
+
+func checkReadiness(clientset *kubernetes.Clientset, check types.ReadinessCheck) (string, error) {
+	listOptions := metav1.ListOptions{LabelSelector: check.LabelSelector}
+	if check.ResourceName != "" {
+		listOptions = metav1.ListOptions{FieldSelector: "metadata.name=" + check.ResourceName}
+	}
+
+	notReady := func(name string) string {
+		return fmt.Sprintf("%s %s/%s is not ready", check.Kind, check.Namespace, name)
+	}
+	found := 0
+	switch check.Kind {
+	case "deployment":
+		list, err := clientset.AppsV1().Deployments(check.Namespace).List(listOptions)
+		if err != nil {
+			return "", err
+		}
+		for _, deployment := range list.Items {
+			found++
+			replicas := int32(1)
+			if deployment.Spec.Replicas != nil {
+				replicas = *deployment.Spec.Replicas
+			}
+			if deployment.Status.ObservedGeneration < deployment.Generation || deployment.Status.UpdatedReplicas < replicas || deployment.Status.AvailableReplicas < replicas {
+				return notReady(deployment.Name), nil
+			}
+		}
+	case "statefulset":
+		list, err := clientset.AppsV1().StatefulSets(check.Namespace).List(listOptions)
+		if err != nil {
+			return "", err
+		}
+		for _, statefulSet := range list.Items {
+			found++
+			replicas := int32(1)
+			if statefulSet.Spec.Replicas != nil {
+				replicas = *statefulSet.Spec.Replicas
+			}
+			if statefulSet.Status.ObservedGeneration < statefulSet.Generation || statefulSet.Status.ReadyReplicas < replicas {
+				return notReady(statefulSet.Name), nil
+			}
+		}
+	case "daemonset":
+		list, err := clientset.AppsV1().DaemonSets(check.Namespace).List(listOptions)
+		if err != nil {
+			return "", err
+		}
+		for _, daemonSet := range list.Items {
+			found++
+			if daemonSet.Status.ObservedGeneration < daemonSet.Generation || daemonSet.Status.NumberReady < daemonSet.Status.DesiredNumberScheduled {
+				return notReady(daemonSet.Name), nil
+			}
+		}
+	case "pod":
+		list, err := clientset.CoreV1().Pods(check.Namespace).List(listOptions)
+		if err != nil {
+			return "", err
+		}
+		for _, pod := range list.Items {
+			found++
+			if !isPodReady(pod) {
+				return notReady(pod.Name), nil
+			}
+		}
+	default:
+		return "", errors.New("unsupported kind " + check.Kind)
+	}
+
+	if found == 0 {
+		return fmt.Sprintf("no %s found in %s", check.Kind, check.Namespace), nil
+	}
+	return "", nil
+}
+
+func isPodReady(pod corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// SendReadinessCheckResult runs the readiness checks of the request and sends the result to graphql server
+func SendReadinessCheckResult(clusterData map[string]string, request types.ReadinessCheckRequest) error {
+	reason, err := CheckReadiness(request.Checks)
+	if err != nil {
+		// the result is still sent so that the server doesn't wait for it, the gate fails with the error
+		logrus.WithError(err).Print("Error while checking readiness")
+		reason = err.Error()
+	}
+
+	result := map[string]interface{}{
+		"request_id": request.RequestID,
+		"cluster_id": map[string]string{
+			"cluster_id": clusterData["CLUSTER_ID"],
+			"access_key": clusterData["ACCESS_KEY"],
+		},
+		"ready": reason == "",
+	}
+	if reason != "" {
+		result["reason"] = reason
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"query":     "mutation($result: ReadinessCheckResult!) { readinessCheckResult(result: $result) }",
+		"variables": map[string]interface{}{"result": result},
+	})
+	if err != nil {
+		return err
+	}
+
+	body, err := graphql.SendRequest(clusterData["SERVER_ADDR"], payload)
+	if err != nil {
+		return err
+	}
+
+	logrus.Println("Response", body)
+	return nil
+}
@@ -16,7 +16,8 @@ import (
 )
 
 func ClusterConnect(clusterData map[string]string) {
-	query := `{"query":"subscription {\n    clusterConnect(clusterInfo: {cluster_id: \"` + clusterData["CLUSTER_ID"] + `\", access_key: \"` + clusterData["ACCESS_KEY"] + `\"}) {\n   \t project_id,\n     action{\n      k8s_manifest,\n      external_data,\n      request_type\n     namespace\n     }\n  }\n}\n"}`
+	// the server issues a new notifier token when the agent doesn't hold the current one
+	query := `{"query":"subscription {\n    clusterConnect(clusterInfo: {cluster_id: \"` + clusterData["CLUSTER_ID"] + `\", access_key: \"` + clusterData["ACCESS_KEY"] + `\", notifier_token_hash: \"` + k8s.NotifierTokenHash() + `\"}) {\n   \t project_id,\n     action{\n      k8s_manifest,\n      external_data,\n      request_type\n     namespace\n     }\n  }\n}\n"}`
 	serverURL, err := url.Parse(clusterData["SERVER_ADDR"])
 	scheme := "ws"
 	if serverURL.Scheme == "https" {
@@ -124,7 +125,31 @@ func RequestProcessor(clusterData map[string]string, r types.RawData) error {
 		if err != nil {
 			return errors.New("error performing cluster operation: " + err.Error())
 		}
-	} else if strings.Index("workflow_delete workflow_sync", strings.ToLower(r.Payload.Data.ClusterConnect.Action.RequestType)) >= 0 {
+	} else if strings.ToLower(r.Payload.Data.ClusterConnect.Action.RequestType) == "notifier_token" {
+		if r.Payload.Data.ClusterConnect.Action.ExternalData == "" {
+			return errors.New("notifier token missing in cluster-action request")
+		}
+		err := k8s.SaveNotifierToken(r.Payload.Data.ClusterConnect.Action.ExternalData)
+		if err != nil {
+			return errors.New("error saving notifier token: " + err.Error())
+		}
+	} else if strings.ToLower(r.Payload.Data.ClusterConnect.Action.RequestType) == "readiness_check" {
+		readinessRequest := types.ReadinessCheckRequest{
+			RequestID: r.Payload.Data.ClusterConnect.ProjectID,
+		}
+
+		err := json.Unmarshal([]byte(r.Payload.Data.ClusterConnect.Action.ExternalData), &readinessRequest.Checks)
+		if err != nil {
+			return errors.New("error reading cluster-action request [external-data]: " + err.Error())
+		}
+
+		go func() {
+			err := k8s.SendReadinessCheckResult(clusterData, readinessRequest)
+			if err != nil {
+				logrus.WithError(err).Print("error sending readiness check result")
+			}
+		}()
+	} else if strings.Index("workflow_delete workflow_sync workflow_abort", strings.ToLower(r.Payload.Data.ClusterConnect.Action.RequestType)) >= 0 {
 		err := utils.WorkflowRequest(clusterData, r.Payload.Data.ClusterConnect.Action.RequestType, r.Payload.Data.ClusterConnect.Action.ExternalData)
 		if err != nil {
 			return errors.New("error performing events operation: " + err.Error())
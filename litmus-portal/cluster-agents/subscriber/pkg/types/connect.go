@@ -58,3 +58,14 @@ type WorkflowSyncExternalData struct {
 	WorkflowID    string `json:"workflow_id"`
 	WorkflowRunID string `json:"workflow_run_id"`
 }
+
+// WorkflowAbortExternalData identifies the workflow run and the chaos engines stopped by a guardrail
+type WorkflowAbortExternalData struct {
+	WorkflowRunID string           `json:"workflow_run_id"`
+	ChaosEngines  []ChaosEngineRef `json:"chaos_engines"`
+}
+
+type ChaosEngineRef struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
@@ -0,0 +1,15 @@
+package types
+
+// ReadinessCheck is a readiness check of a steady state gate, the resource is selected by its name or label selector
+type ReadinessCheck struct {
+	Kind          string `json:"kind"`
+	Namespace     string `json:"namespace"`
+	ResourceName  string `json:"resource_name,omitempty"`
+	LabelSelector string `json:"label_selector,omitempty"`
+}
+
+// ReadinessCheckRequest is a request to run the readiness checks of the steady state gates of a workflow
+type ReadinessCheckRequest struct {
+	RequestID string
+	Checks    []ReadinessCheck
+}
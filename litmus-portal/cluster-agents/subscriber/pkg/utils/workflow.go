@@ -7,10 +7,13 @@ import (
 	"github.com/litmuschaos/litmus/litmus-portal/cluster-agents/subscriber/pkg/types"
 
 	wfclientset "github.com/argoproj/argo/pkg/client/clientset/versioned"
+	chaosTypes "github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	litmusV1alpha1 "github.com/litmuschaos/chaos-operator/pkg/client/clientset/versioned/typed/litmuschaos/v1alpha1"
 	"github.com/litmuschaos/litmus/litmus-portal/cluster-agents/subscriber/pkg/events"
 	"github.com/litmuschaos/litmus/litmus-portal/cluster-agents/subscriber/pkg/k8s"
 	"github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 )
 
 func WorkflowRequest(clusterData map[string]string, requestType string, externalData string) error {
@@ -26,6 +29,19 @@ func WorkflowRequest(clusterData map[string]string, requestType string, external
 		}
 
 		logrus.Info("events delete name: ", wfOb.Name, "namespace: ", wfOb.Namespace)
+	} else if requestType == "workflow_abort" {
+		var extData types.WorkflowAbortExternalData
+		err := json.Unmarshal([]byte(externalData), &extData)
+		if err != nil {
+			return err
+		}
+
+		err = AbortWorkflow(extData, clusterData)
+		if err != nil {
+			return err
+		}
+
+		logrus.Info("workflow run aborted: ", extData.WorkflowRunID)
 	} else if requestType == "workflow_sync" {
 
 		var extData types.WorkflowSyncExternalData
@@ -93,3 +109,38 @@ func DeleteWorflow(wfname string, clusterData map[string]string) error {
 	wfClient := wfclientset.NewForConfigOrDie(conf).ArgoprojV1alpha1().Workflows(clusterData["AGENT_NAMESPACE"])
 	return wfClient.Delete(wfname, &metav1.DeleteOptions{})
 }
+
+// AbortWorkflow terminates the workflow run and stops its chaos engines
+func AbortWorkflow(extData types.WorkflowAbortExternalData, clusterData map[string]string) error {
+	conf, err := k8s.GetKubeConfig()
+	if err != nil {
+		return err
+	}
+
+	// stop the chaos engines first so that the chaos is reverted even if the run can't be terminated
+	chaosClient, err := litmusV1alpha1.NewForConfig(conf)
+	if err != nil {
+		return err
+	}
+	// merge patches only set the changed fields, so they don't conflict with the concurrent updates of the controllers
+	enginePatch := []byte(`{"spec":{"engineState":"` + string(chaosTypes.EngineStateStop) + `"}}`)
+	for _, engine := range extData.ChaosEngines {
+		_, err = chaosClient.ChaosEngines(engine.Namespace).Patch(engine.Name, k8stypes.MergePatchType, enginePatch)
+		if err != nil {
+			logrus.WithError(err).Print("could not stop chaos engine ", engine.Namespace, "/", engine.Name)
+		}
+	}
+
+	wfOb, err := events.GetWorkflowObj(extData.WorkflowRunID)
+	if err != nil {
+		return err
+	}
+	if wfOb == nil {
+		return nil
+	}
+
+	// terminating a workflow sets its active deadline to 0
+	wfClient := wfclientset.NewForConfigOrDie(conf).ArgoprojV1alpha1().Workflows(clusterData["AGENT_NAMESPACE"])
+	_, err = wfClient.Patch(wfOb.Name, k8stypes.MergePatchType, []byte(`{"spec":{"activeDeadlineSeconds":0}}`))
+	return err
+}
@@ -22,6 +22,63 @@ type AgentStat struct {
 	Active  int `json:"Active"`
 }
 
+type AlertEvent struct {
+	RuleID         string        `json:"rule_id"`
+	RuleName       string        `json:"rule_name"`
+	Type           AlertRuleType `json:"type"`
+	State          AlertState    `json:"state"`
+	PreviousState  AlertState    `json:"previous_state"`
+	WorkflowID     string        `json:"workflow_id"`
+	ExperimentName *string       `json:"experiment_name"`
+	Message        string        `json:"message"`
+	TimeStamp      string        `json:"time_stamp"`
+}
+
+type AlertInstance struct {
+	WorkflowID     string     `json:"workflow_id"`
+	WorkflowName   string     `json:"workflow_name"`
+	ExperimentName *string    `json:"experiment_name"`
+	State          AlertState `json:"state"`
+	Message        string     `json:"message"`
+	ActiveSince    string     `json:"active_since"`
+	FiredAt        *string    `json:"fired_at"`
+}
+
+type AlertRule struct {
+	RuleID              string           `json:"rule_id"`
+	ProjectID           string           `json:"project_id"`
+	Name                string           `json:"name"`
+	Type                AlertRuleType    `json:"type"`
+	WorkflowID          *string          `json:"workflow_id"`
+	Threshold           *float64         `json:"threshold"`
+	ExperimentName      *string          `json:"experiment_name"`
+	ConsecutiveFailures *int             `json:"consecutive_failures"`
+	GracePeriod         *int             `json:"grace_period"`
+	PendingFor          int              `json:"pending_for"`
+	Enabled             bool             `json:"enabled"`
+	State               AlertState       `json:"state"`
+	StateChangedAt      *string          `json:"state_changed_at"`
+	LastEvaluatedAt     *string          `json:"last_evaluated_at"`
+	LastFiredAt         *string          `json:"last_fired_at"`
+	Instances           []*AlertInstance `json:"instances"`
+	CreatedAt           string           `json:"created_at"`
+	UpdatedAt           string           `json:"updated_at"`
+}
+
+type AlertRuleInput struct {
+	ProjectID           string        `json:"project_id"`
+	RuleID              *string       `json:"rule_id"`
+	Name                string        `json:"name"`
+	Type                AlertRuleType `json:"type"`
+	WorkflowID          *string       `json:"workflow_id"`
+	Threshold           *float64      `json:"threshold"`
+	ExperimentName      *string       `json:"experiment_name"`
+	ConsecutiveFailures *int          `json:"consecutive_failures"`
+	GracePeriod         *int          `json:"grace_period"`
+	PendingFor          *int          `json:"pending_for"`
+	Enabled             *bool         `json:"enabled"`
+}
+
 type Annotation struct {
 	Categories       string `json:"Categories"`
 	Vendor           string `json:"Vendor"`
@@ -32,15 +89,18 @@ type Annotation struct {
 }
 
 type ChaosWorkFlowInput struct {
-	WorkflowID          *string            `json:"workflow_id"`
-	WorkflowManifest    string             `json:"workflow_manifest"`
-	CronSyntax          string             `json:"cronSyntax"`
-	WorkflowName        string             `json:"workflow_name"`
-	WorkflowDescription string             `json:"workflow_description"`
-	Weightages          []*WeightagesInput `json:"weightages"`
-	IsCustomWorkflow    bool               `json:"isCustomWorkflow"`
-	ProjectID           string             `json:"project_id"`
-	ClusterID           string             `json:"cluster_id"`
+	WorkflowID          *string                 `json:"workflow_id"`
+	WorkflowManifest    string                  `json:"workflow_manifest"`
+	CronSyntax          string                  `json:"cronSyntax"`
+	WorkflowName        string                  `json:"workflow_name"`
+	WorkflowDescription string                  `json:"workflow_description"`
+	Weightages          []*WeightagesInput      `json:"weightages"`
+	IsCustomWorkflow    bool                    `json:"isCustomWorkflow"`
+	ProjectID           string                  `json:"project_id"`
+	ClusterID           string                  `json:"cluster_id"`
+	SteadyStateGates    []*SteadyStateGateInput `json:"steady_state_gates"`
+	Guardrails          []*GuardrailInput       `json:"guardrails"`
+	ImpactAnalysis      *ImpactAnalysisInput    `json:"impact_analysis"`
 }
 
 type ChaosWorkFlowResponse struct {
@@ -77,27 +137,28 @@ type CloningInput struct {
 }
 
 type Cluster struct {
-	ClusterID             string  `json:"cluster_id"`
-	ProjectID             string  `json:"project_id"`
-	ClusterName           string  `json:"cluster_name"`
-	Description           *string `json:"description"`
-	PlatformName          string  `json:"platform_name"`
-	AccessKey             string  `json:"access_key"`
-	IsRegistered          bool    `json:"is_registered"`
-	IsClusterConfirmed    bool    `json:"is_cluster_confirmed"`
-	IsActive              bool    `json:"is_active"`
-	UpdatedAt             string  `json:"updated_at"`
-	CreatedAt             string  `json:"created_at"`
-	ClusterType           string  `json:"cluster_type"`
-	NoOfSchedules         *int    `json:"no_of_schedules"`
-	NoOfWorkflows         *int    `json:"no_of_workflows"`
-	Token                 string  `json:"token"`
-	AgentNamespace        *string `json:"agent_namespace"`
-	Serviceaccount        *string `json:"serviceaccount"`
-	AgentScope            string  `json:"agent_scope"`
-	AgentNsExists         *bool   `json:"agent_ns_exists"`
-	AgentSaExists         *bool   `json:"agent_sa_exists"`
-	LastWorkflowTimestamp string  `json:"last_workflow_timestamp"`
+	ClusterID              string  `json:"cluster_id"`
+	ProjectID              string  `json:"project_id"`
+	ClusterName            string  `json:"cluster_name"`
+	Description            *string `json:"description"`
+	PlatformName           string  `json:"platform_name"`
+	AccessKey              string  `json:"access_key"`
+	IsRegistered           bool    `json:"is_registered"`
+	IsClusterConfirmed     bool    `json:"is_cluster_confirmed"`
+	IsActive               bool    `json:"is_active"`
+	UpdatedAt              string  `json:"updated_at"`
+	CreatedAt              string  `json:"created_at"`
+	ClusterType            string  `json:"cluster_type"`
+	NoOfSchedules          *int    `json:"no_of_schedules"`
+	NoOfWorkflows          *int    `json:"no_of_workflows"`
+	Token                  string  `json:"token"`
+	AgentNamespace         *string `json:"agent_namespace"`
+	Serviceaccount         *string `json:"serviceaccount"`
+	AgentScope             string  `json:"agent_scope"`
+	AgentNsExists          *bool   `json:"agent_ns_exists"`
+	AgentSaExists          *bool   `json:"agent_sa_exists"`
+	LastWorkflowTimestamp  string  `json:"last_workflow_timestamp"`
+	NotifierTokenUpdatedAt *string `json:"notifier_token_updated_at"`
 }
 
 type ClusterAction struct {
@@ -132,8 +193,9 @@ type ClusterEventInput struct {
 }
 
 type ClusterIdentity struct {
-	ClusterID string `json:"cluster_id"`
-	AccessKey string `json:"access_key"`
+	ClusterID         string  `json:"cluster_id"`
+	AccessKey         string  `json:"access_key"`
+	NotifierTokenHash *string `json:"notifier_token_hash"`
 }
 
 type ClusterInput struct {
@@ -150,6 +212,11 @@ type ClusterInput struct {
 	NodeSelector   *string `json:"node_selector"`
 }
 
+type ClusterMapping struct {
+	SourceClusterID string `json:"source_cluster_id"`
+	TargetClusterID string `json:"target_cluster_id"`
+}
+
 type CreateMyHub struct {
 	HubName       string   `json:"HubName"`
 	RepoURL       string   `json:"RepoURL"`
@@ -172,37 +239,59 @@ type CreateUserInput struct {
 	Role        string  `json:"role"`
 }
 
+type DSHeaderInput struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
 type DSInput struct {
-	DsID              *string `json:"ds_id"`
-	DsName            string  `json:"ds_name"`
-	DsType            string  `json:"ds_type"`
-	DsURL             string  `json:"ds_url"`
-	AccessType        string  `json:"access_type"`
-	AuthType          string  `json:"auth_type"`
-	BasicAuthUsername *string `json:"basic_auth_username"`
-	BasicAuthPassword *string `json:"basic_auth_password"`
-	ScrapeInterval    int     `json:"scrape_interval"`
-	QueryTimeout      int     `json:"query_timeout"`
-	HTTPMethod        string  `json:"http_method"`
-	ProjectID         *string `json:"project_id"`
+	DsID               *string          `json:"ds_id"`
+	DsName             string           `json:"ds_name"`
+	DsType             string           `json:"ds_type"`
+	DsURL              string           `json:"ds_url"`
+	AccessType         string           `json:"access_type"`
+	AuthType           string           `json:"auth_type"`
+	BasicAuthUsername  *string          `json:"basic_auth_username"`
+	BasicAuthPassword  *string          `json:"basic_auth_password"`
+	BearerToken        *string          `json:"bearer_token"`
+	CustomHeaders      []*DSHeaderInput `json:"custom_headers"`
+	CaCert             *string          `json:"ca_cert"`
+	ClientCert         *string          `json:"client_cert"`
+	ClientKey          *string          `json:"client_key"`
+	InsecureSkipVerify *bool            `json:"insecure_skip_verify"`
+	TenantID           *string          `json:"tenant_id"`
+	TenantHeader       *string          `json:"tenant_header"`
+	ScrapeInterval     int              `json:"scrape_interval"`
+	QueryTimeout       int              `json:"query_timeout"`
+	HTTPMethod         string           `json:"http_method"`
+	ProjectID          *string          `json:"project_id"`
 }
 
 type DSResponse struct {
-	DsID              *string `json:"ds_id"`
-	DsName            *string `json:"ds_name"`
-	DsType            *string `json:"ds_type"`
-	DsURL             *string `json:"ds_url"`
-	AccessType        *string `json:"access_type"`
-	AuthType          *string `json:"auth_type"`
-	BasicAuthUsername *string `json:"basic_auth_username"`
-	BasicAuthPassword *string `json:"basic_auth_password"`
-	ScrapeInterval    *int    `json:"scrape_interval"`
-	QueryTimeout      *int    `json:"query_timeout"`
-	HTTPMethod        *string `json:"http_method"`
-	ProjectID         string  `json:"project_id"`
-	HealthStatus      string  `json:"health_status"`
-	CreatedAt         *string `json:"created_at"`
-	UpdatedAt         *string `json:"updated_at"`
+	DsID               *string  `json:"ds_id"`
+	DsName             *string  `json:"ds_name"`
+	DsType             *string  `json:"ds_type"`
+	DsURL              *string  `json:"ds_url"`
+	AccessType         *string  `json:"access_type"`
+	AuthType           *string  `json:"auth_type"`
+	BasicAuthUsername  *string  `json:"basic_auth_username"`
+	BasicAuthPassword  *string  `json:"basic_auth_password"`
+	CustomHeaderNames  []string `json:"custom_header_names"`
+	CaCert             *string  `json:"ca_cert"`
+	ClientCert         *string  `json:"client_cert"`
+	InsecureSkipVerify *bool    `json:"insecure_skip_verify"`
+	TenantID           *string  `json:"tenant_id"`
+	TenantHeader       *string  `json:"tenant_header"`
+	ScrapeInterval     *int     `json:"scrape_interval"`
+	QueryTimeout       *int     `json:"query_timeout"`
+	HTTPMethod         *string  `json:"http_method"`
+	ProjectID          string   `json:"project_id"`
+	HealthStatus       string   `json:"health_status"`
+	HealthCheckedAt    *string  `json:"health_checked_at"`
+	HealthChangedAt    *string  `json:"health_changed_at"`
+	UptimePercentage   *float64 `json:"uptime_percentage"`
+	CreatedAt          *string  `json:"created_at"`
+	UpdatedAt          *string  `json:"updated_at"`
 }
 
 type DateRange struct {
@@ -210,6 +299,37 @@ type DateRange struct {
 	EndDate   *string `json:"end_date"`
 }
 
+type EventTrackerPolicy struct {
+	PolicyID  string                    `json:"policy_id"`
+	ProjectID string                    `json:"project_id"`
+	ClusterID string                    `json:"cluster_id"`
+	Name      string                    `json:"name"`
+	Spec      string                    `json:"spec"`
+	Managed   bool                      `json:"managed"`
+	Status    *EventTrackerPolicyStatus `json:"status"`
+	CreatedAt string                    `json:"created_at"`
+	UpdatedAt string                    `json:"updated_at"`
+}
+
+type EventTrackerPolicyInput struct {
+	ProjectID string `json:"project_id"`
+	ClusterID string `json:"cluster_id"`
+	Name      string `json:"name"`
+	Spec      string `json:"spec"`
+}
+
+type EventTrackerPolicyStatus struct {
+	Evaluations        []*PolicyEvaluation `json:"evaluations"`
+	Conditions         []*PolicyCondition  `json:"conditions"`
+	EvaluationCount    int                 `json:"evaluation_count"`
+	PassedCount        int                 `json:"passed_count"`
+	TriggeredCount     int                 `json:"triggered_count"`
+	SkippedCount       int                 `json:"skipped_count"`
+	LastEvaluationTime *string             `json:"last_evaluation_time"`
+	LastTriggerTime    *string             `json:"last_trigger_time"`
+	ReportedAt         *string             `json:"reported_at"`
+}
+
 type ExperimentInput struct {
 	ProjectID      string  `json:"ProjectID"`
 	ChartName      string  `json:"ChartName"`
@@ -224,6 +344,13 @@ type Experiments struct {
 	Desc string `json:"Desc"`
 }
 
+type GenerateReportInput struct {
+	ProjectID  string         `json:"project_id"`
+	Formats    []ReportFormat `json:"formats"`
+	PeriodDays *int           `json:"period_days"`
+	Recipients []string       `json:"recipients"`
+}
+
 type GetWorkflowRunsInput struct {
 	ProjectID      string                  `json:"project_id"`
 	WorkflowRunIds []*string               `json:"workflow_run_ids"`
@@ -250,15 +377,36 @@ type GitConfig struct {
 }
 
 type GitConfigResponse struct {
-	Enabled       bool      `json:"Enabled"`
-	ProjectID     string    `json:"ProjectID"`
-	Branch        *string   `json:"Branch"`
-	RepoURL       *string   `json:"RepoURL"`
-	AuthType      *AuthType `json:"AuthType"`
-	Token         *string   `json:"Token"`
-	UserName      *string   `json:"UserName"`
-	Password      *string   `json:"Password"`
-	SSHPrivateKey *string   `json:"SSHPrivateKey"`
+	Enabled       bool          `json:"Enabled"`
+	ProjectID     string        `json:"ProjectID"`
+	Branch        *string       `json:"Branch"`
+	RepoURL       *string       `json:"RepoURL"`
+	AuthType      *AuthType     `json:"AuthType"`
+	Token         *string       `json:"Token"`
+	UserName      *string       `json:"UserName"`
+	Password      *string       `json:"Password"`
+	SSHPrivateKey *string       `json:"SSHPrivateKey"`
+	Secrets       []*SecretInfo `json:"Secrets"`
+}
+
+type Guardrail struct {
+	Name         string  `json:"name"`
+	DataSourceID string  `json:"data_source_id"`
+	Query        string  `json:"query"`
+	Operator     string  `json:"operator"`
+	Threshold    float64 `json:"threshold"`
+	Interval     *int    `json:"interval"`
+	BreachCount  *int    `json:"breach_count"`
+}
+
+type GuardrailInput struct {
+	Name         string  `json:"name"`
+	DataSourceID string  `json:"data_source_id"`
+	Query        string  `json:"query"`
+	Operator     string  `json:"operator"`
+	Threshold    float64 `json:"threshold"`
+	Interval     *int    `json:"interval"`
+	BreachCount  *int    `json:"breach_count"`
 }
 
 type HeatmapData struct {
@@ -275,6 +423,75 @@ type ImageRegistryResponse struct {
 	IsRemoved         *bool          `json:"is_removed"`
 }
 
+type ImpactAnalysis struct {
+	DbID               string   `json:"db_id"`
+	PanelIds           []string `json:"panel_ids"`
+	BaselineWindow     *int     `json:"baseline_window"`
+	RecoveryWindow     *int     `json:"recovery_window"`
+	DeviationThreshold *float64 `json:"deviation_threshold"`
+}
+
+type ImpactAnalysisInput struct {
+	DbID               string   `json:"db_id"`
+	PanelIds           []string `json:"panel_ids"`
+	BaselineWindow     *int     `json:"baseline_window"`
+	RecoveryWindow     *int     `json:"recovery_window"`
+	DeviationThreshold *float64 `json:"deviation_threshold"`
+}
+
+type ImpactQueryReport struct {
+	PanelID      string             `json:"panel_id"`
+	PanelName    string             `json:"panel_name"`
+	QueryID      string             `json:"query_id"`
+	Query        string             `json:"query"`
+	Series       string             `json:"series"`
+	Baseline     *ImpactWindowStats `json:"baseline"`
+	Chaos        *ImpactWindowStats `json:"chaos"`
+	Recovery     *ImpactWindowStats `json:"recovery"`
+	Deviation    *float64           `json:"deviation"`
+	RecoveryTime *int               `json:"recovery_time"`
+	Significant  bool               `json:"significant"`
+}
+
+type ImpactReport struct {
+	Status        ImpactReportStatus   `json:"status"`
+	Reason        *string              `json:"reason"`
+	BaselineStart string               `json:"baseline_start"`
+	ChaosStart    string               `json:"chaos_start"`
+	ChaosEnd      string               `json:"chaos_end"`
+	RecoveryEnd   string               `json:"recovery_end"`
+	GeneratedAt   *string              `json:"generated_at"`
+	Significant   bool                 `json:"significant"`
+	Queries       []*ImpactQueryReport `json:"queries"`
+}
+
+type ImpactWindowStats struct {
+	Samples int      `json:"samples"`
+	Mean    *float64 `json:"mean"`
+	P95     *float64 `json:"p95"`
+}
+
+type ImportProjectInput struct {
+	ProjectID      string                `json:"project_id"`
+	Bundle         string                `json:"bundle"`
+	ConflictPolicy *ImportConflictPolicy `json:"conflict_policy"`
+	ClusterMapping []*ClusterMapping     `json:"cluster_mapping"`
+}
+
+type ImportProjectResponse struct {
+	Version   int                 `json:"version"`
+	Resources []*ImportedResource `json:"resources"`
+}
+
+type ImportedResource struct {
+	Kind     string       `json:"kind"`
+	Name     string       `json:"name"`
+	SourceID string       `json:"source_id"`
+	TargetID *string      `json:"target_id"`
+	Status   ImportStatus `json:"status"`
+	Message  *string      `json:"message"`
+}
+
 type KubeGVRRequest struct {
 	Group    string `json:"group"`
 	Version  string `json:"version"`
@@ -362,39 +579,41 @@ type Metadata struct {
 }
 
 type MyHub struct {
-	ID            string   `json:"id"`
-	RepoURL       string   `json:"RepoURL"`
-	RepoBranch    string   `json:"RepoBranch"`
-	ProjectID     string   `json:"ProjectID"`
-	HubName       string   `json:"HubName"`
-	IsPrivate     bool     `json:"IsPrivate"`
-	AuthType      AuthType `json:"AuthType"`
-	Token         *string  `json:"Token"`
-	UserName      *string  `json:"UserName"`
-	Password      *string  `json:"Password"`
-	SSHPrivateKey *string  `json:"SSHPrivateKey"`
-	IsRemoved     bool     `json:"IsRemoved"`
-	CreatedAt     string   `json:"CreatedAt"`
-	UpdatedAt     string   `json:"UpdatedAt"`
-	LastSyncedAt  string   `json:"LastSyncedAt"`
+	ID            string        `json:"id"`
+	RepoURL       string        `json:"RepoURL"`
+	RepoBranch    string        `json:"RepoBranch"`
+	ProjectID     string        `json:"ProjectID"`
+	HubName       string        `json:"HubName"`
+	IsPrivate     bool          `json:"IsPrivate"`
+	AuthType      AuthType      `json:"AuthType"`
+	Token         *string       `json:"Token"`
+	UserName      *string       `json:"UserName"`
+	Password      *string       `json:"Password"`
+	SSHPrivateKey *string       `json:"SSHPrivateKey"`
+	Secrets       []*SecretInfo `json:"Secrets"`
+	IsRemoved     bool          `json:"IsRemoved"`
+	CreatedAt     string        `json:"CreatedAt"`
+	UpdatedAt     string        `json:"UpdatedAt"`
+	LastSyncedAt  string        `json:"LastSyncedAt"`
 }
 
 type MyHubStatus struct {
-	ID            string   `json:"id"`
-	RepoURL       string   `json:"RepoURL"`
-	RepoBranch    string   `json:"RepoBranch"`
-	IsAvailable   bool     `json:"IsAvailable"`
-	TotalExp      string   `json:"TotalExp"`
-	HubName       string   `json:"HubName"`
-	IsPrivate     bool     `json:"IsPrivate"`
-	AuthType      AuthType `json:"AuthType"`
-	Token         *string  `json:"Token"`
-	UserName      *string  `json:"UserName"`
-	Password      *string  `json:"Password"`
-	IsRemoved     bool     `json:"IsRemoved"`
-	SSHPrivateKey *string  `json:"SSHPrivateKey"`
-	SSHPublicKey  *string  `json:"SSHPublicKey"`
-	LastSyncedAt  string   `json:"LastSyncedAt"`
+	ID            string        `json:"id"`
+	RepoURL       string        `json:"RepoURL"`
+	RepoBranch    string        `json:"RepoBranch"`
+	IsAvailable   bool          `json:"IsAvailable"`
+	TotalExp      string        `json:"TotalExp"`
+	HubName       string        `json:"HubName"`
+	IsPrivate     bool          `json:"IsPrivate"`
+	AuthType      AuthType      `json:"AuthType"`
+	Token         *string       `json:"Token"`
+	UserName      *string       `json:"UserName"`
+	Password      *string       `json:"Password"`
+	IsRemoved     bool          `json:"IsRemoved"`
+	SSHPrivateKey *string       `json:"SSHPrivateKey"`
+	SSHPublicKey  *string       `json:"SSHPublicKey"`
+	Secrets       []*SecretInfo `json:"Secrets"`
+	LastSyncedAt  string        `json:"LastSyncedAt"`
 }
 
 type Owner struct {
@@ -440,6 +659,25 @@ type PodLogResponse struct {
 	Log           string `json:"log"`
 }
 
+type PolicyCondition struct {
+	Type               string  `json:"type"`
+	Status             string  `json:"status"`
+	Reason             *string `json:"reason"`
+	Message            *string `json:"message"`
+	LastTransitionTime *string `json:"last_transition_time"`
+}
+
+type PolicyEvaluation struct {
+	ID           string  `json:"id"`
+	TimeStamp    string  `json:"time_stamp"`
+	Resource     string  `json:"resource"`
+	ResourceName string  `json:"resource_name"`
+	Result       string  `json:"result"`
+	WorkflowID   string  `json:"workflow_id"`
+	IsTriggered  string  `json:"is_triggered"`
+	SkipReason   *string `json:"skip_reason"`
+}
+
 type PortalDashboardData struct {
 	Name          string `json:"name"`
 	DashboardData string `json:"dashboard_data"`
@@ -455,6 +693,12 @@ type Project struct {
 	RemovedAt string    `json:"removed_at"`
 }
 
+type ProjectBundle struct {
+	FileName string `json:"file_name"`
+	Version  int    `json:"version"`
+	Content  string `json:"content"`
+}
+
 type ProjectData struct {
 	Name      string        `json:"Name"`
 	Workflows *WorkflowStat `json:"Workflows"`
@@ -467,11 +711,64 @@ type Provider struct {
 	Name string `json:"Name"`
 }
 
+type ReadinessCheckResult struct {
+	RequestID string           `json:"request_id"`
+	ClusterID *ClusterIdentity `json:"cluster_id"`
+	Ready     bool             `json:"ready"`
+	Reason    *string          `json:"reason"`
+}
+
+type Report struct {
+	ReportID      string         `json:"report_id"`
+	ProjectID     string         `json:"project_id"`
+	Formats       []ReportFormat `json:"formats"`
+	PeriodStart   string         `json:"period_start"`
+	PeriodEnd     string         `json:"period_end"`
+	GeneratedAt   string         `json:"generated_at"`
+	Scheduled     bool           `json:"scheduled"`
+	Recipients    []string       `json:"recipients"`
+	Delivered     bool           `json:"delivered"`
+	DeliveryError *string        `json:"delivery_error"`
+}
+
+type ReportSchedule struct {
+	ProjectID  string          `json:"project_id"`
+	Enabled    bool            `json:"enabled"`
+	Frequency  ReportFrequency `json:"frequency"`
+	Day        int             `json:"day"`
+	Hour       int             `json:"hour"`
+	Formats    []ReportFormat  `json:"formats"`
+	Recipients []string        `json:"recipients"`
+	PeriodDays int             `json:"period_days"`
+	NextRunAt  *string         `json:"next_run_at"`
+	LastRunAt  *string         `json:"last_run_at"`
+	LastError  *string         `json:"last_error"`
+	CreatedAt  string          `json:"created_at"`
+	UpdatedAt  string          `json:"updated_at"`
+}
+
+type ReportScheduleInput struct {
+	ProjectID  string          `json:"project_id"`
+	Enabled    bool            `json:"enabled"`
+	Frequency  ReportFrequency `json:"frequency"`
+	Day        *int            `json:"day"`
+	Hour       *int            `json:"hour"`
+	Formats    []ReportFormat  `json:"formats"`
+	Recipients []string        `json:"recipients"`
+	PeriodDays *int            `json:"period_days"`
+}
+
 type SSHKey struct {
 	PublicKey  string `json:"publicKey"`
 	PrivateKey string `json:"privateKey"`
 }
 
+type SecretInfo struct {
+	Name        string       `json:"Name"`
+	Status      SecretStatus `json:"Status"`
+	Fingerprint *string      `json:"Fingerprint"`
+}
+
 type Spec struct {
 	DisplayName         string        `json:"DisplayName"`
 	CategoryDescription string        `json:"CategoryDescription"`
@@ -487,6 +784,32 @@ type Spec struct {
 	ChaosType           *string       `json:"ChaosType"`
 }
 
+type SteadyStateGate struct {
+	Name          string              `json:"name"`
+	Type          SteadyStateGateType `json:"type"`
+	DataSourceID  *string             `json:"data_source_id"`
+	Query         *string             `json:"query"`
+	Operator      *string             `json:"operator"`
+	Threshold     *float64            `json:"threshold"`
+	Kind          *string             `json:"kind"`
+	Namespace     *string             `json:"namespace"`
+	ResourceName  *string             `json:"resource_name"`
+	LabelSelector *string             `json:"label_selector"`
+}
+
+type SteadyStateGateInput struct {
+	Name          string              `json:"name"`
+	Type          SteadyStateGateType `json:"type"`
+	DataSourceID  *string             `json:"data_source_id"`
+	Query         *string             `json:"query"`
+	Operator      *string             `json:"operator"`
+	Threshold     *float64            `json:"threshold"`
+	Kind          *string             `json:"kind"`
+	Namespace     *string             `json:"namespace"`
+	ResourceName  *string             `json:"resource_name"`
+	LabelSelector *string             `json:"label_selector"`
+}
+
 type TemplateInput struct {
 	Manifest            string `json:"manifest"`
 	TemplateName        string `json:"template_name"`
@@ -561,20 +884,34 @@ type WeightagesInput struct {
 }
 
 type Workflow struct {
-	WorkflowID          string        `json:"workflow_id"`
-	WorkflowManifest    string        `json:"workflow_manifest"`
-	CronSyntax          string        `json:"cronSyntax"`
-	ClusterName         string        `json:"cluster_name"`
-	WorkflowName        string        `json:"workflow_name"`
-	WorkflowDescription string        `json:"workflow_description"`
-	Weightages          []*Weightages `json:"weightages"`
-	IsCustomWorkflow    bool          `json:"isCustomWorkflow"`
-	UpdatedAt           string        `json:"updated_at"`
-	CreatedAt           string        `json:"created_at"`
-	ProjectID           string        `json:"project_id"`
-	ClusterID           string        `json:"cluster_id"`
-	ClusterType         string        `json:"cluster_type"`
-	IsRemoved           bool          `json:"isRemoved"`
+	WorkflowID          string             `json:"workflow_id"`
+	WorkflowManifest    string             `json:"workflow_manifest"`
+	CronSyntax          string             `json:"cronSyntax"`
+	ClusterName         string             `json:"cluster_name"`
+	WorkflowName        string             `json:"workflow_name"`
+	WorkflowDescription string             `json:"workflow_description"`
+	Weightages          []*Weightages      `json:"weightages"`
+	IsCustomWorkflow    bool               `json:"isCustomWorkflow"`
+	UpdatedAt           string             `json:"updated_at"`
+	CreatedAt           string             `json:"created_at"`
+	ProjectID           string             `json:"project_id"`
+	ClusterID           string             `json:"cluster_id"`
+	ClusterType         string             `json:"cluster_type"`
+	IsRemoved           bool               `json:"isRemoved"`
+	DriftStatus         *DriftStatus       `json:"drift_status"`
+	SteadyStateGates    []*SteadyStateGate `json:"steady_state_gates"`
+	Guardrails          []*Guardrail       `json:"guardrails"`
+	ImpactAnalysis      *ImpactAnalysis    `json:"impact_analysis"`
+}
+
+type WorkflowDrift struct {
+	WorkflowID     string      `json:"workflow_id"`
+	WorkflowName   string      `json:"workflow_name"`
+	DriftStatus    DriftStatus `json:"drift_status"`
+	DetectedAt     *string     `json:"detected_at"`
+	PortalManifest string      `json:"portal_manifest"`
+	GitManifest    *string     `json:"git_manifest"`
+	Diff           string      `json:"diff"`
 }
 
 type WorkflowFilterInput struct {
@@ -602,6 +939,11 @@ type WorkflowRun struct {
 	TotalExperiments   *int          `json:"total_experiments"`
 	ExecutionData      string        `json:"execution_data"`
 	IsRemoved          *bool         `json:"isRemoved"`
+	SkipReason         *string       `json:"skip_reason"`
+	AbortedBy          *string       `json:"aborted_by"`
+	AbortReason        *string       `json:"abort_reason"`
+	ImpactReport       *ImpactReport `json:"impact_report"`
+	Alert              *AlertEvent   `json:"alert"`
 }
 
 type WorkflowRunDetails struct {
@@ -712,6 +1054,7 @@ type CreateDBInput struct {
 	ChaosEventQueryTemplate   string                 `json:"chaos_event_query_template"`
 	ChaosVerdictQueryTemplate string                 `json:"chaos_verdict_query_template"`
 	ApplicationMetadataMap    []*ApplicationMetadata `json:"application_metadata_map"`
+	Variables                 []*DashboardVariable   `json:"variables"`
 	PanelGroups               []*PanelGroup          `json:"panel_groups"`
 	EndTime                   string                 `json:"end_time"`
 	StartTime                 string                 `json:"start_time"`
@@ -725,12 +1068,56 @@ type DashboardPromResponse struct {
 	AnnotationsResponse      []*AnnotationsPromResponse `json:"annotationsResponse"`
 }
 
+type DashboardVariable struct {
+	Name       string   `json:"name"`
+	Label      *string  `json:"label"`
+	Type       string   `json:"type"`
+	Query      *string  `json:"query"`
+	Options    []string `json:"options"`
+	Current    []string `json:"current"`
+	Multi      *bool    `json:"multi"`
+	IncludeAll *bool    `json:"include_all"`
+}
+
+type DashboardVariableOptions struct {
+	Name    string   `json:"name"`
+	Options []string `json:"options"`
+}
+
+type DashboardVariableResponse struct {
+	Name       string   `json:"name"`
+	Label      *string  `json:"label"`
+	Type       string   `json:"type"`
+	Query      *string  `json:"query"`
+	Options    []string `json:"options"`
+	Current    []string `json:"current"`
+	Multi      *bool    `json:"multi"`
+	IncludeAll *bool    `json:"include_all"`
+}
+
+type DataSourceHealth struct {
+	DsID             string                   `json:"ds_id"`
+	HealthStatus     string                   `json:"health_status"`
+	HealthCheckedAt  *string                  `json:"health_checked_at"`
+	HealthChangedAt  *string                  `json:"health_changed_at"`
+	Window           int                      `json:"window"`
+	UptimePercentage *float64                 `json:"uptime_percentage"`
+	History          []*DataSourceHealthEvent `json:"history"`
+}
+
+type DataSourceHealthEvent struct {
+	HealthStatus string  `json:"health_status"`
+	StartedAt    string  `json:"started_at"`
+	EndedAt      *string `json:"ended_at"`
+}
+
 type DataVars struct {
-	URL             string `json:"url"`
-	Start           string `json:"start"`
-	End             string `json:"end"`
-	RelativeTime    int    `json:"relative_time"`
-	RefreshInterval int    `json:"refresh_interval"`
+	URL             string           `json:"url"`
+	Start           string           `json:"start"`
+	End             string           `json:"end"`
+	RelativeTime    int              `json:"relative_time"`
+	RefreshInterval int              `json:"refresh_interval"`
+	Variables       []*VariableValue `json:"variables"`
 }
 
 type DeleteDSInput struct {
@@ -739,9 +1126,10 @@ type DeleteDSInput struct {
 }
 
 type DsDetails struct {
-	URL   string `json:"url"`
-	Start string `json:"start"`
-	End   string `json:"end"`
+	DsID  *string `json:"ds_id"`
+	URL   string  `json:"url"`
+	Start string  `json:"start"`
+	End   string  `json:"end"`
 }
 
 type ImageRegistry struct {
@@ -764,6 +1152,16 @@ type ImageRegistryInput struct {
 	EnableRegistry    *bool   `json:"enable_registry"`
 }
 
+type ImportDBInput struct {
+	ProjectID                 string  `json:"project_id"`
+	ClusterID                 string  `json:"cluster_id"`
+	DsID                      string  `json:"ds_id"`
+	GrafanaJSON               string  `json:"grafana_json"`
+	DbName                    *string `json:"db_name"`
+	ChaosEventQueryTemplate   *string `json:"chaos_event_query_template"`
+	ChaosVerdictQueryTemplate *string `json:"chaos_verdict_query_template"`
+}
+
 type LabelValue struct {
 	Label  string    `json:"label"`
 	Values []*Option `json:"values"`
@@ -779,6 +1177,7 @@ type ListDashboardResponse struct {
 	ChaosEventQueryTemplate   string                         `json:"chaos_event_query_template"`
 	ChaosVerdictQueryTemplate string                         `json:"chaos_verdict_query_template"`
 	ApplicationMetadataMap    []*ApplicationMetadataResponse `json:"application_metadata_map"`
+	Variables                 []*DashboardVariableResponse   `json:"variables"`
 	ClusterName               *string                        `json:"cluster_name"`
 	DsName                    *string                        `json:"ds_name"`
 	DsType                    *string                        `json:"ds_type"`
@@ -880,16 +1279,24 @@ type PromQuery struct {
 	Legend        *string `json:"legend"`
 	Resolution    *string `json:"resolution"`
 	Minstep       *string `json:"minstep"`
+	Instant       *bool   `json:"instant"`
 	Line          *bool   `json:"line"`
 	CloseArea     *bool   `json:"close_area"`
 }
 
+type PromQueryError struct {
+	Message string `json:"message"`
+	Line    *int   `json:"line"`
+	Column  *int   `json:"column"`
+}
+
 type PromQueryInput struct {
 	Queryid    string  `json:"queryid"`
 	Query      string  `json:"query"`
 	Legend     *string `json:"legend"`
 	Resolution *string `json:"resolution"`
 	Minstep    int     `json:"minstep"`
+	Instant    *bool   `json:"instant"`
 }
 
 type PromQueryResponse struct {
@@ -898,10 +1305,17 @@ type PromQueryResponse struct {
 	Legend        *string `json:"legend"`
 	Resolution    *string `json:"resolution"`
 	Minstep       *string `json:"minstep"`
+	Instant       *bool   `json:"instant"`
 	Line          *bool   `json:"line"`
 	CloseArea     *bool   `json:"close_area"`
 }
 
+type PromQueryValidation struct {
+	Queryid string            `json:"queryid"`
+	Valid   bool              `json:"valid"`
+	Errors  []*PromQueryError `json:"errors"`
+}
+
 type PromResponse struct {
 	MetricsResponse     []*MetricsPromResponse     `json:"metricsResponse"`
 	AnnotationsResponse []*AnnotationsPromResponse `json:"annotationsResponse"`
@@ -957,6 +1371,7 @@ type UpdateDBInput struct {
 	ChaosEventQueryTemplate   *string                  `json:"chaos_event_query_template"`
 	ChaosVerdictQueryTemplate *string                  `json:"chaos_verdict_query_template"`
 	ApplicationMetadataMap    []*ApplicationMetadata   `json:"application_metadata_map"`
+	Variables                 []*DashboardVariable     `json:"variables"`
 	PanelGroups               []*UpdatePanelGroupInput `json:"panel_groups"`
 	EndTime                   *string                  `json:"end_time"`
 	StartTime                 *string                  `json:"start_time"`
@@ -970,11 +1385,102 @@ type UpdatePanelGroupInput struct {
 	Panels         []*Panel `json:"panels"`
 }
 
+type VariableValue struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
 type Weightages struct {
 	ExperimentName string `json:"experiment_name"`
 	Weightage      int    `json:"weightage"`
 }
 
+type AlertRuleType string
+
+const (
+	AlertRuleTypeResiliencyScore    AlertRuleType = "ResiliencyScore"
+	AlertRuleTypeExperimentFailures AlertRuleType = "ExperimentFailures"
+	AlertRuleTypeMissedSchedule     AlertRuleType = "MissedSchedule"
+)
+
+var AllAlertRuleType = []AlertRuleType{
+	AlertRuleTypeResiliencyScore,
+	AlertRuleTypeExperimentFailures,
+	AlertRuleTypeMissedSchedule,
+}
+
+func (e AlertRuleType) IsValid() bool {
+	switch e {
+	case AlertRuleTypeResiliencyScore, AlertRuleTypeExperimentFailures, AlertRuleTypeMissedSchedule:
+		return true
+	}
+	return false
+}
+
+func (e AlertRuleType) String() string {
+	return string(e)
+}
+
+func (e *AlertRuleType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AlertRuleType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AlertRuleType", str)
+	}
+	return nil
+}
+
+func (e AlertRuleType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type AlertState string
+
+const (
+	AlertStateOk      AlertState = "Ok"
+	AlertStatePending AlertState = "Pending"
+	AlertStateFiring  AlertState = "Firing"
+)
+
+var AllAlertState = []AlertState{
+	AlertStateOk,
+	AlertStatePending,
+	AlertStateFiring,
+}
+
+func (e AlertState) IsValid() bool {
+	switch e {
+	case AlertStateOk, AlertStatePending, AlertStateFiring:
+		return true
+	}
+	return false
+}
+
+func (e AlertState) String() string {
+	return string(e)
+}
+
+func (e *AlertState) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AlertState(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AlertState", str)
+	}
+	return nil
+}
+
+func (e AlertState) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type AuthType string
 
 const (
@@ -1020,6 +1526,223 @@ func (e AuthType) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type DriftResolution string
+
+const (
+	DriftResolutionTakeGit    DriftResolution = "TakeGit"
+	DriftResolutionTakePortal DriftResolution = "TakePortal"
+)
+
+var AllDriftResolution = []DriftResolution{
+	DriftResolutionTakeGit,
+	DriftResolutionTakePortal,
+}
+
+func (e DriftResolution) IsValid() bool {
+	switch e {
+	case DriftResolutionTakeGit, DriftResolutionTakePortal:
+		return true
+	}
+	return false
+}
+
+func (e DriftResolution) String() string {
+	return string(e)
+}
+
+func (e *DriftResolution) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DriftResolution(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DriftResolution", str)
+	}
+	return nil
+}
+
+func (e DriftResolution) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type DriftStatus string
+
+const (
+	DriftStatusInSync       DriftStatus = "InSync"
+	DriftStatusDiverged     DriftStatus = "Diverged"
+	DriftStatusMissingInGit DriftStatus = "MissingInGit"
+)
+
+var AllDriftStatus = []DriftStatus{
+	DriftStatusInSync,
+	DriftStatusDiverged,
+	DriftStatusMissingInGit,
+}
+
+func (e DriftStatus) IsValid() bool {
+	switch e {
+	case DriftStatusInSync, DriftStatusDiverged, DriftStatusMissingInGit:
+		return true
+	}
+	return false
+}
+
+func (e DriftStatus) String() string {
+	return string(e)
+}
+
+func (e *DriftStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DriftStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DriftStatus", str)
+	}
+	return nil
+}
+
+func (e DriftStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ImpactReportStatus string
+
+const (
+	ImpactReportStatusPending   ImpactReportStatus = "Pending"
+	ImpactReportStatusCompleted ImpactReportStatus = "Completed"
+	ImpactReportStatusFailed    ImpactReportStatus = "Failed"
+)
+
+var AllImpactReportStatus = []ImpactReportStatus{
+	ImpactReportStatusPending,
+	ImpactReportStatusCompleted,
+	ImpactReportStatusFailed,
+}
+
+func (e ImpactReportStatus) IsValid() bool {
+	switch e {
+	case ImpactReportStatusPending, ImpactReportStatusCompleted, ImpactReportStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (e ImpactReportStatus) String() string {
+	return string(e)
+}
+
+func (e *ImpactReportStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ImpactReportStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ImpactReportStatus", str)
+	}
+	return nil
+}
+
+func (e ImpactReportStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ImportConflictPolicy string
+
+const (
+	ImportConflictPolicySkip      ImportConflictPolicy = "Skip"
+	ImportConflictPolicyOverwrite ImportConflictPolicy = "Overwrite"
+	ImportConflictPolicyRename    ImportConflictPolicy = "Rename"
+)
+
+var AllImportConflictPolicy = []ImportConflictPolicy{
+	ImportConflictPolicySkip,
+	ImportConflictPolicyOverwrite,
+	ImportConflictPolicyRename,
+}
+
+func (e ImportConflictPolicy) IsValid() bool {
+	switch e {
+	case ImportConflictPolicySkip, ImportConflictPolicyOverwrite, ImportConflictPolicyRename:
+		return true
+	}
+	return false
+}
+
+func (e ImportConflictPolicy) String() string {
+	return string(e)
+}
+
+func (e *ImportConflictPolicy) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ImportConflictPolicy(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ImportConflictPolicy", str)
+	}
+	return nil
+}
+
+func (e ImportConflictPolicy) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ImportStatus string
+
+const (
+	ImportStatusCreated     ImportStatus = "Created"
+	ImportStatusOverwritten ImportStatus = "Overwritten"
+	ImportStatusRenamed     ImportStatus = "Renamed"
+	ImportStatusSkipped     ImportStatus = "Skipped"
+	ImportStatusFailed      ImportStatus = "Failed"
+)
+
+var AllImportStatus = []ImportStatus{
+	ImportStatusCreated,
+	ImportStatusOverwritten,
+	ImportStatusRenamed,
+	ImportStatusSkipped,
+	ImportStatusFailed,
+}
+
+func (e ImportStatus) IsValid() bool {
+	switch e {
+	case ImportStatusCreated, ImportStatusOverwritten, ImportStatusRenamed, ImportStatusSkipped, ImportStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (e ImportStatus) String() string {
+	return string(e)
+}
+
+func (e *ImportStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ImportStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ImportStatus", str)
+	}
+	return nil
+}
+
+func (e ImportStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type MemberRole string
 
 const (
@@ -1063,6 +1786,172 @@ func (e MemberRole) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type ReportFormat string
+
+const (
+	ReportFormatHTML ReportFormat = "HTML"
+	ReportFormatPdf  ReportFormat = "PDF"
+)
+
+var AllReportFormat = []ReportFormat{
+	ReportFormatHTML,
+	ReportFormatPdf,
+}
+
+func (e ReportFormat) IsValid() bool {
+	switch e {
+	case ReportFormatHTML, ReportFormatPdf:
+		return true
+	}
+	return false
+}
+
+func (e ReportFormat) String() string {
+	return string(e)
+}
+
+func (e *ReportFormat) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReportFormat(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReportFormat", str)
+	}
+	return nil
+}
+
+func (e ReportFormat) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ReportFrequency string
+
+const (
+	ReportFrequencyDaily   ReportFrequency = "Daily"
+	ReportFrequencyWeekly  ReportFrequency = "Weekly"
+	ReportFrequencyMonthly ReportFrequency = "Monthly"
+)
+
+var AllReportFrequency = []ReportFrequency{
+	ReportFrequencyDaily,
+	ReportFrequencyWeekly,
+	ReportFrequencyMonthly,
+}
+
+func (e ReportFrequency) IsValid() bool {
+	switch e {
+	case ReportFrequencyDaily, ReportFrequencyWeekly, ReportFrequencyMonthly:
+		return true
+	}
+	return false
+}
+
+func (e ReportFrequency) String() string {
+	return string(e)
+}
+
+func (e *ReportFrequency) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReportFrequency(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReportFrequency", str)
+	}
+	return nil
+}
+
+func (e ReportFrequency) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type SecretStatus string
+
+const (
+	SecretStatusSet   SecretStatus = "set"
+	SecretStatusUnset SecretStatus = "unset"
+)
+
+var AllSecretStatus = []SecretStatus{
+	SecretStatusSet,
+	SecretStatusUnset,
+}
+
+func (e SecretStatus) IsValid() bool {
+	switch e {
+	case SecretStatusSet, SecretStatusUnset:
+		return true
+	}
+	return false
+}
+
+func (e SecretStatus) String() string {
+	return string(e)
+}
+
+func (e *SecretStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SecretStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SecretStatus", str)
+	}
+	return nil
+}
+
+func (e SecretStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type SteadyStateGateType string
+
+const (
+	SteadyStateGateTypePrometheus          SteadyStateGateType = "Prometheus"
+	SteadyStateGateTypeKubernetesReadiness SteadyStateGateType = "KubernetesReadiness"
+)
+
+var AllSteadyStateGateType = []SteadyStateGateType{
+	SteadyStateGateTypePrometheus,
+	SteadyStateGateTypeKubernetesReadiness,
+}
+
+func (e SteadyStateGateType) IsValid() bool {
+	switch e {
+	case SteadyStateGateTypePrometheus, SteadyStateGateTypeKubernetesReadiness:
+		return true
+	}
+	return false
+}
+
+func (e SteadyStateGateType) String() string {
+	return string(e)
+}
+
+func (e *SteadyStateGateType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SteadyStateGateType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SteadyStateGateType", str)
+	}
+	return nil
+}
+
+func (e SteadyStateGateType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type TimeFrequency string
 
 const (
@@ -15,6 +15,7 @@ import (
 	"github.com/jinzhu/copier"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/generated"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	alertHandler "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/alerts/handler"
 	analyticsHandler "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics/handler"
 	analyticsOps "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics/ops"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/authorization"
@@ -23,11 +24,13 @@ import (
 	clusterHandler "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/cluster/handler"
 	data_store "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/data-store"
 	dbOperationsCluster "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/cluster"
+	eventTrackerHandler "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/eventtracker/handler"
 	gitOpsHandler "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/gitops/handler"
 	imageRegistryOps "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/image_registry/ops"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/myhub"
 	myHubOps "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/myhub/ops"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/project"
+	reportHandler "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/report/handler"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/usage"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/usermanagement"
 	"go.mongodb.org/mongo-driver/bson"
@@ -59,6 +62,15 @@ func (r *mutationResolver) CreateProject(ctx context.Context, projectName string
 	return project.CreateProjectWithUser(ctx, projectName, userUID)
 }
 
+func (r *mutationResolver) ImportProject(ctx context.Context, input model.ImportProjectInput) (*model.ImportProjectResponse, error) {
+	err := authorization.ValidateRole(ctx, input.ProjectID, []model.MemberRole{model.MemberRoleOwner}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return nil, err
+	}
+
+	return project.ImportProject(ctx, input, data_store.Store)
+}
+
 func (r *mutationResolver) UpdateUser(ctx context.Context, user model.UpdateUserInput) (string, error) {
 	return usermanagement.UpdateUser(ctx, user)
 }
@@ -181,6 +193,10 @@ func (r *mutationResolver) KubeObj(ctx context.Context, kubeData model.KubeObjec
 	return wfHandler.KubeObjHandler(kubeData, *data_store.Store)
 }
 
+func (r *mutationResolver) ReadinessCheckResult(ctx context.Context, result model.ReadinessCheckResult) (string, error) {
+	return wfHandler.ReadinessCheckHandler(result, *data_store.Store)
+}
+
 func (r *mutationResolver) AddMyHub(ctx context.Context, myhubInput model.CreateMyHub, projectID string) (*model.MyHub, error) {
 	err := authorization.ValidateRole(ctx, projectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor}, usermanagement.AcceptedInvitation)
 	if err != nil {
@@ -243,6 +259,86 @@ func (r *mutationResolver) GitopsNotifer(ctx context.Context, clusterInfo model.
 	return gitOpsHandler.GitOpsNotificationHandler(ctx, clusterInfo, workflowID)
 }
 
+func (r *mutationResolver) RotateNotifierToken(ctx context.Context, projectID string, clusterID string) (bool, error) {
+	err := authorization.ValidateRole(ctx, projectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return false, err
+	}
+	return clusterHandler.RotateNotifierToken(projectID, clusterID, *data_store.Store)
+}
+
+func (r *mutationResolver) CreateEventTrackerPolicy(ctx context.Context, policy model.EventTrackerPolicyInput) (*model.EventTrackerPolicy, error) {
+	err := authorization.ValidateRole(ctx, policy.ProjectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return nil, err
+	}
+	return eventTrackerHandler.CreatePolicy(ctx, policy, *data_store.Store)
+}
+
+func (r *mutationResolver) UpdateEventTrackerPolicy(ctx context.Context, policy model.EventTrackerPolicyInput) (*model.EventTrackerPolicy, error) {
+	err := authorization.ValidateRole(ctx, policy.ProjectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return nil, err
+	}
+	return eventTrackerHandler.UpdatePolicy(ctx, policy, *data_store.Store)
+}
+
+func (r *mutationResolver) DeleteEventTrackerPolicy(ctx context.Context, projectID string, clusterID string, name string) (bool, error) {
+	err := authorization.ValidateRole(ctx, projectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return false, err
+	}
+	return eventTrackerHandler.DeletePolicy(ctx, projectID, clusterID, name, *data_store.Store)
+}
+
+func (r *mutationResolver) CreateAlertRule(ctx context.Context, rule model.AlertRuleInput) (*model.AlertRule, error) {
+	err := authorization.ValidateRole(ctx, rule.ProjectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return nil, err
+	}
+	return alertHandler.CreateAlertRule(ctx, rule, data_store.Store)
+}
+
+func (r *mutationResolver) UpdateAlertRule(ctx context.Context, rule model.AlertRuleInput) (*model.AlertRule, error) {
+	err := authorization.ValidateRole(ctx, rule.ProjectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return nil, err
+	}
+	return alertHandler.UpdateAlertRule(ctx, rule, data_store.Store)
+}
+
+func (r *mutationResolver) DeleteAlertRule(ctx context.Context, projectID string, ruleID string) (bool, error) {
+	err := authorization.ValidateRole(ctx, projectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return false, err
+	}
+	return alertHandler.DeleteAlertRule(ctx, projectID, ruleID)
+}
+
+func (r *mutationResolver) SaveReportSchedule(ctx context.Context, schedule model.ReportScheduleInput) (*model.ReportSchedule, error) {
+	err := authorization.ValidateRole(ctx, schedule.ProjectID, []model.MemberRole{model.MemberRoleOwner}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return nil, err
+	}
+	return reportHandler.SaveSchedule(ctx, schedule)
+}
+
+func (r *mutationResolver) DeleteReportSchedule(ctx context.Context, projectID string) (bool, error) {
+	err := authorization.ValidateRole(ctx, projectID, []model.MemberRole{model.MemberRoleOwner}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return false, err
+	}
+	return reportHandler.DeleteSchedule(ctx, projectID)
+}
+
+func (r *mutationResolver) GenerateReport(ctx context.Context, input model.GenerateReportInput) (*model.Report, error) {
+	err := authorization.ValidateRole(ctx, input.ProjectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return nil, err
+	}
+	return reportHandler.GenerateReport(ctx, input)
+}
+
 func (r *mutationResolver) EnableGitOps(ctx context.Context, config model.GitConfig) (bool, error) {
 	err := authorization.ValidateRole(ctx, config.ProjectID, []model.MemberRole{model.MemberRoleOwner}, usermanagement.AcceptedInvitation)
 	if err != nil {
@@ -267,6 +363,14 @@ func (r *mutationResolver) UpdateGitOps(ctx context.Context, config model.GitCon
 	return gitOpsHandler.UpdateGitOpsDetailsHandler(ctx, config)
 }
 
+func (r *mutationResolver) ResolveWorkflowDrift(ctx context.Context, projectID string, workflowID string, resolution model.DriftResolution) (bool, error) {
+	err := authorization.ValidateRole(ctx, projectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return false, err
+	}
+	return gitOpsHandler.ResolveWorkflowDriftHandler(ctx, projectID, workflowID, resolution)
+}
+
 func (r *mutationResolver) CreateDataSource(ctx context.Context, datasource *model.DSInput) (*model.DSResponse, error) {
 	return analyticsHandler.CreateDataSource(datasource)
 }
@@ -295,6 +399,14 @@ func (r *mutationResolver) DeleteDataSource(ctx context.Context, input model.Del
 	return analyticsHandler.DeleteDataSource(input)
 }
 
+func (r *mutationResolver) ImportDashboard(ctx context.Context, dashboard model.ImportDBInput) (*model.ListDashboardResponse, error) {
+	err := authorization.ValidateRole(ctx, dashboard.ProjectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return nil, err
+	}
+	return analyticsHandler.ImportDashboard(dashboard)
+}
+
 func (r *mutationResolver) CreateManifestTemplate(ctx context.Context, templateInput *model.TemplateInput) (*model.ManifestTemplate, error) {
 	return wfHandler.SaveWorkflowTemplate(ctx, templateInput)
 }
@@ -369,6 +481,15 @@ func (r *queryResolver) ListProjects(ctx context.Context) ([]*model.Project, err
 	return project.GetProjectsByUserID(ctx, userUID)
 }
 
+func (r *queryResolver) ExportProject(ctx context.Context, projectID string, includeSecrets *bool) (*model.ProjectBundle, error) {
+	err := authorization.ValidateRole(ctx, projectID, []model.MemberRole{model.MemberRoleOwner}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return nil, err
+	}
+
+	return project.ExportProject(ctx, projectID, includeSecrets != nil && *includeSecrets)
+}
+
 func (r *queryResolver) Users(ctx context.Context) ([]*model.User, error) {
 	return usermanagement.GetUsers(ctx)
 }
@@ -445,23 +566,67 @@ func (r *queryResolver) ListDataSource(ctx context.Context, projectID string) ([
 	return analyticsHandler.QueryListDataSource(projectID)
 }
 
+func (r *queryResolver) GetDataSourceHealth(ctx context.Context, projectID string, dsID string, window *int) (*model.DataSourceHealth, error) {
+	err := authorization.ValidateRole(ctx, projectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor, model.MemberRoleViewer}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return nil, err
+	}
+	return analyticsHandler.GetDataSourceHealth(projectID, dsID, window)
+}
+
 func (r *queryResolver) GetPromQuery(ctx context.Context, query *model.PromInput) (*model.PromResponse, error) {
-	promResponseData, _, err := analyticsHandler.GetPromQuery(query)
+	config, err := analyticsHandler.ResolveDataSourceConfig(ctx, query.DsDetails.DsID, query.DsDetails.URL)
+	if err != nil {
+		return nil, err
+	}
+	promResponseData, _, err := analyticsHandler.GetPromQuery(query, config)
 	return promResponseData, err
 }
 
 func (r *queryResolver) GetPromLabelNamesAndValues(ctx context.Context, series *model.PromSeriesInput) (*model.PromSeriesResponse, error) {
-	return analyticsHandler.GetLabelNamesAndValues(series)
+	config, err := analyticsHandler.ResolveDataSourceConfig(ctx, series.DsDetails.DsID, series.DsDetails.URL)
+	if err != nil {
+		return nil, err
+	}
+	return analyticsHandler.GetLabelNamesAndValues(series, config)
 }
 
 func (r *queryResolver) GetPromSeriesList(ctx context.Context, dsDetails *model.DsDetails) (*model.PromSeriesListResponse, error) {
-	return analyticsHandler.GetSeriesList(dsDetails)
+	config, err := analyticsHandler.ResolveDataSourceConfig(ctx, dsDetails.DsID, dsDetails.URL)
+	if err != nil {
+		return nil, err
+	}
+	return analyticsHandler.GetSeriesList(dsDetails, config)
+}
+
+func (r *queryResolver) ValidatePromQueries(ctx context.Context, query model.PromInput) ([]*model.PromQueryValidation, error) {
+	config, err := analyticsHandler.ResolveDataSourceConfig(ctx, query.DsDetails.DsID, query.DsDetails.URL)
+	if err != nil {
+		return nil, err
+	}
+	return analyticsHandler.ValidatePromQueries(&query, config)
 }
 
 func (r *queryResolver) ListDashboard(ctx context.Context, projectID string, clusterID *string, dbID *string) ([]*model.ListDashboardResponse, error) {
 	return analyticsHandler.QueryListDashboard(projectID, clusterID, dbID)
 }
 
+func (r *queryResolver) GetDashboardVariableOptions(ctx context.Context, projectID string, dbID string) ([]*model.DashboardVariableOptions, error) {
+	err := authorization.ValidateRole(ctx, projectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor, model.MemberRoleViewer}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return nil, err
+	}
+	return analyticsHandler.GetDashboardVariableOptions(projectID, dbID)
+}
+
+func (r *queryResolver) ExportDashboard(ctx context.Context, projectID string, dbID string) (string, error) {
+	err := authorization.ValidateRole(ctx, projectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor, model.MemberRoleViewer}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return "", err
+	}
+	return analyticsHandler.ExportDashboard(projectID, dbID)
+}
+
 func (r *queryResolver) PortalDashboardData(ctx context.Context, projectID string, hubName string) ([]*model.PortalDashboardData, error) {
 	err := authorization.ValidateRole(ctx, projectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor, model.MemberRoleViewer}, usermanagement.AcceptedInvitation)
 	if err != nil {
@@ -474,6 +639,14 @@ func (r *queryResolver) GetGitOpsDetails(ctx context.Context, projectID string)
 	return gitOpsHandler.GetGitOpsDetailsHandler(ctx, projectID)
 }
 
+func (r *queryResolver) GetWorkflowDrift(ctx context.Context, projectID string, workflowID *string) ([]*model.WorkflowDrift, error) {
+	err := authorization.ValidateRole(ctx, projectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor, model.MemberRoleViewer}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return nil, err
+	}
+	return gitOpsHandler.GetWorkflowDriftHandler(ctx, projectID, workflowID)
+}
+
 func (r *queryResolver) ListManifestTemplate(ctx context.Context, projectID string) ([]*model.ManifestTemplate, error) {
 	return wfHandler.ListWorkflowTemplate(ctx, projectID)
 }
@@ -508,6 +681,62 @@ func (r *queryResolver) UsageQuery(ctx context.Context, query model.UsageQuery)
 	return usage.GetUsage(ctx, query)
 }
 
+func (r *queryResolver) ListEventTrackerPolicies(ctx context.Context, projectID string, clusterID *string) ([]*model.EventTrackerPolicy, error) {
+	err := authorization.ValidateRole(ctx, projectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor, model.MemberRoleViewer}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return nil, err
+	}
+	return eventTrackerHandler.ListPolicies(ctx, projectID, clusterID)
+}
+
+func (r *queryResolver) GetEventTrackerPolicy(ctx context.Context, projectID string, clusterID string, name string) (*model.EventTrackerPolicy, error) {
+	err := authorization.ValidateRole(ctx, projectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor, model.MemberRoleViewer}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return nil, err
+	}
+	return eventTrackerHandler.GetPolicy(ctx, projectID, clusterID, name)
+}
+
+func (r *queryResolver) ListAlertRules(ctx context.Context, projectID string) ([]*model.AlertRule, error) {
+	err := authorization.ValidateRole(ctx, projectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor, model.MemberRoleViewer}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return nil, err
+	}
+	return alertHandler.ListAlertRules(ctx, projectID)
+}
+
+func (r *queryResolver) GetAlertRule(ctx context.Context, projectID string, ruleID string) (*model.AlertRule, error) {
+	err := authorization.ValidateRole(ctx, projectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor, model.MemberRoleViewer}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return nil, err
+	}
+	return alertHandler.GetAlertRule(ctx, projectID, ruleID)
+}
+
+func (r *queryResolver) GetReportSchedule(ctx context.Context, projectID string) (*model.ReportSchedule, error) {
+	err := authorization.ValidateRole(ctx, projectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor, model.MemberRoleViewer}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return nil, err
+	}
+	return reportHandler.GetSchedule(ctx, projectID)
+}
+
+func (r *queryResolver) ListReports(ctx context.Context, projectID string) ([]*model.Report, error) {
+	err := authorization.ValidateRole(ctx, projectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor, model.MemberRoleViewer}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return nil, err
+	}
+	return reportHandler.ListReports(ctx, projectID)
+}
+
+func (r *queryResolver) DownloadReport(ctx context.Context, projectID string, reportID string, format model.ReportFormat) (string, error) {
+	err := authorization.ValidateRole(ctx, projectID, []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor, model.MemberRoleViewer}, usermanagement.AcceptedInvitation)
+	if err != nil {
+		return "", err
+	}
+	return reportHandler.DownloadReport(ctx, projectID, reportID, format)
+}
+
 func (r *subscriptionResolver) ClusterEventListener(ctx context.Context, projectID string) (<-chan *model.ClusterEvent, error) {
 	log.Print("NEW EVENT ", projectID)
 	clusterEvent := make(chan *model.ClusterEvent, 1)
@@ -597,6 +826,15 @@ func (r *subscriptionResolver) ClusterConnect(ctx context.Context, clusterInfo m
 		return clusterAction, err
 	}
 
+	// agents which don't hold the current token for gitops notifications receive a new one, so a token whose
+	// delivery failed is issued again on the next connection. Agents which don't report their token can't store it
+	if reported := clusterInfo.NotifierTokenHash; reported != nil && (*reported == "" || *reported != verifiedCluster.NotifierTokenHash) {
+		err = clusterHandler.IssueNotifierToken(clusterInfo.ClusterID, *data_store.Store)
+		if err != nil {
+			log.Print("Failed to issue notifier token : ", err)
+		}
+	}
+
 	newVerifiedCluster := model.Cluster{}
 	copier.Copy(&newVerifiedCluster, &verifiedCluster)
 
@@ -622,6 +860,18 @@ func (r *subscriptionResolver) GetKubeObject(ctx context.Context, kubeObjectRequ
 }
 
 func (r *subscriptionResolver) ViewDashboard(ctx context.Context, dashboardID *string, promQueries []*model.PromQueryInput, dashboardQueryMap []*model.QueryMapForPanelGroup, dataVariables model.DataVars) (<-chan *model.DashboardPromResponse, error) {
+	dashboard, err := analyticsHandler.GetViewedDashboard(ctx, dashboardID)
+	if err != nil {
+		return nil, err
+	}
+	var dsID *string
+	if dashboard != nil {
+		dsID = &dashboard.DsID
+	}
+	config, err := analyticsHandler.ResolveDataSourceConfig(ctx, dsID, dataVariables.URL)
+	if err != nil {
+		return nil, err
+	}
 	dashboardData := make(chan *model.DashboardPromResponse)
 	viewID := uuid.New()
 	log.Printf("Dashboard view %v created\n", viewID.String())
@@ -639,7 +889,7 @@ func (r *subscriptionResolver) ViewDashboard(ctx context.Context, dashboardID *s
 			data_store.Store.Mutex.Unlock()
 		}
 	}()
-	go analyticsHandler.DashboardViewer(viewID.String(), dashboardID, promQueries, dashboardQueryMap, dataVariables, *data_store.Store)
+	go analyticsHandler.DashboardViewer(viewID.String(), dashboardID, dashboard, promQueries, dashboardQueryMap, dataVariables, config, *data_store.Store)
 	return dashboardData, nil
 }
 
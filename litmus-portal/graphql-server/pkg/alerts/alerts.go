@@ -0,0 +1,403 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	types "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/chaos-workflow"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/chaos-workflow/ops"
+	store "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/data-store"
+	dbAlert "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/alert"
+	dbOperationsWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+	dbSchemaWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+)
+
+const (
+	// DefaultConsecutiveFailures is the number of failed runs in a row after which experiment failure rules fire
+	DefaultConsecutiveFailures = 3
+	// MaxConsecutiveFailures is the largest number of failed runs in a row experiment failure rules can wait for
+	MaxConsecutiveFailures = 100
+	// DefaultGracePeriod is the time in seconds cron workflows have to run after their scheduled time
+	DefaultGracePeriod = 600
+
+	evaluationInterval = time.Minute
+	failedVerdict      = "Fail"
+)
+
+// evaluations of the rules aren't run concurrently on a server, the state of a rule is the last one evaluated
+var evaluationMutex sync.Mutex
+
+// condition is a workflow, or an experiment of a workflow, for which the condition of a rule holds
+type condition struct {
+	workflow   *dbSchemaWorkflow.ChaosWorkFlowInput
+	run        *dbSchemaWorkflow.ChaosWorkflowRun
+	experiment string
+	message    string
+}
+
+// ValidateSchedule checks that the schedule of a cron workflow can be evaluated by missed schedule rules
+func ValidateSchedule(cronSyntax string) error {
+	_, err := ops.ParseCron(cronSyntax)
+	return err
+}
+
+// RecurringEvaluation evaluates the enabled alert rules every minute, besides the evaluations on run completion
+// this fires the missed schedule rules and the rules which are pending
+func RecurringEvaluation(r *store.StateData) {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		rules, err := dbAlert.ListAlertRules(ctx, bson.D{{"enabled", true}})
+		cancel()
+		if err != nil {
+			log.Print("Could not get the alert rules : ", err)
+		} else {
+			projectRules := map[string][]dbAlert.AlertRule{}
+			for _, rule := range rules {
+				projectRules[rule.ProjectID] = append(projectRules[rule.ProjectID], rule)
+			}
+			for projectID, rules := range projectRules {
+				evaluateRules(projectID, rules, r)
+			}
+		}
+
+		time.Sleep(evaluationInterval)
+	}
+}
+
+// EvaluateProject evaluates the enabled alert rules of the project, it's called when a run of the project completes
+func EvaluateProject(projectID string, r *store.StateData) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rules, err := dbAlert.ListAlertRules(ctx, bson.D{{"project_id", projectID}, {"enabled", true}})
+	if err != nil {
+		log.Print("Could not get the alert rules of project ", projectID, " : ", err)
+		return
+	}
+	if len(rules) == 0 {
+		return
+	}
+
+	evaluateRules(projectID, rules, r)
+}
+
+func evaluateRules(projectID string, rules []dbAlert.AlertRule, r *store.StateData) {
+	evaluationMutex.Lock()
+	defer evaluationMutex.Unlock()
+
+	workflows, err := dbOperationsWorkflow.GetWorkflows(bson.D{{"project_id", projectID}, {"isRemoved", false}})
+	if err != nil {
+		log.Print("Could not get the workflows of project ", projectID, " for its alert rules : ", err)
+		return
+	}
+
+	now := time.Now()
+	for _, rule := range rules {
+		if err := evaluateRule(rule, workflows, now, r); err != nil {
+			log.Print("Could not evaluate alert rule ", rule.RuleID, " : ", err)
+		}
+	}
+}
+
+// evaluateRule updates the instances and the state of the rule and sends an event for every instance which changed state.
+// The rule is only saved if it wasn't updated since it was read, the events are dropped otherwise
+func evaluateRule(rule dbAlert.AlertRule, workflows []dbSchemaWorkflow.ChaosWorkFlowInput, now time.Time, r *store.StateData) error {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	previous := map[string]dbAlert.AlertInstance{}
+	for _, instance := range rule.Instances {
+		previous[instance.WorkflowID+"/"+instance.ExperimentName] = instance
+	}
+
+	var (
+		instances   = []dbAlert.AlertInstance{}
+		events      []model.WorkflowRun
+		state       = model.AlertStateOk
+		lastFiredAt = rule.LastFiredAt
+	)
+	for _, cond := range checkConditions(rule, workflows, now) {
+		key := cond.workflow.WorkflowID + "/" + cond.experiment
+		instance, ok := previous[key]
+		delete(previous, key)
+		if !ok {
+			instance = dbAlert.AlertInstance{
+				WorkflowID:     cond.workflow.WorkflowID,
+				ExperimentName: cond.experiment,
+				State:          string(model.AlertStateOk),
+				ActiveSince:    timestamp,
+			}
+		}
+		previousState := model.AlertState(instance.State)
+		instance.WorkflowName = cond.workflow.WorkflowName
+		instance.Message = cond.message
+
+		activeSince, _ := strconv.ParseInt(instance.ActiveSince, 10, 64)
+		instance.State = string(model.AlertStatePending)
+		if now.Unix()-activeSince >= int64(rule.PendingFor) {
+			instance.State = string(model.AlertStateFiring)
+			if previousState != model.AlertStateFiring {
+				instance.FiredAt = timestamp
+				lastFiredAt = timestamp
+			}
+		}
+
+		if model.AlertState(instance.State) != previousState {
+			events = append(events, alertEvent(rule, instance, previousState, cond.workflow, cond.run, timestamp))
+		}
+		if instance.State == string(model.AlertStateFiring) || state == model.AlertStateOk {
+			state = model.AlertState(instance.State)
+		}
+		instances = append(instances, instance)
+	}
+
+	// the condition doesn't hold anymore for the remaining instances
+	for _, instance := range previous {
+		previousState := model.AlertState(instance.State)
+		instance.State = string(model.AlertStateOk)
+		instance.Message = "resolved: " + instance.Message
+		events = append(events, alertEvent(rule, instance, previousState, workflowByID(workflows, instance.WorkflowID), nil, timestamp))
+	}
+
+	fields := bson.D{
+		{"state", string(state)},
+		{"last_evaluated_at", timestamp},
+		{"last_fired_at", lastFiredAt},
+		{"instances", instances},
+	}
+	if string(state) != rule.State {
+		fields = append(fields, bson.E{"state_changed_at", timestamp})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	updated, err := dbAlert.UpdateAlertRule(ctx, bson.D{{"rule_id", rule.RuleID}, {"revision", rule.Revision}}, bson.D{
+		{"$set", fields},
+		{"$inc", bson.D{{"revision", 1}}},
+	})
+	if err != nil {
+		return errors.New("failed to save the state of the rule : " + err.Error())
+	}
+	if !updated {
+		return nil
+	}
+
+	for _, event := range events {
+		ops.SendWorkflowEvent(event, r)
+	}
+	return nil
+}
+
+// checkConditions returns the workflows, or the experiments of the workflows, for which the condition of the rule holds
+func checkConditions(rule dbAlert.AlertRule, workflows []dbSchemaWorkflow.ChaosWorkFlowInput, now time.Time) []condition {
+	var conditions []condition
+	for i := range workflows {
+		workflow := &workflows[i]
+		if rule.WorkflowID != "" && workflow.WorkflowID != rule.WorkflowID {
+			continue
+		}
+
+		switch model.AlertRuleType(rule.Type) {
+		case model.AlertRuleTypeResiliencyScore:
+			run := lastScoredRun(workflow)
+			if run == nil || rule.Threshold == nil || *run.ResiliencyScore >= *rule.Threshold {
+				continue
+			}
+			conditions = append(conditions, condition{
+				workflow: workflow,
+				run:      run,
+				message: fmt.Sprintf("resiliency score of workflow %v is %.2f, below %.2f",
+					workflow.WorkflowName, *run.ResiliencyScore, *rule.Threshold),
+			})
+		case model.AlertRuleTypeExperimentFailures:
+			failures, runs := consecutiveFailures(workflow, rule.ExperimentName)
+			experiments := make([]string, 0, len(failures))
+			for experiment := range failures {
+				experiments = append(experiments, experiment)
+			}
+			sort.Strings(experiments)
+
+			for _, experiment := range experiments {
+				if failures[experiment] < rule.ConsecutiveFailures {
+					continue
+				}
+				conditions = append(conditions, condition{
+					workflow:   workflow,
+					run:        runs[experiment],
+					experiment: experiment,
+					message: fmt.Sprintf("experiment %v of workflow %v failed in the last %v runs",
+						experiment, workflow.WorkflowName, failures[experiment]),
+				})
+			}
+		case model.AlertRuleTypeMissedSchedule:
+			scheduledAt, missed := missedSchedule(workflow, time.Duration(rule.GracePeriod)*time.Second, now)
+			if !missed {
+				continue
+			}
+			conditions = append(conditions, condition{
+				workflow: workflow,
+				message: fmt.Sprintf("workflow %v didn't run since its scheduled time %v",
+					workflow.WorkflowName, scheduledAt.Format(time.RFC3339)),
+			})
+		}
+	}
+	return conditions
+}
+
+// lastScoredRun returns the last completed run of the workflow with a resiliency score
+func lastScoredRun(workflow *dbSchemaWorkflow.ChaosWorkFlowInput) *dbSchemaWorkflow.ChaosWorkflowRun {
+	for _, run := range completedRuns(workflow) {
+		if run.ResiliencyScore != nil && run.SkipReason == "" {
+			return run
+		}
+	}
+	return nil
+}
+
+// consecutiveFailures returns for every experiment of the workflow the number of its last runs in which it failed,
+// and the last of these runs. Runs without the experiment are ignored
+func consecutiveFailures(workflow *dbSchemaWorkflow.ChaosWorkFlowInput, experimentName string) (map[string]int, map[string]*dbSchemaWorkflow.ChaosWorkflowRun) {
+	failures := map[string]int{}
+	lastRuns := map[string]*dbSchemaWorkflow.ChaosWorkflowRun{}
+	done := map[string]bool{}
+
+	runs := completedRuns(workflow)
+	if len(runs) > MaxConsecutiveFailures {
+		runs = runs[:MaxConsecutiveFailures]
+	}
+	for _, run := range runs {
+		var executionData types.ExecutionData
+		if err := json.Unmarshal([]byte(run.ExecutionData), &executionData); err != nil {
+			continue
+		}
+
+		verdicts := map[string]bool{}
+		for _, node := range executionData.Nodes {
+			if node.Type != "ChaosEngine" || node.ChaosExp == nil {
+				continue
+			}
+			experiment := node.ChaosExp.ExperimentName
+			if experimentName != "" && experiment != experimentName {
+				continue
+			}
+			verdicts[experiment] = verdicts[experiment] || node.ChaosExp.ExperimentVerdict == failedVerdict
+		}
+
+		for experiment, failed := range verdicts {
+			if done[experiment] {
+				continue
+			}
+			if !failed {
+				done[experiment] = true
+				continue
+			}
+			failures[experiment]++
+			if lastRuns[experiment] == nil {
+				lastRuns[experiment] = run
+			}
+		}
+	}
+	return failures, lastRuns
+}
+
+// missedSchedule returns the last time the cron workflow was scheduled before the grace period, and whether
+// the workflow didn't run since then
+func missedSchedule(workflow *dbSchemaWorkflow.ChaosWorkFlowInput, gracePeriod time.Duration, now time.Time) (time.Time, bool) {
+	if workflow.CronSyntax == "" || gjson.Get(workflow.WorkflowManifest, "spec.suspend").Bool() {
+		return time.Time{}, false
+	}
+	schedule, err := ops.ParseCron(workflow.CronSyntax)
+	if err != nil {
+		return time.Time{}, false
+	}
+	loc, err := ops.CronLocation(workflow.WorkflowManifest)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	scheduledAt := schedule.Prev(now.Add(-gracePeriod).In(loc))
+	createdAt, err := strconv.ParseInt(workflow.CreatedAt, 10, 64)
+	if scheduledAt.IsZero() || err != nil || scheduledAt.Unix() < createdAt {
+		return time.Time{}, false
+	}
+
+	for _, run := range workflow.WorkflowRuns {
+		if lastUpdated, err := strconv.ParseInt(run.LastUpdated, 10, 64); err == nil && lastUpdated >= scheduledAt.Unix() {
+			return time.Time{}, false
+		}
+	}
+	return scheduledAt, true
+}
+
+// completedRuns returns the completed runs of the workflow which weren't removed, newest first
+func completedRuns(workflow *dbSchemaWorkflow.ChaosWorkFlowInput) []*dbSchemaWorkflow.ChaosWorkflowRun {
+	var runs []*dbSchemaWorkflow.ChaosWorkflowRun
+	for _, run := range workflow.WorkflowRuns {
+		if run.Completed && (run.IsRemoved == nil || !*run.IsRemoved) {
+			runs = append(runs, run)
+		}
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		first, _ := strconv.ParseInt(runs[i].LastUpdated, 10, 64)
+		second, _ := strconv.ParseInt(runs[j].LastUpdated, 10, 64)
+		return first > second
+	})
+	return runs
+}
+
+func workflowByID(workflows []dbSchemaWorkflow.ChaosWorkFlowInput, workflowID string) *dbSchemaWorkflow.ChaosWorkFlowInput {
+	for i := range workflows {
+		if workflows[i].WorkflowID == workflowID {
+			return &workflows[i]
+		}
+	}
+	return nil
+}
+
+// alertEvent returns the workflow event of a state change of an instance, the run fields are the ones of the run
+// which triggered the change if there's one
+func alertEvent(rule dbAlert.AlertRule, instance dbAlert.AlertInstance, previousState model.AlertState, workflow *dbSchemaWorkflow.ChaosWorkFlowInput, run *dbSchemaWorkflow.ChaosWorkflowRun, timestamp string) model.WorkflowRun {
+	event := &model.AlertEvent{
+		RuleID:        rule.RuleID,
+		RuleName:      rule.Name,
+		Type:          model.AlertRuleType(rule.Type),
+		State:         model.AlertState(instance.State),
+		PreviousState: previousState,
+		WorkflowID:    instance.WorkflowID,
+		Message:       instance.Message,
+		TimeStamp:     timestamp,
+	}
+	if instance.ExperimentName != "" {
+		experimentName := instance.ExperimentName
+		event.ExperimentName = &experimentName
+	}
+
+	wfRun := model.WorkflowRun{
+		ProjectID:    rule.ProjectID,
+		WorkflowID:   instance.WorkflowID,
+		WorkflowName: instance.WorkflowName,
+		LastUpdated:  timestamp,
+		Alert:        event,
+	}
+	if workflow != nil {
+		clusterType := workflow.ClusterType
+		wfRun.ClusterID = workflow.ClusterID
+		wfRun.ClusterName = workflow.ClusterName
+		wfRun.ClusterType = &clusterType
+	}
+	if run != nil {
+		wfRun.WorkflowRunID = run.WorkflowRunID
+		wfRun.Phase = run.Phase
+		wfRun.ResiliencyScore = run.ResiliencyScore
+	}
+	return wfRun
+}
@@ -0,0 +1,136 @@
+package alerts
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	types "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/chaos-workflow"
+	dbSchemaWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+)
+
+// workflowRun returns a completed run with the verdicts of its experiments
+func workflowRun(t *testing.T, id string, lastUpdated int64, verdicts map[string]string) *dbSchemaWorkflow.ChaosWorkflowRun {
+	t.Helper()
+	nodes := map[string]types.Node{"root": {Name: id, Type: "Steps"}}
+	for experiment, verdict := range verdicts {
+		nodes[experiment] = types.Node{
+			Type:     "ChaosEngine",
+			ChaosExp: &types.ChaosData{ExperimentName: experiment, ExperimentVerdict: verdict},
+		}
+	}
+	data, err := json.Marshal(types.ExecutionData{Nodes: nodes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &dbSchemaWorkflow.ChaosWorkflowRun{
+		WorkflowRunID: id,
+		LastUpdated:   strconv.FormatInt(lastUpdated, 10),
+		ExecutionData: string(data),
+		Completed:     true,
+	}
+}
+
+func TestConsecutiveFailures(t *testing.T) {
+	removed := true
+	removedRun := workflowRun(t, "removed", 600, map[string]string{"pod-delete": "Pass"})
+	removedRun.IsRemoved = &removed
+	runningRun := workflowRun(t, "running", 700, map[string]string{"pod-delete": "Pass"})
+	runningRun.Completed = false
+
+	workflow := &dbSchemaWorkflow.ChaosWorkFlowInput{WorkflowRuns: []*dbSchemaWorkflow.ChaosWorkflowRun{
+		workflowRun(t, "run-1", 100, map[string]string{"pod-delete": "Fail", "pod-cpu-hog": "Fail"}),
+		workflowRun(t, "run-2", 200, map[string]string{"pod-delete": "Pass", "pod-cpu-hog": "Fail"}),
+		workflowRun(t, "run-3", 300, map[string]string{"pod-delete": "Fail"}),
+		workflowRun(t, "run-4", 400, map[string]string{"pod-delete": "Fail", "pod-cpu-hog": "Pass"}),
+		workflowRun(t, "run-5", 500, map[string]string{"pod-delete": "Fail", "pod-cpu-hog": "Fail"}),
+		removedRun,
+		runningRun,
+		{WorkflowRunID: "invalid", LastUpdated: "550", ExecutionData: "{", Completed: true},
+	}}
+
+	tests := []struct {
+		name       string
+		experiment string
+		failures   map[string]int
+		lastRuns   map[string]string
+	}{
+		{
+			name:     "every experiment",
+			failures: map[string]int{"pod-delete": 3, "pod-cpu-hog": 1},
+			lastRuns: map[string]string{"pod-delete": "run-5", "pod-cpu-hog": "run-5"},
+		},
+		{
+			name:       "single experiment",
+			experiment: "pod-delete",
+			failures:   map[string]int{"pod-delete": 3},
+			lastRuns:   map[string]string{"pod-delete": "run-5"},
+		},
+		{
+			name:       "unknown experiment",
+			experiment: "node-drain",
+			failures:   map[string]int{},
+			lastRuns:   map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failures, lastRuns := consecutiveFailures(workflow, tt.experiment)
+			if !reflect.DeepEqual(failures, tt.failures) {
+				t.Errorf("failures = %v, want %v", failures, tt.failures)
+			}
+			runIDs := map[string]string{}
+			for experiment, run := range lastRuns {
+				runIDs[experiment] = run.WorkflowRunID
+			}
+			if !reflect.DeepEqual(runIDs, tt.lastRuns) {
+				t.Errorf("last runs = %v, want %v", runIDs, tt.lastRuns)
+			}
+		})
+	}
+}
+
+func TestMissedSchedule(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2021, 1, 1, hour, minute, 0, 0, time.UTC)
+	}
+	unix := func(value time.Time) string {
+		return strconv.FormatInt(value.Unix(), 10)
+	}
+	workflow := func(cron string, manifest string, createdAt time.Time, runsAt ...time.Time) *dbSchemaWorkflow.ChaosWorkFlowInput {
+		wf := &dbSchemaWorkflow.ChaosWorkFlowInput{CronSyntax: cron, WorkflowManifest: manifest, CreatedAt: unix(createdAt)}
+		for _, runAt := range runsAt {
+			wf.WorkflowRuns = append(wf.WorkflowRuns, &dbSchemaWorkflow.ChaosWorkflowRun{LastUpdated: unix(runAt)})
+		}
+		return wf
+	}
+	grace := 10 * time.Minute
+
+	tests := []struct {
+		name     string
+		workflow *dbSchemaWorkflow.ChaosWorkFlowInput
+		now      time.Time
+		want     time.Time
+		missed   bool
+	}{
+		{name: "missed", workflow: workflow("0 * * * *", "{}", at(9, 0)), now: at(12, 30), want: at(12, 0), missed: true},
+		{name: "ran after the schedule", workflow: workflow("0 * * * *", "{}", at(9, 0), at(12, 5)), now: at(12, 30)},
+		{name: "only ran before the schedule", workflow: workflow("0 * * * *", "{}", at(9, 0), at(11, 5)), now: at(12, 30), want: at(12, 0), missed: true},
+		{name: "within the grace period", workflow: workflow("0 * * * *", "{}", at(9, 0), at(11, 5)), now: at(12, 5)},
+		{name: "created after the schedule", workflow: workflow("0 * * * *", "{}", at(12, 10)), now: at(12, 30)},
+		{name: "time zone", workflow: workflow("0 12 * * *", `{"spec":{"timezone":"Asia/Kolkata"}}`, at(0, 0)), now: at(12, 0), want: at(6, 30), missed: true},
+		{name: "suspended", workflow: workflow("0 * * * *", `{"spec":{"suspend":true}}`, at(9, 0)), now: at(12, 30)},
+		{name: "not a cron workflow", workflow: workflow("", "{}", at(9, 0)), now: at(12, 30)},
+		{name: "invalid schedule", workflow: workflow("every hour", "{}", at(9, 0)), now: at(12, 30)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduledAt, missed := missedSchedule(tt.workflow, grace, tt.now)
+			if missed != tt.missed || !scheduledAt.Equal(tt.want) {
+				t.Errorf("missedSchedule() = %v, %v, want %v, %v", scheduledAt, missed, tt.want, tt.missed)
+			}
+		})
+	}
+}
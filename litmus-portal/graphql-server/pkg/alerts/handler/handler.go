@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/alerts"
+	store "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/data-store"
+	dbAlert "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/alert"
+	dbOperationsWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+)
+
+// CreateAlertRule adds an alert rule to the project and evaluates the rules of the project
+func CreateAlertRule(ctx context.Context, input model.AlertRuleInput, r *store.StateData) (*model.AlertRule, error) {
+	if input.RuleID != nil {
+		return nil, errors.New("rule_id can't be set on new alert rules")
+	}
+	rule, err := newAlertRule(input)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	rule.RuleID = uuid.New().String()
+	rule.State = string(model.AlertStateOk)
+	rule.Instances = []dbAlert.AlertInstance{}
+	rule.CreatedAt = timestamp
+	rule.UpdatedAt = timestamp
+
+	err = dbAlert.InsertAlertRule(ctx, rule)
+	if err != nil {
+		return nil, errors.New("failed to create the alert rule : " + err.Error())
+	}
+
+	if rule.Enabled {
+		go alerts.EvaluateProject(rule.ProjectID, r)
+	}
+	return alertRuleToModel(rule), nil
+}
+
+// UpdateAlertRule replaces the configuration of an alert rule, the instances of a disabled rule are cleared
+func UpdateAlertRule(ctx context.Context, input model.AlertRuleInput, r *store.StateData) (*model.AlertRule, error) {
+	if input.RuleID == nil {
+		return nil, errors.New("rule_id is required to update an alert rule")
+	}
+	if _, err := dbAlert.GetAlertRule(ctx, bson.D{{"rule_id", *input.RuleID}, {"project_id", input.ProjectID}}); err != nil {
+		return nil, errors.New("alert rule not found : " + err.Error())
+	}
+	rule, err := newAlertRule(input)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := bson.D{
+		{"name", rule.Name},
+		{"type", rule.Type},
+		{"workflow_id", rule.WorkflowID},
+		{"threshold", rule.Threshold},
+		{"experiment_name", rule.ExperimentName},
+		{"consecutive_failures", rule.ConsecutiveFailures},
+		{"grace_period", rule.GracePeriod},
+		{"pending_for", rule.PendingFor},
+		{"enabled", rule.Enabled},
+		{"updated_at", strconv.FormatInt(time.Now().Unix(), 10)},
+	}
+	if !rule.Enabled {
+		fields = append(fields, bson.E{"state", string(model.AlertStateOk)}, bson.E{"instances", []dbAlert.AlertInstance{}})
+	}
+
+	_, err = dbAlert.UpdateAlertRule(ctx, bson.D{{"rule_id", *input.RuleID}}, bson.D{
+		{"$set", fields},
+		{"$inc", bson.D{{"revision", 1}}},
+	})
+	if err != nil {
+		return nil, errors.New("failed to update the alert rule : " + err.Error())
+	}
+
+	if rule.Enabled {
+		go alerts.EvaluateProject(rule.ProjectID, r)
+	}
+	return GetAlertRule(ctx, input.ProjectID, *input.RuleID)
+}
+
+// DeleteAlertRule removes an alert rule of the project
+func DeleteAlertRule(ctx context.Context, projectID string, ruleID string) (bool, error) {
+	if _, err := dbAlert.GetAlertRule(ctx, bson.D{{"rule_id", ruleID}, {"project_id", projectID}}); err != nil {
+		return false, errors.New("alert rule not found : " + err.Error())
+	}
+
+	return dbAlert.DeleteAlertRule(ctx, ruleID)
+}
+
+// GetAlertRule returns an alert rule of the project with the instances for which its condition holds
+func GetAlertRule(ctx context.Context, projectID string, ruleID string) (*model.AlertRule, error) {
+	rule, err := dbAlert.GetAlertRule(ctx, bson.D{{"rule_id", ruleID}, {"project_id", projectID}})
+	if err != nil {
+		return nil, errors.New("alert rule not found : " + err.Error())
+	}
+
+	return alertRuleToModel(rule), nil
+}
+
+// ListAlertRules returns the alert rules of the project, oldest first
+func ListAlertRules(ctx context.Context, projectID string) ([]*model.AlertRule, error) {
+	rules, err := dbAlert.ListAlertRules(ctx, bson.D{{"project_id", projectID}})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].CreatedAt < rules[j].CreatedAt
+	})
+
+	result := []*model.AlertRule{}
+	for _, rule := range rules {
+		result = append(result, alertRuleToModel(rule))
+	}
+	return result, nil
+}
+
+// newAlertRule validates the input and returns the configuration of the rule, the fields of other rule types are dropped
+func newAlertRule(input model.AlertRuleInput) (dbAlert.AlertRule, error) {
+	rule := dbAlert.AlertRule{
+		ProjectID: input.ProjectID,
+		Name:      strings.TrimSpace(input.Name),
+		Type:      string(input.Type),
+		Enabled:   true,
+	}
+	if rule.Name == "" {
+		return rule, errors.New("name of the alert rule is required")
+	}
+	if !input.Type.IsValid() {
+		return rule, errors.New("invalid alert rule type " + string(input.Type))
+	}
+	if input.Enabled != nil {
+		rule.Enabled = *input.Enabled
+	}
+	if input.PendingFor != nil {
+		if *input.PendingFor < 0 {
+			return rule, errors.New("pending_for can't be negative")
+		}
+		rule.PendingFor = *input.PendingFor
+	}
+
+	if input.WorkflowID != nil && *input.WorkflowID != "" {
+		workflow, err := dbOperationsWorkflow.GetWorkflow(bson.D{{"workflow_id", *input.WorkflowID}, {"project_id", input.ProjectID}, {"isRemoved", false}})
+		if err != nil {
+			return rule, errors.New("workflow " + *input.WorkflowID + " not found in the project")
+		}
+		if input.Type == model.AlertRuleTypeMissedSchedule {
+			if workflow.CronSyntax == "" {
+				return rule, errors.New("workflow " + workflow.WorkflowName + " isn't a cron workflow")
+			}
+			if err := alerts.ValidateSchedule(workflow.CronSyntax); err != nil {
+				return rule, errors.New("schedule of workflow " + workflow.WorkflowName + " isn't supported : " + err.Error())
+			}
+		}
+		rule.WorkflowID = *input.WorkflowID
+	}
+
+	switch input.Type {
+	case model.AlertRuleTypeResiliencyScore:
+		if input.Threshold == nil || *input.Threshold < 0 || *input.Threshold > 100 {
+			return rule, errors.New("threshold between 0 and 100 is required for resiliency score rules")
+		}
+		rule.Threshold = input.Threshold
+	case model.AlertRuleTypeExperimentFailures:
+		rule.ConsecutiveFailures = alerts.DefaultConsecutiveFailures
+		if input.ConsecutiveFailures != nil {
+			rule.ConsecutiveFailures = *input.ConsecutiveFailures
+		}
+		if rule.ConsecutiveFailures < 1 || rule.ConsecutiveFailures > alerts.MaxConsecutiveFailures {
+			return rule, errors.New("consecutive_failures must be between 1 and " + strconv.Itoa(alerts.MaxConsecutiveFailures))
+		}
+		if input.ExperimentName != nil {
+			rule.ExperimentName = strings.TrimSpace(*input.ExperimentName)
+		}
+	case model.AlertRuleTypeMissedSchedule:
+		rule.GracePeriod = alerts.DefaultGracePeriod
+		if input.GracePeriod != nil {
+			rule.GracePeriod = *input.GracePeriod
+		}
+		if rule.GracePeriod < 0 {
+			return rule, errors.New("grace_period can't be negative")
+		}
+	}
+
+	return rule, nil
+}
+
+func alertRuleToModel(rule dbAlert.AlertRule) *model.AlertRule {
+	newRule := &model.AlertRule{
+		RuleID:          rule.RuleID,
+		ProjectID:       rule.ProjectID,
+		Name:            rule.Name,
+		Type:            model.AlertRuleType(rule.Type),
+		WorkflowID:      optionalString(rule.WorkflowID),
+		Threshold:       rule.Threshold,
+		ExperimentName:  optionalString(rule.ExperimentName),
+		PendingFor:      rule.PendingFor,
+		Enabled:         rule.Enabled,
+		State:           model.AlertState(rule.State),
+		StateChangedAt:  optionalString(rule.StateChangedAt),
+		LastEvaluatedAt: optionalString(rule.LastEvaluatedAt),
+		LastFiredAt:     optionalString(rule.LastFiredAt),
+		Instances:       []*model.AlertInstance{},
+		CreatedAt:       rule.CreatedAt,
+		UpdatedAt:       rule.UpdatedAt,
+	}
+	switch newRule.Type {
+	case model.AlertRuleTypeExperimentFailures:
+		consecutiveFailures := rule.ConsecutiveFailures
+		newRule.ConsecutiveFailures = &consecutiveFailures
+	case model.AlertRuleTypeMissedSchedule:
+		gracePeriod := rule.GracePeriod
+		newRule.GracePeriod = &gracePeriod
+	}
+
+	for _, instance := range rule.Instances {
+		newRule.Instances = append(newRule.Instances, &model.AlertInstance{
+			WorkflowID:     instance.WorkflowID,
+			WorkflowName:   instance.WorkflowName,
+			ExperimentName: optionalString(instance.ExperimentName),
+			State:          model.AlertState(instance.State),
+			Message:        instance.Message,
+			ActiveSince:    instance.ActiveSince,
+			FiredAt:        optionalString(instance.FiredAt),
+		})
+	}
+	return newRule
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
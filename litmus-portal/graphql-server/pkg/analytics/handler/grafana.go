@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics/ops/grafana"
+	dbOperationsAnalytics "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/analytics"
+)
+
+const (
+	defaultChaosEventQueryTemplate   = `litmuschaos_awaited_experiments{job="chaos-exporter", chaos_injection_time!=""}`
+	defaultChaosVerdictQueryTemplate = `litmuschaos_experiment_verdict{job="chaos-exporter", chaosresult_verdict!="Awaited"}`
+	customDashboardTypeID            = "custom"
+	customDashboardTypeName          = "Custom dashboard"
+)
+
+// ImportDashboard creates a custom dashboard from a Grafana dashboard
+func ImportDashboard(input model.ImportDBInput) (*model.ListDashboardResponse, error) {
+	datasource, err := dbOperationsAnalytics.GetDataSourceByID(input.DsID)
+	if err != nil {
+		return nil, errors.New("failed to get the data source: " + err.Error())
+	}
+	if datasource.ProjectID != input.ProjectID || datasource.IsRemoved {
+		return nil, errors.New("data source doesn't belong to this project")
+	}
+
+	imported, err := grafana.Import([]byte(input.GrafanaJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	newDashboard := &model.CreateDBInput{
+		DsID:                      input.DsID,
+		DbName:                    imported.Name,
+		DbTypeID:                  customDashboardTypeID,
+		DbTypeName:                customDashboardTypeName,
+		DbInformation:             imported.Information,
+		ChaosEventQueryTemplate:   firstNonEmpty(input.ChaosEventQueryTemplate, imported.ChaosEventQueryTemplate, defaultChaosEventQueryTemplate),
+		ChaosVerdictQueryTemplate: firstNonEmpty(input.ChaosVerdictQueryTemplate, imported.ChaosVerdictQueryTemplate, defaultChaosVerdictQueryTemplate),
+		PanelGroups:               imported.PanelGroups,
+		Variables:                 imported.Variables,
+		EndTime:                   imported.EndTime,
+		StartTime:                 imported.StartTime,
+		ProjectID:                 input.ProjectID,
+		ClusterID:                 input.ClusterID,
+		RefreshRate:               imported.RefreshRate,
+	}
+	if input.DbName != nil && *input.DbName != "" {
+		newDashboard.DbName = *input.DbName
+	}
+	if newDashboard.DbName == "" {
+		return nil, errors.New("dashboard name is required when the grafana dashboard has no title")
+	}
+
+	return CreateDashboard(newDashboard)
+}
+
+// ExportDashboard returns the dashboard as Grafana dashboard JSON
+func ExportDashboard(projectID string, dbID string) (string, error) {
+	dashboards, err := QueryListDashboard(projectID, nil, &dbID)
+	if err != nil {
+		return "", err
+	}
+	if len(dashboards) == 0 {
+		return "", errors.New("dashboard not found")
+	}
+
+	data, err := grafana.Export(dashboards[0])
+	if err != nil {
+		return "", errors.New("failed to export the dashboard: " + err.Error())
+	}
+
+	return string(data), nil
+}
+
+func firstNonEmpty(override *string, values ...string) string {
+	if override != nil && *override != "" {
+		return *override
+	}
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
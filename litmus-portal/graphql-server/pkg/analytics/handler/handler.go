@@ -24,11 +24,14 @@ import (
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics/ops"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics/ops/prometheus"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/authorization"
 	dbOperationsAnalytics "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/analytics"
 	dbSchemaAnalytics "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/analytics"
 	dbOperationsCluster "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/cluster"
+	dbSchemaProject "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/project"
 	dbOperationsWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
 	dbSchemaWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/secrets"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/utils"
 )
 
@@ -36,38 +39,60 @@ const (
 	defaultPath = "/tmp/version/"
 )
 
-var AnalyticsCache = utils.NewCache()
+var (
+	AnalyticsCache = utils.NewCache()
+	// QueryCache holds the results of the dashboard queries
+	QueryCache = ops.NewQueryCache(ops.QueryCacheTTL())
+)
 
 func CreateDataSource(datasource *model.DSInput) (*model.DSResponse, error) {
+	if _, err := ops.GetDriver(datasource.DsType); err != nil {
+		return nil, err
+	}
 
-	datasourceStatus := prometheus.TSDBHealthCheck(datasource.DsURL, datasource.DsType)
+	newDS := dbSchemaAnalytics.DataSource{
+		DsID:              uuid.New().String(),
+		DsName:            datasource.DsName,
+		DsType:            datasource.DsType,
+		DsURL:             datasource.DsURL,
+		AccessType:        datasource.AccessType,
+		AuthType:          datasource.AuthType,
+		BasicAuthUsername: datasource.BasicAuthUsername,
+		BasicAuthPassword: datasource.BasicAuthPassword,
+		BearerToken:       datasource.BearerToken,
+		CustomHeaders:     ops.NewDataSourceHeaders(datasource.CustomHeaders),
+		CACert:            datasource.CaCert,
+		ClientCert:        datasource.ClientCert,
+		ClientKey:         datasource.ClientKey,
+		TenantID:          datasource.TenantID,
+		TenantHeader:      datasource.TenantHeader,
+		ScrapeInterval:    datasource.ScrapeInterval,
+		QueryTimeout:      datasource.QueryTimeout,
+		HTTPMethod:        datasource.HTTPMethod,
+		ProjectID:         *datasource.ProjectID,
+		CreatedAt:         strconv.FormatInt(time.Now().Unix(), 10),
+		UpdatedAt:         strconv.FormatInt(time.Now().Unix(), 10),
+	}
+	if datasource.InsecureSkipVerify != nil {
+		newDS.InsecureSkipVerify = *datasource.InsecureSkipVerify
+	}
+
+	datasourceStatus := ops.CheckDataSourceHealth(&newDS)
 
 	if datasourceStatus == "Active" {
 
-		newDS := dbSchemaAnalytics.DataSource{
-			DsID:              uuid.New().String(),
-			DsName:            datasource.DsName,
-			DsType:            datasource.DsType,
-			DsURL:             datasource.DsURL,
-			AccessType:        datasource.AccessType,
-			AuthType:          datasource.AuthType,
-			BasicAuthUsername: datasource.BasicAuthUsername,
-			BasicAuthPassword: datasource.BasicAuthPassword,
-			ScrapeInterval:    datasource.ScrapeInterval,
-			QueryTimeout:      datasource.QueryTimeout,
-			HTTPMethod:        datasource.HTTPMethod,
-			ProjectID:         *datasource.ProjectID,
-			CreatedAt:         strconv.FormatInt(time.Now().Unix(), 10),
-			UpdatedAt:         strconv.FormatInt(time.Now().Unix(), 10),
+		if err := ops.EncryptDataSourceSecrets(&newDS); err != nil {
+			return nil, errors.New("failed to encrypt the credentials of the data source: " + err.Error())
 		}
-
 		err := dbOperationsAnalytics.InsertDataSource(newDS)
 		if err != nil {
 			return nil, err
 		}
+		recordDataSourceHealth(&newDS, datasourceStatus, time.Now(), ops.HealthCheckInterval())
 
 		var newDSResponse = model.DSResponse{}
 		_ = copier.Copy(&newDSResponse, &newDS)
+		fillDataSourceResponse(&newDSResponse, &newDS)
 
 		return &newDSResponse, nil
 	} else {
@@ -75,8 +100,132 @@ func CreateDataSource(datasource *model.DSInput) (*model.DSResponse, error) {
 	}
 }
 
+// MigrateSecrets encrypts data source credentials still stored in plaintext and re-encrypts the ones written with a rotated key
+func MigrateSecrets(ctx context.Context) error {
+	datasources, err := dbOperationsAnalytics.ListDataSource(bson.D{})
+	if err != nil {
+		return err
+	}
+	for _, ds := range datasources {
+		values := []*string{ds.BasicAuthPassword, ds.BearerToken, ds.ClientKey}
+		for i := range ds.CustomHeaders {
+			values = append(values, &ds.CustomHeaders[i].Value)
+		}
+		migrate := false
+		for _, value := range values {
+			if migrate, err = secrets.NeedsMigration(value); err != nil {
+				return err
+			} else if migrate {
+				break
+			}
+		}
+		if !migrate {
+			continue
+		}
+
+		migrated := *ds
+		if err := ops.DecryptDataSourceSecrets(&migrated); err != nil {
+			log.Print("Failed to migrate the credentials of data source ", ds.DsID, " : ", err)
+			continue
+		}
+		if err := ops.EncryptDataSourceSecrets(&migrated); err != nil {
+			return err
+		}
+		err = dbOperationsAnalytics.UpdateDataSource(bson.D{{"ds_id", ds.DsID}}, bson.D{{"$set", bson.D{
+			{"basic_auth_password", migrated.BasicAuthPassword},
+			{"bearer_token", migrated.BearerToken},
+			{"client_key", migrated.ClientKey},
+			{"custom_headers", migrated.CustomHeaders},
+		}}})
+		if err != nil {
+			return err
+		}
+		log.Print("Migrated credentials for data source : ", ds.DsID)
+	}
+	return nil
+}
+
+// fillDataSourceResponse sets the connection settings which can't be copied to the response, secrets and header values aren't returned
+func fillDataSourceResponse(response *model.DSResponse, datasource *dbSchemaAnalytics.DataSource) {
+	password, err := secrets.DecryptPtr(datasource.BasicAuthPassword)
+	if err != nil {
+		log.Print("Could not decrypt the basic auth password of data source ", datasource.DsID, " : ", err)
+	}
+	response.BasicAuthPassword = password
+	response.CaCert = datasource.CACert
+	response.ClientCert = datasource.ClientCert
+	response.InsecureSkipVerify = &datasource.InsecureSkipVerify
+	response.TenantID = datasource.TenantID
+	response.TenantHeader = datasource.TenantHeader
+	response.CustomHeaderNames = nil
+	for _, header := range datasource.CustomHeaders {
+		response.CustomHeaderNames = append(response.CustomHeaderNames, header.Name)
+	}
+}
+
+// ResolveDataSourceConfig returns the connection settings of the data source queried by the user.
+// Data sources without an ID are looked up by their URL among the ones the user has access to,
+// nil is returned when none is found so that they are queried without auth
+func ResolveDataSourceConfig(ctx context.Context, dsID *string, url string) (*analytics.DataSourceConfig, error) {
+	roles := []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor, model.MemberRoleViewer}
+
+	if dsID != nil && *dsID != "" {
+		datasource, err := dbOperationsAnalytics.GetDataSourceByID(*dsID)
+		if err != nil {
+			return nil, errors.New("failed to get the data source: " + err.Error())
+		}
+		if err := authorization.ValidateRole(ctx, datasource.ProjectID, roles, string(dbSchemaProject.AcceptedInvitation)); err != nil {
+			return nil, err
+		}
+		return ops.NewDataSourceConfig(datasource)
+	}
+
+	datasources, err := dbOperationsAnalytics.ListDataSource(bson.D{{"ds_url", url}, {"is_removed", false}})
+	if err != nil {
+		return nil, err
+	}
+	for _, datasource := range datasources {
+		if authorization.ValidateRole(ctx, datasource.ProjectID, roles, string(dbSchemaProject.AcceptedInvitation)) == nil {
+			return ops.NewDataSourceConfig(datasource)
+		}
+	}
+
+	return nil, nil
+}
+
+// GetViewedDashboard returns the dashboard of a dashboard view when the user has access to it
+func GetViewedDashboard(ctx context.Context, dashboardID *string) (*dbSchemaAnalytics.DashBoard, error) {
+	if dashboardID == nil || *dashboardID == "" {
+		return nil, nil
+	}
+
+	dashboard, err := dbOperationsAnalytics.GetDashboard(bson.D{{"db_id", *dashboardID}, {"is_removed", false}})
+	if err != nil {
+		return nil, errors.New("failed to get the dashboard: " + err.Error())
+	}
+	roles := []model.MemberRole{model.MemberRoleOwner, model.MemberRoleEditor, model.MemberRoleViewer}
+	if err := authorization.ValidateRole(ctx, dashboard.ProjectID, roles, string(dbSchemaProject.AcceptedInvitation)); err != nil {
+		return nil, err
+	}
+
+	return &dashboard, nil
+}
+
 func CreateDashboard(dashboard *model.CreateDBInput) (*model.ListDashboardResponse, error) {
 
+	var panels []*model.Panel
+	for _, panelGroup := range dashboard.PanelGroups {
+		if panelGroup != nil {
+			panels = append(panels, panelGroup.Panels...)
+		}
+	}
+	if err := ops.ValidateVariables(dashboard.Variables); err != nil {
+		return nil, err
+	}
+	if err := validatePanelQueries(dashboard.DsID, panels, dashboard.Variables); err != nil {
+		return nil, err
+	}
+
 	newDashboard := dbSchemaAnalytics.DashBoard{
 		DbID:                      uuid.New().String(),
 		DbName:                    dashboard.DbName,
@@ -121,6 +270,11 @@ func CreateDashboard(dashboard *model.CreateDBInput) (*model.ListDashboardRespon
 
 	newDashboard.ApplicationMetadataMap = newApplicationMetadataMap
 
+	err := copier.Copy(&newDashboard.Variables, &dashboard.Variables)
+	if err != nil {
+		return nil, err
+	}
+
 	for i, panelGroup := range dashboard.PanelGroups {
 
 		panelGroupID := uuid.New().String()
@@ -158,7 +312,7 @@ func CreateDashboard(dashboard *model.CreateDBInput) (*model.ListDashboardRespon
 			newPanels = append(newPanels, &newPanel)
 		}
 	}
-	err := dbOperationsAnalytics.InsertPanel(newPanels)
+	err = dbOperationsAnalytics.InsertPanel(newPanels)
 	if err != nil {
 		return nil, fmt.Errorf("error on inserting panel data", err)
 	}
@@ -193,18 +347,74 @@ func UpdateDataSource(datasource model.DSInput) (*model.DSResponse, error) {
 		return nil, errors.New("data source ID is nil or empty")
 	}
 
-	datasourceStatus := prometheus.TSDBHealthCheck(datasource.DsURL, datasource.DsType)
+	if _, err := ops.GetDriver(datasource.DsType); err != nil {
+		return nil, err
+	}
+
+	existingDS, err := dbOperationsAnalytics.GetDataSourceByID(*datasource.DsID)
+	if err != nil {
+		return nil, errors.New("failed to get the data source: " + err.Error())
+	}
+
+	updatedDS := *existingDS
+	updatedDS.DsName = datasource.DsName
+	updatedDS.DsType = datasource.DsType
+	updatedDS.DsURL = datasource.DsURL
+	updatedDS.AccessType = datasource.AccessType
+	updatedDS.AuthType = datasource.AuthType
+	updatedDS.BasicAuthUsername = datasource.BasicAuthUsername
+	updatedDS.BasicAuthPassword = datasource.BasicAuthPassword
+	updatedDS.ScrapeInterval = datasource.ScrapeInterval
+	updatedDS.QueryTimeout = datasource.QueryTimeout
+	updatedDS.HTTPMethod = datasource.HTTPMethod
+	updatedDS.UpdatedAt = timestamp
+
+	// settings which aren't set are kept so that secrets don't have to be sent back on every update
+	if datasource.BearerToken != nil {
+		updatedDS.BearerToken = datasource.BearerToken
+	}
+	if datasource.CustomHeaders != nil {
+		updatedDS.CustomHeaders = ops.NewDataSourceHeaders(datasource.CustomHeaders)
+	}
+	if datasource.CaCert != nil {
+		updatedDS.CACert = datasource.CaCert
+	}
+	if datasource.ClientCert != nil {
+		updatedDS.ClientCert = datasource.ClientCert
+	}
+	if datasource.ClientKey != nil {
+		updatedDS.ClientKey = datasource.ClientKey
+	}
+	if datasource.InsecureSkipVerify != nil {
+		updatedDS.InsecureSkipVerify = *datasource.InsecureSkipVerify
+	}
+	if datasource.TenantID != nil {
+		updatedDS.TenantID = datasource.TenantID
+	}
+	if datasource.TenantHeader != nil {
+		updatedDS.TenantHeader = datasource.TenantHeader
+	}
+
+	datasourceStatus := ops.CheckDataSourceHealth(&updatedDS)
 
 	if datasourceStatus == "Active" {
 
+		if err := ops.EncryptDataSourceSecrets(&updatedDS); err != nil {
+			return nil, errors.New("failed to encrypt the credentials of the data source: " + err.Error())
+		}
+
 		query := bson.D{{"ds_id", datasource.DsID}}
 
 		update := bson.D{{"$set", bson.D{
-			{"ds_name", datasource.DsName}, {"ds_type", datasource.DsType},
-			{"ds_url", datasource.DsURL}, {"access_type", datasource.AccessType},
-			{"auth_type", datasource.AuthType}, {"basic_auth_username", datasource.BasicAuthUsername},
-			{"basic_auth_password", datasource.BasicAuthPassword}, {"scrape_interval", datasource.ScrapeInterval},
-			{"query_timeout", datasource.QueryTimeout}, {"http_method", datasource.HTTPMethod},
+			{"ds_name", updatedDS.DsName}, {"ds_type", updatedDS.DsType},
+			{"ds_url", updatedDS.DsURL}, {"access_type", updatedDS.AccessType},
+			{"auth_type", updatedDS.AuthType}, {"basic_auth_username", updatedDS.BasicAuthUsername},
+			{"basic_auth_password", updatedDS.BasicAuthPassword}, {"bearer_token", updatedDS.BearerToken},
+			{"custom_headers", updatedDS.CustomHeaders}, {"ca_cert", updatedDS.CACert},
+			{"client_cert", updatedDS.ClientCert}, {"client_key", updatedDS.ClientKey},
+			{"insecure_skip_verify", updatedDS.InsecureSkipVerify}, {"tenant_id", updatedDS.TenantID},
+			{"tenant_header", updatedDS.TenantHeader}, {"scrape_interval", updatedDS.ScrapeInterval},
+			{"query_timeout", updatedDS.QueryTimeout}, {"http_method", updatedDS.HTTPMethod},
 			{"updated_at", timestamp},
 		}}}
 
@@ -212,8 +422,9 @@ func UpdateDataSource(datasource model.DSInput) (*model.DSResponse, error) {
 		if err != nil {
 			return nil, err
 		}
+		recordDataSourceHealth(existingDS, datasourceStatus, time.Now(), ops.HealthCheckInterval())
 
-		return &model.DSResponse{
+		newDSResponse := &model.DSResponse{
 			DsID:              datasource.DsID,
 			DsName:            &datasource.DsName,
 			DsType:            &datasource.DsType,
@@ -226,7 +437,10 @@ func UpdateDataSource(datasource model.DSInput) (*model.DSResponse, error) {
 			QueryTimeout:      &datasource.QueryTimeout,
 			HTTPMethod:        &datasource.HTTPMethod,
 			UpdatedAt:         &timestamp,
-		}, nil
+		}
+		fillDataSourceResponse(newDSResponse, &updatedDS)
+
+		return newDSResponse, nil
 
 	} else {
 		return nil, errors.New("data source is inactive")
@@ -249,6 +463,38 @@ func UpdateDashBoard(dashboard model.UpdateDBInput, chaosQueryUpdate bool) (stri
 	var update bson.D
 
 	if !chaosQueryUpdate {
+		dsID := dashboard.DsID
+		variables := dashboard.Variables
+		if dsID == nil || *dsID == "" || variables == nil {
+			existingDashboard, err := dbOperationsAnalytics.GetDashboard(query)
+			if err != nil {
+				return "error fetching the dashboard", errors.New("failed to get the dashboard: " + err.Error())
+			}
+			if dsID == nil || *dsID == "" {
+				dsID = &existingDashboard.DsID
+			}
+			if variables == nil {
+				err = copier.Copy(&variables, &existingDashboard.Variables)
+				if err != nil {
+					return "error fetching the dashboard variables", err
+				}
+			}
+		}
+
+		if err := ops.ValidateVariables(variables); err != nil {
+			return "invalid dashboard variable", err
+		}
+
+		var panels []*model.Panel
+		for _, panelGroup := range dashboard.PanelGroups {
+			if panelGroup != nil {
+				panels = append(panels, panelGroup.Panels...)
+			}
+		}
+		if err := validatePanelQueries(*dsID, panels, variables); err != nil {
+			return "invalid panel query", err
+		}
+
 		var (
 			newPanelGroups                = make([]dbSchemaAnalytics.PanelGroup, len(dashboard.PanelGroups))
 			panelsToCreate                []*dbSchemaAnalytics.Panel
@@ -444,12 +690,23 @@ func UpdateDashBoard(dashboard model.UpdateDBInput, chaosQueryUpdate bool) (stri
 			}
 		}
 
-		update = bson.D{{"$set", bson.D{{"ds_id", dashboard.DsID}, {"db_name", dashboard.DbName},
+		fields := bson.D{{"ds_id", dashboard.DsID}, {"db_name", dashboard.DbName},
 			{"db_type_id", dashboard.DbTypeID}, {"db_type_name", dashboard.DbTypeName},
 			{"db_information", dashboard.DbInformation}, {"cluster_id", dashboard.ClusterID},
 			{"application_metadata_map", newApplicationMetadataMap}, {"end_time", dashboard.EndTime},
 			{"start_time", dashboard.StartTime}, {"refresh_rate", dashboard.RefreshRate},
-			{"panel_groups", newPanelGroups}, {"updated_at", timestamp}}}}
+			{"panel_groups", newPanelGroups}, {"updated_at", timestamp}}
+
+		if dashboard.Variables != nil {
+			var newVariables []dbSchemaAnalytics.DashboardVariable
+			err = copier.Copy(&newVariables, &dashboard.Variables)
+			if err != nil {
+				return "error updating variables", err
+			}
+			fields = append(fields, bson.E{"variables", newVariables})
+		}
+
+		update = bson.D{{"$set", fields}}
 	} else {
 		update = bson.D{{"$set", bson.D{
 			{"chaos_event_query_template", dashboard.ChaosEventQueryTemplate}, {"chaos_verdict_query_template", dashboard.ChaosVerdictQueryTemplate},
@@ -651,44 +908,34 @@ func QueryListDataSource(projectID string) ([]*model.DSResponse, error) {
 		return nil, err
 	}
 
-	var (
-		newDataSources []*model.DSResponse
-		wg             sync.WaitGroup
-	)
+	var newDataSources []*model.DSResponse
 	err = copier.Copy(&newDataSources, &datasource)
 	if err != nil {
 		return nil, err
 	}
 
-	tsdbHealthCheckMap := make(map[string]string)
-
-	var mutex = &sync.Mutex{}
-	wg.Add(len(newDataSources))
-
-	for _, datasource := range newDataSources {
-		datasource := datasource
-		go func(val *model.DSResponse) {
-			defer wg.Done()
-
-			if _, ok := tsdbHealthCheckMap[*datasource.DsURL]; !ok {
-				mutex.Lock()
-				tsdbHealthCheckMap[*datasource.DsURL] = prometheus.TSDBHealthCheck(*datasource.DsURL, *datasource.DsType)
-				mutex.Unlock()
-			}
-		}(datasource)
+	// the health is checked in the background, data sources without a recent check are checked now
+	now := time.Now()
+	interval := ops.HealthCheckInterval()
+	var unchecked []*dbSchemaAnalytics.DataSource
+	for _, ds := range datasource {
+		if ops.IsHealthStale(ds.Health, now, interval) {
+			unchecked = append(unchecked, ds)
+		}
 	}
+	checkDataSourcesHealth(unchecked, interval)
 
-	wg.Wait()
-
-	for _, datasource := range newDataSources {
-		datasource.HealthStatus = tsdbHealthCheckMap[*datasource.DsURL]
+	for i, newDataSource := range newDataSources {
+		newDataSource.HealthStatus = ops.HealthUnknown
+		fillDataSourceHealth(newDataSource, datasource[i].Health, now, interval)
+		fillDataSourceResponse(newDataSource, datasource[i])
 	}
 
 	return newDataSources, nil
 }
 
 // GetPromQuery takes prometheus queries and returns response for annotations and metrics with a query map
-func GetPromQuery(promInput *model.PromInput) (*model.PromResponse, map[string]*model.MetricsPromResponse, error) {
+func GetPromQuery(promInput *model.PromInput, config *analytics.DataSourceConfig) (*model.PromResponse, map[string]*model.MetricsPromResponse, error) {
 	var (
 		metrics         []*model.MetricsPromResponse
 		annotations     []*model.AnnotationsPromResponse
@@ -696,6 +943,11 @@ func GetPromQuery(promInput *model.PromInput) (*model.PromResponse, map[string]*
 		verdictResponse *model.AnnotationsPromResponse
 	)
 
+	driver, err := ops.DataSourceDriver(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	patchEventWithVerdict := false
 	queryResponseMap := make(map[string]*model.MetricsPromResponse)
 	var mutex = &sync.Mutex{}
@@ -711,66 +963,37 @@ func GetPromQuery(promInput *model.PromInput) (*model.PromResponse, map[string]*
 				Legend:     val.Legend,
 				Resolution: val.Resolution,
 				Minstep:    val.Minstep,
-				DSdetails:  (*analytics.PromDSDetails)(promInput.DsDetails),
+				Instant:    val.Instant != nil && *val.Instant,
+				DSdetails:  ops.NewPromDSDetails(promInput.DsDetails, config),
 			}
 
-			cacheKey := val.Query + "-" + promInput.DsDetails.Start + "-" + promInput.DsDetails.End + "-" + promInput.DsDetails.URL
-
 			queryType := "metrics"
 			if strings.Contains(val.Queryid, "chaos-event") || strings.Contains(val.Queryid, "chaos-verdict") {
 				queryType = "annotation"
-				cacheKey = val.Queryid + "-" + promInput.DsDetails.Start + "-" + promInput.DsDetails.End + "-" + promInput.DsDetails.URL
 			}
 
-			if obj, isExist := AnalyticsCache.Get(cacheKey); isExist {
-				if queryType == "metrics" {
-					metrics = append(metrics, obj.(*model.MetricsPromResponse))
-					mutex.Lock()
-					queryResponseMap[val.Queryid] = obj.(*model.MetricsPromResponse)
-					mutex.Unlock()
-				} else {
-					if strings.Contains(val.Queryid, "chaos-event") {
-						annotations = append(annotations, obj.(*model.AnnotationsPromResponse))
-					}
-				}
+			cacheKey := QueryCache.Align(&newPromQuery, queryType)
+			response, err := QueryCache.Get(cacheKey, func() (interface{}, error) {
+				return driver.Query(newPromQuery, queryType)
+			})
+			if err != nil {
+				log.Printf("Error during data source query %v: %v\n", val.Queryid, err)
+				return
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			if queryType == "metrics" {
+				// the cached response is shared by the queries of every view
+				metricsResponse := *response.(*model.MetricsPromResponse)
+				metricsResponse.Queryid = val.Queryid
+				metrics = append(metrics, &metricsResponse)
+				queryResponseMap[val.Queryid] = &metricsResponse
+			} else if strings.Contains(val.Queryid, "chaos-event") {
+				annotations = append(annotations, ops.CloneAnnotations(response.(*model.AnnotationsPromResponse), val.Queryid))
 			} else {
-				response, err := prometheus.Query(newPromQuery, queryType)
-				if err != nil {
-					return
-				}
-				if queryType == "metrics" {
-					metrics = append(metrics, response.(*model.MetricsPromResponse))
-					mutex.Lock()
-					queryResponseMap[val.Queryid] = response.(*model.MetricsPromResponse)
-					mutex.Unlock()
-					cacheError := utils.AddCache(AnalyticsCache, cacheKey, response)
-					if cacheError != nil {
-						errorStr := fmt.Sprintf("%v", cacheError)
-						if strings.Contains(errorStr, "already exists") {
-							cacheError = utils.UpdateCache(AnalyticsCache, cacheKey, response)
-							if cacheError != nil {
-								log.Printf("Error while caching: %v\n", cacheError)
-							}
-						}
-					}
-				} else {
-					if strings.Contains(val.Queryid, "chaos-event") {
-						annotations = append(annotations, response.(*model.AnnotationsPromResponse))
-						cacheError := utils.AddCache(AnalyticsCache, cacheKey, response)
-						if cacheError != nil {
-							errorStr := fmt.Sprintf("%v", cacheError)
-							if strings.Contains(errorStr, "already exists") {
-								cacheError = utils.UpdateCache(AnalyticsCache, cacheKey, response)
-								if cacheError != nil {
-									log.Printf("Error while caching: %v\n", cacheError)
-								}
-							}
-						}
-					} else if strings.Contains(val.Queryid, "chaos-verdict") {
-						patchEventWithVerdict = true
-						verdictResponse = response.(*model.AnnotationsPromResponse)
-					}
-				}
+				patchEventWithVerdict = true
+				verdictResponse = ops.CloneAnnotations(response.(*model.AnnotationsPromResponse), val.Queryid)
 			}
 		}(v)
 	}
@@ -778,7 +1001,7 @@ func GetPromQuery(promInput *model.PromInput) (*model.PromResponse, map[string]*
 	wg.Wait()
 
 	if patchEventWithVerdict == true {
-		annotations = ops.PatchChaosEventWithVerdict(annotations, verdictResponse, promInput, AnalyticsCache)
+		annotations = ops.PatchChaosEventWithVerdict(annotations, verdictResponse)
 	}
 
 	newPromResponse := model.PromResponse{
@@ -789,8 +1012,9 @@ func GetPromQuery(promInput *model.PromInput) (*model.PromResponse, map[string]*
 	return &newPromResponse, queryResponseMap, nil
 }
 
-// DashboardViewer takes a dashboard view id, prometheus queries, dashboard query map and data variables to query prometheus and send data periodically to the subscribed client
-func DashboardViewer(viewID string, dashboardID *string, promQueries []*model.PromQueryInput, dashboardQueryMap []*model.QueryMapForPanelGroup, dataVariables model.DataVars, r store.StateData) {
+// DashboardViewer takes a dashboard view id, prometheus queries, dashboard query map and data variables to query prometheus and send data periodically to the subscribed client.
+// The variables of the dashboard are replaced in the queries before they are sent to the data source
+func DashboardViewer(viewID string, dashboardID *string, dashboard *dbSchemaAnalytics.DashBoard, promQueries []*model.PromQueryInput, dashboardQueryMap []*model.QueryMapForPanelGroup, dataVariables model.DataVars, config *analytics.DataSourceConfig, r store.StateData) {
 	if viewChan, ok := r.DashboardData[viewID]; ok {
 
 		currentTime := time.Now().Unix()
@@ -810,6 +1034,12 @@ func DashboardViewer(viewID string, dashboardID *string, promQueries []*model.Pr
 			queryType = "invalid"
 		}
 
+		if queryType == "fixed" {
+			promQueries = substituteDashboardVariables(dashboard, promQueries, dataVariables, config, dataVariables.Start, dataVariables.End)
+		} else if queryType != "invalid" {
+			promQueries = substituteDashboardVariables(dashboard, promQueries, dataVariables, config, startTime, endTime)
+		}
+
 		switch queryType {
 
 		case "fixed":
@@ -824,7 +1054,7 @@ func DashboardViewer(viewID string, dashboardID *string, promQueries []*model.Pr
 				DsDetails: dsDetails,
 			}
 
-			newPromResponse, queryResponseMap, err := GetPromQuery(newPromInput)
+			newPromResponse, queryResponseMap, err := GetPromQuery(newPromInput, config)
 			if err != nil {
 				log.Printf("Error during data source query of the dashboard view: %v\n", viewID)
 			} else {
@@ -845,7 +1075,7 @@ func DashboardViewer(viewID string, dashboardID *string, promQueries []*model.Pr
 					DsDetails: dsDetails,
 				}
 
-				newPromResponse, queryResponseMap, err := GetPromQuery(newPromInput)
+				newPromResponse, queryResponseMap, err := GetPromQuery(newPromInput, config)
 				if err != nil {
 					log.Printf("Error during data source query of the dashboard view: %v at: %v \n", viewID, currentTime)
 					break
@@ -875,7 +1105,7 @@ func DashboardViewer(viewID string, dashboardID *string, promQueries []*model.Pr
 				DsDetails: dsDetails,
 			}
 
-			newPromResponse, queryResponseMap, err := GetPromQuery(newPromInput)
+			newPromResponse, queryResponseMap, err := GetPromQuery(newPromInput, config)
 			if err != nil {
 				log.Printf("Error during data source query of the dashboard view: %v at: %v \n", viewID, currentTime)
 			} else {
@@ -896,18 +1126,102 @@ func DashboardViewer(viewID string, dashboardID *string, promQueries []*model.Pr
 	}
 }
 
-func GetLabelNamesAndValues(promSeriesInput *model.PromSeriesInput) (*model.PromSeriesResponse, error) {
+// GetDashboardVariableOptions returns the values which can be selected for the variables of a dashboard,
+// query variables list the label values over the time range of the dashboard
+func GetDashboardVariableOptions(projectID string, dbID string) ([]*model.DashboardVariableOptions, error) {
+	dashboard, err := dbOperationsAnalytics.GetDashboard(bson.D{{"project_id", projectID}, {"db_id", dbID}, {"is_removed", false}})
+	if err != nil {
+		return nil, errors.New("failed to get the dashboard: " + err.Error())
+	}
+
+	datasource, err := dbOperationsAnalytics.GetDataSourceByID(dashboard.DsID)
+	if err != nil {
+		return nil, errors.New("failed to get the data source: " + err.Error())
+	}
+	config, err := ops.NewDataSourceConfig(datasource)
+	if err != nil {
+		return nil, errors.New("failed to decrypt the credentials of the data source: " + err.Error())
+	}
+	driver, err := ops.DataSourceDriver(config)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := dashboard.StartTime, dashboard.EndTime
+	if _, err := strconv.ParseInt(start, 10, 64); err != nil {
+		start = strconv.FormatInt(time.Now().Unix()-1800, 10)
+	}
+	if _, err := strconv.ParseInt(end, 10, 64); err != nil {
+		end = strconv.FormatInt(time.Now().Unix(), 10)
+	}
+	dsDetails := ops.NewPromDSDetails(&model.DsDetails{URL: datasource.DsURL, Start: start, End: end}, config)
+
+	variableOptions := []*model.DashboardVariableOptions{}
+	for _, variable := range dashboard.Variables {
+		options, err := ops.VariableOptions(variable, dashboard.ApplicationMetadataMap, driver, dsDetails)
+		if err != nil {
+			return nil, err
+		}
+		if options == nil {
+			options = []string{}
+		}
+		variableOptions = append(variableOptions, &model.DashboardVariableOptions{
+			Name:    variable.Name,
+			Options: options,
+		})
+	}
+
+	return variableOptions, nil
+}
+
+// substituteDashboardVariables replaces the variables of the dashboard and the global variables in the queries of a dashboard view,
+// the selected values of the view override the current values of the variables
+func substituteDashboardVariables(dashboard *dbSchemaAnalytics.DashBoard, promQueries []*model.PromQueryInput, dataVariables model.DataVars, config *analytics.DataSourceConfig, start, end string) []*model.PromQueryInput {
+	startTime, err := strconv.ParseInt(start, 10, 64)
+	if err != nil {
+		return promQueries
+	}
+	endTime, err := strconv.ParseInt(end, 10, 64)
+	if err != nil {
+		return promQueries
+	}
+
+	if dashboard == nil {
+		dashboard = &dbSchemaAnalytics.DashBoard{}
+	}
+	driver, err := ops.DataSourceDriver(config)
+	if err != nil {
+		log.Printf("Error while resolving the dashboard variables: %v\n", err)
+		return promQueries
+	}
+
+	dsDetails := &model.DsDetails{
+		URL:   dataVariables.URL,
+		Start: start,
+		End:   end,
+	}
+	values := ops.VariableValues(dashboard, dataVariables.Variables, driver, ops.NewPromDSDetails(dsDetails, config), startTime, endTime)
+
+	return ops.SubstituteQueryVariables(promQueries, values)
+}
+
+func GetLabelNamesAndValues(promSeriesInput *model.PromSeriesInput, config *analytics.DataSourceConfig) (*model.PromSeriesResponse, error) {
 	var newPromSeriesResponse *model.PromSeriesResponse
+	driver, err := ops.DataSourceDriver(config)
+	if err != nil {
+		return nil, err
+	}
+
 	newPromSeriesInput := analytics.PromSeries{
 		Series:    promSeriesInput.Series,
-		DSdetails: (*analytics.PromDSDetails)(promSeriesInput.DsDetails),
+		DSdetails: ops.NewPromDSDetails(promSeriesInput.DsDetails, config),
 	}
 	cacheKey := promSeriesInput.Series + " - " + promSeriesInput.DsDetails.URL
 
 	if obj, isExist := AnalyticsCache.Get(cacheKey); isExist {
 		newPromSeriesResponse = obj.(*model.PromSeriesResponse)
 	} else {
-		response, err := prometheus.LabelNamesAndValues(newPromSeriesInput)
+		response, err := driver.LabelNamesAndValues(newPromSeriesInput)
 		if err != nil {
 			return nil, err
 		}
@@ -929,19 +1243,20 @@ func GetLabelNamesAndValues(promSeriesInput *model.PromSeriesInput) (*model.Prom
 	return newPromSeriesResponse, nil
 }
 
-func GetSeriesList(promSeriesListInput *model.DsDetails) (*model.PromSeriesListResponse, error) {
+func GetSeriesList(promSeriesListInput *model.DsDetails, config *analytics.DataSourceConfig) (*model.PromSeriesListResponse, error) {
 	var newPromSeriesListResponse *model.PromSeriesListResponse
-	newPromSeriesListInput := analytics.PromDSDetails{
-		URL:   promSeriesListInput.URL,
-		Start: promSeriesListInput.Start,
-		End:   promSeriesListInput.End,
+	driver, err := ops.DataSourceDriver(config)
+	if err != nil {
+		return nil, err
 	}
+
+	newPromSeriesListInput := *ops.NewPromDSDetails(promSeriesListInput, config)
 	cacheKey := "series list - " + promSeriesListInput.URL
 
 	if obj, isExist := AnalyticsCache.Get(cacheKey); isExist {
 		newPromSeriesListResponse = obj.(*model.PromSeriesListResponse)
 	} else {
-		response, err := prometheus.SeriesList(newPromSeriesListInput)
+		response, err := driver.SeriesList(newPromSeriesListInput)
 		if err != nil {
 			return nil, err
 		}
@@ -963,6 +1278,85 @@ func GetSeriesList(promSeriesListInput *model.DsDetails) (*model.PromSeriesListR
 	return newPromSeriesListResponse, nil
 }
 
+// ValidatePromQueries parses the queries with the PromQL parser of the data source
+func ValidatePromQueries(promInput *model.PromInput, config *analytics.DataSourceConfig) ([]*model.PromQueryValidation, error) {
+	dsConfig := analytics.DataSourceConfig{URL: promInput.DsDetails.URL}
+	if config != nil {
+		if !ops.SupportsPromQL(config.DsType) {
+			return nil, errors.New("data source of type " + config.DsType + " doesn't support PromQL queries")
+		}
+		dsConfig = *config
+	}
+
+	validations := []*model.PromQueryValidation{}
+	for _, query := range promInput.Queries {
+		if query == nil {
+			continue
+		}
+
+		queryErrors, err := prometheus.ValidateQuery(dsConfig, query.Query)
+		if err != nil {
+			return nil, errors.New("failed to validate query " + query.Queryid + ": " + err.Error())
+		}
+		if queryErrors == nil {
+			queryErrors = []*model.PromQueryError{}
+		}
+
+		validations = append(validations, &model.PromQueryValidation{
+			Queryid: query.Queryid,
+			Valid:   len(queryErrors) == 0,
+			Errors:  queryErrors,
+		})
+	}
+
+	return validations, nil
+}
+
+// validatePanelQueries rejects panels with queries which the data source can't parse, the dashboard variables
+// are replaced by their current values first. There is no local PromQL parser, so the panels are saved without
+// validation when the data source can't be reached rather than blocking dashboard edits on its availability
+func validatePanelQueries(dsID string, panels []*model.Panel, variables []*model.DashboardVariable) error {
+	datasource, err := dbOperationsAnalytics.GetDataSourceByID(dsID)
+	if err != nil {
+		return errors.New("failed to get the data source: " + err.Error())
+	}
+	if !ops.SupportsPromQL(datasource.DsType) {
+		return nil
+	}
+	config, err := ops.NewDataSourceConfig(datasource)
+	if err != nil {
+		return errors.New("failed to decrypt the credentials of the data source: " + err.Error())
+	}
+	values := ops.StaticVariableValues(variables)
+
+	for _, panel := range panels {
+		if panel == nil {
+			continue
+		}
+		for _, promQuery := range panel.PromQueries {
+			if promQuery == nil || promQuery.PromQueryName == nil || *promQuery.PromQueryName == "" {
+				continue
+			}
+
+			queryErrors, err := prometheus.ValidateQuery(*config, ops.SubstituteVariables(*promQuery.PromQueryName, values))
+			if err != nil {
+				log.Printf("Skipping the validation of the panel queries, the data source couldn't validate them: %v\n", err)
+				return nil
+			}
+			if len(queryErrors) > 0 {
+				queryError := queryErrors[0]
+				position := ""
+				if queryError.Line != nil && queryError.Column != nil {
+					position = strconv.Itoa(*queryError.Line) + ":" + strconv.Itoa(*queryError.Column) + ": "
+				}
+				return errors.New("query " + promQuery.Queryid + " of panel " + panel.PanelName + " is invalid: " + position + queryError.Message)
+			}
+		}
+	}
+
+	return nil
+}
+
 // QueryListDashboard lists all the dashboards present in a project using the projectID
 func QueryListDashboard(projectID string, clusterID *string, dbID *string) ([]*model.ListDashboardResponse, error) {
 
@@ -1021,7 +1415,7 @@ func QueryListDashboard(projectID string, clusterID *string, dbID *string) ([]*m
 					defer wg.Done()
 
 					if _, ok := dataSourceHealthCheckMap[val.DsID]; !ok {
-						dataSourceStatus := prometheus.TSDBHealthCheck(datasource.DsURL, datasource.DsType)
+						dataSourceStatus := ops.CheckDataSourceHealth(val)
 						mutex.Lock()
 						dataSourceHealthCheckMap[val.DsID] = dataSourceStatus
 						mutex.Unlock()
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics/ops"
+	dbOperationsAnalytics "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/analytics"
+	dbSchemaAnalytics "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/analytics"
+)
+
+const (
+	// health checks running at once
+	maxConcurrentHealthChecks = 10
+	// window of the uptime of the listed data sources and of the default health history
+	defaultHealthWindow = 24 * 60 * 60
+)
+
+// MonitorDataSourceHealth checks the health of every data source at the health check interval and records the changes
+func MonitorDataSourceHealth() {
+	interval := ops.HealthCheckInterval()
+	for {
+		datasources, err := dbOperationsAnalytics.ListDataSource(bson.D{{"is_removed", false}})
+		if err != nil {
+			log.Print("Could not get the data sources for their health checks : ", err)
+		} else {
+			checkDataSourcesHealth(datasources, interval)
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+// checkDataSourcesHealth checks and records the health of the data sources concurrently
+func checkDataSourcesHealth(datasources []*dbSchemaAnalytics.DataSource, interval time.Duration) {
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, maxConcurrentHealthChecks)
+
+	for _, ds := range datasources {
+		wg.Add(1)
+		limit <- struct{}{}
+		go func(ds *dbSchemaAnalytics.DataSource) {
+			defer wg.Done()
+			defer func() { <-limit }()
+
+			status := ops.CheckDataSourceHealth(ds)
+			recordDataSourceHealth(ds, status, time.Now(), interval)
+		}(ds)
+	}
+
+	wg.Wait()
+}
+
+// recordDataSourceHealth stores the result of a health check and updates the health of the data source in place.
+// The update only applies to the health which was checked, concurrent checks of other servers are dropped
+func recordDataSourceHealth(ds *dbSchemaAnalytics.DataSource, status string, now time.Time, interval time.Duration) {
+	checkedAt := strconv.FormatInt(now.Unix(), 10)
+	events := ops.HealthEvents(ds.Health, status, now, interval)
+
+	query := bson.D{{"ds_id", ds.DsID}, {"health", nil}}
+	if ds.Health != nil {
+		query = bson.D{{"ds_id", ds.DsID}, {"health.checked_at", ds.Health.CheckedAt}}
+	}
+
+	fields := bson.D{{"health.status", status}, {"health.checked_at", checkedAt}}
+	update := bson.D{{"$set", fields}}
+	if len(events) > 0 {
+		update = bson.D{
+			{"$set", append(fields, bson.E{"health.last_change_at", checkedAt})},
+			{"$push", bson.D{{"health.history", bson.D{
+				{"$each", events},
+				{"$slice", -ops.MaxHealthEvents},
+			}}}},
+		}
+	}
+
+	updated, err := dbOperationsAnalytics.UpdateDataSourceHealth(query, update)
+	if err != nil {
+		log.Print("Could not record the health of data source ", ds.DsID, " : ", err)
+		return
+	}
+	if !updated {
+		return
+	}
+
+	if ds.Health == nil {
+		ds.Health = &dbSchemaAnalytics.DataSourceHealth{}
+	}
+	ds.Health.Status = status
+	ds.Health.CheckedAt = checkedAt
+	if len(events) > 0 {
+		ds.Health.LastChangeAt = checkedAt
+		ds.Health.History = append(ds.Health.History, events...)
+		if len(ds.Health.History) > ops.MaxHealthEvents {
+			ds.Health.History = ds.Health.History[len(ds.Health.History)-ops.MaxHealthEvents:]
+		}
+	}
+}
+
+// fillDataSourceHealth sets the cached health of the data source and its uptime over the last 24 hours
+func fillDataSourceHealth(response *model.DSResponse, health *dbSchemaAnalytics.DataSourceHealth, now time.Time, interval time.Duration) {
+	if health == nil {
+		return
+	}
+
+	response.HealthStatus = health.Status
+	response.HealthCheckedAt = &health.CheckedAt
+	if health.LastChangeAt != "" {
+		response.HealthChangedAt = &health.LastChangeAt
+	}
+	response.UptimePercentage = ops.HealthUptime(ops.HealthPeriods(health, now.Unix()-defaultHealthWindow, now.Unix(), interval))
+}
+
+// GetDataSourceHealth returns the health history of the data source over the window in seconds
+func GetDataSourceHealth(projectID string, dsID string, window *int) (*model.DataSourceHealth, error) {
+	ds, err := dbOperationsAnalytics.GetDataSourceByID(dsID)
+	if err != nil || ds.ProjectID != projectID || ds.IsRemoved {
+		return nil, errors.New("data source not found")
+	}
+
+	windowSeconds := defaultHealthWindow
+	if window != nil {
+		if *window <= 0 {
+			return nil, errors.New("window must be positive")
+		}
+		windowSeconds = *window
+	}
+
+	now := time.Now()
+	interval := ops.HealthCheckInterval()
+	periods := ops.HealthPeriods(ds.Health, now.Unix()-int64(windowSeconds), now.Unix(), interval)
+
+	health := &model.DataSourceHealth{
+		DsID:             ds.DsID,
+		HealthStatus:     ops.HealthUnknown,
+		Window:           windowSeconds,
+		UptimePercentage: ops.HealthUptime(periods),
+		History:          []*model.DataSourceHealthEvent{},
+	}
+	if ds.Health != nil {
+		health.HealthStatus = ds.Health.Status
+		health.HealthCheckedAt = &ds.Health.CheckedAt
+		if ds.Health.LastChangeAt != "" {
+			health.HealthChangedAt = &ds.Health.LastChangeAt
+		}
+	}
+
+	for i, period := range periods {
+		event := &model.DataSourceHealthEvent{
+			HealthStatus: period.Status,
+			StartedAt:    strconv.FormatInt(period.Start, 10),
+		}
+		if i < len(periods)-1 {
+			endedAt := strconv.FormatInt(period.End, 10)
+			event.EndedAt = &endedAt
+		}
+		health.History = append(health.History, event)
+	}
+
+	return health, nil
+}
@@ -0,0 +1,157 @@
+package ops
+
+import (
+	"log"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics"
+	dbSchemaAnalytics "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/analytics"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/secrets"
+)
+
+// NewDataSourceConfig returns the connection settings of a stored data source with its credentials decrypted
+func NewDataSourceConfig(datasource *dbSchemaAnalytics.DataSource) (*analytics.DataSourceConfig, error) {
+	decrypted := *datasource
+	if err := DecryptDataSourceSecrets(&decrypted); err != nil {
+		return nil, err
+	}
+	datasource = &decrypted
+
+	config := &analytics.DataSourceConfig{
+		DsID:               datasource.DsID,
+		DsType:             datasource.DsType,
+		URL:                datasource.DsURL,
+		AuthType:           datasource.AuthType,
+		BasicAuthUsername:  stringValue(datasource.BasicAuthUsername),
+		BasicAuthPassword:  stringValue(datasource.BasicAuthPassword),
+		BearerToken:        stringValue(datasource.BearerToken),
+		CACert:             stringValue(datasource.CACert),
+		ClientCert:         stringValue(datasource.ClientCert),
+		ClientKey:          stringValue(datasource.ClientKey),
+		InsecureSkipVerify: datasource.InsecureSkipVerify,
+		TenantID:           stringValue(datasource.TenantID),
+		TenantHeader:       stringValue(datasource.TenantHeader),
+		QueryTimeout:       datasource.QueryTimeout,
+		HTTPMethod:         datasource.HTTPMethod,
+	}
+
+	if len(datasource.CustomHeaders) > 0 {
+		config.CustomHeaders = make(map[string]string)
+		for _, header := range datasource.CustomHeaders {
+			config.CustomHeaders[header.Name] = header.Value
+		}
+	}
+
+	return config, nil
+}
+
+// CheckDataSourceHealth checks the health of a stored data source, data sources whose credentials can't be
+// decrypted are inactive
+func CheckDataSourceHealth(datasource *dbSchemaAnalytics.DataSource) string {
+	config, err := NewDataSourceConfig(datasource)
+	if err != nil {
+		log.Print("Could not decrypt the credentials of data source ", datasource.DsID, " : ", err)
+		return "Inactive"
+	}
+	return HealthCheck(*config)
+}
+
+// EncryptDataSourceSecrets encrypts the credentials of the data source before it's stored, values which are
+// already encrypted are kept
+func EncryptDataSourceSecrets(datasource *dbSchemaAnalytics.DataSource) error {
+	var err error
+	if datasource.BasicAuthPassword, err = secrets.EncryptPtr(datasource.BasicAuthPassword); err != nil {
+		return err
+	}
+	if datasource.BearerToken, err = secrets.EncryptPtr(datasource.BearerToken); err != nil {
+		return err
+	}
+	if datasource.ClientKey, err = secrets.EncryptPtr(datasource.ClientKey); err != nil {
+		return err
+	}
+
+	headers := make([]dbSchemaAnalytics.DataSourceHeader, len(datasource.CustomHeaders))
+	for i, header := range datasource.CustomHeaders {
+		value, err := secrets.EncryptPtr(&header.Value)
+		if err != nil {
+			return err
+		}
+		headers[i] = dbSchemaAnalytics.DataSourceHeader{Name: header.Name, Value: *value}
+	}
+	if datasource.CustomHeaders != nil {
+		datasource.CustomHeaders = headers
+	}
+	return nil
+}
+
+// DecryptDataSourceSecrets decrypts the stored credentials of the data source, plaintext values written before
+// they were encrypted are returned unchanged
+func DecryptDataSourceSecrets(datasource *dbSchemaAnalytics.DataSource) error {
+	var err error
+	if datasource.BasicAuthPassword, err = secrets.DecryptPtr(datasource.BasicAuthPassword); err != nil {
+		return err
+	}
+	if datasource.BearerToken, err = secrets.DecryptPtr(datasource.BearerToken); err != nil {
+		return err
+	}
+	if datasource.ClientKey, err = secrets.DecryptPtr(datasource.ClientKey); err != nil {
+		return err
+	}
+
+	headers := make([]dbSchemaAnalytics.DataSourceHeader, len(datasource.CustomHeaders))
+	for i, header := range datasource.CustomHeaders {
+		value, err := secrets.Decrypt(header.Value)
+		if err != nil {
+			return err
+		}
+		headers[i] = dbSchemaAnalytics.DataSourceHeader{Name: header.Name, Value: value}
+	}
+	if datasource.CustomHeaders != nil {
+		datasource.CustomHeaders = headers
+	}
+	return nil
+}
+
+// RemoveDataSourceSecrets drops the credentials of the data source, the names of the custom headers are kept
+func RemoveDataSourceSecrets(datasource *dbSchemaAnalytics.DataSource) {
+	datasource.BasicAuthPassword = nil
+	datasource.BearerToken = nil
+	datasource.ClientKey = nil
+
+	headers := make([]dbSchemaAnalytics.DataSourceHeader, len(datasource.CustomHeaders))
+	for i, header := range datasource.CustomHeaders {
+		headers[i] = dbSchemaAnalytics.DataSourceHeader{Name: header.Name}
+	}
+	if datasource.CustomHeaders != nil {
+		datasource.CustomHeaders = headers
+	}
+}
+
+// NewDataSourceHeaders converts the custom headers of a data source input
+func NewDataSourceHeaders(headers []*model.DSHeaderInput) []dbSchemaAnalytics.DataSourceHeader {
+	var newHeaders []dbSchemaAnalytics.DataSourceHeader
+	for _, header := range headers {
+		newHeaders = append(newHeaders, dbSchemaAnalytics.DataSourceHeader{
+			Name:  header.Name,
+			Value: header.Value,
+		})
+	}
+	return newHeaders
+}
+
+// NewPromDSDetails converts the data source details of a query, config is nil for data sources which aren't stored
+func NewPromDSDetails(dsDetails *model.DsDetails, config *analytics.DataSourceConfig) *analytics.PromDSDetails {
+	return &analytics.PromDSDetails{
+		URL:    dsDetails.URL,
+		Start:  dsDetails.Start,
+		End:    dsDetails.End,
+		Config: config,
+	}
+}
+
+func stringValue(str *string) string {
+	if str == nil {
+		return ""
+	}
+	return *str
+}
@@ -0,0 +1,64 @@
+package ops
+
+import (
+	"errors"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics/ops/graphite"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics/ops/prometheus"
+)
+
+// Driver queries a type of data source, every driver returns the responses of the prometheus queries
+type Driver interface {
+	// Query runs a range query, queryType is either "metrics" or "annotation"
+	Query(prom analytics.PromQuery, queryType string) (interface{}, error)
+	LabelNamesAndValues(prom analytics.PromSeries) (*model.PromSeriesResponse, error)
+	SeriesList(prom analytics.PromDSDetails) (*model.PromSeriesListResponse, error)
+	// HealthCheck returns "Active" when the data source can be queried
+	HealthCheck(config analytics.DataSourceConfig) string
+}
+
+var drivers = map[string]Driver{
+	"Prometheus":      prometheus.Driver{},
+	"VictoriaMetrics": prometheus.CompatibleDriver{},
+	"Thanos":          prometheus.CompatibleDriver{},
+	"Cortex":          prometheus.CompatibleDriver{},
+	"Mimir":           prometheus.CompatibleDriver{},
+	"Graphite":        graphite.Driver{},
+}
+
+// GetDriver returns the driver of a data source type
+func GetDriver(dsType string) (Driver, error) {
+	driver, ok := drivers[dsType]
+	if !ok {
+		return nil, errors.New("unsupported data source type " + dsType)
+	}
+	return driver, nil
+}
+
+// DataSourceDriver returns the driver of the data source, data sources which aren't stored are queried as Prometheus
+func DataSourceDriver(config *analytics.DataSourceConfig) (Driver, error) {
+	if config == nil {
+		return drivers["Prometheus"], nil
+	}
+	return GetDriver(config.DsType)
+}
+
+// HealthCheck returns the health status of the data source
+func HealthCheck(config analytics.DataSourceConfig) string {
+	driver, err := GetDriver(config.DsType)
+	if err != nil {
+		return "Unsupported"
+	}
+	return driver.HealthCheck(config)
+}
+
+// SupportsPromQL returns whether the data source type is queried with PromQL
+func SupportsPromQL(dsType string) bool {
+	switch drivers[dsType].(type) {
+	case prometheus.Driver, prometheus.CompatibleDriver:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,596 @@
+package grafana
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics/ops"
+)
+
+const (
+	// ChaosEventsAnnotation is the name of the annotation carrying the chaos event query of a dashboard
+	ChaosEventsAnnotation = "Chaos events"
+	// ChaosVerdictsAnnotation is the name of the annotation carrying the chaos verdict query of a dashboard
+	ChaosVerdictsAnnotation = "Chaos verdicts"
+
+	defaultPanelGroupName = "General"
+	defaultRelativeTime   = 1800
+	defaultRefreshRate    = "15"
+	defaultMinstep        = "5"
+	schemaVersion         = 27
+)
+
+var (
+	relativeTime = regexp.MustCompile(`^now-(\d+)([smhdwMy])$`)
+	unitSeconds  = map[string]int64{"s": 1, "m": 60, "h": 3600, "d": 86400, "w": 604800, "M": 2592000, "y": 31536000}
+)
+
+// ImportedDashboard is a Grafana dashboard converted to the portal model
+type ImportedDashboard struct {
+	Name                      string
+	Information               *string
+	ChaosEventQueryTemplate   string
+	ChaosVerdictQueryTemplate string
+	PanelGroups               []*model.PanelGroup
+	Variables                 []*model.DashboardVariable
+	StartTime                 string
+	EndTime                   string
+	RefreshRate               string
+}
+
+// Import converts a Grafana dashboard, either the dashboard JSON or the envelope of the dashboard API.
+// The template variables become dashboard variables
+func Import(data []byte) (*ImportedDashboard, error) {
+	var envelope DashboardEnvelope
+	if err := json.Unmarshal(data, &envelope); err == nil && len(envelope.Dashboard) > 0 {
+		data = envelope.Dashboard
+	}
+
+	var dashboard Dashboard
+	if err := json.Unmarshal(data, &dashboard); err != nil {
+		return nil, errors.New("invalid grafana dashboard: " + err.Error())
+	}
+
+	now := time.Now().Unix()
+	rangeSeconds := parseRelativeTime(dashboard.Time.From)
+
+	imported := &ImportedDashboard{
+		Name:        dashboard.Title,
+		StartTime:   strconv.FormatInt(now-rangeSeconds, 10),
+		EndTime:     strconv.FormatInt(now, 10),
+		RefreshRate: parseRefresh(dashboard.Refresh),
+		Variables:   importVariables(dashboard.Templating),
+	}
+	if dashboard.Description != "" {
+		imported.Information = &dashboard.Description
+	}
+	for _, annotation := range dashboard.Annotations.List {
+		switch annotation.Name {
+		case ChaosEventsAnnotation:
+			imported.ChaosEventQueryTemplate = annotation.Expr
+		case ChaosVerdictsAnnotation:
+			imported.ChaosVerdictQueryTemplate = annotation.Expr
+		}
+	}
+
+	if len(dashboard.Rows) > 0 {
+		for _, row := range dashboard.Rows {
+			imported.addPanelGroup(row.Title, row.Panels)
+		}
+	} else {
+		var (
+			groupName = defaultPanelGroupName
+			panels    []Panel
+		)
+		for _, panel := range dashboard.Panels {
+			if panel.Type != "row" {
+				panels = append(panels, panel)
+				continue
+			}
+			imported.addPanelGroup(groupName, panels)
+			groupName = panel.Title
+			// collapsed rows hold their panels
+			panels = append([]Panel{}, panel.Panels...)
+		}
+		imported.addPanelGroup(groupName, panels)
+	}
+
+	if len(imported.PanelGroups) == 0 {
+		return nil, errors.New("grafana dashboard has no panels with queries")
+	}
+
+	return imported, nil
+}
+
+func (imported *ImportedDashboard) addPanelGroup(name string, panels []Panel) {
+	panelGroup := &model.PanelGroup{PanelGroupName: name}
+	if panelGroup.PanelGroupName == "" {
+		panelGroup.PanelGroupName = defaultPanelGroupName
+	}
+
+	for _, panel := range panels {
+		if newPanel := importPanel(panel); newPanel != nil {
+			panelGroup.Panels = append(panelGroup.Panels, newPanel)
+		}
+	}
+
+	if len(panelGroup.Panels) > 0 {
+		imported.PanelGroups = append(imported.PanelGroups, panelGroup)
+	}
+}
+
+// importPanel converts a graph or time series panel, panels without queries are skipped
+func importPanel(panel Panel) *model.Panel {
+	var promQueries []*model.PromQuery
+
+	closeArea := panel.Fill != nil && *panel.Fill > 0
+	line := panel.Lines == nil || *panel.Lines
+	points := panel.Points != nil && *panel.Points
+	var unit, yAxisLeft, yAxisRight string
+	if len(panel.Yaxes) > 0 {
+		unit = panel.Yaxes[0].Format
+		yAxisLeft = panel.Yaxes[0].Label
+	}
+	if len(panel.Yaxes) > 1 {
+		yAxisRight = panel.Yaxes[1].Label
+	}
+	if panel.FieldConfig != nil {
+		if panel.FieldConfig.Defaults.Unit != "" {
+			unit = panel.FieldConfig.Defaults.Unit
+		}
+		if custom := panel.FieldConfig.Defaults.Custom; custom != nil {
+			if custom.AxisLabel != "" {
+				yAxisLeft = custom.AxisLabel
+			}
+			closeArea = closeArea || custom.FillOpacity > 0
+			points = points || custom.ShowPoints == "always"
+			line = line && custom.DrawStyle != "points" && custom.DrawStyle != "bars"
+		}
+	}
+
+	for _, target := range panel.Targets {
+		if target.Hide || strings.TrimSpace(target.Expr) == "" {
+			continue
+		}
+
+		query := target.Expr
+		legend := target.LegendFormat
+		resolution := "1/1"
+		if target.IntervalFactor > 1 {
+			resolution = "1/" + strconv.Itoa(target.IntervalFactor)
+		}
+		minstep := defaultMinstep
+		if interval, err := time.ParseDuration(strings.TrimPrefix(target.Interval, ">")); err == nil && interval >= time.Second {
+			minstep = strconv.Itoa(int(interval.Seconds()))
+		}
+		instant := target.Instant
+
+		promQueries = append(promQueries, &model.PromQuery{
+			Queryid:       uuid.New().String(),
+			PromQueryName: &query,
+			Legend:        &legend,
+			Resolution:    &resolution,
+			Minstep:       &minstep,
+			Instant:       &instant,
+			Line:          &line,
+			CloseArea:     &closeArea,
+		})
+	}
+
+	if len(promQueries) == 0 {
+		return nil
+	}
+
+	panelID := ""
+	xAxisDown := "Time"
+	grids, leftAxis := true, true
+	return &model.Panel{
+		PanelID:     &panelID,
+		PanelName:   panel.Title,
+		YAxisLeft:   &yAxisLeft,
+		YAxisRight:  &yAxisRight,
+		XAxisDown:   &xAxisDown,
+		Unit:        &unit,
+		PromQueries: promQueries,
+		PanelOptions: &model.PanelOption{
+			Points:   &points,
+			Grids:    &grids,
+			LeftAxis: &leftAxis,
+		},
+	}
+}
+
+// importVariables converts the template variables which have a counterpart among the dashboard variables.
+// Constants and text boxes become custom variables, query variables are kept when they list label values
+func importVariables(templating Templating) []*model.DashboardVariable {
+	var variables []*model.DashboardVariable
+
+	for _, variable := range templating.List {
+		newVariable := &model.DashboardVariable{
+			Name:    variable.Name,
+			Current: currentValues(variable.Current),
+		}
+		// the loop variable is reused, so its fields are copied before being referenced
+		label, multi, includeAll := variable.Label, variable.Multi, variable.IncludeAll
+		if label != "" {
+			newVariable.Label = &label
+		}
+		if multi {
+			newVariable.Multi = &multi
+		}
+		if includeAll {
+			newVariable.IncludeAll = &includeAll
+		}
+
+		query := variableQuery(variable.Query)
+		switch variable.Type {
+		case "query":
+			if ops.IsLabelValuesQuery(query) {
+				newVariable.Type = ops.VariableTypeQuery
+				newVariable.Query = &query
+			} else {
+				// the values of other queries can't be listed, the options known to grafana are kept
+				newVariable.Type = ops.VariableTypeCustom
+				newVariable.Options = optionValues(variable.Options)
+			}
+		case "custom":
+			newVariable.Type = ops.VariableTypeCustom
+			newVariable.Options = optionValues(variable.Options)
+			if len(newVariable.Options) == 0 {
+				newVariable.Options = splitOptions(query)
+			}
+		case "interval":
+			newVariable.Type = ops.VariableTypeInterval
+			newVariable.Options = splitOptions(query)
+			if variable.Auto {
+				newVariable.Options = append([]string{ops.AutoInterval}, newVariable.Options...)
+			}
+		case "constant", "textbox":
+			newVariable.Type = ops.VariableTypeCustom
+			newVariable.Options = []string{query}
+		default:
+			continue
+		}
+
+		if len(newVariable.Options) == 0 && newVariable.Type != ops.VariableTypeQuery {
+			newVariable.Options = newVariable.Current
+		}
+		if len(newVariable.Options) == 0 && newVariable.Type != ops.VariableTypeQuery {
+			continue
+		}
+		variables = append(variables, newVariable)
+	}
+
+	return variables
+}
+
+// exportVariables converts the dashboard variables to template variables using the default data source of Grafana.
+// Namespace and application variables become custom variables listing the application metadata of the dashboard
+func exportVariables(dashboard *model.ListDashboardResponse) []Variable {
+	variables := []Variable{}
+
+	for _, variable := range dashboard.Variables {
+		if variable == nil {
+			continue
+		}
+
+		newVariable := Variable{
+			Name:       variable.Name,
+			Multi:      variable.Multi != nil && *variable.Multi,
+			IncludeAll: variable.IncludeAll != nil && *variable.IncludeAll,
+		}
+		if variable.Label != nil {
+			newVariable.Label = *variable.Label
+		}
+
+		options := variable.Options
+		switch variable.Type {
+		case ops.VariableTypeQuery:
+			newVariable.Type = "query"
+			newVariable.Refresh = 2
+			if variable.Query != nil {
+				newVariable.Query = *variable.Query
+			}
+		case ops.VariableTypeInterval:
+			newVariable.Type = "interval"
+			var intervals []string
+			for _, option := range options {
+				if option == ops.AutoInterval {
+					newVariable.Auto = true
+					continue
+				}
+				intervals = append(intervals, option)
+			}
+			newVariable.Query = strings.Join(intervals, ",")
+		case ops.VariableTypeNamespace, ops.VariableTypeApplication:
+			options = applicationOptions(dashboard.ApplicationMetadataMap, variable)
+			fallthrough
+		default:
+			newVariable.Type = "custom"
+			newVariable.Query = strings.Join(options, ",")
+		}
+
+		for _, option := range options {
+			if option == ops.AutoInterval {
+				continue
+			}
+			newVariable.Options = append(newVariable.Options, VariableValue{Text: option, Value: option})
+		}
+
+		current := variable.Current
+		if len(current) == 0 && len(newVariable.Options) > 0 {
+			current = []string{newVariable.Options[0].Value}
+		}
+		if newVariable.Multi {
+			newVariable.Current = VariableCurrent{Text: current, Value: current}
+		} else if len(current) > 0 {
+			newVariable.Current = VariableCurrent{Text: current[0], Value: current[0]}
+		}
+
+		variables = append(variables, newVariable)
+	}
+
+	return variables
+}
+
+func applicationOptions(applicationMetadataMap []*model.ApplicationMetadataResponse, variable *model.DashboardVariableResponse) []string {
+	var options []string
+	for _, applicationMetadata := range applicationMetadataMap {
+		if applicationMetadata == nil {
+			continue
+		}
+		if variable.Type == ops.VariableTypeNamespace {
+			options = append(options, applicationMetadata.Namespace)
+			continue
+		}
+		for _, application := range applicationMetadata.Applications {
+			if application == nil || (variable.Query != nil && *variable.Query != "" && !strings.EqualFold(application.Kind, *variable.Query)) {
+				continue
+			}
+			for _, name := range application.Names {
+				if name != nil {
+					options = append(options, *name)
+				}
+			}
+		}
+	}
+	return options
+}
+
+// currentValues returns the selected values of a template variable, "All" is kept as $__all
+func currentValues(current VariableCurrent) []string {
+	var values []string
+	switch value := current.Value.(type) {
+	case string:
+		if value != "" {
+			values = []string{value}
+		}
+	case []interface{}:
+		for _, v := range value {
+			if str, ok := v.(string); ok {
+				values = append(values, str)
+			}
+		}
+	}
+	return values
+}
+
+// variableQuery returns the query of a template variable, newer versions of Grafana store it in an object
+func variableQuery(query interface{}) string {
+	switch value := query.(type) {
+	case string:
+		return value
+	case map[string]interface{}:
+		if str, ok := value["query"].(string); ok {
+			return str
+		}
+	}
+	return ""
+}
+
+func optionValues(options []VariableValue) []string {
+	var values []string
+	for _, option := range options {
+		if option.Value != "" && option.Value != ops.AllValue {
+			values = append(values, option.Value)
+		}
+	}
+	return values
+}
+
+func splitOptions(query string) []string {
+	var values []string
+	for _, value := range strings.Split(query, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func parseRelativeTime(from string) int64 {
+	match := relativeTime.FindStringSubmatch(from)
+	if match == nil {
+		return defaultRelativeTime
+	}
+	count, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil || count <= 0 {
+		return defaultRelativeTime
+	}
+	return count * unitSeconds[match[2]]
+}
+
+func parseRefresh(refresh interface{}) string {
+	str, ok := refresh.(string)
+	if !ok {
+		return defaultRefreshRate
+	}
+	duration, err := time.ParseDuration(str)
+	if err != nil || duration < time.Second {
+		return defaultRefreshRate
+	}
+	return strconv.Itoa(int(duration.Seconds()))
+}
+
+// Export converts a portal dashboard to a Grafana dashboard using the default data source of Grafana.
+// The chaos event and verdict queries become annotations so that they survive a round trip
+func Export(dashboard *model.ListDashboardResponse) ([]byte, error) {
+	newDashboard := Dashboard{
+		Title:         dashboard.DbName,
+		Tags:          []string{"litmuschaos"},
+		Editable:      true,
+		SchemaVersion: schemaVersion,
+		Time:          TimeRange{From: "now-" + exportRange(dashboard) + "s", To: "now"},
+		Refresh:       dashboard.RefreshRate + "s",
+		Templating:    Templating{List: exportVariables(dashboard)},
+		Panels:        []Panel{},
+	}
+	if dashboard.DbInformation != nil {
+		newDashboard.Description = *dashboard.DbInformation
+	}
+	if dashboard.RefreshRate == "" {
+		newDashboard.Refresh = defaultRefreshRate + "s"
+	}
+
+	newDashboard.Annotations.List = []Annotation{
+		{BuiltIn: 1, Datasource: "-- Grafana --", Enable: true, IconColor: "rgba(0, 211, 255, 1)", Name: "Annotations & Alerts"},
+		{Enable: true, Expr: dashboard.ChaosEventQueryTemplate, IconColor: "red", Name: ChaosEventsAnnotation, Step: defaultMinstep + "s", TitleFormat: "{{chaosengine_context}}"},
+		{Enable: false, Expr: dashboard.ChaosVerdictQueryTemplate, IconColor: "green", Name: ChaosVerdictsAnnotation, Step: defaultMinstep + "s", TitleFormat: "{{chaosresult_verdict}}"},
+	}
+
+	id, y := 1, 0
+	for _, panelGroup := range dashboard.PanelGroups {
+		if panelGroup == nil {
+			continue
+		}
+
+		newDashboard.Panels = append(newDashboard.Panels, Panel{
+			ID:      id,
+			Type:    "row",
+			Title:   panelGroup.PanelGroupName,
+			GridPos: GridPos{H: 1, W: 24, X: 0, Y: y},
+		})
+		id++
+		y++
+
+		for i, panel := range panelGroup.Panels {
+			if panel == nil {
+				continue
+			}
+			newPanel := exportPanel(panel)
+			newPanel.ID = id
+			newPanel.GridPos = GridPos{H: 8, W: 12, X: (i % 2) * 12, Y: y + (i/2)*8}
+			newDashboard.Panels = append(newDashboard.Panels, newPanel)
+			id++
+		}
+		y += ((len(panelGroup.Panels) + 1) / 2) * 8
+	}
+
+	return json.MarshalIndent(newDashboard, "", "  ")
+}
+
+func exportPanel(panel *model.PanelResponse) Panel {
+	var unit, yAxisLeft, yAxisRight string
+	if panel.Unit != nil {
+		unit = *panel.Unit
+	}
+	if unit == "" {
+		unit = "short"
+	}
+	if panel.YAxisLeft != nil {
+		yAxisLeft = *panel.YAxisLeft
+	}
+	if panel.YAxisRight != nil {
+		yAxisRight = *panel.YAxisRight
+	}
+
+	lines, points, fill := true, false, 0
+	showLeftAxis := true
+	if panel.PanelOptions != nil {
+		if panel.PanelOptions.Points != nil {
+			points = *panel.PanelOptions.Points
+		}
+		if panel.PanelOptions.LeftAxis != nil {
+			showLeftAxis = *panel.PanelOptions.LeftAxis
+		}
+	}
+
+	newPanel := Panel{
+		Type:   "graph",
+		Yaxes:  []Yaxis{{Format: unit, Label: yAxisLeft, Show: showLeftAxis}, {Format: unit, Label: yAxisRight, Show: yAxisRight != ""}},
+		Xaxis:  &Xaxis{Mode: "time", Show: true},
+		Lines:  &lines,
+		Points: &points,
+		Fill:   &fill,
+	}
+	if panel.PanelName != nil {
+		newPanel.Title = *panel.PanelName
+	}
+
+	for i, promQuery := range panel.PromQueries {
+		if promQuery == nil || promQuery.PromQueryName == nil {
+			continue
+		}
+
+		target := Target{
+			RefID:          refID(i),
+			Expr:           *promQuery.PromQueryName,
+			IntervalFactor: 1,
+		}
+		if promQuery.Legend != nil {
+			target.LegendFormat = *promQuery.Legend
+		}
+		if promQuery.Minstep != nil && *promQuery.Minstep != "" {
+			target.Interval = *promQuery.Minstep + "s"
+		}
+		if promQuery.Resolution != nil {
+			if parts := strings.Split(*promQuery.Resolution, "/"); len(parts) == 2 {
+				if factor, err := strconv.Atoi(parts[1]); err == nil && factor > 0 {
+					target.IntervalFactor = factor
+				}
+			}
+		}
+		if promQuery.Instant != nil {
+			target.Instant = *promQuery.Instant
+		}
+		if promQuery.Line != nil && !*promQuery.Line {
+			lines = false
+		}
+		if promQuery.CloseArea != nil && *promQuery.CloseArea {
+			fill = 1
+		}
+
+		newPanel.Targets = append(newPanel.Targets, target)
+	}
+
+	return newPanel
+}
+
+func exportRange(dashboard *model.ListDashboardResponse) string {
+	start, err := strconv.ParseInt(dashboard.StartTime, 10, 64)
+	if err != nil {
+		return strconv.Itoa(defaultRelativeTime)
+	}
+	end, err := strconv.ParseInt(dashboard.EndTime, 10, 64)
+	if err != nil || end <= start {
+		return strconv.Itoa(defaultRelativeTime)
+	}
+	return strconv.FormatInt(end-start, 10)
+}
+
+// refID returns the Grafana query ids A, B, ..., Z, AA, AB, ...
+func refID(i int) string {
+	id := ""
+	for i >= 0 {
+		id = string(rune('A'+i%26)) + id
+		i = i/26 - 1
+	}
+	return id
+}
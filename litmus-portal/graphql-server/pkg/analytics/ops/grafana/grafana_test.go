@@ -0,0 +1,404 @@
+package grafana
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+)
+
+const dashboardJSON = `{
+  "title": "Pod metrics",
+  "description": "CPU and memory of the application",
+  "time": {"from": "now-6h", "to": "now"},
+  "refresh": "1m",
+  "annotations": {"list": [
+    {"name": "Annotations & Alerts", "builtIn": 1},
+    {"name": "Chaos events", "expr": "litmuschaos_awaited_experiments"},
+    {"name": "Chaos verdicts", "expr": "litmuschaos_experiment_verdict"}
+  ]},
+  "panels": [
+    {"type": "graph", "title": "Requests", "yaxes": [{"format": "reqps", "label": "requests"}, {"format": "short", "label": "errors"}],
+     "targets": [{"refId": "A", "expr": "rate(http_requests_total[5m])", "legendFormat": "{{pod}}", "interval": ">30s", "intervalFactor": 2}]},
+    {"type": "row", "title": "Resources"},
+    {"type": "timeseries", "title": "CPU", "fieldConfig": {"defaults": {"unit": "percent", "custom": {"axisLabel": "cpu", "fillOpacity": 10, "showPoints": "always"}}},
+     "targets": [
+       {"refId": "A", "expr": "container_cpu_usage_seconds_total", "instant": true},
+       {"refId": "B", "expr": "hidden", "hide": true},
+       {"refId": "C", "expr": " "}
+     ]},
+    {"type": "text", "title": "Notes"},
+    {"type": "row", "title": "Collapsed", "collapsed": true, "panels": [
+      {"type": "graph", "title": "Memory", "lines": false, "points": true, "targets": [{"refId": "A", "expr": "container_memory_usage_bytes"}]}
+    ]},
+    {"type": "row", "title": "Empty"}
+  ]
+}`
+
+func TestImport(t *testing.T) {
+	imported, err := Import([]byte(dashboardJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if imported.Name != "Pod metrics" || imported.Information == nil || *imported.Information != "CPU and memory of the application" {
+		t.Errorf("unexpected name %q and information %v", imported.Name, imported.Information)
+	}
+	if imported.ChaosEventQueryTemplate != "litmuschaos_awaited_experiments" || imported.ChaosVerdictQueryTemplate != "litmuschaos_experiment_verdict" {
+		t.Errorf("unexpected annotation queries %q, %q", imported.ChaosEventQueryTemplate, imported.ChaosVerdictQueryTemplate)
+	}
+	start, _ := strconv.ParseInt(imported.StartTime, 10, 64)
+	end, _ := strconv.ParseInt(imported.EndTime, 10, 64)
+	if end-start != 21600 || imported.RefreshRate != "60" {
+		t.Errorf("range = %d, refresh = %s, want 21600, 60", end-start, imported.RefreshRate)
+	}
+
+	var groups []string
+	for _, group := range imported.PanelGroups {
+		var panels []string
+		for _, panel := range group.Panels {
+			panels = append(panels, panel.PanelName)
+		}
+		groups = append(groups, group.PanelGroupName+":"+strings.Join(panels, ","))
+	}
+	if got, want := strings.Join(groups, " "), "General:Requests Resources:CPU Collapsed:Memory"; got != want {
+		t.Fatalf("panel groups = %s, want %s", got, want)
+	}
+
+	requests := imported.PanelGroups[0].Panels[0]
+	query := requests.PromQueries[0]
+	if *requests.Unit != "reqps" || *requests.YAxisLeft != "requests" || *requests.YAxisRight != "errors" {
+		t.Errorf("unexpected axes of %s : %s, %s, %s", requests.PanelName, *requests.Unit, *requests.YAxisLeft, *requests.YAxisRight)
+	}
+	if *query.PromQueryName != "rate(http_requests_total[5m])" || *query.Legend != "{{pod}}" || *query.Minstep != "30" || *query.Resolution != "1/2" || *query.Instant {
+		t.Errorf("unexpected query %s, legend %s, minstep %s, resolution %s, instant %v", *query.PromQueryName, *query.Legend, *query.Minstep, *query.Resolution, *query.Instant)
+	}
+
+	cpu := imported.PanelGroups[1].Panels[0]
+	if len(cpu.PromQueries) != 1 {
+		t.Fatalf("got %d queries of %s, hidden and empty targets should be skipped", len(cpu.PromQueries), cpu.PanelName)
+	}
+	query = cpu.PromQueries[0]
+	if *cpu.Unit != "percent" || *cpu.YAxisLeft != "cpu" || !*cpu.PanelOptions.Points {
+		t.Errorf("unexpected options of %s : %s, %s, points %v", cpu.PanelName, *cpu.Unit, *cpu.YAxisLeft, *cpu.PanelOptions.Points)
+	}
+	if *query.Minstep != defaultMinstep || *query.Resolution != "1/1" || !*query.Instant || !*query.CloseArea || !*query.Line {
+		t.Errorf("unexpected query minstep %s, resolution %s, instant %v, close area %v, line %v", *query.Minstep, *query.Resolution, *query.Instant, *query.CloseArea, *query.Line)
+	}
+
+	memory := imported.PanelGroups[2].Panels[0]
+	if query = memory.PromQueries[0]; *query.Line || *query.CloseArea || !*memory.PanelOptions.Points {
+		t.Errorf("unexpected options of %s : line %v, close area %v, points %v", memory.PanelName, *query.Line, *query.CloseArea, *memory.PanelOptions.Points)
+	}
+}
+
+func TestImportFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		groups  string
+		wantErr string
+	}{
+		{name: "dashboard api", data: `{"dashboard": {"title": "api", "panels": [{"type": "graph", "title": "up", "targets": [{"expr": "up"}]}]}, "meta": {}}`, groups: "General"},
+		{name: "legacy rows", data: `{"title": "rows", "rows": [{"title": "first", "panels": [{"type": "graph", "targets": [{"expr": "up"}]}]}, {"panels": [{"type": "graph", "targets": [{"expr": "up"}]}]}]}`, groups: "first General"},
+		{name: "no queries", data: `{"title": "empty", "panels": [{"type": "text", "title": "notes"}]}`, wantErr: "no panels with queries"},
+		{name: "invalid json", data: `{"title":`, wantErr: "invalid grafana dashboard"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imported, err := Import([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Import() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var groups []string
+			for _, group := range imported.PanelGroups {
+				groups = append(groups, group.PanelGroupName)
+			}
+			if got := strings.Join(groups, " "); got != tt.groups {
+				t.Errorf("panel groups = %s, want %s", got, tt.groups)
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	name := func(value string) *string { return &value }
+	query := func(expr, legend, minstep, resolution string) *model.PromQueryResponse {
+		return &model.PromQueryResponse{PromQueryName: &expr, Legend: &legend, Minstep: &minstep, Resolution: &resolution}
+	}
+	dashboard := &model.ListDashboardResponse{
+		DbName:                    "Pod metrics",
+		DbInformation:             name("CPU and memory of the application"),
+		ChaosEventQueryTemplate:   "litmuschaos_awaited_experiments",
+		ChaosVerdictQueryTemplate: "litmuschaos_experiment_verdict",
+		StartTime:                 "1000",
+		EndTime:                   "4600",
+		RefreshRate:               "30",
+		PanelGroups: []*model.PanelGroupResponse{
+			{PanelGroupName: "Requests", Panels: []*model.PanelResponse{
+				{PanelName: name("Rate"), Unit: name("reqps"), PromQueries: []*model.PromQueryResponse{
+					query("rate(http_requests_total[5m])", "{{pod}}", "30", "1/2"),
+					query("rate(http_errors_total[5m])", "", "", "1/1"),
+				}},
+				{PanelName: name("Latency"), PromQueries: []*model.PromQueryResponse{query("histogram_quantile(0.99, latency)", "", "5", "1/1")}},
+				{PanelName: name("Saturation"), PromQueries: []*model.PromQueryResponse{query("saturation", "", "5", "1/1")}},
+			}},
+			{PanelGroupName: "Resources", Panels: []*model.PanelResponse{
+				{PanelName: name("CPU"), PromQueries: []*model.PromQueryResponse{query("container_cpu_usage_seconds_total", "", "5", "1/1")}},
+			}},
+		},
+	}
+
+	data, err := Export(dashboard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var exported Dashboard
+	if err := json.Unmarshal(data, &exported); err != nil {
+		t.Fatal(err)
+	}
+
+	if exported.Title != "Pod metrics" || exported.Time.From != "now-3600s" || exported.Refresh != "30s" {
+		t.Errorf("unexpected title %q, time %v, refresh %v", exported.Title, exported.Time, exported.Refresh)
+	}
+
+	var layout []string
+	for _, panel := range exported.Panels {
+		layout = append(layout, panel.Type+":"+panel.Title+"@"+strconv.Itoa(panel.GridPos.X)+","+strconv.Itoa(panel.GridPos.Y))
+	}
+	want := "row:Requests@0,0 graph:Rate@0,1 graph:Latency@12,1 graph:Saturation@0,9 row:Resources@0,17 graph:CPU@0,18"
+	if got := strings.Join(layout, " "); got != want {
+		t.Errorf("layout = %s, want %s", got, want)
+	}
+
+	targets := exported.Panels[1].Targets
+	if len(targets) != 2 || targets[0].RefID != "A" || targets[1].RefID != "B" {
+		t.Fatalf("unexpected targets %+v", targets)
+	}
+	if targets[0].Interval != "30s" || targets[0].IntervalFactor != 2 || targets[0].LegendFormat != "{{pod}}" || targets[1].Interval != "" {
+		t.Errorf("unexpected targets %+v", targets)
+	}
+
+	// the chaos queries and panels survive a round trip
+	imported, err := Import(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported.ChaosEventQueryTemplate != dashboard.ChaosEventQueryTemplate || imported.ChaosVerdictQueryTemplate != dashboard.ChaosVerdictQueryTemplate {
+		t.Errorf("unexpected annotation queries %q, %q", imported.ChaosEventQueryTemplate, imported.ChaosVerdictQueryTemplate)
+	}
+	if imported.RefreshRate != "30" || len(imported.PanelGroups) != 2 || len(imported.PanelGroups[0].Panels) != 3 {
+		t.Fatalf("unexpected round trip %+v", imported)
+	}
+	query0 := imported.PanelGroups[0].Panels[0].PromQueries[0]
+	if *query0.PromQueryName != "rate(http_requests_total[5m])" || *query0.Minstep != "30" || *query0.Resolution != "1/2" || *imported.PanelGroups[0].Panels[0].Unit != "reqps" {
+		t.Errorf("unexpected round trip of the query %s, minstep %s, resolution %s", *query0.PromQueryName, *query0.Minstep, *query0.Resolution)
+	}
+}
+
+func TestParseRelativeTime(t *testing.T) {
+	tests := []struct {
+		from string
+		want int64
+	}{
+		{from: "now-15m", want: 900},
+		{from: "now-6h", want: 21600},
+		{from: "now-7d", want: 604800},
+		{from: "now-1y", want: 31536000},
+		{from: "now-0m", want: defaultRelativeTime},
+		{from: "now/d", want: defaultRelativeTime},
+		{from: "2021-01-01T00:00:00.000Z", want: defaultRelativeTime},
+		{from: "", want: defaultRelativeTime},
+	}
+	for _, tt := range tests {
+		if got := parseRelativeTime(tt.from); got != tt.want {
+			t.Errorf("parseRelativeTime(%q) = %d, want %d", tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestParseRefresh(t *testing.T) {
+	tests := []struct {
+		refresh interface{}
+		want    string
+	}{
+		{refresh: "5s", want: "5"},
+		{refresh: "1m", want: "60"},
+		{refresh: "1h", want: "3600"},
+		{refresh: "500ms", want: defaultRefreshRate},
+		{refresh: "", want: defaultRefreshRate},
+		{refresh: false, want: defaultRefreshRate},
+		{refresh: nil, want: defaultRefreshRate},
+	}
+	for _, tt := range tests {
+		if got := parseRefresh(tt.refresh); got != tt.want {
+			t.Errorf("parseRefresh(%v) = %s, want %s", tt.refresh, got, tt.want)
+		}
+	}
+}
+
+func TestExportRange(t *testing.T) {
+	tests := []struct {
+		start, end string
+		want       string
+	}{
+		{start: "1000", end: "4600", want: "3600"},
+		{start: "4600", end: "1000", want: "1800"},
+		{start: "", end: "4600", want: "1800"},
+		{start: "1000", end: "now", want: "1800"},
+	}
+	for _, tt := range tests {
+		if got := exportRange(&model.ListDashboardResponse{StartTime: tt.start, EndTime: tt.end}); got != tt.want {
+			t.Errorf("exportRange(%s, %s) = %s, want %s", tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestRefID(t *testing.T) {
+	tests := []struct {
+		i    int
+		want string
+	}{
+		{i: 0, want: "A"},
+		{i: 25, want: "Z"},
+		{i: 26, want: "AA"},
+		{i: 27, want: "AB"},
+		{i: 701, want: "ZZ"},
+		{i: 702, want: "AAA"},
+	}
+	for _, tt := range tests {
+		if got := refID(tt.i); got != tt.want {
+			t.Errorf("refID(%d) = %s, want %s", tt.i, got, tt.want)
+		}
+	}
+}
+
+func TestImportVariables(t *testing.T) {
+	templating := Templating{List: []Variable{
+		{Name: "namespace", Label: "Namespace", Type: "query", Query: map[string]interface{}{"query": "label_values(kube_pod_info, namespace)"}, Multi: true, IncludeAll: true,
+			Current: VariableCurrent{Value: []interface{}{"default", "litmus"}}},
+		{Name: "series", Type: "query", Query: "query_result(up)", Options: []VariableValue{{Value: "$__all"}, {Value: "a"}, {Value: "b"}}},
+		{Name: "pod", Type: "custom", Query: "web, api ,", Current: VariableCurrent{Value: "web"}},
+		{Name: "window", Type: "interval", Query: "1m,5m", Auto: true},
+		{Name: "job", Type: "constant", Query: "kubelet"},
+		{Name: "source", Type: "datasource", Query: "prometheus"},
+		{Name: "empty", Type: "custom"},
+	}}
+
+	tests := []struct {
+		name    string
+		varType string
+		query   string
+		options string
+		current string
+	}{
+		{name: "namespace", varType: "query", query: "label_values(kube_pod_info, namespace)", current: "default,litmus"},
+		{name: "series", varType: "custom", options: "a,b"},
+		{name: "pod", varType: "custom", options: "web,api", current: "web"},
+		{name: "window", varType: "interval", options: "auto,1m,5m"},
+		{name: "job", varType: "custom", options: "kubelet"},
+	}
+
+	variables := importVariables(templating)
+	if len(variables) != len(tests) {
+		t.Fatalf("got %d variables, want %d", len(variables), len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variable := variables[i]
+			query := ""
+			if variable.Query != nil {
+				query = *variable.Query
+			}
+			if variable.Name != tt.name || variable.Type != tt.varType || query != tt.query {
+				t.Errorf("variable = %s %s %q, want %s %s %q", variable.Name, variable.Type, query, tt.name, tt.varType, tt.query)
+			}
+			if options := strings.Join(variable.Options, ","); options != tt.options {
+				t.Errorf("options = %s, want %s", options, tt.options)
+			}
+			if current := strings.Join(variable.Current, ","); current != tt.current {
+				t.Errorf("current = %s, want %s", current, tt.current)
+			}
+		})
+	}
+	if namespace := variables[0]; namespace.Label == nil || *namespace.Label != "Namespace" || namespace.Multi == nil || namespace.IncludeAll == nil {
+		t.Errorf("the label and selection of %s weren't kept", namespace.Name)
+	}
+}
+
+func TestExportVariables(t *testing.T) {
+	value := func(value string) *string { return &value }
+	multi := true
+	dashboard := &model.ListDashboardResponse{
+		ApplicationMetadataMap: []*model.ApplicationMetadataResponse{
+			{Namespace: "default", Applications: []*model.ResourceResponse{
+				{Kind: "deployment", Names: []*string{value("web")}},
+				{Kind: "statefulset", Names: []*string{value("db")}},
+			}},
+			{Namespace: "litmus", Applications: []*model.ResourceResponse{{Kind: "deployment", Names: []*string{value("api")}}}},
+		},
+		Variables: []*model.DashboardVariableResponse{
+			{Name: "instance", Type: "query", Query: value("label_values(up, instance)")},
+			{Name: "window", Type: "interval", Options: []string{"auto", "1m", "5m"}, Current: []string{"5m"}},
+			{Name: "namespace", Type: "namespace", Multi: &multi},
+			{Name: "deployment", Type: "application", Query: value("Deployment")},
+			nil,
+		},
+	}
+
+	tests := []struct {
+		name    string
+		varType string
+		query   string
+		options string
+		current interface{}
+	}{
+		{name: "instance", varType: "query", query: "label_values(up, instance)"},
+		{name: "window", varType: "interval", query: "1m,5m", options: "1m,5m", current: "5m"},
+		{name: "namespace", varType: "custom", query: "default,litmus", options: "default,litmus", current: []interface{}{"default"}},
+		{name: "deployment", varType: "custom", query: "web,api", options: "web,api", current: "web"},
+	}
+
+	data, err := json.Marshal(exportVariables(dashboard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// compare the variables as Grafana reads them
+	var variables []Variable
+	if err := json.Unmarshal(data, &variables); err != nil {
+		t.Fatal(err)
+	}
+	if len(variables) != len(tests) {
+		t.Fatalf("got %d variables, want %d", len(variables), len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variable := variables[i]
+			if variable.Name != tt.name || variable.Type != tt.varType || variable.Query != tt.query {
+				t.Errorf("variable = %s %s %v, want %s %s %q", variable.Name, variable.Type, variable.Query, tt.name, tt.varType, tt.query)
+			}
+			var options []string
+			for _, option := range variable.Options {
+				options = append(options, option.Value)
+			}
+			if got := strings.Join(options, ","); got != tt.options {
+				t.Errorf("options = %s, want %s", got, tt.options)
+			}
+			if !reflect.DeepEqual(variable.Current.Value, tt.current) {
+				t.Errorf("current = %v, want %v", variable.Current.Value, tt.current)
+			}
+		})
+	}
+	if !variables[1].Auto {
+		t.Error("the auto interval wasn't exported")
+	}
+}
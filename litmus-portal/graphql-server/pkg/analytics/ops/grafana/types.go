@@ -0,0 +1,145 @@
+package grafana
+
+import "encoding/json"
+
+// Dashboard is the part of the Grafana dashboard JSON model which maps to portal dashboards
+type Dashboard struct {
+	ID            *int        `json:"id"`
+	UID           string      `json:"uid,omitempty"`
+	Title         string      `json:"title"`
+	Description   string      `json:"description,omitempty"`
+	Tags          []string    `json:"tags"`
+	Editable      bool        `json:"editable"`
+	SchemaVersion int         `json:"schemaVersion"`
+	Time          TimeRange   `json:"time"`
+	Refresh       interface{} `json:"refresh"`
+	Annotations   Annotations `json:"annotations"`
+	Templating    Templating  `json:"templating"`
+	Panels        []Panel     `json:"panels"`
+	// Rows are used by dashboards of Grafana 4 and older instead of row panels
+	Rows []Row `json:"rows,omitempty"`
+}
+
+// DashboardEnvelope is the format of the dashboard API of Grafana
+type DashboardEnvelope struct {
+	Dashboard json.RawMessage `json:"dashboard"`
+}
+
+type TimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type Annotations struct {
+	List []Annotation `json:"list"`
+}
+
+type Annotation struct {
+	BuiltIn     int         `json:"builtIn"`
+	Datasource  interface{} `json:"datasource"`
+	Enable      bool        `json:"enable"`
+	Expr        string      `json:"expr,omitempty"`
+	IconColor   string      `json:"iconColor,omitempty"`
+	Name        string      `json:"name"`
+	Step        string      `json:"step,omitempty"`
+	TitleFormat string      `json:"titleFormat,omitempty"`
+}
+
+type Templating struct {
+	List []Variable `json:"list"`
+}
+
+type Variable struct {
+	Name       string          `json:"name"`
+	Label      string          `json:"label,omitempty"`
+	Type       string          `json:"type"`
+	Query      interface{}     `json:"query,omitempty"`
+	Datasource interface{}     `json:"datasource"`
+	Current    VariableCurrent `json:"current"`
+	Options    []VariableValue `json:"options,omitempty"`
+	Multi      bool            `json:"multi"`
+	IncludeAll bool            `json:"includeAll"`
+	Auto       bool            `json:"auto,omitempty"`
+	Refresh    int             `json:"refresh,omitempty"`
+	Hide       int             `json:"hide"`
+}
+
+type VariableCurrent struct {
+	Text  interface{} `json:"text"`
+	Value interface{} `json:"value"`
+}
+
+type VariableValue struct {
+	Text     string `json:"text"`
+	Value    string `json:"value"`
+	Selected bool   `json:"selected"`
+}
+
+type Row struct {
+	Title  string  `json:"title"`
+	Panels []Panel `json:"panels"`
+}
+
+type Panel struct {
+	ID          int          `json:"id"`
+	Type        string       `json:"type"`
+	Title       string       `json:"title"`
+	Description string       `json:"description,omitempty"`
+	Datasource  interface{}  `json:"datasource"`
+	GridPos     GridPos      `json:"gridPos"`
+	Collapsed   bool         `json:"collapsed,omitempty"`
+	Targets     []Target     `json:"targets,omitempty"`
+	FieldConfig *FieldConfig `json:"fieldConfig,omitempty"`
+	// graph panel settings
+	Yaxes  []Yaxis `json:"yaxes,omitempty"`
+	Xaxis  *Xaxis  `json:"xaxis,omitempty"`
+	Lines  *bool   `json:"lines,omitempty"`
+	Points *bool   `json:"points,omitempty"`
+	Fill   *int    `json:"fill,omitempty"`
+	// Panels of a collapsed row
+	Panels []Panel `json:"panels,omitempty"`
+}
+
+type GridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type Target struct {
+	RefID          string `json:"refId"`
+	Expr           string `json:"expr"`
+	LegendFormat   string `json:"legendFormat,omitempty"`
+	Interval       string `json:"interval,omitempty"`
+	IntervalFactor int    `json:"intervalFactor,omitempty"`
+	Instant        bool   `json:"instant,omitempty"`
+	Hide           bool   `json:"hide,omitempty"`
+}
+
+type FieldConfig struct {
+	Defaults FieldDefaults `json:"defaults"`
+}
+
+type FieldDefaults struct {
+	Unit   string       `json:"unit,omitempty"`
+	Custom *FieldCustom `json:"custom,omitempty"`
+}
+
+type FieldCustom struct {
+	AxisLabel   string `json:"axisLabel,omitempty"`
+	DrawStyle   string `json:"drawStyle,omitempty"`
+	FillOpacity int    `json:"fillOpacity,omitempty"`
+	ShowPoints  string `json:"showPoints,omitempty"`
+}
+
+type Yaxis struct {
+	Format string `json:"format"`
+	Label  string `json:"label,omitempty"`
+	Show   bool   `json:"show"`
+}
+
+type Xaxis struct {
+	Mode string `json:"mode"`
+	Show bool   `json:"show"`
+}
@@ -0,0 +1,217 @@
+package graphite
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics/ops/prometheus"
+)
+
+// Driver queries Graphite data sources through the render, tags and metrics APIs.
+// Graphite has no chaos events or verdicts, so annotation queries return no data
+type Driver struct{}
+
+type renderSeries struct {
+	Target     string            `json:"target"`
+	Tags       map[string]string `json:"tags"`
+	Datapoints [][2]*float64     `json:"datapoints"`
+}
+
+var legendTemplate = regexp.MustCompile(`\{\{(.*?)\}\}`)
+
+// Query runs the target of the query over its time range with the render API
+func (Driver) Query(prom analytics.PromQuery, queryType string) (interface{}, error) {
+	if queryType != "metrics" {
+		return &model.AnnotationsPromResponse{Queryid: prom.Queryid}, nil
+	}
+
+	params := url.Values{}
+	params.Set("target", prom.Query)
+	params.Set("from", prom.DSdetails.Start)
+	params.Set("until", prom.DSdetails.End)
+	params.Set("format", "json")
+	// consolidates the datapoints of long time ranges like the step of prometheus range queries
+	if points := maxDataPoints(prom); points > 0 {
+		params.Set("maxDataPoints", strconv.Itoa(points))
+	}
+
+	var series []renderSeries
+	if err := get(prom.DSdetails, "/render", params, &series); err != nil {
+		return nil, err
+	}
+
+	var (
+		legends []*string
+		tsvs    [][]*model.MetricsTimeStampValue
+	)
+	for _, s := range series {
+		legend := s.Target
+		if prom.Legend != nil && *prom.Legend != "" {
+			legend = legendTemplate.ReplaceAllStringFunc(*prom.Legend, func(element string) string {
+				tag := strings.Trim(element, "{}")
+				if value, ok := s.Tags[tag]; ok {
+					return value
+				}
+				return tag
+			})
+		}
+
+		var tsv []*model.MetricsTimeStampValue
+		for _, datapoint := range s.Datapoints {
+			if datapoint[0] == nil || datapoint[1] == nil {
+				continue
+			}
+			// graphite timestamps are in seconds, the dashboards expect milliseconds like prometheus
+			date := *datapoint[1] * 1000
+			value := *datapoint[0]
+			tsv = append(tsv, &model.MetricsTimeStampValue{Date: &date, Value: &value})
+		}
+		// graphite has no instant queries, the latest datapoint is their result
+		if prom.Instant && len(tsv) > 1 {
+			tsv = tsv[len(tsv)-1:]
+		}
+
+		legends = append(legends, &legend)
+		tsvs = append(tsvs, tsv)
+	}
+
+	if len(legends) == 0 {
+		return &model.MetricsPromResponse{}, nil
+	}
+
+	return &model.MetricsPromResponse{
+		Queryid: prom.Queryid,
+		Legends: legends,
+		Tsvs:    tsvs,
+	}, nil
+}
+
+// LabelNamesAndValues returns the tags of the series and their values
+func (Driver) LabelNamesAndValues(prom analytics.PromSeries) (*model.PromSeriesResponse, error) {
+	expr := "name=" + prom.Series
+
+	params := url.Values{}
+	params.Set("expr", expr)
+	var tags []string
+	if err := get(prom.DSdetails, "/tags/autoComplete/tags", params, &tags); err != nil {
+		return &model.PromSeriesResponse{}, err
+	}
+
+	response := &model.PromSeriesResponse{Series: prom.Series}
+	for _, tag := range tags {
+		if tag == "name" {
+			continue
+		}
+
+		params := url.Values{}
+		params.Set("expr", expr)
+		params.Set("tag", tag)
+		var values []string
+		if err := get(prom.DSdetails, "/tags/autoComplete/values", params, &values); err != nil {
+			log.Printf("Error while getting the values of tag %v: %v\n", tag, err)
+			continue
+		}
+
+		labelValue := &model.LabelValue{Label: tag}
+		for _, value := range values {
+			labelValue.Values = append(labelValue.Values, &model.Option{Name: value})
+		}
+		response.LabelValues = append(response.LabelValues, labelValue)
+	}
+
+	return response, nil
+}
+
+// SeriesList returns the names of every metric of the data source
+func (Driver) SeriesList(prom analytics.PromDSDetails) (*model.PromSeriesListResponse, error) {
+	var metrics []string
+	if err := get(&prom, "/metrics/index.json", url.Values{}, &metrics); err != nil {
+		return &model.PromSeriesListResponse{}, err
+	}
+
+	sort.Strings(metrics)
+	response := &model.PromSeriesListResponse{}
+	for _, metric := range metrics {
+		metric := metric
+		response.SeriesList = append(response.SeriesList, &metric)
+	}
+
+	return response, nil
+}
+
+// HealthCheck checks that graphite-web answers metric lookups
+func (Driver) HealthCheck(config analytics.DataSourceConfig) string {
+	client, err := prometheus.NewHTTPClient(config)
+	if err != nil {
+		log.Print(err)
+		return "Inactive"
+	}
+	client.Timeout = time.Second * 5
+
+	resp, err := client.Get(strings.TrimSuffix(config.URL, "/") + "/metrics/find?query=*")
+	if err != nil {
+		log.Print(err)
+		return "Inactive"
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Server reachable but returned [%s]", resp.Status)
+		return "Not Ready"
+	}
+
+	return "Active"
+}
+
+// get calls an endpoint of graphite-web and decodes its JSON response
+func get(details *analytics.PromDSDetails, path string, params url.Values, v interface{}) error {
+	config := analytics.DataSourceConfig{URL: details.URL}
+	if details.Config != nil {
+		config = *details.Config
+	}
+
+	client, err := prometheus.NewHTTPClient(config)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Get(strings.TrimSuffix(config.URL, "/") + path + "?" + params.Encode())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("graphite returned [" + resp.Status + "]: " + string(body))
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+func maxDataPoints(prom analytics.PromQuery) int {
+	start, err := strconv.ParseInt(prom.DSdetails.Start, 10, 64)
+	if err != nil {
+		return 0
+	}
+	end, err := strconv.ParseInt(prom.DSdetails.End, 10, 64)
+	if err != nil {
+		return 0
+	}
+	step := prometheus.Step(start, end, prom.Minstep, prom.Resolution)
+	return int(time.Duration(end-start)*time.Second/step) + 1
+}
@@ -0,0 +1,150 @@
+package ops
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	dbSchemaAnalytics "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/analytics"
+)
+
+const (
+	// HealthCheckIntervalEnv sets the interval of the data source health checks in seconds
+	HealthCheckIntervalEnv     = "DATASOURCE_HEALTH_CHECK_INTERVAL"
+	defaultHealthCheckInterval = time.Minute
+	minHealthCheckInterval     = 10 * time.Second
+
+	// HealthActive is the status of the data sources which answer their health checks
+	HealthActive = "Active"
+	// HealthUnknown is the status of the time the data source wasn't checked, e.g. while the server was down
+	HealthUnknown = "Unknown"
+
+	// MaxHealthEvents is the number of health changes kept per data source
+	MaxHealthEvents = 200
+	// missed checks after which the health of a data source is stale
+	staleHealthChecks = 3
+)
+
+// HealthCheckInterval returns the interval configured in DATASOURCE_HEALTH_CHECK_INTERVAL, 60 seconds by default
+func HealthCheckInterval() time.Duration {
+	value := os.Getenv(HealthCheckIntervalEnv)
+	if value == "" {
+		return defaultHealthCheckInterval
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err != nil || time.Duration(seconds)*time.Second < minHealthCheckInterval {
+		log.Printf("Invalid %v %v, using the default health check interval\n", HealthCheckIntervalEnv, value)
+		return defaultHealthCheckInterval
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// IsHealthStale reports whether the last health check of the data source is older than a few intervals
+func IsHealthStale(health *dbSchemaAnalytics.DataSourceHealth, now time.Time, interval time.Duration) bool {
+	if health == nil {
+		return true
+	}
+	checkedAt, err := strconv.ParseInt(health.CheckedAt, 10, 64)
+	if err != nil {
+		return true
+	}
+	return now.Sub(time.Unix(checkedAt, 0)) > staleHealthChecks*interval
+}
+
+// HealthEvents returns the events to add to the history for a new health check. The time since a stale
+// check is recorded as unknown, nothing is added when the status didn't change
+func HealthEvents(health *dbSchemaAnalytics.DataSourceHealth, status string, now time.Time, interval time.Duration) []dbSchemaAnalytics.DataSourceHealthEvent {
+	var events []dbSchemaAnalytics.DataSourceHealthEvent
+	if health != nil && IsHealthStale(health, now, interval) {
+		if checkedAt, err := strconv.ParseInt(health.CheckedAt, 10, 64); err == nil {
+			events = append(events, dbSchemaAnalytics.DataSourceHealthEvent{
+				Status:    HealthUnknown,
+				StartedAt: strconv.FormatInt(time.Unix(checkedAt, 0).Add(interval).Unix(), 10),
+			})
+		}
+	}
+
+	if health == nil || health.Status != status || len(events) > 0 {
+		events = append(events, dbSchemaAnalytics.DataSourceHealthEvent{
+			Status:    status,
+			StartedAt: strconv.FormatInt(now.Unix(), 10),
+		})
+	}
+	return events
+}
+
+// HealthPeriod is a status of the data source over a time range in unix seconds
+type HealthPeriod struct {
+	Status string
+	Start  int64
+	End    int64
+}
+
+// HealthPeriods returns the statuses of the data source over the time range. The last status lasts until an
+// interval after the last check, the time after it is unknown
+func HealthPeriods(health *dbSchemaAnalytics.DataSourceHealth, start, end int64, interval time.Duration) []HealthPeriod {
+	var periods []HealthPeriod
+	if health == nil {
+		return periods
+	}
+
+	lastEnd := end
+	if checkedAt, err := strconv.ParseInt(health.CheckedAt, 10, 64); err == nil && checkedAt+int64(interval/time.Second) < end {
+		lastEnd = checkedAt + int64(interval/time.Second)
+	}
+
+	for i, event := range health.History {
+		eventStart, err := strconv.ParseInt(event.StartedAt, 10, 64)
+		if err != nil {
+			continue
+		}
+		eventEnd := lastEnd
+		if i+1 < len(health.History) {
+			if nextStart, err := strconv.ParseInt(health.History[i+1].StartedAt, 10, 64); err == nil {
+				eventEnd = nextStart
+			}
+		}
+
+		if eventStart < start {
+			eventStart = start
+		}
+		if eventEnd > end {
+			eventEnd = end
+		}
+		if eventEnd <= eventStart {
+			continue
+		}
+		periods = append(periods, HealthPeriod{Status: event.Status, Start: eventStart, End: eventEnd})
+	}
+
+	if lastEnd < start {
+		lastEnd = start
+	}
+	if lastEnd < end {
+		periods = append(periods, HealthPeriod{Status: HealthUnknown, Start: lastEnd, End: end})
+	}
+	return periods
+}
+
+// HealthUptime returns the percentage of the monitored time of the periods the data source was active,
+// nil when none of the time was monitored
+func HealthUptime(periods []HealthPeriod) *float64 {
+	var monitored, active int64
+	for _, period := range periods {
+		if period.Status == HealthUnknown {
+			continue
+		}
+		monitored += period.End - period.Start
+		if period.Status == HealthActive {
+			active += period.End - period.Start
+		}
+	}
+	if monitored == 0 {
+		return nil
+	}
+
+	uptime := float64(active) / float64(monitored) * 100
+	return &uptime
+}
@@ -0,0 +1,119 @@
+package ops
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	dbSchemaAnalytics "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/analytics"
+)
+
+const healthInactive = "Inactive"
+
+func TestHealthEvents(t *testing.T) {
+	now := time.Unix(10000, 0)
+	health := func(status, checkedAt string) *dbSchemaAnalytics.DataSourceHealth {
+		return &dbSchemaAnalytics.DataSourceHealth{Status: status, CheckedAt: checkedAt}
+	}
+
+	tests := []struct {
+		name   string
+		health *dbSchemaAnalytics.DataSourceHealth
+		status string
+		want   []dbSchemaAnalytics.DataSourceHealthEvent
+	}{
+		{name: "first check", health: nil, status: HealthActive, want: []dbSchemaAnalytics.DataSourceHealthEvent{{Status: HealthActive, StartedAt: "10000"}}},
+		{name: "unchanged", health: health(HealthActive, "9950"), status: HealthActive, want: nil},
+		{name: "changed", health: health(HealthActive, "9950"), status: healthInactive, want: []dbSchemaAnalytics.DataSourceHealthEvent{{Status: healthInactive, StartedAt: "10000"}}},
+		{name: "stale check", health: health(HealthActive, "9000"), status: HealthActive, want: []dbSchemaAnalytics.DataSourceHealthEvent{
+			{Status: HealthUnknown, StartedAt: "9060"},
+			{Status: HealthActive, StartedAt: "10000"},
+		}},
+		{name: "stale check with a change", health: health(HealthActive, "9000"), status: healthInactive, want: []dbSchemaAnalytics.DataSourceHealthEvent{
+			{Status: HealthUnknown, StartedAt: "9060"},
+			{Status: healthInactive, StartedAt: "10000"},
+		}},
+		{name: "invalid check time", health: health(HealthActive, ""), status: HealthActive, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HealthEvents(tt.health, tt.status, now, time.Minute); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HealthEvents() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthPeriods(t *testing.T) {
+	health := &dbSchemaAnalytics.DataSourceHealth{
+		Status:    HealthActive,
+		CheckedAt: "3500",
+		History: []dbSchemaAnalytics.DataSourceHealthEvent{
+			{Status: HealthActive, StartedAt: "1000"},
+			{Status: healthInactive, StartedAt: "2000"},
+			{Status: HealthActive, StartedAt: "3000"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		health     *dbSchemaAnalytics.DataSourceHealth
+		start, end int64
+		want       []HealthPeriod
+	}{
+		{name: "whole history", health: health, start: 0, end: 4000, want: []HealthPeriod{
+			{Status: HealthActive, Start: 1000, End: 2000},
+			{Status: healthInactive, Start: 2000, End: 3000},
+			{Status: HealthActive, Start: 3000, End: 3560},
+			{Status: HealthUnknown, Start: 3560, End: 4000},
+		}},
+		{name: "clipped range", health: health, start: 1500, end: 2500, want: []HealthPeriod{
+			{Status: HealthActive, Start: 1500, End: 2000},
+			{Status: healthInactive, Start: 2000, End: 2500},
+		}},
+		{name: "range ending before the next check", health: health, start: 3000, end: 3530, want: []HealthPeriod{
+			{Status: HealthActive, Start: 3000, End: 3530},
+		}},
+		{name: "range after the last check", health: health, start: 5000, end: 6000, want: []HealthPeriod{
+			{Status: HealthUnknown, Start: 5000, End: 6000},
+		}},
+		{name: "no health", health: nil, start: 0, end: 4000, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HealthPeriods(tt.health, tt.start, tt.end, time.Minute); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HealthPeriods() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthUptime(t *testing.T) {
+	tests := []struct {
+		name    string
+		periods []HealthPeriod
+		want    *float64
+	}{
+		{name: "no periods", periods: nil, want: nil},
+		{name: "only unknown", periods: []HealthPeriod{{Status: HealthUnknown, Start: 0, End: 100}}, want: nil},
+		{name: "always active", periods: []HealthPeriod{{Status: HealthActive, Start: 0, End: 100}}, want: float64Ptr(100)},
+		{name: "unknown time isn't counted", periods: []HealthPeriod{
+			{Status: HealthActive, Start: 1000, End: 2000},
+			{Status: healthInactive, Start: 2000, End: 3000},
+			{Status: HealthUnknown, Start: 3000, End: 4000},
+		}, want: float64Ptr(50)},
+		{name: "never active", periods: []HealthPeriod{{Status: healthInactive, Start: 0, End: 100}}, want: float64Ptr(0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := HealthUptime(tt.periods)
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Errorf("HealthUptime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func float64Ptr(value float64) *float64 {
+	return &value
+}
@@ -11,8 +11,6 @@ import (
 	"github.com/jinzhu/copier"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
 	dbOperationsAnalytics "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/analytics"
-	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/utils"
-	"github.com/patrickmn/go-cache"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
@@ -29,11 +27,11 @@ func CreateDateMap(updatedAt string, filter model.TimeFrequency, statsMap map[st
 	switch filter {
 	case model.TimeFrequencyMonthly:
 		key := int(lastUpdatedTime.Month())
-		month := statsMap[string(key)]
+		month := statsMap[string(rune(key))]
 
 		// Incrementing the value for each month
 		month.Value++
-		statsMap[string(key)] = month
+		statsMap[string(rune(key))] = month
 
 	case model.TimeFrequencyDaily:
 		key := fmt.Sprintf("%d-%d", lastUpdatedTime.Month(), lastUpdatedTime.Day())
@@ -57,8 +55,8 @@ func CreateDateMap(updatedAt string, filter model.TimeFrequency, statsMap map[st
 	return nil
 }
 
-// PatchChaosEventWithVerdict takes annotations with chaos events, chaos verdict prometheus response, prometheus queries and cache object to patch and update chaos events with chaos verdict
-func PatchChaosEventWithVerdict(annotations []*model.AnnotationsPromResponse, verdictResponse *model.AnnotationsPromResponse, promInput *model.PromInput, AnalyticsCache *cache.Cache) []*model.AnnotationsPromResponse {
+// PatchChaosEventWithVerdict takes annotations with chaos events and chaos verdict prometheus response to patch and update chaos events with chaos verdict
+func PatchChaosEventWithVerdict(annotations []*model.AnnotationsPromResponse, verdictResponse *model.AnnotationsPromResponse) []*model.AnnotationsPromResponse {
 	var existingAnnotations []*model.AnnotationsPromResponse
 	err := copier.Copy(&existingAnnotations, &annotations)
 	if err != nil {
@@ -164,18 +162,6 @@ func PatchChaosEventWithVerdict(annotations []*model.AnnotationsPromResponse, ve
 					}
 				}
 			}
-
-			eventCacheKey := annotation.Queryid + "-" + promInput.DsDetails.Start + "-" + promInput.DsDetails.End + "-" + promInput.DsDetails.URL
-			cacheError := utils.AddCache(AnalyticsCache, eventCacheKey, annotations[annotationIndex])
-			if cacheError != nil {
-				errorStr := fmt.Sprintf("%v", cacheError)
-				if strings.Contains(errorStr, "already exists") {
-					cacheError = utils.UpdateCache(AnalyticsCache, eventCacheKey, annotations[annotationIndex])
-					if cacheError != nil {
-						log.Printf("Error while caching: %v\n", cacheError)
-					}
-				}
-			}
 		}
 	}
 
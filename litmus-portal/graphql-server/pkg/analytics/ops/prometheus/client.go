@@ -0,0 +1,144 @@
+package prometheus
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/api"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics"
+)
+
+const (
+	AuthTypeNone   = "no auth"
+	AuthTypeBasic  = "basic auth"
+	AuthTypeBearer = "bearer token"
+
+	// DefaultTenantHeader is the tenant header of Cortex, Mimir and Loki
+	DefaultTenantHeader = "X-Scope-OrgID"
+
+	defaultQueryTimeout = 30 * time.Second
+)
+
+// NewRoundTripper returns a round tripper applying the auth, TLS settings and HTTP method of the data source
+func NewRoundTripper(config analytics.DataSourceConfig) (http.RoundTripper, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}
+
+	if config.CACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(config.CACert)) {
+			return nil, errors.New("failed to parse the CA certificate of the data source")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.ClientCert != "" || config.ClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(config.ClientCert), []byte(config.ClientKey))
+		if err != nil {
+			return nil, errors.New("failed to parse the client certificate of the data source: " + err.Error())
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := api.DefaultRoundTripper.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &dataSourceRoundTripper{config: config, next: transport}, nil
+}
+
+// NewHTTPClient returns a http client for the data source which times out after its query timeout
+func NewHTTPClient(config analytics.DataSourceConfig) (*http.Client, error) {
+	rt, err := NewRoundTripper(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{Transport: rt, Timeout: QueryTimeout(config)}, nil
+}
+
+// QueryTimeout returns the query timeout of the data source
+func QueryTimeout(config analytics.DataSourceConfig) time.Duration {
+	if config.QueryTimeout <= 0 {
+		return defaultQueryTimeout
+	}
+	return time.Duration(config.QueryTimeout) * time.Second
+}
+
+// QueryContext returns a context which is cancelled after the query timeout of the data source
+func QueryContext(config analytics.DataSourceConfig) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), QueryTimeout(config))
+}
+
+type dataSourceRoundTripper struct {
+	config analytics.DataSourceConfig
+	next   http.RoundTripper
+}
+
+func (rt *dataSourceRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// the request is cloned as a RoundTripper must not modify the request it was given
+	req = req.Clone(req.Context())
+
+	for name, value := range rt.config.CustomHeaders {
+		req.Header.Set(name, value)
+	}
+
+	if rt.config.TenantID != "" {
+		tenantHeader := rt.config.TenantHeader
+		if tenantHeader == "" {
+			tenantHeader = DefaultTenantHeader
+		}
+		req.Header.Set(tenantHeader, rt.config.TenantID)
+	}
+
+	switch rt.config.AuthType {
+	case AuthTypeBasic:
+		req.SetBasicAuth(rt.config.BasicAuthUsername, rt.config.BasicAuthPassword)
+	case AuthTypeBearer:
+		req.Header.Set("Authorization", "Bearer "+rt.config.BearerToken)
+	}
+
+	if strings.EqualFold(rt.config.HTTPMethod, http.MethodGet) && req.Method == http.MethodPost {
+		if err := toGetRequest(req); err != nil {
+			return nil, err
+		}
+	}
+
+	return rt.next.RoundTrip(req)
+}
+
+// toGetRequest moves the form encoded body of a POST request into its query string
+func toGetRequest(req *http.Request) error {
+	query := req.URL.Query()
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return err
+		}
+		_ = req.Body.Close()
+
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return err
+		}
+		for key, values := range form {
+			for _, value := range values {
+				query.Add(key, value)
+			}
+		}
+	}
+
+	req.Method = http.MethodGet
+	req.URL.RawQuery = query.Encode()
+	req.Body = nil
+	req.GetBody = nil
+	req.ContentLength = 0
+	req.Header.Del("Content-Type")
+	return nil
+}
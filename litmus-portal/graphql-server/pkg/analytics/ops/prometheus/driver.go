@@ -0,0 +1,36 @@
+package prometheus
+
+import (
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics"
+)
+
+// Driver queries Prometheus data sources
+type Driver struct{}
+
+func (Driver) Query(prom analytics.PromQuery, queryType string) (interface{}, error) {
+	return Query(prom, queryType)
+}
+
+func (Driver) LabelNamesAndValues(prom analytics.PromSeries) (*model.PromSeriesResponse, error) {
+	return LabelNamesAndValues(prom)
+}
+
+func (Driver) SeriesList(prom analytics.PromDSDetails) (*model.PromSeriesListResponse, error) {
+	return SeriesList(prom)
+}
+
+func (Driver) HealthCheck(config analytics.DataSourceConfig) string {
+	return TSDBHealthCheck(config, "Prometheus")
+}
+
+// CompatibleDriver queries data sources serving the Prometheus HTTP API like VictoriaMetrics, Thanos, Cortex and Mimir.
+// Multi-tenant data sources get the tenant of the data source in its tenant header
+type CompatibleDriver struct {
+	Driver
+}
+
+// HealthCheck runs a query as these data sources don't serve the health endpoints of Prometheus
+func (CompatibleDriver) HealthCheck(config analytics.DataSourceConfig) string {
+	return prometheusAPIHealthCheck(config)
+}
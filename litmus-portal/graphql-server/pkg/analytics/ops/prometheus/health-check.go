@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics"
@@ -15,19 +16,29 @@ const (
 	INACTIVE  analytics.STATE = "INACTIVE"
 )
 
-func TSDBHealthCheck(url, datasourceType string) string {
+func TSDBHealthCheck(config analytics.DataSourceConfig, datasourceType string) string {
 	dbHealth := "Inactive"
-	dbPingState, _ := pingCheck(url)
+
+	client, err := NewHTTPClient(config)
+	if err != nil {
+		log.Print(err)
+		return dbHealth
+	}
+	// health checks shouldn't wait for the query timeout of the data source
+	client.Timeout = time.Second * 5
+
+	url := config.URL
+	dbPingState, _ := pingCheck(client, url)
 
 	if dbPingState == "ACTIVE" {
 		dbHealth = "Active"
 
 		if datasourceType == "Prometheus" {
-			prometheusHealth, prometheusHealthMsg := prometheusHealthCheck(url)
+			prometheusHealth, prometheusHealthMsg := prometheusHealthCheck(client, url)
 			log.Printf(prometheusHealthMsg)
 
 			if prometheusHealth == "ACTIVE" {
-				prometheusReadiness, prometheusReadinessMsg := prometheusReadinessCheck(url)
+				prometheusReadiness, prometheusReadinessMsg := prometheusReadinessCheck(client, url)
 				log.Printf(prometheusReadinessMsg)
 
 				if prometheusReadiness != "ACTIVE" {
@@ -44,17 +55,13 @@ func TSDBHealthCheck(url, datasourceType string) string {
 	return dbHealth
 }
 
-func pingCheck(url string) (analytics.STATE, string) {
-
-	client := &http.Client{
-		Timeout: time.Second * 5,
-	}
-
+func pingCheck(client *http.Client, url string) (analytics.STATE, string) {
 	resp, err := client.Get(url)
 
 	if err != nil {
 		return INACTIVE, err.Error()
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode == 200 {
 		return ACTIVE, "Server Up [200 OK]"
@@ -63,17 +70,13 @@ func pingCheck(url string) (analytics.STATE, string) {
 	return NOT_READY, "Server reachable but returned [" + resp.Status + "]"
 }
 
-func prometheusHealthCheck(url string) (analytics.STATE, string) {
-
-	client := &http.Client{
-		Timeout: time.Second * 5,
-	}
-
+func prometheusHealthCheck(client *http.Client, url string) (analytics.STATE, string) {
 	resp, err := client.Get(url + "/-/healthy")
 
 	if err != nil {
 		return INACTIVE, err.Error()
 	}
+	defer resp.Body.Close()
 
 	buffer := new(bytes.Buffer)
 	_, _ = buffer.ReadFrom(resp.Body)
@@ -86,17 +89,13 @@ func prometheusHealthCheck(url string) (analytics.STATE, string) {
 	return NOT_READY, "Server reachable but returned [" + bodyString + "]"
 }
 
-func prometheusReadinessCheck(url string) (analytics.STATE, string) {
-
-	client := &http.Client{
-		Timeout: time.Second * 5,
-	}
-
+func prometheusReadinessCheck(client *http.Client, url string) (analytics.STATE, string) {
 	resp, err := client.Get(url + "/-/ready")
 
 	if err != nil {
 		return INACTIVE, err.Error()
 	}
+	defer resp.Body.Close()
 
 	buffer := new(bytes.Buffer)
 	_, _ = buffer.ReadFrom(resp.Body)
@@ -108,3 +107,27 @@ func prometheusReadinessCheck(url string) (analytics.STATE, string) {
 
 	return NOT_READY, "Server reachable but returned [" + bodyString + "]"
 }
+
+// prometheusAPIHealthCheck checks that the Prometheus HTTP API of the data source answers queries
+func prometheusAPIHealthCheck(config analytics.DataSourceConfig) string {
+	client, err := NewHTTPClient(config)
+	if err != nil {
+		log.Print(err)
+		return "Inactive"
+	}
+	client.Timeout = time.Second * 5
+
+	resp, err := client.Get(strings.TrimSuffix(config.URL, "/") + "/api/v1/query?query=1")
+	if err != nil {
+		log.Print(err)
+		return "Inactive"
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Server reachable but returned [%s]", resp.Status)
+		return "Not Ready"
+	}
+
+	return "Active"
+}
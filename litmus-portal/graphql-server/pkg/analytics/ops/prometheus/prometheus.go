@@ -1,7 +1,6 @@
 package prometheus
 
 import (
-	"context"
 	"fmt"
 	"log"
 	"regexp"
@@ -20,13 +19,16 @@ import (
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics"
 )
 
-// CreateClient creates a prometheus client from a URL
-func CreateClient(url string) (apiV1.API, error) {
-	var DefaultRT = api.DefaultRoundTripper
+// CreateClient creates a prometheus client from the connection settings of a data source
+func CreateClient(config analytics.DataSourceConfig) (apiV1.API, error) {
+	rt, err := NewRoundTripper(config)
+	if err != nil {
+		return nil, err
+	}
 
 	cfg := api.Config{
-		Address:      url,
-		RoundTripper: DefaultRT,
+		Address:      config.URL,
+		RoundTripper: rt,
 	}
 
 	client, err := api.NewClient(cfg)
@@ -37,9 +39,18 @@ func CreateClient(url string) (apiV1.API, error) {
 	return apiV1.NewAPI(client), nil
 }
 
+// dataSourceConfig returns the connection settings of the data source details, unknown data sources are queried without auth
+func dataSourceConfig(details *analytics.PromDSDetails) analytics.DataSourceConfig {
+	if details.Config == nil {
+		return analytics.DataSourceConfig{URL: details.URL}
+	}
+	return *details.Config
+}
+
 // Query is used to query prometheus using client
 func Query(prom analytics.PromQuery, queryType string) (interface{}, error) {
-	client, err := CreateClient(prom.DSdetails.URL)
+	config := dataSourceConfig(prom.DSdetails)
+	client, err := CreateClient(config)
 
 	if err != nil {
 		return nil, err
@@ -55,21 +66,28 @@ func Query(prom analytics.PromQuery, queryType string) (interface{}, error) {
 		return nil, err
 	}
 
-	timeRange := apiV1.Range{
-		Start: time.Unix(startTime, 0).UTC(),
-		End:   time.Unix(endTime, 0).UTC(),
-		Step:  time.Duration(int64(prom.Minstep)) * time.Second,
-	}
+	ctx, cancel := QueryContext(config)
+	defer cancel()
 
-	value, _, err := client.QueryRange(context.TODO(), prom.Query, timeRange)
+	var value md.Value
+	if prom.Instant {
+		value, _, err = client.Query(ctx, prom.Query, time.Unix(endTime, 0).UTC())
+	} else {
+		timeRange := apiV1.Range{
+			Start: time.Unix(startTime, 0).UTC(),
+			End:   time.Unix(endTime, 0).UTC(),
+			Step:  Step(startTime, endTime, prom.Minstep, prom.Resolution),
+		}
+		value, _, err = client.QueryRange(ctx, prom.Query, timeRange)
+	}
 
 	if err != nil {
 		return nil, err
 	}
 
-	data, ok := value.(md.Matrix)
-	if !ok {
-		log.Printf("Unsupported result format: %s", value.Type().String())
+	data, err := toMatrix(value)
+	if err != nil {
+		return nil, err
 	}
 
 	chaosEventLabels := map[string]string{
@@ -246,7 +264,8 @@ func Query(prom analytics.PromQuery, queryType string) (interface{}, error) {
 
 // LabelNamesAndValues is used to query prometheus using client for label names and values of a series
 func LabelNamesAndValues(prom analytics.PromSeries) (*model.PromSeriesResponse, error) {
-	client, err := CreateClient(prom.DSdetails.URL)
+	config := dataSourceConfig(prom.DSdetails)
+	client, err := CreateClient(config)
 	if err != nil {
 		return &model.PromSeriesResponse{}, err
 	}
@@ -265,7 +284,10 @@ func LabelNamesAndValues(prom analytics.PromSeries) (*model.PromSeriesResponse,
 	end := time.Unix(endTime, 0).UTC()
 	matcher := []string{prom.Series}
 
-	labelNames, _, err := client.LabelNames(context.TODO(), matcher, start, end)
+	ctx, cancel := QueryContext(config)
+	defer cancel()
+
+	labelNames, _, err := client.LabelNames(ctx, matcher, start, end)
 	if err != nil {
 		return &model.PromSeriesResponse{}, err
 	}
@@ -283,7 +305,7 @@ func LabelNamesAndValues(prom analytics.PromSeries) (*model.PromSeriesResponse,
 				go func(index int, label string) {
 					defer wg.Done()
 					var newValues []*model.Option
-					values, _, err := client.LabelValues(context.TODO(), label, matcher, start, end)
+					values, _, err := client.LabelValues(ctx, label, matcher, start, end)
 					if err != nil {
 						return
 					}
@@ -317,7 +339,8 @@ func LabelNamesAndValues(prom analytics.PromSeries) (*model.PromSeriesResponse,
 
 // SeriesList is used to query prometheus using client for names of time series
 func SeriesList(prom analytics.PromDSDetails) (*model.PromSeriesListResponse, error) {
-	client, err := CreateClient(prom.URL)
+	config := dataSourceConfig(&prom)
+	client, err := CreateClient(config)
 	if err != nil {
 		return &model.PromSeriesListResponse{}, err
 	}
@@ -341,7 +364,10 @@ func SeriesList(prom analytics.PromDSDetails) (*model.PromSeriesListResponse, er
 		newResponse model.PromSeriesListResponse
 	)
 
-	labelValues, _, err := client.LabelValues(context.TODO(), "__name__", matcher, start, end)
+	ctx, cancel := QueryContext(config)
+	defer cancel()
+
+	labelValues, _, err := client.LabelValues(ctx, "__name__", matcher, start, end)
 	if err != nil {
 		return &model.PromSeriesListResponse{}, err
 	}
@@ -360,3 +386,27 @@ func SeriesList(prom analytics.PromDSDetails) (*model.PromSeriesListResponse, er
 
 	return &resp, nil
 }
+
+// toMatrix converts the result of a query to series, instant vectors and scalars become series of a single sample
+func toMatrix(value md.Value) (md.Matrix, error) {
+	switch result := value.(type) {
+	case md.Matrix:
+		return result, nil
+	case md.Vector:
+		matrix := make(md.Matrix, 0, len(result))
+		for _, sample := range result {
+			matrix = append(matrix, &md.SampleStream{
+				Metric: sample.Metric,
+				Values: []md.SamplePair{{Timestamp: sample.Timestamp, Value: sample.Value}},
+			})
+		}
+		return matrix, nil
+	case *md.Scalar:
+		return md.Matrix{{
+			Metric: md.Metric{},
+			Values: []md.SamplePair{{Timestamp: result.Timestamp, Value: result.Value}},
+		}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported result format: %s", value.Type().String())
+	}
+}
@@ -0,0 +1,49 @@
+package prometheus
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// autoStepDataPoints is the number of points returned over the time range at a resolution of 1/1
+const autoStepDataPoints = 1000
+
+// Step returns the step of a range query. It is the larger of the min step and the step returning
+// autoStepDataPoints over the time range, a resolution of 1/N increases the latter N times
+func Step(start, end int64, minstep int, resolution *string) time.Duration {
+	step := int64(minstep)
+
+	autoStep := int64(math.Ceil(float64(end-start) * resolutionFactor(resolution) / autoStepDataPoints))
+	if autoStep > step {
+		step = autoStep
+	}
+	if step < 1 {
+		step = 1
+	}
+
+	return time.Duration(step) * time.Second
+}
+
+// resolutionFactor returns N for resolutions like 1/N, invalid resolutions are treated as 1/1
+func resolutionFactor(resolution *string) float64 {
+	if resolution == nil {
+		return 1
+	}
+
+	parts := strings.Split(strings.TrimSpace(*resolution), "/")
+	if len(parts) != 2 {
+		return 1
+	}
+	numerator, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil || numerator <= 0 {
+		return 1
+	}
+	denominator, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || denominator <= 0 {
+		return 1
+	}
+
+	return denominator / numerator
+}
@@ -0,0 +1,38 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStep(t *testing.T) {
+	resolution := func(value string) *string { return &value }
+
+	tests := []struct {
+		name       string
+		start, end int64
+		minstep    int
+		resolution *string
+		want       time.Duration
+	}{
+		{name: "auto step", start: 0, end: 3600, want: 4 * time.Second},
+		{name: "auto step over a week", start: 0, end: 7 * 24 * 3600, want: 605 * time.Second},
+		{name: "min step", start: 0, end: 3600, minstep: 15, want: 15 * time.Second},
+		{name: "auto step larger than min step", start: 0, end: 24 * 3600, minstep: 60, want: 87 * time.Second},
+		{name: "resolution 1/2", start: 0, end: 3600, resolution: resolution("1/2"), want: 8 * time.Second},
+		{name: "resolution 1/10", start: 0, end: 3600, resolution: resolution(" 1/10 "), want: 36 * time.Second},
+		{name: "resolution 1/1", start: 0, end: 3600, resolution: resolution("1/1"), want: 4 * time.Second},
+		{name: "invalid resolution", start: 0, end: 3600, resolution: resolution("high"), want: 4 * time.Second},
+		{name: "zero numerator", start: 0, end: 3600, resolution: resolution("0/2"), want: 4 * time.Second},
+		{name: "zero denominator", start: 0, end: 3600, resolution: resolution("1/0"), want: 4 * time.Second},
+		{name: "empty range", start: 3600, end: 3600, want: time.Second},
+		{name: "inverted range", start: 3600, end: 0, want: time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Step(tt.start, tt.end, tt.minstep, tt.resolution); got != tt.want {
+				t.Errorf("Step() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
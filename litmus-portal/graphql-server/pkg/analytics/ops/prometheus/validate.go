@@ -0,0 +1,91 @@
+package prometheus
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	apiV1 "github.com/prometheus/client_golang/api/prometheus/v1"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics"
+)
+
+var (
+	// parse errors of Prometheus 2.20 and later, like 1:12: parse error: unexpected <EOF>
+	positionParseError = regexp.MustCompile(`(\d+):(\d+): parse error: ([^\n;]*)`)
+	// parse errors of older versions, like parse error at char 12: unexpected <EOF>
+	charParseError = regexp.MustCompile(`parse error at (?:line (\d+), )?char (\d+): ([^\n;]*)`)
+
+	// queries are validated at the unix epoch, no data source has samples there so the parsed query selects nothing
+	validationTime = time.Unix(0, 0)
+)
+
+// ValidateQuery parses the query with the PromQL parser of the data source, so that the extensions of PromQL
+// compatible data sources are accepted. The query is evaluated at a time without data to keep the validation cheap.
+// It returns the parse errors of invalid queries, the error is only returned when the data source couldn't
+// validate the query, e.g. when it is unreachable, and the callers skip the validation then
+func ValidateQuery(config analytics.DataSourceConfig, query string) ([]*model.PromQueryError, error) {
+	if strings.TrimSpace(query) == "" {
+		return []*model.PromQueryError{{Message: "query is empty"}}, nil
+	}
+
+	client, err := CreateClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := QueryContext(config)
+	defer cancel()
+
+	_, _, err = client.Query(ctx, query, validationTime)
+	if err == nil {
+		return nil, nil
+	}
+
+	apiErr, ok := err.(*apiV1.Error)
+	if !ok || apiErr.Type != apiV1.ErrBadData {
+		return nil, err
+	}
+
+	return parseQueryErrors(apiErr.Msg), nil
+}
+
+// parseQueryErrors extracts the positions of the parse errors reported by the data source
+func parseQueryErrors(msg string) []*model.PromQueryError {
+	var queryErrors []*model.PromQueryError
+
+	for _, match := range positionParseError.FindAllStringSubmatch(msg, -1) {
+		line, _ := strconv.Atoi(match[1])
+		column, _ := strconv.Atoi(match[2])
+		queryErrors = append(queryErrors, &model.PromQueryError{
+			Message: strings.TrimSpace(match[3]),
+			Line:    &line,
+			Column:  &column,
+		})
+	}
+
+	if len(queryErrors) == 0 {
+		for _, match := range charParseError.FindAllStringSubmatch(msg, -1) {
+			line := 1
+			if match[1] != "" {
+				line, _ = strconv.Atoi(match[1])
+			}
+			column, _ := strconv.Atoi(match[2])
+			queryErrors = append(queryErrors, &model.PromQueryError{
+				Message: strings.TrimSpace(match[3]),
+				Line:    &line,
+				Column:  &column,
+			})
+		}
+	}
+
+	if len(queryErrors) == 0 {
+		queryErrors = append(queryErrors, &model.PromQueryError{
+			Message: strings.TrimPrefix(msg, `invalid parameter "query": `),
+		})
+	}
+
+	return queryErrors
+}
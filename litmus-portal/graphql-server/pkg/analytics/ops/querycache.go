@@ -0,0 +1,163 @@
+package ops
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics/ops/prometheus"
+)
+
+const (
+	// QueryCacheTTLEnv sets the TTL of the query cache in seconds
+	QueryCacheTTLEnv       = "ANALYTICS_QUERY_CACHE_TTL"
+	defaultQueryCacheTTL   = 15 * time.Second
+	queryCacheCleanupRatio = 2
+)
+
+// QueryCache shares the results of data source queries between dashboard views. The time ranges of the
+// queries are aligned so that views refreshing at different moments hit the same entries, and concurrent
+// views asking for an entry which isn't cached yet wait for a single data source query
+type QueryCache struct {
+	cache *cache.Cache
+	group singleflight.Group
+	ttl   time.Duration
+}
+
+// NewQueryCache returns a query cache keeping results for the ttl
+func NewQueryCache(ttl time.Duration) *QueryCache {
+	if ttl < time.Second {
+		ttl = defaultQueryCacheTTL
+	}
+	return &QueryCache{
+		cache: cache.New(ttl, queryCacheCleanupRatio*ttl),
+		ttl:   ttl,
+	}
+}
+
+// QueryCacheTTL returns the TTL configured in ANALYTICS_QUERY_CACHE_TTL, 15 seconds by default
+func QueryCacheTTL() time.Duration {
+	value := os.Getenv(QueryCacheTTLEnv)
+	if value == "" {
+		return defaultQueryCacheTTL
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		log.Printf("Invalid %v %v, using the default query cache TTL\n", QueryCacheTTLEnv, value)
+		return defaultQueryCacheTTL
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Align moves the time range of the query to multiples of its step or of the TTL, whichever is larger,
+// and returns the cache key of the query. Queries with invalid time ranges aren't aligned
+func (c *QueryCache) Align(prom *analytics.PromQuery, queryType string) string {
+	start, startErr := strconv.ParseInt(prom.DSdetails.Start, 10, 64)
+	end, endErr := strconv.ParseInt(prom.DSdetails.End, 10, 64)
+
+	step := time.Duration(0)
+	if startErr == nil && endErr == nil {
+		alignment := int64(c.ttl / time.Second)
+		if stepSeconds := int64(prometheus.Step(start, end, prom.Minstep, prom.Resolution) / time.Second); stepSeconds > alignment {
+			alignment = stepSeconds
+		}
+		start = start - start%alignment
+		if end%alignment != 0 {
+			end = end - end%alignment + alignment
+		}
+
+		details := *prom.DSdetails
+		details.Start = strconv.FormatInt(start, 10)
+		details.End = strconv.FormatInt(end, 10)
+		prom.DSdetails = &details
+		step = prometheus.Step(start, end, prom.Minstep, prom.Resolution)
+	}
+
+	return strings.Join([]string{
+		dataSourceKey(prom.DSdetails),
+		queryType,
+		strconv.FormatBool(prom.Instant),
+		prom.DSdetails.Start,
+		prom.DSdetails.End,
+		step.String(),
+		legendKey(prom.Legend),
+		prom.Query,
+	}, "|")
+}
+
+// Get returns the cached result of the key, otherwise the query runs once for all the concurrent callers of the key.
+// Failed queries aren't cached
+func (c *QueryCache) Get(key string, query func() (interface{}, error)) (interface{}, error) {
+	if result, ok := c.cache.Get(key); ok {
+		return result, nil
+	}
+
+	result, err, _ := c.group.Do(key, func() (interface{}, error) {
+		if result, ok := c.cache.Get(key); ok {
+			return result, nil
+		}
+
+		result, err := query()
+		if err != nil {
+			return nil, err
+		}
+		c.cache.Set(key, result, c.ttl)
+		return result, nil
+	})
+
+	return result, err
+}
+
+// legendKey separates the queries which only differ in their legend, the legends are applied to the cached series
+func legendKey(legend *string) string {
+	if legend == nil {
+		return ""
+	}
+	return *legend
+}
+
+// dataSourceKey identifies the data source and the credentials a query runs with
+func dataSourceKey(details *analytics.PromDSDetails) string {
+	if details.Config == nil {
+		return "url:" + details.URL
+	}
+	if details.Config.DsID != "" {
+		return "ds:" + details.Config.DsID
+	}
+	return "url:" + details.Config.URL + "|" + details.Config.TenantID
+}
+
+// CloneAnnotations copies a cached annotation response, the chaos events are patched with their verdicts in place
+func CloneAnnotations(response *model.AnnotationsPromResponse, queryID string) *model.AnnotationsPromResponse {
+	clone := &model.AnnotationsPromResponse{
+		Queryid: queryID,
+		Legends: append([]*string{}, response.Legends...),
+	}
+
+	for _, tsv := range response.Tsvs {
+		clone.Tsvs = append(clone.Tsvs, append([]*model.AnnotationsTimeStampValue{}, tsv...))
+	}
+
+	for _, subDataList := range response.SubDataArray {
+		var newSubDataList []*model.SubData
+		for _, subData := range subDataList {
+			if subData == nil {
+				newSubDataList = append(newSubDataList, nil)
+				continue
+			}
+			newSubData := *subData
+			newSubDataList = append(newSubDataList, &newSubData)
+		}
+		clone.SubDataArray = append(clone.SubDataArray, newSubDataList)
+	}
+
+	return clone
+}
@@ -0,0 +1,129 @@
+package ops
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics"
+)
+
+func TestQueryCacheAlign(t *testing.T) {
+	cache := NewQueryCache(15 * time.Second)
+	legend := func(value string) *string { return &value }
+	query := func(start, end string) *analytics.PromQuery {
+		return &analytics.PromQuery{
+			Query:     "up",
+			DSdetails: &analytics.PromDSDetails{Start: start, End: end, Config: &analytics.DataSourceConfig{DsID: "ds-1"}},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		query     *analytics.PromQuery
+		queryType string
+		start     string
+		end       string
+		sameKey   bool
+	}{
+		{name: "aligned to the ttl", query: query("1001", "4601"), start: "990", end: "4605", sameKey: true},
+		{name: "later refresh within the ttl", query: query("1003", "4603"), start: "990", end: "4605", sameKey: true},
+		{name: "already aligned", query: query("990", "4605"), start: "990", end: "4605", sameKey: true},
+		{name: "next ttl window", query: query("1006", "4606"), start: "1005", end: "4620"},
+		{name: "aligned to a step larger than the ttl", query: query("1000", "87400"), start: "957", end: "87435"},
+		{name: "different query type", query: query("1001", "4601"), queryType: "annotation", start: "990", end: "4605"},
+		{name: "different legend", query: func() *analytics.PromQuery {
+			q := query("1001", "4601")
+			q.Legend = legend("{{pod}}")
+			return q
+		}(), start: "990", end: "4605"},
+		{name: "instant query", query: func() *analytics.PromQuery {
+			q := query("1001", "4601")
+			q.Instant = true
+			return q
+		}(), start: "990", end: "4605"},
+		{name: "different data source", query: func() *analytics.PromQuery {
+			q := query("1001", "4601")
+			q.DSdetails.Config = &analytics.DataSourceConfig{DsID: "ds-2"}
+			return q
+		}(), start: "990", end: "4605"},
+		{name: "invalid time range", query: query("now-1h", "now"), start: "now-1h", end: "now"},
+	}
+
+	base := cache.Align(query("1001", "4601"), "metrics")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryType := tt.queryType
+			if queryType == "" {
+				queryType = "metrics"
+			}
+			key := cache.Align(tt.query, queryType)
+			if tt.query.DSdetails.Start != tt.start || tt.query.DSdetails.End != tt.end {
+				t.Errorf("aligned range = %s-%s, want %s-%s", tt.query.DSdetails.Start, tt.query.DSdetails.End, tt.start, tt.end)
+			}
+			if (key == base) != tt.sameKey {
+				t.Errorf("key %q, base key %q, want same = %v", key, base, tt.sameKey)
+			}
+		})
+	}
+}
+
+func TestQueryCacheAlignKeepsDetails(t *testing.T) {
+	details := &analytics.PromDSDetails{Start: "1001", End: "4601", URL: "http://prometheus:9090"}
+	query := &analytics.PromQuery{Query: "up", DSdetails: details}
+	key := NewQueryCache(15*time.Second).Align(query, "metrics")
+
+	// the details may be shared with other queries of the panel, so they are copied before being aligned
+	if details.Start != "1001" || details.End != "4601" {
+		t.Errorf("the original details were modified : %s-%s", details.Start, details.End)
+	}
+	if !strings.HasPrefix(key, "url:http://prometheus:9090|") {
+		t.Errorf("unexpected key %q", key)
+	}
+}
+
+func TestQueryCacheGet(t *testing.T) {
+	cache := NewQueryCache(time.Minute)
+
+	var calls int32
+	query := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(10 * time.Millisecond)
+		return "result", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if result, err := cache.Get("key", query); err != nil || result != "result" {
+				t.Errorf("Get() = %v, %v", result, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if result, _ := cache.Get("key", query); result != "result" {
+		t.Errorf("Get() = %v", result)
+	}
+	if calls != 1 {
+		t.Errorf("query ran %d times, want once", calls)
+	}
+
+	failures := 0
+	failing := func() (interface{}, error) {
+		failures++
+		return nil, errors.New("data source unreachable")
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Get("failing", failing); err == nil {
+			t.Error("expected an error")
+		}
+	}
+	if failures != 2 {
+		t.Errorf("failed query ran %d times, want it to run again", failures)
+	}
+}
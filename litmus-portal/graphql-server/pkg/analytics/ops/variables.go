@@ -0,0 +1,327 @@
+package ops
+
+import (
+	"errors"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics/ops/prometheus"
+	dbSchemaAnalytics "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/analytics"
+)
+
+const (
+	VariableTypeQuery       = "query"
+	VariableTypeCustom      = "custom"
+	VariableTypeInterval    = "interval"
+	VariableTypeNamespace   = "namespace"
+	VariableTypeApplication = "application"
+
+	// AllValue selects every option of a variable
+	AllValue = "$__all"
+	// AutoInterval is the interval option which follows the time range of the dashboard
+	AutoInterval = "auto"
+
+	// scrape interval assumed for $__rate_interval
+	defaultScrapeInterval = 15 * time.Second
+	// time range of the dashboards when validating their queries
+	defaultValidationRange = 1800
+)
+
+var (
+	variableReference = regexp.MustCompile(`\$\{(\w+)(?::\w+)?\}|\[\[(\w+)(?::\w+)?\]\]|\$(\w+)`)
+	variableName      = regexp.MustCompile(`^[a-zA-Z_]\w*$`)
+	labelValuesQuery  = regexp.MustCompile(`^\s*label_values\(\s*(?:(.*?)\s*,\s*)?(\w+)\s*\)\s*$`)
+)
+
+// ValidateVariables checks the names, types and queries of the dashboard variables
+func ValidateVariables(variables []*model.DashboardVariable) error {
+	names := make(map[string]bool)
+	for _, variable := range variables {
+		if variable == nil {
+			continue
+		}
+		if !variableName.MatchString(variable.Name) || strings.HasPrefix(variable.Name, "__") {
+			return errors.New("invalid variable name " + variable.Name)
+		}
+		if names[variable.Name] {
+			return errors.New("duplicate variable " + variable.Name)
+		}
+		names[variable.Name] = true
+
+		switch variable.Type {
+		case VariableTypeQuery:
+			if variable.Query == nil || !IsLabelValuesQuery(*variable.Query) {
+				return errors.New("query of variable " + variable.Name + " must be label_values(series, label) or label_values(label)")
+			}
+		case VariableTypeCustom, VariableTypeInterval:
+			if len(variable.Options) == 0 {
+				return errors.New("variable " + variable.Name + " has no options")
+			}
+		case VariableTypeNamespace, VariableTypeApplication:
+		default:
+			return errors.New("unsupported type " + variable.Type + " of variable " + variable.Name)
+		}
+	}
+
+	return nil
+}
+
+// IsLabelValuesQuery reports whether the query is supported by query variables
+func IsLabelValuesQuery(query string) bool {
+	return labelValuesQuery.MatchString(query)
+}
+
+// GlobalVariables returns the values of the global variables for a time range in unix seconds
+func GlobalVariables(start, end int64) map[string]string {
+	interval := prometheus.Step(start, end, 0, nil)
+	rateInterval := interval + defaultScrapeInterval
+	if rateInterval < 4*defaultScrapeInterval {
+		rateInterval = 4 * defaultScrapeInterval
+	}
+	rangeSeconds := end - start
+	if rangeSeconds < 0 {
+		rangeSeconds = 0
+	}
+
+	return map[string]string{
+		"__interval":      formatSeconds(interval),
+		"__interval_ms":   strconv.FormatInt(interval.Milliseconds(), 10),
+		"__rate_interval": formatSeconds(rateInterval),
+		"__range":         strconv.FormatInt(rangeSeconds, 10) + "s",
+		"__range_s":       strconv.FormatInt(rangeSeconds, 10),
+		"__range_ms":      strconv.FormatInt(rangeSeconds*1000, 10),
+	}
+}
+
+// VariableOptions returns the values which can be selected for a variable. Namespace and application
+// variables list the application metadata of the dashboard, query variables ask the data source
+func VariableOptions(variable dbSchemaAnalytics.DashboardVariable, applicationMetadataMap []dbSchemaAnalytics.ApplicationMetadata, driver Driver, details *analytics.PromDSDetails) ([]string, error) {
+	var options []string
+
+	switch variable.Type {
+	case VariableTypeCustom, VariableTypeInterval:
+		options = append(options, variable.Options...)
+
+	case VariableTypeNamespace:
+		for _, applicationMetadata := range applicationMetadataMap {
+			options = append(options, applicationMetadata.Namespace)
+		}
+
+	case VariableTypeApplication:
+		kind := ""
+		if variable.Query != nil {
+			kind = strings.TrimSpace(*variable.Query)
+		}
+		for _, applicationMetadata := range applicationMetadataMap {
+			for _, application := range applicationMetadata.Applications {
+				if application == nil || (kind != "" && !strings.EqualFold(application.Kind, kind)) {
+					continue
+				}
+				for _, name := range application.Names {
+					if name != nil {
+						options = append(options, *name)
+					}
+				}
+			}
+		}
+
+	case VariableTypeQuery:
+		if variable.Query == nil {
+			return nil, errors.New("variable " + variable.Name + " has no query")
+		}
+		match := labelValuesQuery.FindStringSubmatch(*variable.Query)
+		if match == nil {
+			return nil, errors.New("unsupported query of variable " + variable.Name + ": " + *variable.Query)
+		}
+		series, label := match[1], match[2]
+		if series == "" {
+			series = "{" + label + `!=""}`
+		}
+
+		response, err := driver.LabelNamesAndValues(analytics.PromSeries{Series: series, DSdetails: details})
+		if err != nil {
+			return nil, errors.New("failed to get the values of variable " + variable.Name + ": " + err.Error())
+		}
+		for _, labelValue := range response.LabelValues {
+			if labelValue == nil || labelValue.Label != label {
+				continue
+			}
+			for _, value := range labelValue.Values {
+				if value != nil {
+					options = append(options, value.Name)
+				}
+			}
+		}
+		sort.Strings(options)
+
+	default:
+		return nil, errors.New("unsupported type " + variable.Type + " of variable " + variable.Name)
+	}
+
+	return unique(options), nil
+}
+
+// VariableValues resolves the values of the dashboard variables over a time range, the selected values
+// of a dashboard view override the current values of the dashboard. Variables with several values
+// become regex alternations like Grafana formats them for Prometheus
+func VariableValues(dashboard *dbSchemaAnalytics.DashBoard, selected []*model.VariableValue, driver Driver, details *analytics.PromDSDetails, start, end int64) map[string]string {
+	values := make(map[string]string)
+	globals := GlobalVariables(start, end)
+
+	selectedValues := make(map[string][]string)
+	for _, value := range selected {
+		if value != nil {
+			selectedValues[value.Name] = value.Values
+		}
+	}
+
+	for _, variable := range dashboard.Variables {
+		current, ok := selectedValues[variable.Name]
+		if !ok {
+			current = variable.Current
+		}
+
+		if len(current) == 0 || containsAll(current) {
+			options, err := VariableOptions(variable, dashboard.ApplicationMetadataMap, driver, details)
+			if err != nil {
+				log.Print(err)
+			}
+			if len(current) == 0 {
+				if len(options) > 0 {
+					current = options[:1]
+				}
+			} else {
+				current = options
+			}
+			if len(current) == 0 && variable.Type != VariableTypeInterval {
+				current = []string{".*"}
+			}
+		}
+
+		values[variable.Name] = formatValues(variable, current, globals)
+	}
+
+	for name, value := range globals {
+		values[name] = value
+	}
+
+	return values
+}
+
+// StaticVariableValues resolves the values of the variables without the data source, the first option or
+// a regex matching everything stand in for unknown values. Used to validate the queries of the dashboards
+func StaticVariableValues(variables []*model.DashboardVariable) map[string]string {
+	now := time.Now().Unix()
+	values := GlobalVariables(now-defaultValidationRange, now)
+
+	for _, variable := range variables {
+		if variable == nil {
+			continue
+		}
+
+		current := variable.Current
+		if containsAll(current) {
+			current = variable.Options
+		}
+		if len(current) == 0 && len(variable.Options) > 0 {
+			current = variable.Options[:1]
+		}
+
+		if len(current) == 0 && variable.Type != VariableTypeInterval {
+			current = []string{".*"}
+		}
+		values[variable.Name] = formatValues(dbSchemaAnalytics.DashboardVariable{Type: variable.Type}, current, values)
+	}
+
+	return values
+}
+
+// SubstituteVariables replaces the $var, ${var} and [[var]] references of the known variables
+func SubstituteVariables(expr string, values map[string]string) string {
+	if len(values) == 0 || !strings.ContainsAny(expr, "$[") {
+		return expr
+	}
+
+	return variableReference.ReplaceAllStringFunc(expr, func(reference string) string {
+		match := variableReference.FindStringSubmatch(reference)
+		name := match[1] + match[2] + match[3]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return reference
+	})
+}
+
+// SubstituteQueryVariables returns copies of the queries with the variables of their queries and legends replaced
+func SubstituteQueryVariables(promQueries []*model.PromQueryInput, values map[string]string) []*model.PromQueryInput {
+	if len(values) == 0 {
+		return promQueries
+	}
+
+	newPromQueries := make([]*model.PromQueryInput, 0, len(promQueries))
+	for _, promQuery := range promQueries {
+		if promQuery == nil {
+			continue
+		}
+
+		newPromQuery := *promQuery
+		newPromQuery.Query = SubstituteVariables(promQuery.Query, values)
+		if promQuery.Legend != nil {
+			legend := SubstituteVariables(*promQuery.Legend, values)
+			newPromQuery.Legend = &legend
+		}
+		newPromQueries = append(newPromQueries, &newPromQuery)
+	}
+
+	return newPromQueries
+}
+
+func formatValues(variable dbSchemaAnalytics.DashboardVariable, values []string, globals map[string]string) string {
+	if variable.Type == VariableTypeInterval {
+		if len(values) == 0 || values[0] == AutoInterval {
+			return globals["__interval"]
+		}
+		return values[0]
+	}
+
+	if len(values) == 1 {
+		return values[0]
+	}
+
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = regexp.QuoteMeta(value)
+	}
+	return strings.Join(escaped, "|")
+}
+
+func formatSeconds(duration time.Duration) string {
+	return strconv.FormatInt(int64(duration/time.Second), 10) + "s"
+}
+
+func containsAll(values []string) bool {
+	for _, value := range values {
+		if value == AllValue {
+			return true
+		}
+	}
+	return false
+}
+
+func unique(values []string) []string {
+	seen := make(map[string]bool)
+	var uniqueValues []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			uniqueValues = append(uniqueValues, value)
+		}
+	}
+	return uniqueValues
+}
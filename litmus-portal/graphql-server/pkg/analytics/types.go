@@ -2,10 +2,35 @@ package analytics
 
 type STATE string
 
+// DataSourceConfig holds the connection settings of a data source
+type DataSourceConfig struct {
+	// DsID is empty for data sources which aren't stored in the portal
+	DsID               string
+	DsType             string
+	URL                string
+	AuthType           string
+	BasicAuthUsername  string
+	BasicAuthPassword  string
+	BearerToken        string
+	CustomHeaders      map[string]string
+	CACert             string
+	ClientCert         string
+	ClientKey          string
+	InsecureSkipVerify bool
+	// TenantID is sent in the TenantHeader to multi-tenant data sources
+	TenantID     string
+	TenantHeader string
+	// QueryTimeout is in seconds
+	QueryTimeout int
+	HTTPMethod   string
+}
+
 type PromDSDetails struct {
 	URL   string
 	Start string
 	End   string
+	// Config is nil for data sources which aren't stored in the portal
+	Config *DataSourceConfig
 }
 
 type PromQuery struct {
@@ -14,7 +39,9 @@ type PromQuery struct {
 	Legend     *string
 	Resolution *string
 	Minstep    int
-	DSdetails  *PromDSDetails
+	// Instant queries are evaluated at the end of the time range only
+	Instant   bool
+	DSdetails *PromDSDetails
 }
 
 type PromSeries struct {
@@ -18,6 +18,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/alerts"
 	types "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/chaos-workflow"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/chaos-workflow/ops"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/cluster"
@@ -32,6 +33,15 @@ import (
 )
 
 func CreateChaosWorkflow(ctx context.Context, input *model.ChaosWorkFlowInput, r *store.StateData) (*model.ChaosWorkFlowResponse, error) {
+	return createChaosWorkflow(ctx, input, ops.ProcessWorkflowCreation, r)
+}
+
+// ImportChaosWorkflow creates a workflow like CreateChaosWorkflow without running it, schedules are still deployed
+func ImportChaosWorkflow(ctx context.Context, input *model.ChaosWorkFlowInput, r *store.StateData) (*model.ChaosWorkFlowResponse, error) {
+	return createChaosWorkflow(ctx, input, ops.ProcessWorkflowImport, r)
+}
+
+func createChaosWorkflow(ctx context.Context, input *model.ChaosWorkFlowInput, processCreation func(*model.ChaosWorkFlowInput, *dbSchemaWorkflow.ChaosWorkflowType, *store.StateData) error, r *store.StateData) (*model.ChaosWorkFlowResponse, error) {
 	input, wfType, err := ops.ProcessWorkflow(input)
 	if err != nil {
 		log.Print("Error processing workflow: ", err)
@@ -45,7 +55,7 @@ func CreateChaosWorkflow(ctx context.Context, input *model.ChaosWorkFlowInput, r
 		return nil, err
 	}
 
-	err = ops.ProcessWorkflowCreation(input, wfType, r)
+	err = processCreation(input, wfType, r)
 	if err != nil {
 		log.Print("Error executing workflow: ", err)
 		return nil, err
@@ -414,6 +424,16 @@ func QueryWorkflowRuns(input model.GetWorkflowRunsInput) (*model.GetWorkflowsOut
 			ClusterType:        &workflow.ClusterType,
 			IsRemoved:          workflowRun.IsRemoved,
 		}
+		if workflowRun.SkipReason != "" {
+			skipReason := workflowRun.SkipReason
+			newWorkflowRun.SkipReason = &skipReason
+		}
+		if workflowRun.AbortedBy != "" {
+			abortedBy, abortReason := workflowRun.AbortedBy, workflowRun.AbortReason
+			newWorkflowRun.AbortedBy = &abortedBy
+			newWorkflowRun.AbortReason = &abortReason
+		}
+		newWorkflowRun.ImpactReport = ops.ImpactReportToModel(workflowRun.ImpactReport)
 		result = append(result, &newWorkflowRun)
 	}
 
@@ -610,6 +630,13 @@ func QueryListWorkflow(workflowInput model.ListWorkflowsInput) (*model.ListWorkf
 			ClusterName:         cluster.ClusterName,
 			ClusterID:           cluster.ClusterID,
 			ClusterType:         cluster.ClusterType,
+			SteadyStateGates:    ops.SteadyStateGatesToModel(workflow.SteadyStateGates),
+			Guardrails:          ops.GuardrailsToModel(workflow.Guardrails),
+			ImpactAnalysis:      ops.ImpactAnalysisToModel(workflow.ImpactAnalysis),
+		}
+		if workflow.GitOps != nil {
+			driftStatus := workflow.GitOps.DriftStatus
+			newChaosWorkflows.DriftStatus = &driftStatus
 		}
 		result = append(result, &newChaosWorkflows)
 	}
@@ -695,6 +722,14 @@ func WorkFlowRunHandler(input model.WorkflowRunInput, r store.StateData) (string
 		IsRemoved:          &isRemoved,
 	}, &r)
 
+	if input.Completed {
+		ops.StopRunGuardrails(input.WorkflowRunID)
+		go ops.ScheduleImpactReport(input.WorkflowID, input.WorkflowRunID, executionData)
+		go alerts.EvaluateProject(cluster.ProjectID, &r)
+	} else {
+		go ops.WatchRunGuardrails(input.WorkflowID, input.WorkflowRunID, &r)
+	}
+
 	return "Workflow Run Accepted", nil
 }
 
@@ -770,11 +805,13 @@ func ReRunWorkflow(workflowID string) (string, error) {
 		return "", errors.New("Failed to updated workflow name " + err.Error())
 	}
 
-	ops.SendWorkflowToSubscriber(&model.ChaosWorkFlowInput{
-		WorkflowManifest: workflows[0].WorkflowManifest,
-		ProjectID:        workflows[0].ProjectID,
-		ClusterID:        workflows[0].ClusterID,
-	}, nil, "create", store.Store)
+	triggered, err := ops.DispatchWorkflowRun(workflows[0], store.Store)
+	if err != nil {
+		return "", err
+	}
+	if !triggered {
+		return "Re-run skipped, steady state gates of workflowID: " + workflowID + " failed", nil
+	}
 
 	return "Request for re-run acknowledged, workflowID: " + workflowID, nil
 }
@@ -798,6 +835,25 @@ func KubeObjHandler(kubeData model.KubeObjectData, r store.StateData) (string, e
 	return "KubeData sent successfully", nil
 }
 
+// ReadinessCheckHandler receives the result of the readiness checks of steady state gates from subscriber
+func ReadinessCheckHandler(result model.ReadinessCheckResult, r store.StateData) (string, error) {
+	_, err := cluster.VerifyCluster(*result.ClusterID)
+	if err != nil {
+		log.Print("Error", err)
+		return "", err
+	}
+	r.Mutex.Lock()
+	resultChan, ok := r.ReadinessCheckData[result.RequestID]
+	r.Mutex.Unlock()
+	if ok {
+		select {
+		case resultChan <- &result:
+		default:
+		}
+	}
+	return "Readiness check result received", nil
+}
+
 func GetKubeObjData(reqID string, kubeObject model.KubeObjectRequest, r store.StateData) {
 	reqType := kubeObject.ObjectType
 	data, err := json.Marshal(kubeObject)
@@ -855,6 +911,7 @@ func SaveWorkflowTemplate(ctx context.Context, templateInput *model.TemplateInpu
 	if err != nil {
 		log.Print("Error", err)
 	}
+	gitOpsHandler.SyncProjectConfig(ctx, template.ProjectID)
 	return template.GetManifestTemplateOutput(), nil
 }
 
@@ -890,6 +947,9 @@ func DeleteWorkflowTemplate(ctx context.Context, templateID string) (bool, error
 		log.Print("Err", err)
 		return false, err
 	}
+	if template, err := dbOperationsWorkflowTemplate.GetTemplateByTemplateID(ctx, templateID); err == nil {
+		gitOpsHandler.SyncProjectConfig(ctx, template.ProjectID)
+	}
 	return true, err
 }
 
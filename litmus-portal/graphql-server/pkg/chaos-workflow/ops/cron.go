@@ -0,0 +1,203 @@
+package ops
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// CronSchedule is a parsed five field cron expression, the fields are bit sets of the matching values
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// when one of the day fields is restricted and the other isn't, only the restricted one is checked
+	domStar, dowStar bool
+}
+
+type cronField struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = cronField{min: 0, max: 59}
+	hourField   = cronField{min: 0, max: 23}
+	domField    = cronField{min: 1, max: 31}
+	monthField  = cronField{min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 is sunday as well
+	dowField = cronField{min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+
+	cronDescriptors = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// maximum days searched back for a scheduled time, schedules like the 29th of february match at least every 8 years
+const maxCronLookback = 8 * 366
+
+// ParseCron parses a cron expression of cron workflows, i.e. five fields or a descriptor like @daily
+func ParseCron(spec string) (*CronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if descriptor, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = descriptor
+	} else if strings.HasPrefix(spec, "@") {
+		return nil, errors.New("unsupported cron descriptor " + spec)
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.New("cron expression " + spec + " must have 5 fields")
+	}
+
+	var (
+		schedule CronSchedule
+		err      error
+	)
+	if schedule.minute, err = parseCronField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+	if schedule.hour, err = parseCronField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+	if schedule.dom, err = parseCronField(fields[2], domField); err != nil {
+		return nil, err
+	}
+	if schedule.month, err = parseCronField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+	if schedule.dow, err = parseCronField(fields[4], dowField); err != nil {
+		return nil, err
+	}
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+	schedule.domStar = strings.HasPrefix(fields[2], "*") || strings.HasPrefix(fields[2], "?")
+	schedule.dowStar = strings.HasPrefix(fields[4], "*") || strings.HasPrefix(fields[4], "?")
+
+	return &schedule, nil
+}
+
+// parseCronField parses a comma separated list of values, ranges and steps like 1,5-10,*/15
+func parseCronField(value string, field cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(value, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rangePart = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, errors.New("invalid step in cron field " + value)
+			}
+		}
+
+		var start, end int
+		switch {
+		case rangePart == "*" || rangePart == "?":
+			start, end = field.min, field.max
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if start, err = field.value(bounds[0]); err != nil {
+				return 0, err
+			}
+			if end, err = field.value(bounds[1]); err != nil {
+				return 0, err
+			}
+		default:
+			var err error
+			if start, err = field.value(rangePart); err != nil {
+				return 0, err
+			}
+			end = start
+			// a single value with a step runs until the end of the range
+			if strings.Contains(part, "/") {
+				end = field.max
+			}
+		}
+		if start > end {
+			return 0, errors.New("invalid range in cron field " + value)
+		}
+
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+	return bits, nil
+}
+
+func (field cronField) value(value string) (int, error) {
+	if number, ok := field.names[strings.ToLower(value)]; ok {
+		return number, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < field.min || number > field.max {
+		return 0, errors.New("invalid value " + value + " in cron expression")
+	}
+	return number, nil
+}
+
+// matchesDay reports whether the schedule runs on the day, the day of month and the day of week fields
+// match either of them when both are restricted
+func (schedule *CronSchedule) matchesDay(t time.Time) bool {
+	if schedule.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	domMatch := schedule.dom&(1<<uint(t.Day())) != 0
+	dowMatch := schedule.dow&(1<<uint(t.Weekday())) != 0
+	if schedule.domStar || schedule.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Prev returns the last scheduled time at or before t in the location of t, the zero time when none was found
+func (schedule *CronSchedule) Prev(t time.Time) time.Time {
+	loc := t.Location()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	lastHour, lastMinute := t.Hour(), t.Minute()
+
+	for i := 0; i < maxCronLookback; i++ {
+		if schedule.matchesDay(day) {
+			for hour := lastHour; hour >= 0; hour-- {
+				if schedule.hour&(1<<uint(hour)) == 0 {
+					continue
+				}
+				minute := 59
+				if hour == lastHour {
+					minute = lastMinute
+				}
+				for ; minute >= 0; minute-- {
+					if schedule.minute&(1<<uint(minute)) != 0 {
+						return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, loc)
+					}
+				}
+			}
+		}
+		day = time.Date(day.Year(), day.Month(), day.Day()-1, 0, 0, 0, 0, loc)
+		lastHour, lastMinute = 23, 59
+	}
+	return time.Time{}
+}
+
+// CronLocation returns the location of the schedule of a cron workflow manifest, UTC when it has no timezone
+func CronLocation(manifest string) (*time.Location, error) {
+	timezone := gjson.Get(manifest, "spec.timezone").String()
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(timezone)
+}
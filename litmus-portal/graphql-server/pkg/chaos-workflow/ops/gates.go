@@ -0,0 +1,354 @@
+package ops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	analyticsOps "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics/ops"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics/ops/prometheus"
+	types "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/chaos-workflow"
+	store "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/data-store"
+	dbOperationsAnalytics "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/analytics"
+	dbOperationsCluster "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/cluster"
+	dbOperationsWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+	dbSchemaWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+	md "github.com/prometheus/common/model"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// WorkflowRunSkipped is the phase of the runs skipped because of a failing steady state gate
+	WorkflowRunSkipped = "Skipped"
+
+	readinessCheckTimeout = 30 * time.Second
+)
+
+var (
+	gateOperators  = []string{">", ">=", "<", "<=", "==", "!="}
+	readinessKinds = []string{"deployment", "statefulset", "daemonset", "pod"}
+)
+
+// readinessCheck is a readiness check sent to the subscriber
+type readinessCheck struct {
+	Kind          string `json:"kind"`
+	Namespace     string `json:"namespace"`
+	ResourceName  string `json:"resource_name,omitempty"`
+	LabelSelector string `json:"label_selector,omitempty"`
+}
+
+// ValidateSteadyStateGates checks the steady state gates of a workflow of the project
+func ValidateSteadyStateGates(projectID string, gates []*model.SteadyStateGateInput) error {
+	for _, gate := range gates {
+		if gate.Name == "" {
+			return errors.New("steady state gate name is required")
+		}
+		switch gate.Type {
+		case model.SteadyStateGateTypePrometheus:
+			if gate.DataSourceID == nil || gate.Query == nil || *gate.Query == "" || gate.Threshold == nil {
+				return errors.New("steady state gate " + gate.Name + " requires a data source, a query and a threshold")
+			}
+			if gate.Operator == nil || !containsString(gateOperators, *gate.Operator) {
+				return errors.New("steady state gate " + gate.Name + " has an invalid operator, supported operators are " + strings.Join(gateOperators, " "))
+			}
+			ds, err := dbOperationsAnalytics.GetDataSourceByID(*gate.DataSourceID)
+			if err != nil {
+				return errors.New("steady state gate " + gate.Name + " data source not found : " + err.Error())
+			}
+			if ds.ProjectID != projectID || ds.IsRemoved {
+				return errors.New("steady state gate " + gate.Name + " data source doesn't belong to this project")
+			}
+			if !analyticsOps.SupportsPromQL(ds.DsType) {
+				return errors.New("steady state gate " + gate.Name + " data source of type " + ds.DsType + " doesn't support PromQL queries")
+			}
+		case model.SteadyStateGateTypeKubernetesReadiness:
+			if gate.Kind == nil || !containsString(readinessKinds, strings.ToLower(*gate.Kind)) {
+				return errors.New("steady state gate " + gate.Name + " has an invalid kind, supported kinds are " + strings.Join(readinessKinds, " "))
+			}
+			if gate.Namespace == nil || *gate.Namespace == "" {
+				return errors.New("steady state gate " + gate.Name + " requires a namespace")
+			}
+			if (gate.ResourceName == nil || *gate.ResourceName == "") == (gate.LabelSelector == nil || *gate.LabelSelector == "") {
+				return errors.New("steady state gate " + gate.Name + " requires either a resource name or a label selector")
+			}
+		default:
+			return errors.New("steady state gate " + gate.Name + " has an invalid type")
+		}
+	}
+	return nil
+}
+
+// NewSteadyStateGates converts the steady state gates of a workflow input to their DB schema
+func NewSteadyStateGates(gates []*model.SteadyStateGateInput) []*dbSchemaWorkflow.SteadyStateGate {
+	var newGates []*dbSchemaWorkflow.SteadyStateGate
+	for _, gate := range gates {
+		newGates = append(newGates, &dbSchemaWorkflow.SteadyStateGate{
+			Name:          gate.Name,
+			Type:          gate.Type,
+			DataSourceID:  gate.DataSourceID,
+			Query:         gate.Query,
+			Operator:      gate.Operator,
+			Threshold:     gate.Threshold,
+			Kind:          gate.Kind,
+			Namespace:     gate.Namespace,
+			ResourceName:  gate.ResourceName,
+			LabelSelector: gate.LabelSelector,
+		})
+	}
+	return newGates
+}
+
+// SteadyStateGatesToModel converts the steady state gates of a workflow to their graphql model
+func SteadyStateGatesToModel(gates []*dbSchemaWorkflow.SteadyStateGate) []*model.SteadyStateGate {
+	var newGates []*model.SteadyStateGate
+	for _, gate := range gates {
+		newGates = append(newGates, &model.SteadyStateGate{
+			Name:          gate.Name,
+			Type:          gate.Type,
+			DataSourceID:  gate.DataSourceID,
+			Query:         gate.Query,
+			Operator:      gate.Operator,
+			Threshold:     gate.Threshold,
+			Kind:          gate.Kind,
+			Namespace:     gate.Namespace,
+			ResourceName:  gate.ResourceName,
+			LabelSelector: gate.LabelSelector,
+		})
+	}
+	return newGates
+}
+
+// EvaluateSteadyStateGates evaluates the steady state gates of the workflow, it returns the reason of the first
+// failing gate or an empty string when all of them pass. Gates which can't be evaluated fail
+func EvaluateSteadyStateGates(workflow dbSchemaWorkflow.ChaosWorkFlowInput, r *store.StateData) string {
+	var checks []readinessCheck
+	for _, gate := range workflow.SteadyStateGates {
+		switch gate.Type {
+		case model.SteadyStateGateTypePrometheus:
+			if reason := evaluatePrometheusGate(gate); reason != "" {
+				return "steady state gate " + gate.Name + " failed : " + reason
+			}
+		case model.SteadyStateGateTypeKubernetesReadiness:
+			checks = append(checks, readinessCheck{
+				Kind:          strings.ToLower(stringValue(gate.Kind)),
+				Namespace:     stringValue(gate.Namespace),
+				ResourceName:  stringValue(gate.ResourceName),
+				LabelSelector: stringValue(gate.LabelSelector),
+			})
+		}
+	}
+
+	if len(checks) > 0 {
+		if reason := requestReadinessChecks(workflow, checks, r); reason != "" {
+			return "steady state gate failed : " + reason
+		}
+	}
+	return ""
+}
+
+// evaluatePrometheusGate runs the instant query of the gate, every sample of the result must satisfy the threshold
+func evaluatePrometheusGate(gate *dbSchemaWorkflow.SteadyStateGate) string {
+	ds, err := dbOperationsAnalytics.GetDataSourceByID(stringValue(gate.DataSourceID))
+	if err != nil {
+		return "could not get data source : " + err.Error()
+	}
+	if ds.IsRemoved {
+		return "data source was removed"
+	}
+
+	dsConfig, err := analyticsOps.NewDataSourceConfig(ds)
+	if err != nil {
+		return "could not decrypt the credentials of the data source : " + err.Error()
+	}
+	config := *dsConfig
+	client, err := prometheus.CreateClient(config)
+	if err != nil {
+		return "could not create prometheus client : " + err.Error()
+	}
+
+	ctx, cancel := prometheus.QueryContext(config)
+	defer cancel()
+	value, _, err := client.Query(ctx, stringValue(gate.Query), time.Now())
+	if err != nil {
+		return "query failed : " + err.Error()
+	}
+
+	var samples []float64
+	switch result := value.(type) {
+	case md.Vector:
+		for _, sample := range result {
+			samples = append(samples, float64(sample.Value))
+		}
+	case *md.Scalar:
+		samples = append(samples, float64(result.Value))
+	default:
+		return "unsupported result type " + value.Type().String()
+	}
+	if len(samples) == 0 {
+		return "query returned no data"
+	}
+
+	for _, sample := range samples {
+		if !compareThreshold(sample, stringValue(gate.Operator), *gate.Threshold) {
+			return fmt.Sprintf("%v %s %v is not satisfied", sample, stringValue(gate.Operator), *gate.Threshold)
+		}
+	}
+	return ""
+}
+
+func compareThreshold(value float64, operator string, threshold float64) bool {
+	switch operator {
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	case "==":
+		return value == threshold
+	case "!=":
+		return value != threshold
+	}
+	return false
+}
+
+// requestReadinessChecks sends the readiness checks to the subscriber of the workflow's cluster and waits for the result
+func requestReadinessChecks(workflow dbSchemaWorkflow.ChaosWorkFlowInput, checks []readinessCheck, r *store.StateData) string {
+	data, err := json.Marshal(checks)
+	if err != nil {
+		return err.Error()
+	}
+	externalData := string(data)
+
+	reqID := uuid.New().String()
+	resultChan := make(chan *model.ReadinessCheckResult, 1)
+	r.Mutex.Lock()
+	clusterChan, ok := r.ConnectedCluster[workflow.ClusterID]
+	if ok {
+		r.ReadinessCheckData[reqID] = resultChan
+	}
+	r.Mutex.Unlock()
+	if !ok {
+		return "cluster is not connected"
+	}
+	defer func() {
+		r.Mutex.Lock()
+		delete(r.ReadinessCheckData, reqID)
+		r.Mutex.Unlock()
+	}()
+
+	clusterChan <- &model.ClusterAction{
+		ProjectID: reqID,
+		Action: &model.ActionPayload{
+			RequestType:  "readiness_check",
+			ExternalData: &externalData,
+		},
+	}
+
+	select {
+	case result := <-resultChan:
+		if result.Ready {
+			return ""
+		}
+		if result.Reason == nil {
+			return "resources are not ready"
+		}
+		return *result.Reason
+	case <-time.After(readinessCheckTimeout):
+		return "timed out waiting for the readiness checks"
+	}
+}
+
+// DispatchWorkflowRun sends a run of the workflow to its cluster if its steady state gates pass, otherwise the run
+// is recorded as skipped. It returns false if the run was skipped
+func DispatchWorkflowRun(workflow dbSchemaWorkflow.ChaosWorkFlowInput, r *store.StateData) (bool, error) {
+	if reason := EvaluateSteadyStateGates(workflow, r); reason != "" {
+		log.Print("Skipping run of workflow ", workflow.WorkflowID, " : ", reason)
+		return false, RecordSkippedRun(workflow, uuid.New().String(), reason, r)
+	}
+
+	SendWorkflowToSubscriber(&model.ChaosWorkFlowInput{
+		WorkflowManifest: workflow.WorkflowManifest,
+		ProjectID:        workflow.ProjectID,
+		ClusterID:        workflow.ClusterID,
+	}, nil, "create", r)
+	return true, nil
+}
+
+// RecordSkippedRun records a completed run of the workflow skipped for the reason
+func RecordSkippedRun(workflow dbSchemaWorkflow.ChaosWorkFlowInput, workflowRunID string, reason string, r *store.StateData) error {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	executionData, err := json.Marshal(types.ExecutionData{
+		WorkflowType:      "events",
+		Namespace:         gjson.Get(workflow.WorkflowManifest, "metadata.namespace").String(),
+		Name:              gjson.Get(workflow.WorkflowManifest, "metadata.name").String(),
+		CreationTimestamp: now,
+		Phase:             WorkflowRunSkipped,
+		Message:           reason,
+		StartedAt:         now,
+		FinishedAt:        now,
+		Nodes:             map[string]types.Node{},
+	})
+	if err != nil {
+		return err
+	}
+
+	isRemoved := false
+	_, err = dbOperationsWorkflow.UpdateWorkflowRun(workflow.WorkflowID, dbSchemaWorkflow.ChaosWorkflowRun{
+		WorkflowRunID: workflowRunID,
+		LastUpdated:   now,
+		Phase:         WorkflowRunSkipped,
+		ExecutionData: string(executionData),
+		Completed:     true,
+		IsRemoved:     &isRemoved,
+		SkipReason:    reason,
+	})
+	if err != nil {
+		return errors.New("could not record skipped workflow run : " + err.Error())
+	}
+
+	cluster, err := dbOperationsCluster.GetCluster(workflow.ClusterID)
+	if err != nil {
+		return err
+	}
+
+	SendWorkflowEvent(model.WorkflowRun{
+		WorkflowRunID: workflowRunID,
+		WorkflowID:    workflow.WorkflowID,
+		ClusterName:   cluster.ClusterName,
+		LastUpdated:   now,
+		ProjectID:     workflow.ProjectID,
+		ClusterID:     workflow.ClusterID,
+		WorkflowName:  workflow.WorkflowName,
+		ClusterType:   &cluster.ClusterType,
+		Phase:         WorkflowRunSkipped,
+		ExecutionData: string(executionData),
+		IsRemoved:     &isRemoved,
+		SkipReason:    &reason,
+	}, r)
+	return nil
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
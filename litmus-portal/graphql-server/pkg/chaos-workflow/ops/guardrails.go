@@ -0,0 +1,398 @@
+package ops
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics"
+	analyticsOps "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics/ops"
+	types "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/chaos-workflow"
+	store "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/data-store"
+	dbOperationsAnalytics "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/analytics"
+	dbOperationsWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+	dbSchemaWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	defaultGuardrailInterval    = 30
+	minGuardrailInterval        = 5
+	defaultGuardrailBreachCount = 1
+	defaultGuardrailStep        = 15
+	// the abort request is sent again when an aborted run is still executing after the interval
+	abortRetryInterval = time.Minute
+)
+
+// ChaosEngineRef identifies a chaos engine of a workflow run
+type ChaosEngineRef struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// WorkflowAbortRequest is sent to the subscriber to stop a workflow run and its chaos engines
+type WorkflowAbortRequest struct {
+	WorkflowRunID string           `json:"workflow_run_id"`
+	ChaosEngines  []ChaosEngineRef `json:"chaos_engines"`
+}
+
+// guardrailMonitors are the cancel functions of the guardrail monitors of the executing runs, runs of workflows
+// without guardrails have no cancel function
+var guardrailMonitors = struct {
+	sync.Mutex
+	runs map[string]context.CancelFunc
+}{runs: make(map[string]context.CancelFunc)}
+
+// ValidateGuardrails checks the guardrails of a workflow of the project
+func ValidateGuardrails(projectID string, guardrails []*model.GuardrailInput) error {
+	names := make(map[string]bool)
+	for _, guardrail := range guardrails {
+		if guardrail.Name == "" || guardrail.Query == "" {
+			return errors.New("guardrail name and query are required")
+		}
+		if names[guardrail.Name] {
+			return errors.New("duplicate guardrail " + guardrail.Name)
+		}
+		names[guardrail.Name] = true
+		if !containsString(gateOperators, guardrail.Operator) {
+			return errors.New("guardrail " + guardrail.Name + " has an invalid operator, supported operators are " + strings.Join(gateOperators, " "))
+		}
+		if guardrail.Interval != nil && *guardrail.Interval < minGuardrailInterval {
+			return errors.New("guardrail " + guardrail.Name + " interval must be at least " + strconv.Itoa(minGuardrailInterval) + " seconds")
+		}
+		if guardrail.BreachCount != nil && *guardrail.BreachCount < 1 {
+			return errors.New("guardrail " + guardrail.Name + " breach count must be at least 1")
+		}
+		ds, err := dbOperationsAnalytics.GetDataSourceByID(guardrail.DataSourceID)
+		if err != nil {
+			return errors.New("guardrail " + guardrail.Name + " data source not found : " + err.Error())
+		}
+		if ds.ProjectID != projectID || ds.IsRemoved {
+			return errors.New("guardrail " + guardrail.Name + " data source doesn't belong to this project")
+		}
+	}
+	return nil
+}
+
+// NewGuardrails converts the guardrails of a workflow input to their DB schema
+func NewGuardrails(guardrails []*model.GuardrailInput) []*dbSchemaWorkflow.Guardrail {
+	var newGuardrails []*dbSchemaWorkflow.Guardrail
+	for _, guardrail := range guardrails {
+		newGuardrails = append(newGuardrails, &dbSchemaWorkflow.Guardrail{
+			Name:         guardrail.Name,
+			DataSourceID: guardrail.DataSourceID,
+			Query:        guardrail.Query,
+			Operator:     guardrail.Operator,
+			Threshold:    guardrail.Threshold,
+			Interval:     guardrail.Interval,
+			BreachCount:  guardrail.BreachCount,
+		})
+	}
+	return newGuardrails
+}
+
+// GuardrailsToModel converts the guardrails of a workflow to their graphql model
+func GuardrailsToModel(guardrails []*dbSchemaWorkflow.Guardrail) []*model.Guardrail {
+	var newGuardrails []*model.Guardrail
+	for _, guardrail := range guardrails {
+		newGuardrails = append(newGuardrails, &model.Guardrail{
+			Name:         guardrail.Name,
+			DataSourceID: guardrail.DataSourceID,
+			Query:        guardrail.Query,
+			Operator:     guardrail.Operator,
+			Threshold:    guardrail.Threshold,
+			Interval:     guardrail.Interval,
+			BreachCount:  guardrail.BreachCount,
+		})
+	}
+	return newGuardrails
+}
+
+// WatchRunGuardrails starts the guardrail monitor of an executing run of the workflow if it isn't running yet
+func WatchRunGuardrails(workflowID string, workflowRunID string, r *store.StateData) {
+	if isRunWatched(workflowRunID) {
+		return
+	}
+
+	workflow, err := dbOperationsWorkflow.GetWorkflow(bson.D{{"workflow_id", workflowID}, {"isRemoved", false}})
+	if err != nil {
+		log.Print("Could not get workflow ", workflowID, " : ", err)
+		return
+	}
+	running, err := isRunExecuting(workflowID, workflowRunID)
+	if err != nil || !running {
+		return
+	}
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if len(workflow.Guardrails) > 0 {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	guardrailMonitors.Lock()
+	if _, ok := guardrailMonitors.runs[workflowRunID]; ok {
+		guardrailMonitors.Unlock()
+		if cancel != nil {
+			cancel()
+		}
+		return
+	}
+	guardrailMonitors.runs[workflowRunID] = cancel
+	guardrailMonitors.Unlock()
+
+	// the run may have completed before it was registered, StopRunGuardrails then didn't find it
+	if running, err := isRunExecuting(workflowID, workflowRunID); err == nil && !running {
+		StopRunGuardrails(workflowRunID)
+		return
+	}
+	if cancel != nil {
+		go monitorGuardrails(ctx, workflow, workflowRunID, r)
+	}
+}
+
+// isRunWatched reports whether the run is registered in the guardrail monitors
+func isRunWatched(workflowRunID string) bool {
+	guardrailMonitors.Lock()
+	defer guardrailMonitors.Unlock()
+	_, ok := guardrailMonitors.runs[workflowRunID]
+	return ok
+}
+
+// StopRunGuardrails stops the guardrail monitor of a completed run
+func StopRunGuardrails(workflowRunID string) {
+	guardrailMonitors.Lock()
+	defer guardrailMonitors.Unlock()
+	if cancel := guardrailMonitors.runs[workflowRunID]; cancel != nil {
+		cancel()
+	}
+	delete(guardrailMonitors.runs, workflowRunID)
+}
+
+// monitorGuardrails evaluates the guardrails of the workflow on their interval until one of them is breached, the
+// run is then monitored until it completes so that the abort is retried if the run keeps executing
+func monitorGuardrails(ctx context.Context, workflow dbSchemaWorkflow.ChaosWorkFlowInput, workflowRunID string, r *store.StateData) {
+	// the monitor only returns once the run completed or was stopped, later events of the run don't restart it
+	defer StopRunGuardrails(workflowRunID)
+
+	tick := time.Duration(guardrailInterval(workflow.Guardrails[0])) * time.Second
+	for _, guardrail := range workflow.Guardrails {
+		if interval := time.Duration(guardrailInterval(guardrail)) * time.Second; interval < tick {
+			tick = interval
+		}
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	nextEvaluation := make(map[string]time.Time)
+	breaches := make(map[string]int)
+	var abortedAt time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			running, err := isRunExecuting(workflow.WorkflowID, workflowRunID)
+			if err != nil {
+				log.Print("Could not get run ", workflowRunID, " : ", err)
+				continue
+			}
+			if !running {
+				return
+			}
+
+			if !abortedAt.IsZero() {
+				if now.Sub(abortedAt) >= abortRetryInterval {
+					log.Print("Run ", workflowRunID, " of workflow ", workflow.WorkflowID, " is still executing, sending the abort request again")
+					if err := sendAbortRequest(workflow, workflowRunID, r); err != nil {
+						log.Print("Could not abort run ", workflowRunID, " : ", err)
+					}
+					abortedAt = now
+				}
+				continue
+			}
+
+			for _, guardrail := range workflow.Guardrails {
+				if now.Before(nextEvaluation[guardrail.Name]) {
+					continue
+				}
+				nextEvaluation[guardrail.Name] = now.Add(time.Duration(guardrailInterval(guardrail))*time.Second - tick/2)
+
+				reason, err := evaluateGuardrail(guardrail, now)
+				if err != nil {
+					log.Print("Could not evaluate guardrail ", guardrail.Name, " of run ", workflowRunID, " : ", err)
+					continue
+				}
+				if reason == "" {
+					breaches[guardrail.Name] = 0
+					continue
+				}
+
+				breaches[guardrail.Name]++
+				if breaches[guardrail.Name] < guardrailBreachCount(guardrail) {
+					continue
+				}
+
+				log.Print("Aborting run ", workflowRunID, " of workflow ", workflow.WorkflowID, ", guardrail ", guardrail.Name, " breached : ", reason)
+				if err := abortWorkflowRun(workflow, workflowRunID, guardrail.Name, reason, r); err != nil {
+					log.Print("Could not abort run ", workflowRunID, " : ", err)
+					continue
+				}
+				abortedAt = now
+				break
+			}
+		}
+	}
+}
+
+// evaluateGuardrail queries the latest samples of the guardrail, it returns the reason of the breach or an empty
+// string when every series satisfies the threshold. Queries without data don't breach
+func evaluateGuardrail(guardrail *dbSchemaWorkflow.Guardrail, now time.Time) (string, error) {
+	ds, err := dbOperationsAnalytics.GetDataSourceByID(guardrail.DataSourceID)
+	if err != nil {
+		return "", err
+	}
+	driver, err := analyticsOps.GetDriver(ds.DsType)
+	if err != nil {
+		return "", err
+	}
+	config, err := analyticsOps.NewDataSourceConfig(ds)
+	if err != nil {
+		return "", err
+	}
+
+	step := ds.ScrapeInterval
+	if step <= 0 {
+		step = defaultGuardrailStep
+	}
+	// the range covers a few samples so that the latest one is found even if a scrape was missed
+	window := int64(step * 4)
+	if interval := int64(guardrailInterval(guardrail)); interval > window {
+		window = interval
+	}
+
+	response, err := driver.Query(analytics.PromQuery{
+		Queryid: "guardrail-" + guardrail.Name,
+		Query:   guardrail.Query,
+		Minstep: step,
+		DSdetails: &analytics.PromDSDetails{
+			URL:    ds.DsURL,
+			Start:  strconv.FormatInt(now.Unix()-window, 10),
+			End:    strconv.FormatInt(now.Unix(), 10),
+			Config: config,
+		},
+	}, "metrics")
+	if err != nil {
+		return "", err
+	}
+
+	metrics, ok := response.(*model.MetricsPromResponse)
+	if !ok {
+		return "", errors.New("unexpected query response")
+	}
+	for i, tsv := range metrics.Tsvs {
+		if len(tsv) == 0 || tsv[len(tsv)-1].Value == nil {
+			continue
+		}
+		value := *tsv[len(tsv)-1].Value
+		if !compareThreshold(value, guardrail.Operator, guardrail.Threshold) {
+			series := ""
+			if i < len(metrics.Legends) && metrics.Legends[i] != nil {
+				series = *metrics.Legends[i] + " "
+			}
+			return fmt.Sprintf("%s%v %s %v is not satisfied", series, value, guardrail.Operator, guardrail.Threshold), nil
+		}
+	}
+	return "", nil
+}
+
+// isRunExecuting reports whether the run of the workflow hasn't completed yet
+func isRunExecuting(workflowID string, workflowRunID string) (bool, error) {
+	workflows, err := dbOperationsWorkflow.GetWorkflows(bson.D{
+		{"workflow_id", workflowID},
+		{"workflow_runs", bson.D{
+			{"$elemMatch", bson.D{
+				{"workflow_run_id", workflowRunID},
+				{"completed", false},
+			}},
+		}},
+	})
+	if err != nil {
+		return false, err
+	}
+	return len(workflows) > 0, nil
+}
+
+// abortWorkflowRun records the guardrail which aborted the run and asks the subscriber to stop the run and its chaos
+// engines
+func abortWorkflowRun(workflow dbSchemaWorkflow.ChaosWorkFlowInput, workflowRunID string, guardrail string, reason string, r *store.StateData) error {
+	query := bson.D{{"workflow_id", workflow.WorkflowID}, {"workflow_runs.workflow_run_id", workflowRunID}}
+	update := bson.D{{"$set", bson.D{
+		{"workflow_runs.$.aborted_by", guardrail},
+		{"workflow_runs.$.abort_reason", reason},
+	}}}
+	err := dbOperationsWorkflow.UpdateChaosWorkflow(query, update)
+	if err != nil {
+		return err
+	}
+
+	return sendAbortRequest(workflow, workflowRunID, r)
+}
+
+// sendAbortRequest asks the subscriber to stop the run and the chaos engines of its experiments
+func sendAbortRequest(workflow dbSchemaWorkflow.ChaosWorkFlowInput, workflowRunID string, r *store.StateData) error {
+	request := WorkflowAbortRequest{WorkflowRunID: workflowRunID}
+	current, err := dbOperationsWorkflow.GetWorkflow(bson.D{{"workflow_id", workflow.WorkflowID}})
+	if err != nil {
+		return err
+	}
+	for _, run := range current.WorkflowRuns {
+		if run.WorkflowRunID != workflowRunID {
+			continue
+		}
+		var executionData types.ExecutionData
+		if err := json.Unmarshal([]byte(run.ExecutionData), &executionData); err != nil {
+			log.Print("Can not parse Execution Data of workflow run with id: ", workflowRunID)
+			break
+		}
+		for _, node := range executionData.Nodes {
+			if node.ChaosExp != nil && node.ChaosExp.EngineName != "" {
+				request.ChaosEngines = append(request.ChaosEngines, ChaosEngineRef{
+					Name:      node.ChaosExp.EngineName,
+					Namespace: node.ChaosExp.Namespace,
+				})
+			}
+		}
+	}
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	externalData := string(data)
+	SendWorkflowToSubscriber(&model.ChaosWorkFlowInput{
+		ProjectID: workflow.ProjectID,
+		ClusterID: workflow.ClusterID,
+	}, &externalData, "workflow_abort", r)
+	return nil
+}
+
+func guardrailInterval(guardrail *dbSchemaWorkflow.Guardrail) int {
+	if guardrail.Interval != nil && *guardrail.Interval >= minGuardrailInterval {
+		return *guardrail.Interval
+	}
+	return defaultGuardrailInterval
+}
+
+func guardrailBreachCount(guardrail *dbSchemaWorkflow.Guardrail) int {
+	if guardrail.BreachCount != nil && *guardrail.BreachCount > 0 {
+		return *guardrail.BreachCount
+	}
+	return defaultGuardrailBreachCount
+}
@@ -0,0 +1,486 @@
+package ops
+
+import (
+	"errors"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics"
+	analyticsOps "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics/ops"
+	types "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/chaos-workflow"
+	dbOperationsAnalytics "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/analytics"
+	dbSchemaAnalytics "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/analytics"
+	dbOperationsWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+	dbSchemaWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	defaultImpactWindow             = 600
+	minImpactWindow                 = 60
+	defaultImpactDeviationThreshold = 20
+	defaultImpactStep               = 15
+	// series of a query beyond this count aren't analysed
+	maxImpactSeries = 10
+)
+
+// ValidateImpactAnalysis checks that the dashboard of the impact analysis belongs to the project
+func ValidateImpactAnalysis(projectID string, impactAnalysis *model.ImpactAnalysisInput) error {
+	if impactAnalysis == nil {
+		return nil
+	}
+	if impactAnalysis.BaselineWindow != nil && *impactAnalysis.BaselineWindow < minImpactWindow {
+		return errors.New("impact analysis baseline window must be at least " + strconv.Itoa(minImpactWindow) + " seconds")
+	}
+	if impactAnalysis.RecoveryWindow != nil && *impactAnalysis.RecoveryWindow < minImpactWindow {
+		return errors.New("impact analysis recovery window must be at least " + strconv.Itoa(minImpactWindow) + " seconds")
+	}
+	if impactAnalysis.DeviationThreshold != nil && *impactAnalysis.DeviationThreshold <= 0 {
+		return errors.New("impact analysis deviation threshold must be positive")
+	}
+
+	_, err := dbOperationsAnalytics.GetDashboard(bson.D{{"db_id", impactAnalysis.DbID}, {"project_id", projectID}, {"is_removed", false}})
+	if err != nil {
+		return errors.New("impact analysis dashboard not found : " + err.Error())
+	}
+	return nil
+}
+
+// NewImpactAnalysis converts the impact analysis of a workflow input to its DB schema
+func NewImpactAnalysis(impactAnalysis *model.ImpactAnalysisInput) *dbSchemaWorkflow.ImpactAnalysis {
+	if impactAnalysis == nil {
+		return nil
+	}
+	return &dbSchemaWorkflow.ImpactAnalysis{
+		DbID:               impactAnalysis.DbID,
+		PanelIDs:           impactAnalysis.PanelIds,
+		BaselineWindow:     impactAnalysis.BaselineWindow,
+		RecoveryWindow:     impactAnalysis.RecoveryWindow,
+		DeviationThreshold: impactAnalysis.DeviationThreshold,
+	}
+}
+
+// ImpactAnalysisToModel converts the impact analysis of a workflow to its graphql model
+func ImpactAnalysisToModel(impactAnalysis *dbSchemaWorkflow.ImpactAnalysis) *model.ImpactAnalysis {
+	if impactAnalysis == nil {
+		return nil
+	}
+	return &model.ImpactAnalysis{
+		DbID:               impactAnalysis.DbID,
+		PanelIds:           impactAnalysis.PanelIDs,
+		BaselineWindow:     impactAnalysis.BaselineWindow,
+		RecoveryWindow:     impactAnalysis.RecoveryWindow,
+		DeviationThreshold: impactAnalysis.DeviationThreshold,
+	}
+}
+
+// ImpactReportToModel converts the impact report of a run to its graphql model
+func ImpactReportToModel(report *dbSchemaWorkflow.ImpactReport) *model.ImpactReport {
+	if report == nil {
+		return nil
+	}
+
+	newReport := &model.ImpactReport{
+		Status:        report.Status,
+		BaselineStart: report.BaselineStart,
+		ChaosStart:    report.ChaosStart,
+		ChaosEnd:      report.ChaosEnd,
+		RecoveryEnd:   report.RecoveryEnd,
+		Significant:   report.Significant,
+		Queries:       []*model.ImpactQueryReport{},
+	}
+	if report.Reason != "" {
+		reason := report.Reason
+		newReport.Reason = &reason
+	}
+	if report.GeneratedAt != "" {
+		generatedAt := report.GeneratedAt
+		newReport.GeneratedAt = &generatedAt
+	}
+	for _, query := range report.Queries {
+		newReport.Queries = append(newReport.Queries, &model.ImpactQueryReport{
+			PanelID:      query.PanelID,
+			PanelName:    query.PanelName,
+			QueryID:      query.QueryID,
+			Query:        query.Query,
+			Series:       query.Series,
+			Baseline:     &model.ImpactWindowStats{Samples: query.Baseline.Samples, Mean: query.Baseline.Mean, P95: query.Baseline.P95},
+			Chaos:        &model.ImpactWindowStats{Samples: query.Chaos.Samples, Mean: query.Chaos.Mean, P95: query.Chaos.P95},
+			Recovery:     &model.ImpactWindowStats{Samples: query.Recovery.Samples, Mean: query.Recovery.Mean, P95: query.Recovery.P95},
+			Deviation:    query.Deviation,
+			RecoveryTime: query.RecoveryTime,
+			Significant:  query.Significant,
+		})
+	}
+	return newReport
+}
+
+// ScheduleImpactReport records a pending impact report for a completed run of a workflow with an impact analysis and
+// generates it once the recovery window has elapsed. Runs without chaos experiments get no report
+func ScheduleImpactReport(workflowID string, workflowRunID string, executionData types.ExecutionData) {
+	workflow, err := dbOperationsWorkflow.GetWorkflow(bson.D{{"workflow_id", workflowID}, {"isRemoved", false}})
+	if err != nil {
+		log.Print("Could not get workflow ", workflowID, " : ", err)
+		return
+	}
+	if workflow.ImpactAnalysis == nil {
+		return
+	}
+	for _, run := range workflow.WorkflowRuns {
+		if run.WorkflowRunID == workflowRunID && run.ImpactReport != nil {
+			return
+		}
+	}
+
+	chaosStart, chaosEnd, ok := chaosInterval(executionData)
+	if !ok {
+		return
+	}
+
+	report := &dbSchemaWorkflow.ImpactReport{
+		Status:        model.ImpactReportStatusPending,
+		BaselineStart: strconv.FormatInt(chaosStart.Unix()-int64(impactWindow(workflow.ImpactAnalysis.BaselineWindow)), 10),
+		ChaosStart:    strconv.FormatInt(chaosStart.Unix(), 10),
+		ChaosEnd:      strconv.FormatInt(chaosEnd.Unix(), 10),
+		RecoveryEnd:   strconv.FormatInt(chaosEnd.Unix()+int64(impactWindow(workflow.ImpactAnalysis.RecoveryWindow)), 10),
+		Queries:       []*dbSchemaWorkflow.ImpactQueryReport{},
+	}
+	if err := saveImpactReport(workflowID, workflowRunID, report); err != nil {
+		log.Print("Could not save the impact report of run ", workflowRunID, " : ", err)
+		return
+	}
+
+	generateImpactReportAfterRecovery(workflow, workflowRunID, report)
+}
+
+// ResumeImpactReports generates the pending impact reports of the runs completed before a restart of the server
+func ResumeImpactReports() {
+	workflows, err := dbOperationsWorkflow.GetWorkflows(bson.D{
+		{"isRemoved", false},
+		{"workflow_runs.impact_report.status", model.ImpactReportStatusPending},
+	})
+	if err != nil {
+		log.Print("Could not get the pending impact reports : ", err)
+		return
+	}
+
+	for _, workflow := range workflows {
+		for _, run := range workflow.WorkflowRuns {
+			if run.ImpactReport != nil && run.ImpactReport.Status == model.ImpactReportStatusPending {
+				go generateImpactReportAfterRecovery(workflow, run.WorkflowRunID, run.ImpactReport)
+			}
+		}
+	}
+}
+
+func generateImpactReportAfterRecovery(workflow dbSchemaWorkflow.ChaosWorkFlowInput, workflowRunID string, report *dbSchemaWorkflow.ImpactReport) {
+	recoveryEnd, err := strconv.ParseInt(report.RecoveryEnd, 10, 64)
+	if err == nil {
+		// the samples of the end of the recovery window need to be scraped first
+		time.Sleep(time.Until(time.Unix(recoveryEnd, 0).Add(defaultImpactStep * time.Second)))
+	}
+
+	if workflow.ImpactAnalysis == nil {
+		report.Status = model.ImpactReportStatusFailed
+		report.Reason = "the workflow has no impact analysis"
+	} else if err := generateImpactReport(workflow.ImpactAnalysis, report); err != nil {
+		report.Status = model.ImpactReportStatusFailed
+		report.Reason = err.Error()
+	} else {
+		report.Status = model.ImpactReportStatusCompleted
+	}
+	report.GeneratedAt = strconv.FormatInt(time.Now().Unix(), 10)
+
+	if err := saveImpactReport(workflow.WorkflowID, workflowRunID, report); err != nil {
+		log.Print("Could not save the impact report of run ", workflowRunID, " : ", err)
+	}
+}
+
+// generateImpactReport queries the panels of the impact analysis over the windows of the report and compares the
+// statistics of every series
+func generateImpactReport(impactAnalysis *dbSchemaWorkflow.ImpactAnalysis, report *dbSchemaWorkflow.ImpactReport) error {
+	baselineStart, err := strconv.ParseInt(report.BaselineStart, 10, 64)
+	if err != nil {
+		return err
+	}
+	chaosStart, err := strconv.ParseInt(report.ChaosStart, 10, 64)
+	if err != nil {
+		return err
+	}
+	chaosEnd, err := strconv.ParseInt(report.ChaosEnd, 10, 64)
+	if err != nil {
+		return err
+	}
+	recoveryEnd, err := strconv.ParseInt(report.RecoveryEnd, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	dashboard, err := dbOperationsAnalytics.GetDashboard(bson.D{{"db_id", impactAnalysis.DbID}, {"is_removed", false}})
+	if err != nil {
+		return errors.New("dashboard not found : " + err.Error())
+	}
+	ds, err := dbOperationsAnalytics.GetDataSourceByID(dashboard.DsID)
+	if err != nil {
+		return errors.New("data source not found : " + err.Error())
+	}
+	driver, err := analyticsOps.GetDriver(ds.DsType)
+	if err != nil {
+		return err
+	}
+	config, err := analyticsOps.NewDataSourceConfig(ds)
+	if err != nil {
+		return errors.New("could not decrypt the credentials of the data source : " + err.Error())
+	}
+
+	step := ds.ScrapeInterval
+	if step <= 0 {
+		step = defaultImpactStep
+	}
+	dsDetails := &analytics.PromDSDetails{
+		URL:    ds.DsURL,
+		Start:  report.BaselineStart,
+		End:    report.RecoveryEnd,
+		Config: config,
+	}
+	variables := analyticsOps.VariableValues(&dashboard, nil, driver, dsDetails, baselineStart, recoveryEnd)
+	threshold := float64(defaultImpactDeviationThreshold)
+	if impactAnalysis.DeviationThreshold != nil {
+		threshold = *impactAnalysis.DeviationThreshold
+	}
+
+	panels, err := impactPanels(dashboard, impactAnalysis.PanelIDs)
+	if err != nil {
+		return err
+	}
+
+	var (
+		queries   int
+		lastError error
+	)
+	report.Queries = []*dbSchemaWorkflow.ImpactQueryReport{}
+	report.Significant = false
+	for _, panel := range panels {
+		for _, promQuery := range panel.PromQueries {
+			if promQuery == nil || promQuery.PromQueryName == nil || *promQuery.PromQueryName == "" {
+				continue
+			}
+			queries++
+
+			query := analyticsOps.SubstituteVariables(*promQuery.PromQueryName, variables)
+			legend := promQuery.Legend
+			if legend != nil {
+				substituted := analyticsOps.SubstituteVariables(*legend, variables)
+				legend = &substituted
+			}
+
+			response, err := driver.Query(analytics.PromQuery{
+				Queryid:   promQuery.Queryid,
+				Query:     query,
+				Legend:    legend,
+				Minstep:   step,
+				DSdetails: dsDetails,
+			}, "metrics")
+			if err != nil {
+				log.Print("Could not query ", promQuery.Queryid, " of panel ", panel.PanelName, " for the impact report : ", err)
+				lastError = err
+				continue
+			}
+			metrics, ok := response.(*model.MetricsPromResponse)
+			if !ok {
+				continue
+			}
+
+			for i, tsv := range metrics.Tsvs {
+				if i >= maxImpactSeries {
+					break
+				}
+				series := query
+				if i < len(metrics.Legends) && metrics.Legends[i] != nil && *metrics.Legends[i] != "" {
+					series = *metrics.Legends[i]
+				}
+
+				queryReport := analyseSeries(tsv, baselineStart, chaosStart, chaosEnd, recoveryEnd, threshold)
+				queryReport.PanelID = panel.PanelID
+				queryReport.PanelName = panel.PanelName
+				queryReport.QueryID = promQuery.Queryid
+				queryReport.Query = query
+				queryReport.Series = series
+
+				report.Queries = append(report.Queries, queryReport)
+				report.Significant = report.Significant || queryReport.Significant
+			}
+		}
+	}
+
+	if queries == 0 {
+		return errors.New("the panels of the impact analysis have no queries")
+	}
+	if len(report.Queries) == 0 && lastError != nil {
+		return errors.New("failed to query the data source : " + lastError.Error())
+	}
+	return nil
+}
+
+// impactPanels returns the panels of the dashboard which are analysed, every panel when no panel is selected
+func impactPanels(dashboard dbSchemaAnalytics.DashBoard, panelIDs []string) ([]*dbSchemaAnalytics.Panel, error) {
+	var panels []*dbSchemaAnalytics.Panel
+	for _, panelGroup := range dashboard.PanelGroups {
+		groupPanels, err := dbOperationsAnalytics.ListPanel(bson.D{{"panel_group_id", panelGroup.PanelGroupID}, {"is_removed", false}})
+		if err != nil {
+			return nil, err
+		}
+		for _, panel := range groupPanels {
+			if len(panelIDs) == 0 || containsString(panelIDs, panel.PanelID) {
+				panels = append(panels, panel)
+			}
+		}
+	}
+	if len(panels) == 0 {
+		return nil, errors.New("none of the panels of the impact analysis were found")
+	}
+	return panels, nil
+}
+
+// analyseSeries compares the samples of a series before, during and after the chaos, the timestamps of the samples
+// are in milliseconds
+func analyseSeries(tsv []*model.MetricsTimeStampValue, baselineStart, chaosStart, chaosEnd, recoveryEnd int64, threshold float64) *dbSchemaWorkflow.ImpactQueryReport {
+	var baseline, chaos, recovery []float64
+	var recoveryTimestamps []float64
+	for _, sample := range tsv {
+		if sample == nil || sample.Date == nil || sample.Value == nil || math.IsNaN(*sample.Value) {
+			continue
+		}
+		timestamp := *sample.Date / 1000
+		switch {
+		case timestamp < float64(baselineStart) || timestamp > float64(recoveryEnd):
+		case timestamp < float64(chaosStart):
+			baseline = append(baseline, *sample.Value)
+		case timestamp <= float64(chaosEnd):
+			chaos = append(chaos, *sample.Value)
+		default:
+			recovery = append(recovery, *sample.Value)
+			recoveryTimestamps = append(recoveryTimestamps, timestamp)
+		}
+	}
+
+	report := &dbSchemaWorkflow.ImpactQueryReport{
+		Baseline: windowStats(baseline),
+		Chaos:    windowStats(chaos),
+		Recovery: windowStats(recovery),
+	}
+	if report.Baseline.Mean == nil {
+		return report
+	}
+
+	baselineMean := *report.Baseline.Mean
+	withinBaseline := func(value float64) bool {
+		return math.Abs(value-baselineMean) <= threshold/100*math.Abs(baselineMean)
+	}
+
+	deviated := false
+	if report.Chaos.Mean != nil {
+		if baselineMean != 0 {
+			deviation := (*report.Chaos.Mean - baselineMean) / math.Abs(baselineMean) * 100
+			report.Deviation = &deviation
+		}
+		deviated = !withinBaseline(*report.Chaos.Mean)
+	}
+
+	if !deviated {
+		recoveryTime := 0
+		report.RecoveryTime = &recoveryTime
+	} else {
+		for i, value := range recovery {
+			if withinBaseline(value) {
+				recoveryTime := int(recoveryTimestamps[i] - float64(chaosEnd))
+				report.RecoveryTime = &recoveryTime
+				break
+			}
+		}
+	}
+
+	report.Significant = deviated || report.RecoveryTime == nil
+	return report
+}
+
+func windowStats(values []float64) dbSchemaWorkflow.ImpactWindowStats {
+	stats := dbSchemaWorkflow.ImpactWindowStats{Samples: len(values)}
+	if len(values) == 0 {
+		return stats
+	}
+
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	// nearest rank percentile
+	p95 := sorted[int(math.Ceil(0.95*float64(len(sorted))))-1]
+
+	stats.Mean = &mean
+	stats.P95 = &p95
+	return stats
+}
+
+// chaosInterval returns the time from the start of the first chaos experiment of the run to the end of the last one
+func chaosInterval(executionData types.ExecutionData) (time.Time, time.Time, bool) {
+	var start, end time.Time
+	for _, node := range executionData.Nodes {
+		if node.ChaosExp == nil {
+			continue
+		}
+		startedAt, err := unixTime(node.StartedAt)
+		if err != nil {
+			continue
+		}
+		finishedAt, err := unixTime(node.FinishedAt)
+		if err != nil {
+			// experiments stopped with the run have no end
+			finishedAt, err = unixTime(executionData.FinishedAt)
+			if err != nil {
+				finishedAt = time.Now()
+			}
+		}
+
+		if start.IsZero() || startedAt.Before(start) {
+			start = startedAt
+		}
+		if finishedAt.After(end) {
+			end = finishedAt
+		}
+	}
+	return start, end, !start.IsZero() && !end.Before(start)
+}
+
+// unixTime parses the times of the execution data, the subscriber sends them as unix seconds and empty when unset
+func unixTime(value string) (time.Time, error) {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if seconds <= 0 {
+		return time.Time{}, errors.New("time " + value + " isn't set")
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+func saveImpactReport(workflowID string, workflowRunID string, report *dbSchemaWorkflow.ImpactReport) error {
+	query := bson.D{{"workflow_id", workflowID}, {"workflow_runs.workflow_run_id", workflowRunID}}
+	update := bson.D{{"$set", bson.D{{"workflow_runs.$.impact_report", report}}}}
+	return dbOperationsWorkflow.UpdateChaosWorkflow(query, update)
+}
+
+func impactWindow(window *int) int {
+	if window != nil && *window >= minImpactWindow {
+		return *window
+	}
+	return defaultImpactWindow
+}
@@ -43,6 +43,21 @@ func ProcessWorkflow(workflow *model.ChaosWorkFlowInput) (*model.ChaosWorkFlowIn
 		return nil, nil, errors.New("cluster doesn't belong to this project")
 	}
 
+	err = ValidateSteadyStateGates(workflow.ProjectID, workflow.SteadyStateGates)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = ValidateGuardrails(workflow.ProjectID, workflow.Guardrails)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = ValidateImpactAnalysis(workflow.ProjectID, workflow.ImpactAnalysis)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	wfType := dbSchemaWorkflow.Workflow
 	var (
 		workflow_id = uuid.New().String()
@@ -112,6 +127,15 @@ func ProcessWorkflow(workflow *model.ChaosWorkFlowInput) (*model.ChaosWorkFlowIn
 
 // ProcessWorkflowCreation creates new workflow entry and sends the workflow to the specific agent for execution
 func ProcessWorkflowCreation(input *model.ChaosWorkFlowInput, wfType *dbSchemaWorkflow.ChaosWorkflowType, r *store.StateData) error {
+	return processWorkflowCreation(input, wfType, true, r)
+}
+
+// ProcessWorkflowImport creates new workflow entry without running it, only schedules are sent to the agent
+func ProcessWorkflowImport(input *model.ChaosWorkFlowInput, wfType *dbSchemaWorkflow.ChaosWorkflowType, r *store.StateData) error {
+	return processWorkflowCreation(input, wfType, false, r)
+}
+
+func processWorkflowCreation(input *model.ChaosWorkFlowInput, wfType *dbSchemaWorkflow.ChaosWorkflowType, run bool, r *store.StateData) error {
 	var Weightages []*dbSchemaWorkflow.WeightagesInput
 	if input.Weightages != nil {
 		copier.Copy(&Weightages, &input.Weightages)
@@ -140,6 +164,9 @@ func ProcessWorkflowCreation(input *model.ChaosWorkFlowInput, wfType *dbSchemaWo
 		UpdatedAt:           strconv.FormatInt(time.Now().Unix(), 10),
 		WorkflowRuns:        []*dbSchemaWorkflow.ChaosWorkflowRun{},
 		IsRemoved:           false,
+		SteadyStateGates:    NewSteadyStateGates(input.SteadyStateGates),
+		Guardrails:          NewGuardrails(input.Guardrails),
+		ImpactAnalysis:      NewImpactAnalysis(input.ImpactAnalysis),
 	}
 
 	err = dbOperationsWorkflow.InsertChaosWorkflow(newChaosWorkflow)
@@ -148,7 +175,26 @@ func ProcessWorkflowCreation(input *model.ChaosWorkFlowInput, wfType *dbSchemaWo
 	}
 
 	if r != nil {
-		SendWorkflowToSubscriber(input, nil, "create", r)
+		if isSchedule(input.WorkflowManifest) {
+			manifest, err := clusterManifest(input.WorkflowManifest, newChaosWorkflow.SteadyStateGates)
+			if err != nil {
+				return err
+			}
+			SendWorkflowToSubscriber(&model.ChaosWorkFlowInput{
+				WorkflowManifest: manifest,
+				ProjectID:        input.ProjectID,
+				ClusterID:        input.ClusterID,
+			}, nil, "create", r)
+			return nil
+		}
+		// imported workflows don't run
+		if !run {
+			return nil
+		}
+		_, err = DispatchWorkflowRun(newChaosWorkflow, r)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -162,7 +208,18 @@ func ProcessWorkflowUpdate(workflow *model.ChaosWorkFlowInput, wfType *dbSchemaW
 	}
 
 	query := bson.D{{"workflow_id", workflow.WorkflowID}, {"project_id", workflow.ProjectID}}
-	update := bson.D{{"$set", bson.D{{"workflow_manifest", workflow.WorkflowManifest}, {"type", *wfType}, {"cronSyntax", workflow.CronSyntax}, {"workflow_name", workflow.WorkflowName}, {"workflow_description", workflow.WorkflowDescription}, {"isCustomWorkflow", workflow.IsCustomWorkflow}, {"weightages", Weightages}, {"updated_at", strconv.FormatInt(time.Now().Unix(), 10)}}}}
+	fields := bson.D{{"workflow_manifest", workflow.WorkflowManifest}, {"type", *wfType}, {"cronSyntax", workflow.CronSyntax}, {"workflow_name", workflow.WorkflowName}, {"workflow_description", workflow.WorkflowDescription}, {"isCustomWorkflow", workflow.IsCustomWorkflow}, {"weightages", Weightages}, {"updated_at", strconv.FormatInt(time.Now().Unix(), 10)}}
+	// the steady state gates, guardrails and impact analysis are kept when the update doesn't set them, e.g. on GitOps syncs
+	if workflow.SteadyStateGates != nil {
+		fields = append(fields, bson.E{"steady_state_gates", NewSteadyStateGates(workflow.SteadyStateGates)})
+	}
+	if workflow.Guardrails != nil {
+		fields = append(fields, bson.E{"guardrails", NewGuardrails(workflow.Guardrails)})
+	}
+	if workflow.ImpactAnalysis != nil {
+		fields = append(fields, bson.E{"impact_analysis", NewImpactAnalysis(workflow.ImpactAnalysis)})
+	}
+	update := bson.D{{"$set", fields}}
 
 	err := dbOperationsWorkflow.UpdateChaosWorkflow(query, update)
 	if err != nil {
@@ -170,7 +227,23 @@ func ProcessWorkflowUpdate(workflow *model.ChaosWorkFlowInput, wfType *dbSchemaW
 	}
 
 	if r != nil {
-		SendWorkflowToSubscriber(workflow, nil, "update", r)
+		gates := NewSteadyStateGates(workflow.SteadyStateGates)
+		if workflow.SteadyStateGates == nil {
+			stored, err := dbOperationsWorkflow.GetWorkflow(query)
+			if err != nil {
+				return err
+			}
+			gates = stored.SteadyStateGates
+		}
+		manifest, err := clusterManifest(workflow.WorkflowManifest, gates)
+		if err != nil {
+			return err
+		}
+		SendWorkflowToSubscriber(&model.ChaosWorkFlowInput{
+			WorkflowManifest: manifest,
+			ProjectID:        workflow.ProjectID,
+			ClusterID:        workflow.ClusterID,
+		}, nil, "update", r)
 	}
 	return nil
 }
@@ -0,0 +1,255 @@
+package ops
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/argoproj/argo/pkg/apis/workflow/v1alpha1"
+	chaosTypes "github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	scheduleTypes "github.com/litmuschaos/chaos-scheduler/pkg/apis/litmuschaos/v1alpha1"
+	store "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/data-store"
+	dbOperationsWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+	dbSchemaWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.mongodb.org/mongo-driver/bson"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	scheduleInterval = 30 * time.Second
+	// scheduled runs which couldn't be dispatched in time, e.g. while the server was down, are not run
+	scheduleStartingDeadline = 5 * time.Minute
+)
+
+// isSchedule reports whether the manifest is a cron workflow or a chaos schedule
+func isSchedule(manifest string) bool {
+	kind := strings.ToLower(gjson.Get(manifest, "kind").String())
+	return kind == "cronworkflow" || kind == "chaosschedule"
+}
+
+// clusterManifest returns the manifest of the workflow sent to its cluster. The steady state gates of scheduled runs
+// have to pass before the runs start, so the schedules with gates are suspended on the cluster and the server
+// dispatches their runs
+func clusterManifest(manifest string, gates []*dbSchemaWorkflow.SteadyStateGate) (string, error) {
+	if len(gates) == 0 {
+		return manifest, nil
+	}
+	switch strings.ToLower(gjson.Get(manifest, "kind").String()) {
+	case "cronworkflow":
+		return sjson.Set(manifest, "spec.suspend", true)
+	case "chaosschedule":
+		return sjson.Set(manifest, "spec.scheduleState", string(scheduleTypes.StateHalted))
+	}
+	return manifest, nil
+}
+
+// RecurringScheduledRuns dispatches the due runs of the cron workflows and chaos schedules with steady state gates
+func RecurringScheduledRuns(r *store.StateData) {
+	for {
+		workflows, err := dbOperationsWorkflow.GetWorkflows(bson.D{{"isRemoved", false}, {"steady_state_gates.0", bson.D{{"$exists", true}}}})
+		if err != nil {
+			log.Print("Could not get the scheduled workflows : ", err)
+		}
+
+		now := time.Now()
+		for _, workflow := range workflows {
+			if !isSchedule(workflow.WorkflowManifest) {
+				continue
+			}
+			scheduledAt, err := lastScheduledTime(workflow, now)
+			if err != nil {
+				log.Print("Could not get the schedule of workflow ", workflow.WorkflowID, " : ", err)
+				continue
+			}
+			if !isDue(workflow, scheduledAt, now) {
+				continue
+			}
+
+			claimed, err := dbOperationsWorkflow.ClaimScheduledRun(workflow.WorkflowID, workflow.LastScheduledAt, strconv.FormatInt(scheduledAt.Unix(), 10))
+			if err != nil {
+				log.Print("Could not claim the scheduled run of workflow ", workflow.WorkflowID, " : ", err)
+				continue
+			}
+			if claimed {
+				go dispatchScheduledRun(workflow, scheduledAt, r)
+			}
+		}
+
+		time.Sleep(scheduleInterval)
+	}
+}
+
+// isDue reports whether the run scheduled at scheduledAt wasn't dispatched yet and its starting deadline didn't pass
+func isDue(workflow dbSchemaWorkflow.ChaosWorkFlowInput, scheduledAt time.Time, now time.Time) bool {
+	if scheduledAt.IsZero() || now.Sub(scheduledAt) > scheduleStartingDeadline {
+		return false
+	}
+	if lastScheduledAt, err := strconv.ParseInt(workflow.LastScheduledAt, 10, 64); err == nil {
+		return scheduledAt.Unix() > lastScheduledAt
+	}
+	createdAt, err := strconv.ParseInt(workflow.CreatedAt, 10, 64)
+	return err == nil && scheduledAt.Unix() >= createdAt
+}
+
+// lastScheduledTime returns the last time a run of the schedule was due at or before now, the zero time when none
+// was due or the schedule was suspended by the user
+func lastScheduledTime(workflow dbSchemaWorkflow.ChaosWorkFlowInput, now time.Time) (time.Time, error) {
+	manifest := workflow.WorkflowManifest
+	if strings.ToLower(gjson.Get(manifest, "kind").String()) == "cronworkflow" {
+		if gjson.Get(manifest, "spec.suspend").Bool() {
+			return time.Time{}, nil
+		}
+		schedule, err := ParseCron(gjson.Get(manifest, "spec.schedule").String())
+		if err != nil {
+			return time.Time{}, err
+		}
+		loc, err := CronLocation(manifest)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return schedule.Prev(now.In(loc)), nil
+	}
+
+	var chaosSchedule scheduleTypes.ChaosSchedule
+	if err := json.Unmarshal([]byte(manifest), &chaosSchedule); err != nil {
+		return time.Time{}, errors.New("failed to unmarshal chaos schedule : " + err.Error())
+	}
+	return chaosScheduleTime(chaosSchedule, workflow.CreatedAt, now)
+}
+
+// chaosScheduleTime returns the last time a run of the chaos schedule was due at or before now. Repeated schedules
+// run at the start of every interval within their time range, work hours and work days
+func chaosScheduleTime(chaosSchedule scheduleTypes.ChaosSchedule, createdAt string, now time.Time) (time.Time, error) {
+	if state := chaosSchedule.Spec.ScheduleState; state != "" && state != scheduleTypes.StateActive {
+		return time.Time{}, nil
+	}
+
+	created, err := strconv.ParseInt(createdAt, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	schedule := chaosSchedule.Spec.Schedule
+	switch {
+	case schedule.Now:
+		return time.Unix(created, 0), nil
+	case schedule.Once != nil:
+		if schedule.Once.ExecutionTime.Time.After(now) {
+			return time.Time{}, nil
+		}
+		return schedule.Once.ExecutionTime.Time, nil
+	case schedule.Repeat == nil:
+		return time.Time{}, errors.New("chaos schedule has no schedule")
+	}
+
+	repeat := schedule.Repeat
+	interval, err := time.ParseDuration(repeat.Properties.MinChaosInterval)
+	if err != nil || interval <= 0 {
+		return time.Time{}, errors.New("invalid minChaosInterval " + repeat.Properties.MinChaosInterval)
+	}
+	start, end := time.Unix(created, 0), time.Time{}
+	if repeat.TimeRange != nil {
+		if repeat.TimeRange.StartTime != nil {
+			start = repeat.TimeRange.StartTime.Time
+		}
+		if repeat.TimeRange.EndTime != nil {
+			end = repeat.TimeRange.EndTime.Time
+		}
+	}
+	if now.Before(start) {
+		return time.Time{}, nil
+	}
+	scheduledAt := start.Add(now.Sub(start) / interval * interval).UTC()
+	if !end.IsZero() && scheduledAt.After(end) {
+		return time.Time{}, nil
+	}
+
+	if repeat.WorkHours != nil && repeat.WorkHours.IncludedHours != "" {
+		hours, err := parseCronField(repeat.WorkHours.IncludedHours, hourField)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if hours&(1<<uint(scheduledAt.Hour())) == 0 {
+			return time.Time{}, nil
+		}
+	}
+	if repeat.WorkDays != nil && repeat.WorkDays.IncludedDays != "" {
+		days, err := parseCronField(repeat.WorkDays.IncludedDays, dowField)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if days&(1<<7) != 0 {
+			days |= 1
+		}
+		if days&(1<<uint(scheduledAt.Weekday())) == 0 {
+			return time.Time{}, nil
+		}
+	}
+	return scheduledAt, nil
+}
+
+// dispatchScheduledRun creates the run of the schedule due at scheduledAt from its template, the run is sent to the
+// cluster after the steady state gates pass
+func dispatchScheduledRun(workflow dbSchemaWorkflow.ChaosWorkFlowInput, scheduledAt time.Time, r *store.StateData) {
+	manifest, err := scheduledRunManifest(workflow.WorkflowManifest, scheduledAt)
+	if err != nil {
+		log.Print("Could not create the scheduled run of workflow ", workflow.WorkflowID, " : ", err)
+		return
+	}
+
+	workflow.WorkflowManifest = manifest
+	if _, err := DispatchWorkflowRun(workflow, r); err != nil {
+		log.Print("Could not dispatch the scheduled run of workflow ", workflow.WorkflowID, " : ", err)
+	}
+}
+
+// scheduledRunManifest returns the workflow, or the chaos engine, created by the schedule for its run at scheduledAt.
+// The run is named after the schedule and the scheduled time like the runs created on the cluster
+func scheduledRunManifest(manifest string, scheduledAt time.Time) (string, error) {
+	var run interface{}
+	suffix := "-" + strconv.FormatInt(scheduledAt.Unix(), 10)
+
+	if strings.ToLower(gjson.Get(manifest, "kind").String()) == "cronworkflow" {
+		var cronWorkflow v1alpha1.CronWorkflow
+		if err := json.Unmarshal([]byte(manifest), &cronWorkflow); err != nil {
+			return "", errors.New("failed to unmarshal cron workflow : " + err.Error())
+		}
+		workflow := v1alpha1.Workflow{
+			TypeMeta: v1.TypeMeta{APIVersion: cronWorkflow.APIVersion, Kind: "Workflow"},
+			ObjectMeta: v1.ObjectMeta{
+				Name:      cronWorkflow.Name + suffix,
+				Namespace: cronWorkflow.Namespace,
+			},
+			Spec: cronWorkflow.Spec.WorkflowSpec,
+		}
+		if cronWorkflow.Spec.WorkflowMetadata != nil {
+			workflow.Labels = cronWorkflow.Spec.WorkflowMetadata.Labels
+			workflow.Annotations = cronWorkflow.Spec.WorkflowMetadata.Annotations
+		}
+		run = workflow
+	} else {
+		var chaosSchedule scheduleTypes.ChaosSchedule
+		if err := json.Unmarshal([]byte(manifest), &chaosSchedule); err != nil {
+			return "", errors.New("failed to unmarshal chaos schedule : " + err.Error())
+		}
+		run = chaosTypes.ChaosEngine{
+			TypeMeta: v1.TypeMeta{APIVersion: chaosSchedule.APIVersion, Kind: "ChaosEngine"},
+			ObjectMeta: v1.ObjectMeta{
+				Name:      chaosSchedule.Name + suffix,
+				Namespace: chaosSchedule.Namespace,
+				Labels:    chaosSchedule.Labels,
+			},
+			Spec: chaosSchedule.Spec.EngineTemplateSpec,
+		}
+	}
+
+	out, err := json.Marshal(run)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
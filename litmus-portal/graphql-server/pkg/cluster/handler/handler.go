@@ -207,6 +207,9 @@ func QueryGetClusters(projectID string, clusterType *string) ([]*model.Cluster,
 			}
 		}
 		newCluster.LastWorkflowTimestamp = lastWorkflowTimestamp
+		if cluster.NotifierTokenUpdatedAt == "" {
+			newCluster.NotifierTokenUpdatedAt = nil
+		}
 		newCluster.NoOfSchedules = func(i int) *int { return &i }(totalNoOfSchedules)
 
 		newClusters = append(newClusters, &newCluster)
@@ -260,3 +263,51 @@ func SendRequestToSubscriber(subscriberRequest clusterOps.SubscriberRequests, r
 
 	r.Mutex.Unlock()
 }
+
+// IssueNotifierToken generates a new gitops notifier token for the cluster and sends it to the connected subscriber,
+// the previous token of the cluster is invalidated
+func IssueNotifierToken(clusterID string, r store.StateData) error {
+	r.Mutex.Lock()
+	_, connected := r.ConnectedCluster[clusterID]
+	r.Mutex.Unlock()
+	if !connected {
+		return errors.New("cluster is not connected, the notifier token can't be delivered")
+	}
+
+	token, hash, err := clusterOps.GenerateNotifierToken(clusterID)
+	if err != nil {
+		return errors.New("failed to generate notifier token : " + err.Error())
+	}
+
+	query := bson.D{{"cluster_id", clusterID}}
+	update := bson.D{{"$set", bson.D{{"notifier_token_hash", hash}, {"notifier_token_updated_at", strconv.FormatInt(time.Now().Unix(), 10)}}}}
+	err = dbOperationsCluster.UpdateCluster(query, update)
+	if err != nil {
+		return errors.New("failed to store notifier token : " + err.Error())
+	}
+
+	SendRequestToSubscriber(clusterOps.SubscriberRequests{
+		RequestType:  clusterOps.NotifierTokenRequestType,
+		ExternalData: &token,
+		ClusterID:    clusterID,
+	}, r)
+	return nil
+}
+
+// RotateNotifierToken replaces the gitops notifier token of a cluster of the project
+func RotateNotifierToken(projectID, clusterID string, r store.StateData) (bool, error) {
+	cluster, err := dbOperationsCluster.GetCluster(clusterID)
+	if err != nil {
+		return false, err
+	}
+	if cluster.ProjectID != projectID || cluster.IsRemoved {
+		return false, errors.New("cluster " + clusterID + " doesn't belong to this project")
+	}
+
+	err = IssueNotifierToken(clusterID, r)
+	if err != nil {
+		return false, err
+	}
+	log.Print("Notifier token rotated for cluster: ", clusterID)
+	return true, nil
+}
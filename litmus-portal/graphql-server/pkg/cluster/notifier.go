@@ -0,0 +1,58 @@
+package cluster
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	dbOperationsCluster "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/cluster"
+	dbSchemaCluster "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/cluster"
+)
+
+// NotifierTokenRequestType is the request type of the cluster action delivering a notifier token to the subscriber
+const NotifierTokenRequestType = "notifier_token"
+
+// notifier tokens have the form <cluster_id>.<secret>, only the hash of the whole token is stored
+const notifierTokenSeparator = "."
+
+var errInvalidNotifierToken = errors.New("invalid notifier token")
+
+// GenerateNotifierToken returns a new notifier token for the cluster along with the hash to be stored
+func GenerateNotifierToken(clusterID string) (string, string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	token := clusterID + notifierTokenSeparator + base64.RawURLEncoding.EncodeToString(secret)
+	return token, HashNotifierToken(token), nil
+}
+
+// HashNotifierToken returns the hash of a notifier token as stored in the DB
+func HashNotifierToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyNotifierToken returns the cluster a notifier token was issued to. The token only authorizes
+// gitops notifications of that cluster, it can't be used in place of the access key
+func VerifyNotifierToken(token string) (*dbSchemaCluster.Cluster, error) {
+	index := strings.LastIndex(token, notifierTokenSeparator)
+	if index <= 0 {
+		return nil, errInvalidNotifierToken
+	}
+	cluster, err := dbOperationsCluster.GetCluster(token[:index])
+	if err != nil {
+		return nil, errInvalidNotifierToken
+	}
+	if !cluster.IsRegistered || cluster.IsRemoved || cluster.NotifierTokenHash == "" {
+		return nil, errInvalidNotifierToken
+	}
+	if subtle.ConstantTimeCompare([]byte(cluster.NotifierTokenHash), []byte(HashNotifierToken(token))) != 1 {
+		return nil, errInvalidNotifierToken
+	}
+	return &cluster, nil
+}
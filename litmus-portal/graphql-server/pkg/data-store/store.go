@@ -14,6 +14,7 @@ type StateData struct {
 	WorkflowLog          map[string]chan *model.PodLogResponse
 	KubeObjectData       map[string]chan *model.KubeObjectResponse
 	DashboardData        map[string]chan *model.DashboardPromResponse
+	ReadinessCheckData   map[string]chan *model.ReadinessCheckResult
 	Mutex                *sync.Mutex
 }
 
@@ -25,6 +26,7 @@ func NewStore() *StateData {
 		WorkflowLog:          make(map[string]chan *model.PodLogResponse),
 		KubeObjectData:       make(map[string]chan *model.KubeObjectResponse),
 		DashboardData:        make(map[string]chan *model.DashboardPromResponse),
+		ReadinessCheckData:   make(map[string]chan *model.ReadinessCheckResult),
 		Mutex:                &sync.Mutex{},
 	}
 }
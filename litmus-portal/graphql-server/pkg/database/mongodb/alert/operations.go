@@ -0,0 +1,70 @@
+package alert
+
+import (
+	"context"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// InsertAlertRule adds an alert rule
+func InsertAlertRule(ctx context.Context, rule AlertRule) error {
+	err := mongodb.Operator.Create(ctx, mongodb.AlertRuleCollection, rule)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetAlertRule returns the alert rule matching the query
+func GetAlertRule(ctx context.Context, query bson.D) (AlertRule, error) {
+	result, err := mongodb.Operator.Get(ctx, mongodb.AlertRuleCollection, query)
+	if err != nil {
+		return AlertRule{}, err
+	}
+
+	var rule AlertRule
+	err = result.Decode(&rule)
+	if err != nil {
+		return AlertRule{}, err
+	}
+
+	return rule, nil
+}
+
+// ListAlertRules returns the alert rules matching the query
+func ListAlertRules(ctx context.Context, query bson.D) ([]AlertRule, error) {
+	results, err := mongodb.Operator.List(ctx, mongodb.AlertRuleCollection, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []AlertRule
+	err = results.All(ctx, &rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// UpdateAlertRule updates the alert rule matching the query and returns whether a rule matched it
+func UpdateAlertRule(ctx context.Context, query bson.D, update bson.D) (bool, error) {
+	result, err := mongodb.Operator.Update(ctx, mongodb.AlertRuleCollection, query, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+// DeleteAlertRule removes the alert rule and returns whether it existed
+func DeleteAlertRule(ctx context.Context, ruleID string) (bool, error) {
+	result, err := mongodb.Operator.Delete(ctx, mongodb.AlertRuleCollection, bson.D{{"rule_id", ruleID}})
+	if err != nil {
+		return false, err
+	}
+
+	return result.DeletedCount > 0, nil
+}
@@ -0,0 +1,36 @@
+package alert
+
+// AlertRule is a condition on the runs of the workflows of a project, evaluated when runs complete and periodically
+type AlertRule struct {
+	RuleID              string          `bson:"rule_id"`
+	ProjectID           string          `bson:"project_id"`
+	Name                string          `bson:"name"`
+	Type                string          `bson:"type"`
+	WorkflowID          string          `bson:"workflow_id"`
+	Threshold           *float64        `bson:"threshold,omitempty"`
+	ExperimentName      string          `bson:"experiment_name"`
+	ConsecutiveFailures int             `bson:"consecutive_failures"`
+	GracePeriod         int             `bson:"grace_period"`
+	PendingFor          int             `bson:"pending_for"`
+	Enabled             bool            `bson:"enabled"`
+	State               string          `bson:"state"`
+	StateChangedAt      string          `bson:"state_changed_at"`
+	LastEvaluatedAt     string          `bson:"last_evaluated_at"`
+	LastFiredAt         string          `bson:"last_fired_at"`
+	Instances           []AlertInstance `bson:"instances"`
+	// Revision is incremented on every update, evaluations only save the rule if it didn't change meanwhile
+	Revision  int64  `bson:"revision"`
+	CreatedAt string `bson:"created_at"`
+	UpdatedAt string `bson:"updated_at"`
+}
+
+// AlertInstance is a workflow, or an experiment of a workflow, for which the condition of the rule holds
+type AlertInstance struct {
+	WorkflowID     string `bson:"workflow_id"`
+	WorkflowName   string `bson:"workflow_name"`
+	ExperimentName string `bson:"experiment_name"`
+	State          string `bson:"state"`
+	Message        string `bson:"message"`
+	ActiveSince    string `bson:"active_since"`
+	FiredAt        string `bson:"fired_at"`
+}
@@ -135,6 +135,19 @@ func UpdateDataSource(query bson.D, update bson.D) error {
 	return nil
 }
 
+// UpdateDataSourceHealth updates the health of the data source matching the query and returns whether it matched
+func UpdateDataSourceHealth(query bson.D, update bson.D) (bool, error) {
+	ctx, cancel := context.WithTimeout(backgroundContext, 10*time.Second)
+	defer cancel()
+
+	result, err := mongodb.Operator.Update(ctx, mongodb.DataSourceCollection, query, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
 // UpdateDashboard takes query and update parameters to update the dashboard details in the database
 func UpdateDashboard(query bson.D, update bson.D) error {
 	ctx, _ := context.WithTimeout(backgroundContext, 10*time.Second)
@@ -149,7 +162,8 @@ func UpdateDashboard(query bson.D, update bson.D) error {
 
 // UpdatePanel takes query and update parameters to update the dashboard panel details in the database
 func UpdatePanel(query bson.D, update bson.D) error {
-	ctx, _ := context.WithTimeout(backgroundContext, 10*time.Second)
+	ctx, cancel := context.WithTimeout(backgroundContext, 10*time.Second)
+	defer cancel()
 
 	updateResult, err := mongodb.Operator.Update(ctx, mongodb.PanelCollection, query, update)
 	if err != nil {
@@ -165,7 +179,8 @@ func UpdatePanel(query bson.D, update bson.D) error {
 
 // GetDashboard takes a query parameter to retrieve the dashboard details from the database
 func GetDashboard(query bson.D) (DashBoard, error) {
-	ctx, _ := context.WithTimeout(backgroundContext, 10*time.Second)
+	ctx, cancel := context.WithTimeout(backgroundContext, 10*time.Second)
+	defer cancel()
 
 	var dashboard DashBoard
 	result, err := mongodb.Operator.Get(ctx, mongodb.DashboardCollection, query)
@@ -1,21 +1,49 @@
 package analytics
 
 type DataSource struct {
-	DsID              string  `bson:"ds_id"`
-	DsName            string  `bson:"ds_name"`
-	DsType            string  `bson:"ds_type"`
-	DsURL             string  `bson:"ds_url"`
-	AccessType        string  `bson:"access_type"`
-	AuthType          string  `bson:"auth_type"`
-	BasicAuthUsername *string `bson:"basic_auth_username"`
-	BasicAuthPassword *string `bson:"basic_auth_password"`
-	ScrapeInterval    int     `bson:"scrape_interval"`
-	QueryTimeout      int     `bson:"query_timeout"`
-	HTTPMethod        string  `bson:"http_method"`
-	CreatedAt         string  `bson:"created_at"`
-	UpdatedAt         string  `bson:"updated_at"`
-	ProjectID         string  `bson:"project_id"`
-	IsRemoved         bool    `bson:"is_removed"`
+	DsID               string             `bson:"ds_id"`
+	DsName             string             `bson:"ds_name"`
+	DsType             string             `bson:"ds_type"`
+	DsURL              string             `bson:"ds_url"`
+	AccessType         string             `bson:"access_type"`
+	AuthType           string             `bson:"auth_type"`
+	BasicAuthUsername  *string            `bson:"basic_auth_username"`
+	BasicAuthPassword  *string            `bson:"basic_auth_password"`
+	BearerToken        *string            `bson:"bearer_token,omitempty"`
+	CustomHeaders      []DataSourceHeader `bson:"custom_headers,omitempty"`
+	CACert             *string            `bson:"ca_cert,omitempty"`
+	ClientCert         *string            `bson:"client_cert,omitempty"`
+	ClientKey          *string            `bson:"client_key,omitempty"`
+	InsecureSkipVerify bool               `bson:"insecure_skip_verify"`
+	TenantID           *string            `bson:"tenant_id,omitempty"`
+	TenantHeader       *string            `bson:"tenant_header,omitempty"`
+	ScrapeInterval     int                `bson:"scrape_interval"`
+	QueryTimeout       int                `bson:"query_timeout"`
+	HTTPMethod         string             `bson:"http_method"`
+	CreatedAt          string             `bson:"created_at"`
+	UpdatedAt          string             `bson:"updated_at"`
+	ProjectID          string             `bson:"project_id"`
+	IsRemoved          bool               `bson:"is_removed"`
+	Health             *DataSourceHealth  `bson:"health,omitempty"`
+}
+
+// DataSourceHealth is the state of the background health checks of a data source
+type DataSourceHealth struct {
+	Status       string                  `bson:"status"`
+	CheckedAt    string                  `bson:"checked_at"`
+	LastChangeAt string                  `bson:"last_change_at"`
+	History      []DataSourceHealthEvent `bson:"history"`
+}
+
+// DataSourceHealthEvent is a change of the health status of a data source, the status lasts until the next event
+type DataSourceHealthEvent struct {
+	Status    string `bson:"status"`
+	StartedAt string `bson:"started_at"`
+}
+
+type DataSourceHeader struct {
+	Name  string `bson:"name"`
+	Value string `bson:"value"`
 }
 
 type DashBoard struct {
@@ -28,6 +56,7 @@ type DashBoard struct {
 	ChaosEventQueryTemplate   string                `bson:"chaos_event_query_template"`
 	ChaosVerdictQueryTemplate string                `bson:"chaos_verdict_query_template"`
 	ApplicationMetadataMap    []ApplicationMetadata `bson:"application_metadata_map"`
+	Variables                 []DashboardVariable   `bson:"variables,omitempty"`
 	CreatedAt                 string                `bson:"created_at"`
 	UpdatedAt                 string                `bson:"updated_at"`
 	ViewedAt                  string                `bson:"viewed_at"`
@@ -50,6 +79,17 @@ type Resource struct {
 	Names []*string `bson:"names"`
 }
 
+type DashboardVariable struct {
+	Name       string   `bson:"name"`
+	Label      *string  `bson:"label"`
+	Type       string   `bson:"type"`
+	Query      *string  `bson:"query"`
+	Options    []string `bson:"options"`
+	Current    []string `bson:"current"`
+	Multi      *bool    `bson:"multi"`
+	IncludeAll *bool    `bson:"include_all"`
+}
+
 type PanelGroup struct {
 	PanelGroupName string `bson:"panel_group_name"`
 	PanelGroupID   string `bson:"panel_group_id"`
@@ -82,6 +122,7 @@ type PromQuery struct {
 	Legend        *string `bson:"Legend"`
 	Resolution    *string `bson:"resolution"`
 	Minstep       *string `bson:"minstep"`
+	Instant       *bool   `bson:"instant,omitempty"`
 	Line          *bool   `bson:"line"`
 	CloseArea     *bool   `bson:"close_area"`
 }
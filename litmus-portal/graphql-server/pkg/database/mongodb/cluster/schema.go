@@ -22,4 +22,7 @@ type Cluster struct {
 	Token              string  `bson:"token"`
 	IsRemoved          bool    `bson:"is_removed"`
 	NodeSelector       *string `json:"node_selector"`
+	// NotifierTokenHash is the sha256 hash of the token used by the agent to trigger gitops workflows
+	NotifierTokenHash      string `bson:"notifier_token_hash"`
+	NotifierTokenUpdatedAt string `bson:"notifier_token_updated_at"`
 }
@@ -42,6 +42,14 @@ func (g *GetCollectionStruct) getCollection(collectionType int) (*mongo.Collecti
 		return mongoClient.(*MongoClient).DashboardCollection, nil
 	case ImageRegistryCollection:
 		return mongoClient.(*MongoClient).ImageRegistryCollection, nil
+	case EventTrackerPolicyCollection:
+		return mongoClient.(*MongoClient).EventTrackerPolicyCollection, nil
+	case ReportScheduleCollection:
+		return mongoClient.(*MongoClient).ReportScheduleCollection, nil
+	case ReportCollection:
+		return mongoClient.(*MongoClient).ReportCollection, nil
+	case AlertRuleCollection:
+		return mongoClient.(*MongoClient).AlertRuleCollection, nil
 	default:
 		return nil, errors.New("unknown collection name")
 	}
@@ -0,0 +1,61 @@
+package eventtracker
+
+import (
+	"context"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// InsertPolicy adds a policy
+func InsertPolicy(ctx context.Context, policy EventTrackerPolicy) error {
+	err := mongodb.Operator.Create(ctx, mongodb.EventTrackerPolicyCollection, policy)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdatePolicy updates the policy matching the query, the policy is inserted if upsert is set and it doesn't exist
+func UpdatePolicy(ctx context.Context, query bson.D, update bson.D, upsert bool) error {
+	_, err := mongodb.Operator.Update(ctx, mongodb.EventTrackerPolicyCollection, query, update, options.Update().SetUpsert(upsert))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetPolicy returns the policy matching the query
+func GetPolicy(ctx context.Context, query bson.D) (EventTrackerPolicy, error) {
+	result, err := mongodb.Operator.Get(ctx, mongodb.EventTrackerPolicyCollection, query)
+	if err != nil {
+		return EventTrackerPolicy{}, err
+	}
+
+	var policy EventTrackerPolicy
+	err = result.Decode(&policy)
+	if err != nil {
+		return EventTrackerPolicy{}, err
+	}
+
+	return policy, nil
+}
+
+// ListPolicies returns the policies matching the query
+func ListPolicies(ctx context.Context, query bson.D) ([]EventTrackerPolicy, error) {
+	results, err := mongodb.Operator.List(ctx, mongodb.EventTrackerPolicyCollection, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []EventTrackerPolicy
+	err = results.All(ctx, &policies)
+	if err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
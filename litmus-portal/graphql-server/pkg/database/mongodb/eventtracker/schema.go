@@ -0,0 +1,50 @@
+package eventtracker
+
+// EventTrackerPolicy is an EventTrackerPolicy of an agent, policies created with kubectl are added once their status
+// is reported by the event-tracker
+type EventTrackerPolicy struct {
+	PolicyID  string        `bson:"policy_id"`
+	ProjectID string        `bson:"project_id"`
+	ClusterID string        `bson:"cluster_id"`
+	Name      string        `bson:"name"`
+	Spec      string        `bson:"spec"`
+	Managed   bool          `bson:"managed"`
+	Status    *PolicyStatus `bson:"status"`
+	CreatedAt string        `bson:"created_at"`
+	UpdatedAt string        `bson:"updated_at"`
+	IsRemoved bool          `bson:"is_removed"`
+}
+
+// PolicyStatus is the status of a policy as reported by the event-tracker
+type PolicyStatus struct {
+	Evaluations        []PolicyEvaluation `bson:"evaluations" json:"evaluations"`
+	Conditions         []PolicyCondition  `bson:"conditions" json:"conditions"`
+	EvaluationCount    int64              `bson:"evaluation_count" json:"evaluation_count"`
+	PassedCount        int64              `bson:"passed_count" json:"passed_count"`
+	TriggeredCount     int64              `bson:"triggered_count" json:"triggered_count"`
+	SkippedCount       int64              `bson:"skipped_count" json:"skipped_count"`
+	LastEvaluationTime string             `bson:"last_evaluation_time" json:"last_evaluation_time"`
+	LastTriggerTime    string             `bson:"last_trigger_time" json:"last_trigger_time"`
+	ReportedAt         string             `bson:"reported_at" json:"-"`
+}
+
+// PolicyEvaluation is an evaluation of a policy
+type PolicyEvaluation struct {
+	ID           string `bson:"id" json:"id"`
+	TimeStamp    string `bson:"time_stamp" json:"time_stamp"`
+	Resource     string `bson:"resource" json:"resource"`
+	ResourceName string `bson:"resource_name" json:"resource_name"`
+	Result       string `bson:"result" json:"result"`
+	WorkflowID   string `bson:"workflow_id" json:"workflow_id"`
+	IsTriggered  string `bson:"is_triggered" json:"is_triggered"`
+	SkipReason   string `bson:"skip_reason" json:"skip_reason"`
+}
+
+// PolicyCondition is a condition of a policy
+type PolicyCondition struct {
+	Type               string `bson:"type" json:"type"`
+	Status             string `bson:"status" json:"status"`
+	Reason             string `bson:"reason" json:"reason"`
+	Message            string `bson:"message" json:"message"`
+	LastTransitionTime string `bson:"last_transition_time" json:"lastTransitionTime"`
+}
@@ -24,6 +24,10 @@ const (
 	PanelCollection
 	DashboardCollection
 	ImageRegistryCollection
+	EventTrackerPolicyCollection
+	ReportScheduleCollection
+	ReportCollection
+	AlertRuleCollection
 )
 
 // MongoInterface requires a MongoClient that implements the Initialize method to create the Mongo DB client
@@ -35,35 +39,43 @@ type MongoInterface interface {
 
 // MongoClient structure contains all the Database collections and the instance of the Database
 type MongoClient struct {
-	Database                   *mongo.Database
-	ClusterCollection          *mongo.Collection
-	UserCollection             *mongo.Collection
-	ProjectCollection          *mongo.Collection
-	WorkflowCollection         *mongo.Collection
-	WorkflowTemplateCollection *mongo.Collection
-	GitOpsCollection           *mongo.Collection
-	MyHubCollection            *mongo.Collection
-	DataSourceCollection       *mongo.Collection
-	PanelCollection            *mongo.Collection
-	DashboardCollection        *mongo.Collection
-	ImageRegistryCollection    *mongo.Collection
+	Database                     *mongo.Database
+	ClusterCollection            *mongo.Collection
+	UserCollection               *mongo.Collection
+	ProjectCollection            *mongo.Collection
+	WorkflowCollection           *mongo.Collection
+	WorkflowTemplateCollection   *mongo.Collection
+	GitOpsCollection             *mongo.Collection
+	MyHubCollection              *mongo.Collection
+	DataSourceCollection         *mongo.Collection
+	PanelCollection              *mongo.Collection
+	DashboardCollection          *mongo.Collection
+	ImageRegistryCollection      *mongo.Collection
+	EventTrackerPolicyCollection *mongo.Collection
+	ReportScheduleCollection     *mongo.Collection
+	ReportCollection             *mongo.Collection
+	AlertRuleCollection          *mongo.Collection
 }
 
 var (
 	Client MongoInterface = &MongoClient{}
 
 	collections = map[int]string{
-		ClusterCollection:          "cluster-collection",
-		UserCollection:             "user",
-		ProjectCollection:          "project",
-		WorkflowCollection:         "workflow-collection",
-		WorkflowTemplateCollection: "workflow-template",
-		GitOpsCollection:           "gitops-collection",
-		MyHubCollection:            "myhub",
-		DataSourceCollection:       "datasource-collection",
-		PanelCollection:            "panel-collection",
-		DashboardCollection:        "dashboard-collection",
-		ImageRegistryCollection:    "image-registry-collection",
+		ClusterCollection:            "cluster-collection",
+		UserCollection:               "user",
+		ProjectCollection:            "project",
+		WorkflowCollection:           "workflow-collection",
+		WorkflowTemplateCollection:   "workflow-template",
+		GitOpsCollection:             "gitops-collection",
+		MyHubCollection:              "myhub",
+		DataSourceCollection:         "datasource-collection",
+		PanelCollection:              "panel-collection",
+		DashboardCollection:          "dashboard-collection",
+		ImageRegistryCollection:      "image-registry-collection",
+		EventTrackerPolicyCollection: "eventtracker-policy-collection",
+		ReportScheduleCollection:     "report-schedule-collection",
+		ReportCollection:             "report-collection",
+		AlertRuleCollection:          "alert-rule-collection",
 	}
 
 	dbName            = "litmus"
@@ -94,7 +106,8 @@ func (m *MongoClient) Initialize() *MongoClient {
 		logrus.Fatal(err)
 	}
 
-	ctx, _ := context.WithTimeout(backgroundContext, ConnectionTimeout)
+	ctx, cancel := context.WithTimeout(backgroundContext, ConnectionTimeout)
+	defer cancel()
 
 	// Check the connection
 	err = client.Ping(ctx, nil)
@@ -153,4 +166,66 @@ func (m *MongoClient) initAllCollection() {
 	m.PanelCollection = m.Database.Collection(collections[PanelCollection])
 	m.DashboardCollection = m.Database.Collection(collections[DashboardCollection])
 	m.ImageRegistryCollection = m.Database.Collection(collections[ImageRegistryCollection])
+	m.EventTrackerPolicyCollection = m.Database.Collection(collections[EventTrackerPolicyCollection])
+	_, err = m.EventTrackerPolicyCollection.Indexes().CreateMany(backgroundContext, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{"cluster_id", 1},
+				{"name", 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	if err != nil {
+		logrus.Fatal("Error Creating Index for EventTrackerPolicy Collection : ", err)
+	}
+
+	m.ReportScheduleCollection = m.Database.Collection(collections[ReportScheduleCollection])
+	_, err = m.ReportScheduleCollection.Indexes().CreateMany(backgroundContext, []mongo.IndexModel{
+		{
+			Keys: bson.M{
+				"project_id": 1,
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	if err != nil {
+		logrus.Fatal("Error Creating Index for ReportSchedule Collection : ", err)
+	}
+
+	m.ReportCollection = m.Database.Collection(collections[ReportCollection])
+	_, err = m.ReportCollection.Indexes().CreateMany(backgroundContext, []mongo.IndexModel{
+		{
+			Keys: bson.M{
+				"report_id": 1,
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.M{
+				"project_id": 1,
+			},
+		},
+	})
+	if err != nil {
+		logrus.Fatal("Error Creating Index for Report Collection : ", err)
+	}
+
+	m.AlertRuleCollection = m.Database.Collection(collections[AlertRuleCollection])
+	_, err = m.AlertRuleCollection.Indexes().CreateMany(backgroundContext, []mongo.IndexModel{
+		{
+			Keys: bson.M{
+				"rule_id": 1,
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.M{
+				"project_id": 1,
+			},
+		},
+	})
+	if err != nil {
+		logrus.Fatal("Error Creating Index for AlertRule Collection : ", err)
+	}
 }
@@ -1,6 +1,9 @@
 package myhub
 
-import "github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+import (
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/secrets"
+)
 
 // MyHub ...
 type MyHub struct {
@@ -26,20 +29,27 @@ type MyHub struct {
 func (myhub *MyHub) GetOutputMyHub() *model.MyHub {
 
 	return &model.MyHub{
-		ID:            myhub.ID,
-		ProjectID:     myhub.ProjectID,
-		RepoURL:       myhub.RepoURL,
-		RepoBranch:    myhub.RepoBranch,
-		HubName:       myhub.HubName,
-		IsPrivate:     myhub.IsPrivate,
-		UserName:      myhub.UserName,
-		Password:      myhub.Password,
-		AuthType:      model.AuthType(myhub.AuthType),
-		Token:         myhub.Token,
-		IsRemoved:     myhub.IsRemoved,
-		SSHPrivateKey: myhub.SSHPrivateKey,
-		CreatedAt:     myhub.CreatedAt,
-		UpdatedAt:     myhub.UpdatedAt,
-		LastSyncedAt:  myhub.LastSyncedAt,
+		ID:           myhub.ID,
+		ProjectID:    myhub.ProjectID,
+		RepoURL:      myhub.RepoURL,
+		RepoBranch:   myhub.RepoBranch,
+		HubName:      myhub.HubName,
+		IsPrivate:    myhub.IsPrivate,
+		UserName:     myhub.UserName,
+		AuthType:     model.AuthType(myhub.AuthType),
+		Secrets:      myhub.GetSecretsInfo(),
+		IsRemoved:    myhub.IsRemoved,
+		CreatedAt:    myhub.CreatedAt,
+		UpdatedAt:    myhub.UpdatedAt,
+		LastSyncedAt: myhub.LastSyncedAt,
+	}
+}
+
+// GetSecretsInfo returns the redacted view of the hub credentials
+func (myhub *MyHub) GetSecretsInfo() []*model.SecretInfo {
+	return []*model.SecretInfo{
+		secrets.Describe("Token", myhub.Token),
+		secrets.Describe("Password", myhub.Password),
+		secrets.Describe("SSHPrivateKey", myhub.SSHPrivateKey),
 	}
 }
@@ -0,0 +1,114 @@
+package report
+
+import (
+	"context"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UpdateSchedule updates the schedule matching the query, the schedule is inserted if upsert is set and it doesn't exist.
+// It returns whether a schedule matched the query
+func UpdateSchedule(ctx context.Context, query bson.D, update bson.D, upsert bool) (bool, error) {
+	result, err := mongodb.Operator.Update(ctx, mongodb.ReportScheduleCollection, query, update, options.Update().SetUpsert(upsert))
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0 || result.UpsertedCount > 0, nil
+}
+
+// GetSchedule returns the schedule matching the query
+func GetSchedule(ctx context.Context, query bson.D) (ReportSchedule, error) {
+	result, err := mongodb.Operator.Get(ctx, mongodb.ReportScheduleCollection, query)
+	if err != nil {
+		return ReportSchedule{}, err
+	}
+
+	var schedule ReportSchedule
+	err = result.Decode(&schedule)
+	if err != nil {
+		return ReportSchedule{}, err
+	}
+
+	return schedule, nil
+}
+
+// ListSchedules returns the schedules matching the query
+func ListSchedules(ctx context.Context, query bson.D) ([]ReportSchedule, error) {
+	results, err := mongodb.Operator.List(ctx, mongodb.ReportScheduleCollection, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var schedules []ReportSchedule
+	err = results.All(ctx, &schedules)
+	if err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+// DeleteSchedule removes the schedule of the project
+func DeleteSchedule(ctx context.Context, projectID string) error {
+	_, err := mongodb.Operator.Delete(ctx, mongodb.ReportScheduleCollection, bson.D{{"project_id", projectID}})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// InsertReport adds a report
+func InsertReport(ctx context.Context, report Report) error {
+	err := mongodb.Operator.Create(ctx, mongodb.ReportCollection, report)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetReport returns the report matching the query
+func GetReport(ctx context.Context, query bson.D) (Report, error) {
+	result, err := mongodb.Operator.Get(ctx, mongodb.ReportCollection, query)
+	if err != nil {
+		return Report{}, err
+	}
+
+	var report Report
+	err = result.Decode(&report)
+	if err != nil {
+		return Report{}, err
+	}
+
+	return report, nil
+}
+
+// ListReports returns the reports matching the query
+func ListReports(ctx context.Context, query bson.D) ([]Report, error) {
+	results, err := mongodb.Operator.List(ctx, mongodb.ReportCollection, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []Report
+	err = results.All(ctx, &reports)
+	if err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+// DeleteReport removes the report
+func DeleteReport(ctx context.Context, reportID string) error {
+	_, err := mongodb.Operator.Delete(ctx, mongodb.ReportCollection, bson.D{{"report_id", reportID}})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package report
+
+// ReportSchedule is the schedule of the resiliency reports of a project
+type ReportSchedule struct {
+	ProjectID  string   `bson:"project_id"`
+	Enabled    bool     `bson:"enabled"`
+	Frequency  string   `bson:"frequency"`
+	Day        int      `bson:"day"`
+	Hour       int      `bson:"hour"`
+	Formats    []string `bson:"formats"`
+	Recipients []string `bson:"recipients"`
+	PeriodDays int      `bson:"period_days"`
+	NextRunAt  string   `bson:"next_run_at"`
+	LastRunAt  string   `bson:"last_run_at"`
+	LastError  string   `bson:"last_error"`
+	CreatedAt  string   `bson:"created_at"`
+	UpdatedAt  string   `bson:"updated_at"`
+}
+
+// Report is a generated resiliency report, the documents are stored for download
+type Report struct {
+	ReportID      string   `bson:"report_id"`
+	ProjectID     string   `bson:"project_id"`
+	Formats       []string `bson:"formats"`
+	PeriodStart   string   `bson:"period_start"`
+	PeriodEnd     string   `bson:"period_end"`
+	GeneratedAt   string   `bson:"generated_at"`
+	Scheduled     bool     `bson:"scheduled"`
+	Recipients    []string `bson:"recipients"`
+	Delivered     bool     `bson:"delivered"`
+	DeliveryError string   `bson:"delivery_error"`
+	HTML          string   `bson:"html,omitempty"`
+	PDF           []byte   `bson:"pdf,omitempty"`
+}
@@ -65,6 +65,7 @@ func UpdateWorkflowRun(workflowID string, wfRun ChaosWorkflowRun) (int, error) {
 				{"workflow_runs.$.execution_data", wfRun.ExecutionData},
 				{"workflow_runs.$.completed", wfRun.Completed},
 				{"workflow_runs.$.isRemoved", wfRun.IsRemoved},
+				{"workflow_runs.$.skip_reason", wfRun.SkipReason},
 			}}}
 
 		result, err := mongodb.Operator.Update(ctx, mongodb.WorkflowCollection, query, update)
@@ -166,3 +167,21 @@ func UpdateChaosWorkflow(query bson.D, update bson.D) error {
 
 	return nil
 }
+
+// ClaimScheduledRun sets the scheduled time of the last run dispatched by the server if it's still lastScheduledAt,
+// it returns false when the run was already claimed, e.g. by another replica of the server
+func ClaimScheduledRun(workflowID string, lastScheduledAt string, scheduledAt string) (bool, error) {
+	ctx, cancel := context.WithTimeout(backgroundContext, 10*time.Second)
+	defer cancel()
+
+	var last interface{} = lastScheduledAt
+	if lastScheduledAt == "" {
+		last = bson.D{{"$in", bson.A{"", nil}}}
+	}
+	query := bson.D{{"workflow_id", workflowID}, {"last_scheduled_at", last}}
+	result, err := mongodb.Operator.Update(ctx, mongodb.WorkflowCollection, query, bson.D{{"$set", bson.D{{"last_scheduled_at", scheduledAt}}}})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
@@ -1,5 +1,7 @@
 package workflow
 
+import "github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+
 type ChaosWorkflowType string
 
 const (
@@ -25,6 +27,90 @@ type ChaosWorkFlowInput struct {
 	ClusterType         string              `bson:"cluster_type"`
 	WorkflowRuns        []*ChaosWorkflowRun `bson:"workflow_runs"`
 	IsRemoved           bool                `bson:"isRemoved"`
+	GitOps              *GitOpsState        `bson:"gitops,omitempty"`
+	SteadyStateGates    []*SteadyStateGate  `bson:"steady_state_gates,omitempty"`
+	Guardrails          []*Guardrail        `bson:"guardrails,omitempty"`
+	ImpactAnalysis      *ImpactAnalysis     `bson:"impact_analysis,omitempty"`
+	// LastScheduledAt is the scheduled time of the last run dispatched by the server for schedules with steady state gates
+	LastScheduledAt string `bson:"last_scheduled_at,omitempty"`
+}
+
+// SteadyStateGate is a check evaluated before a run of the workflow is dispatched, the run is skipped when it fails
+type SteadyStateGate struct {
+	Name          string                    `bson:"name"`
+	Type          model.SteadyStateGateType `bson:"type"`
+	DataSourceID  *string                   `bson:"data_source_id,omitempty"`
+	Query         *string                   `bson:"query,omitempty"`
+	Operator      *string                   `bson:"operator,omitempty"`
+	Threshold     *float64                  `bson:"threshold,omitempty"`
+	Kind          *string                   `bson:"kind,omitempty"`
+	Namespace     *string                   `bson:"namespace,omitempty"`
+	ResourceName  *string                   `bson:"resource_name,omitempty"`
+	LabelSelector *string                   `bson:"label_selector,omitempty"`
+}
+
+// GitOpsState contains the sync state of a workflow with respect to the GitOps repository
+type GitOpsState struct {
+	// SyncedHash is the hash of the manifest last known to be identical in the DB and in git
+	SyncedHash string `bson:"synced_hash"`
+	// GitHash is the hash of the git manifest at the last sync, the git manifest is only processed again when it changes
+	GitHash     string            `bson:"git_hash,omitempty"`
+	DriftStatus model.DriftStatus `bson:"drift_status"`
+	GitManifest string            `bson:"git_manifest"`
+	DetectedAt  string            `bson:"detected_at"`
+}
+
+// Guardrail is a query evaluated while a run of the workflow is executing, the run is aborted when it is breached
+type Guardrail struct {
+	Name         string  `bson:"name"`
+	DataSourceID string  `bson:"data_source_id"`
+	Query        string  `bson:"query"`
+	Operator     string  `bson:"operator"`
+	Threshold    float64 `bson:"threshold"`
+	Interval     *int    `bson:"interval,omitempty"`
+	BreachCount  *int    `bson:"breach_count,omitempty"`
+}
+
+// ImpactAnalysis selects the dashboard panels which are compared before, during and after the chaos of the runs
+type ImpactAnalysis struct {
+	DbID               string   `bson:"db_id"`
+	PanelIDs           []string `bson:"panel_ids,omitempty"`
+	BaselineWindow     *int     `bson:"baseline_window,omitempty"`
+	RecoveryWindow     *int     `bson:"recovery_window,omitempty"`
+	DeviationThreshold *float64 `bson:"deviation_threshold,omitempty"`
+}
+
+// ImpactReport is the impact analysis of a completed run, it stays pending until its recovery window has elapsed
+type ImpactReport struct {
+	Status        model.ImpactReportStatus `bson:"status"`
+	Reason        string                   `bson:"reason,omitempty"`
+	BaselineStart string                   `bson:"baseline_start"`
+	ChaosStart    string                   `bson:"chaos_start"`
+	ChaosEnd      string                   `bson:"chaos_end"`
+	RecoveryEnd   string                   `bson:"recovery_end"`
+	GeneratedAt   string                   `bson:"generated_at,omitempty"`
+	Significant   bool                     `bson:"significant"`
+	Queries       []*ImpactQueryReport     `bson:"queries"`
+}
+
+type ImpactQueryReport struct {
+	PanelID      string            `bson:"panel_id"`
+	PanelName    string            `bson:"panel_name"`
+	QueryID      string            `bson:"query_id"`
+	Query        string            `bson:"query"`
+	Series       string            `bson:"series"`
+	Baseline     ImpactWindowStats `bson:"baseline"`
+	Chaos        ImpactWindowStats `bson:"chaos"`
+	Recovery     ImpactWindowStats `bson:"recovery"`
+	Deviation    *float64          `bson:"deviation,omitempty"`
+	RecoveryTime *int              `bson:"recovery_time,omitempty"`
+	Significant  bool              `bson:"significant"`
+}
+
+type ImpactWindowStats struct {
+	Samples int      `bson:"samples"`
+	Mean    *float64 `bson:"mean,omitempty"`
+	P95     *float64 `bson:"p95,omitempty"`
 }
 
 // WeightagesInput contains the required fields to be stored in the database for a weightages input
@@ -35,19 +121,23 @@ type WeightagesInput struct {
 
 // ChaosWorkflowRun contains the required fields to be stored in the database for a workflow run
 type ChaosWorkflowRun struct {
-	WorkflowRunID      string   `bson:"workflow_run_id"`
-	LastUpdated        string   `bson:"last_updated"`
-	Phase              string   `bson:"phase"`
-	ResiliencyScore    *float64 `bson:"resiliency_score,string,omitempty"`
-	ExperimentsPassed  *int     `bson:"experiments_passed,string,omitempty"`
-	ExperimentsFailed  *int     `bson:"experiments_failed,string,omitempty"`
-	ExperimentsAwaited *int     `bson:"experiments_awaited,string,omitempty"`
-	ExperimentsStopped *int     `bson:"experiments_stopped,string,omitempty"`
-	ExperimentsNA      *int     `bson:"experiments_na,string,omitempty"`
-	TotalExperiments   *int     `bson:"total_experiments,string,omitempty"`
-	ExecutionData      string   `bson:"execution_data"`
-	Completed          bool     `bson:"completed"`
-	IsRemoved          *bool    `bson:"isRemoved"`
+	WorkflowRunID      string        `bson:"workflow_run_id"`
+	LastUpdated        string        `bson:"last_updated"`
+	Phase              string        `bson:"phase"`
+	ResiliencyScore    *float64      `bson:"resiliency_score,string,omitempty"`
+	ExperimentsPassed  *int          `bson:"experiments_passed,string,omitempty"`
+	ExperimentsFailed  *int          `bson:"experiments_failed,string,omitempty"`
+	ExperimentsAwaited *int          `bson:"experiments_awaited,string,omitempty"`
+	ExperimentsStopped *int          `bson:"experiments_stopped,string,omitempty"`
+	ExperimentsNA      *int          `bson:"experiments_na,string,omitempty"`
+	TotalExperiments   *int          `bson:"total_experiments,string,omitempty"`
+	ExecutionData      string        `bson:"execution_data"`
+	Completed          bool          `bson:"completed"`
+	IsRemoved          *bool         `bson:"isRemoved"`
+	SkipReason         string        `bson:"skip_reason,omitempty"`
+	AbortedBy          string        `bson:"aborted_by,omitempty"`
+	AbortReason        string        `bson:"abort_reason,omitempty"`
+	ImpactReport       *ImpactReport `bson:"impact_report,omitempty"`
 }
 
 type AggregatedWorkflowRuns struct {
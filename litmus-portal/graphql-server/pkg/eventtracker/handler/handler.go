@@ -0,0 +1,307 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	clusterOps "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/cluster"
+	clusterHandler "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/cluster/handler"
+	store "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/data-store"
+	dbOperationsCluster "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/cluster"
+	dbSchemaCluster "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/cluster"
+	dbEventTracker "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/eventtracker"
+)
+
+const (
+	policyAPIVersion = "eventtracker.litmuschaos.io/v1"
+	policyKind       = "EventTrackerPolicy"
+)
+
+// CreatePolicy stores a new policy of the cluster and sends it to the subscriber of the cluster
+func CreatePolicy(ctx context.Context, input model.EventTrackerPolicyInput, r store.StateData) (*model.EventTrackerPolicy, error) {
+	cluster, err := getProjectCluster(input.ProjectID, input.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := parsePolicy(input)
+	if err != nil {
+		return nil, err
+	}
+
+	query := bson.D{{"cluster_id", input.ClusterID}, {"name", input.Name}}
+	existing, err := dbEventTracker.GetPolicy(ctx, query)
+	if err == nil && !existing.IsRemoved {
+		return nil, errors.New("EventTrackerPolicy " + input.Name + " already exists on the cluster")
+	} else if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	currentTime := strconv.FormatInt(time.Now().Unix(), 10)
+	policy := dbEventTracker.EventTrackerPolicy{
+		PolicyID:  uuid.New().String(),
+		ProjectID: input.ProjectID,
+		ClusterID: input.ClusterID,
+		Name:      input.Name,
+		Spec:      spec,
+		Managed:   true,
+		CreatedAt: currentTime,
+		UpdatedAt: currentTime,
+	}
+	// removed policies keep their document, it is replaced by the new policy
+	update := bson.D{{"$set", bson.D{
+		{"policy_id", policy.PolicyID},
+		{"project_id", policy.ProjectID},
+		{"spec", policy.Spec},
+		{"managed", policy.Managed},
+		{"status", nil},
+		{"created_at", policy.CreatedAt},
+		{"updated_at", policy.UpdatedAt},
+		{"is_removed", false},
+	}}}
+	err = dbEventTracker.UpdatePolicy(ctx, query, update, true)
+	if err != nil {
+		return nil, err
+	}
+
+	err = sendPolicy(cluster, policy, "create", r)
+	if err != nil {
+		return nil, err
+	}
+
+	return toModel(policy), nil
+}
+
+// UpdatePolicy replaces the spec of a policy of the cluster, policies created with kubectl are managed by the portal
+// once they are updated through it
+func UpdatePolicy(ctx context.Context, input model.EventTrackerPolicyInput, r store.StateData) (*model.EventTrackerPolicy, error) {
+	cluster, err := getProjectCluster(input.ProjectID, input.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := parsePolicy(input)
+	if err != nil {
+		return nil, err
+	}
+
+	query := bson.D{{"cluster_id", input.ClusterID}, {"name", input.Name}, {"is_removed", false}}
+	policy, err := dbEventTracker.GetPolicy(ctx, query)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.New("EventTrackerPolicy " + input.Name + " not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	policy.Spec = spec
+	policy.Managed = true
+	policy.UpdatedAt = strconv.FormatInt(time.Now().Unix(), 10)
+	update := bson.D{{"$set", bson.D{{"spec", policy.Spec}, {"managed", policy.Managed}, {"updated_at", policy.UpdatedAt}}}}
+	err = dbEventTracker.UpdatePolicy(ctx, query, update, false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = sendPolicy(cluster, policy, "update", r)
+	if err != nil {
+		return nil, err
+	}
+
+	return toModel(policy), nil
+}
+
+// DeletePolicy removes a policy from the cluster
+func DeletePolicy(ctx context.Context, projectID, clusterID, name string, r store.StateData) (bool, error) {
+	cluster, err := getProjectCluster(projectID, clusterID)
+	if err != nil {
+		return false, err
+	}
+
+	query := bson.D{{"cluster_id", clusterID}, {"name", name}, {"is_removed", false}}
+	policy, err := dbEventTracker.GetPolicy(ctx, query)
+	if err == mongo.ErrNoDocuments {
+		return false, errors.New("EventTrackerPolicy " + name + " not found")
+	} else if err != nil {
+		return false, err
+	}
+
+	update := bson.D{{"$set", bson.D{{"is_removed", true}, {"updated_at", strconv.FormatInt(time.Now().Unix(), 10)}}}}
+	err = dbEventTracker.UpdatePolicy(ctx, query, update, false)
+	if err != nil {
+		return false, err
+	}
+
+	err = sendPolicy(cluster, policy, "delete", r)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ListPolicies returns the policies of the project, or of one of its clusters, with the status reported by the agents
+func ListPolicies(ctx context.Context, projectID string, clusterID *string) ([]*model.EventTrackerPolicy, error) {
+	query := bson.D{{"project_id", projectID}, {"is_removed", false}}
+	if clusterID != nil && *clusterID != "" {
+		query = append(query, bson.E{"cluster_id", *clusterID})
+	}
+
+	policies, err := dbEventTracker.ListPolicies(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*model.EventTrackerPolicy{}
+	for _, policy := range policies {
+		result = append(result, toModel(policy))
+	}
+	return result, nil
+}
+
+// GetPolicy returns a policy of the cluster with the status reported by the agent
+func GetPolicy(ctx context.Context, projectID, clusterID, name string) (*model.EventTrackerPolicy, error) {
+	query := bson.D{{"project_id", projectID}, {"cluster_id", clusterID}, {"name", name}, {"is_removed", false}}
+	policy, err := dbEventTracker.GetPolicy(ctx, query)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.New("EventTrackerPolicy " + name + " not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return toModel(policy), nil
+}
+
+func getProjectCluster(projectID, clusterID string) (dbSchemaCluster.Cluster, error) {
+	cluster, err := dbOperationsCluster.GetCluster(clusterID)
+	if err != nil {
+		return dbSchemaCluster.Cluster{}, err
+	}
+	if cluster.ProjectID != projectID || cluster.IsRemoved {
+		return dbSchemaCluster.Cluster{}, errors.New("cluster " + clusterID + " doesn't belong to this project")
+	}
+	return cluster, nil
+}
+
+// parsePolicy validates the name and the spec of the policy and returns the spec as JSON
+func parsePolicy(input model.EventTrackerPolicyInput) (string, error) {
+	if errs := validation.IsDNS1123Subdomain(input.Name); len(errs) > 0 {
+		return "", errors.New("invalid EventTrackerPolicy name : " + strings.Join(errs, ", "))
+	}
+
+	data, err := yaml.YAMLToJSON([]byte(input.Spec))
+	if err != nil {
+		return "", errors.New("invalid EventTrackerPolicy spec : " + err.Error())
+	}
+
+	var spec map[string]interface{}
+	err = json.Unmarshal(data, &spec)
+	if err != nil || spec == nil {
+		return "", errors.New("EventTrackerPolicy spec must be an object")
+	}
+
+	data, err = json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// sendPolicy applies the policy on the cluster through the subscriber
+func sendPolicy(cluster dbSchemaCluster.Cluster, policy dbEventTracker.EventTrackerPolicy, requestType string, r store.StateData) error {
+	namespace := os.Getenv("AGENT_NAMESPACE")
+	if cluster.AgentNamespace != nil && *cluster.AgentNamespace != "" {
+		namespace = *cluster.AgentNamespace
+	}
+
+	manifest, err := json.Marshal(map[string]interface{}{
+		"apiVersion": policyAPIVersion,
+		"kind":       policyKind,
+		"metadata": map[string]interface{}{
+			"name":      policy.Name,
+			"namespace": namespace,
+		},
+		"spec": json.RawMessage(policy.Spec),
+	})
+	if err != nil {
+		return err
+	}
+
+	clusterHandler.SendRequestToSubscriber(clusterOps.SubscriberRequests{
+		K8sManifest: string(manifest),
+		RequestType: requestType,
+		ProjectID:   cluster.ProjectID,
+		ClusterID:   cluster.ClusterID,
+		Namespace:   namespace,
+	}, r)
+	return nil
+}
+
+func toModel(policy dbEventTracker.EventTrackerPolicy) *model.EventTrackerPolicy {
+	result := &model.EventTrackerPolicy{
+		PolicyID:  policy.PolicyID,
+		ProjectID: policy.ProjectID,
+		ClusterID: policy.ClusterID,
+		Name:      policy.Name,
+		Spec:      policy.Spec,
+		Managed:   policy.Managed,
+		CreatedAt: policy.CreatedAt,
+		UpdatedAt: policy.UpdatedAt,
+	}
+	if policy.Status == nil {
+		return result
+	}
+
+	status := &model.EventTrackerPolicyStatus{
+		Evaluations:        []*model.PolicyEvaluation{},
+		Conditions:         []*model.PolicyCondition{},
+		EvaluationCount:    int(policy.Status.EvaluationCount),
+		PassedCount:        int(policy.Status.PassedCount),
+		TriggeredCount:     int(policy.Status.TriggeredCount),
+		SkippedCount:       int(policy.Status.SkippedCount),
+		LastEvaluationTime: optionalString(policy.Status.LastEvaluationTime),
+		LastTriggerTime:    optionalString(policy.Status.LastTriggerTime),
+		ReportedAt:         optionalString(policy.Status.ReportedAt),
+	}
+	for _, evaluation := range policy.Status.Evaluations {
+		status.Evaluations = append(status.Evaluations, &model.PolicyEvaluation{
+			ID:           evaluation.ID,
+			TimeStamp:    evaluation.TimeStamp,
+			Resource:     evaluation.Resource,
+			ResourceName: evaluation.ResourceName,
+			Result:       evaluation.Result,
+			WorkflowID:   evaluation.WorkflowID,
+			IsTriggered:  evaluation.IsTriggered,
+			SkipReason:   optionalString(evaluation.SkipReason),
+		})
+	}
+	for _, condition := range policy.Status.Conditions {
+		status.Conditions = append(status.Conditions, &model.PolicyCondition{
+			Type:               condition.Type,
+			Status:             condition.Status,
+			Reason:             optionalString(condition.Reason),
+			Message:            optionalString(condition.Message),
+			LastTransitionTime: optionalString(condition.LastTransitionTime),
+		})
+	}
+	result.Status = status
+	return result
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
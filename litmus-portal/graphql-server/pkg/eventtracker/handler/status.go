@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/cluster"
+	dbSchemaCluster "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/cluster"
+	dbEventTracker "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/eventtracker"
+)
+
+const maxStatusReportSize = 1 << 20
+
+// StatusReport is the body of a policy status report of the event-tracker
+type StatusReport struct {
+	Name    string                       `json:"name"`
+	Spec    json.RawMessage              `json:"spec"`
+	Status  *dbEventTracker.PolicyStatus `json:"status"`
+	Deleted bool                         `json:"deleted"`
+}
+
+// StatusResponse is the body of a policy status report response
+type StatusResponse struct {
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// PolicyStatusHandler stores the status of a policy reported by the event-tracker of an agent. Requests are
+// authenticated with the notifier token of the agent, so that agents only report the policies of their cluster
+func PolicyStatusHandler(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if token == "" {
+		writeStatusResponse(w, http.StatusUnauthorized, StatusResponse{Error: "missing notifier token"})
+		return
+	}
+	cInfo, err := cluster.VerifyNotifierToken(token)
+	if err != nil {
+		writeStatusResponse(w, http.StatusUnauthorized, StatusResponse{Error: err.Error()})
+		return
+	}
+
+	var report StatusReport
+	err = json.NewDecoder(io.LimitReader(r.Body, maxStatusReportSize)).Decode(&report)
+	if err != nil || report.Name == "" {
+		writeStatusResponse(w, http.StatusBadRequest, StatusResponse{Error: "request body must contain the name of the policy"})
+		return
+	}
+
+	err = savePolicyStatus(r.Context(), cInfo, report)
+	if err != nil {
+		log.WithError(err).Error("failed to save the status of EventTrackerPolicy ", report.Name, " of cluster ", cInfo.ClusterID)
+		writeStatusResponse(w, http.StatusInternalServerError, StatusResponse{Error: err.Error()})
+		return
+	}
+
+	writeStatusResponse(w, http.StatusOK, StatusResponse{Message: "status of " + report.Name + " saved"})
+}
+
+// savePolicyStatus updates the policy with the reported status, policies created with kubectl are added
+func savePolicyStatus(ctx context.Context, cInfo *dbSchemaCluster.Cluster, report StatusReport) error {
+	currentTime := strconv.FormatInt(time.Now().Unix(), 10)
+	query := bson.D{{"cluster_id", cInfo.ClusterID}, {"name", report.Name}}
+
+	if report.Deleted {
+		update := bson.D{{"$set", bson.D{{"is_removed", true}, {"updated_at", currentTime}}}}
+		return dbEventTracker.UpdatePolicy(ctx, query, update, false)
+	}
+
+	set := bson.D{{"is_removed", false}}
+	if report.Status != nil {
+		report.Status.ReportedAt = currentTime
+		set = append(set, bson.E{"status", report.Status})
+	}
+	if len(report.Spec) > 0 {
+		set = append(set, bson.E{"spec", string(report.Spec)})
+	}
+
+	update := bson.D{
+		{"$set", set},
+		{"$setOnInsert", bson.D{
+			{"policy_id", uuid.New().String()},
+			{"project_id", cInfo.ProjectID},
+			{"managed", false},
+			{"created_at", currentTime},
+			{"updated_at", currentTime},
+		}},
+	}
+	return dbEventTracker.UpdatePolicy(ctx, query, update, true)
+}
+
+func writeStatusResponse(w http.ResponseWriter, code int, response StatusResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.WithError(err).Error("failed to write policy status response")
+	}
+}
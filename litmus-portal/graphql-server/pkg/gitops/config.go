@@ -0,0 +1,707 @@
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	dbOperationsImageRegistry "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/image_registry"
+	dbOperationsMyHub "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/myhub"
+	dbSchemaMyHub "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/myhub"
+	dbOperationsProject "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/project"
+	dbOperationsWorkflowTemplate "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflowtemplate"
+	myHubOps "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/myhub/ops"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/secrets"
+)
+
+// Project configuration is stored next to the workflows in a reserved directory of the project,
+// one YAML document per resource:
+//
+//	litmus/<project_id>/.litmus-config/templates/<template_name>.yaml   kind: ManifestTemplate
+//	litmus/<project_id>/.litmus-config/chaoshubs/<hub_name>.yaml        kind: ChaosHub
+//	litmus/<project_id>/.litmus-config/image-registry.yaml              kind: ImageRegistry
+//
+// The format is described in configReadme, which is also written to the directory.
+const (
+	ConfigDataPath    = ".litmus-config"
+	ConfigAPIVersion  = "litmuschaos.io/v1alpha1"
+	templatesDir      = "templates"
+	chaosHubsDir      = "chaoshubs"
+	imageRegistryFile = "image-registry.yaml"
+
+	KindManifestTemplate = "ManifestTemplate"
+	KindChaosHub         = "ChaosHub"
+	KindImageRegistry    = "ImageRegistry"
+)
+
+const configReadme = `# Litmus Project Configuration
+
+This directory is managed by the Litmus Portal GitOps sync. Changes made here are applied to the
+project and changes made in the portal are written back here. Every file holds a single resource:
+
+    apiVersion: litmuschaos.io/v1alpha1
+    kind: ManifestTemplate | ChaosHub | ImageRegistry
+    metadata:
+      name: <resource name>
+      id: <resource id, assigned by the portal, leave empty for new resources>
+    spec: ...
+
+## templates/<name>.yaml (ManifestTemplate)
+
+    spec:
+      description: <template description>
+      isCustomWorkflow: <true|false>
+      manifest: <workflow manifest, as a YAML or JSON string>
+
+## chaoshubs/<name>.yaml (ChaosHub)
+
+    spec:
+      repoURL: <git repository url>
+      repoBranch: <branch>
+      isPrivate: <true|false>
+      authType: <none|basic|token|ssh>
+      userName: <user name for basic auth>
+
+Credentials of private hubs are never stored in git, they have to be set from the portal.
+
+## image-registry.yaml (ImageRegistry)
+
+    spec:
+      registryName: <registry host>
+      repoName: <repository name>
+      registryType: <public|private>
+      secretName: <image pull secret name>
+      secretNamespace: <image pull secret namespace>
+      isDefault: <true|false>
+      enableRegistry: <true|false>
+
+The image registry is required by the project, deleting the file restores it from the portal.
+Deleting a template or hub file removes the resource from the project.
+`
+
+// ConfigMetadata identifies a project configuration resource
+type ConfigMetadata struct {
+	Name string `json:"name"`
+	ID   string `json:"id,omitempty"`
+}
+
+// ConfigResource is the serialized form of a project configuration resource
+type ConfigResource struct {
+	APIVersion string          `json:"apiVersion"`
+	Kind       string          `json:"kind"`
+	Metadata   ConfigMetadata  `json:"metadata"`
+	Spec       json.RawMessage `json:"spec"`
+}
+
+// TemplateSpec is the spec of a ManifestTemplate resource
+type TemplateSpec struct {
+	Description      string `json:"description,omitempty"`
+	IsCustomWorkflow bool   `json:"isCustomWorkflow"`
+	Manifest         string `json:"manifest"`
+}
+
+// ChaosHubSpec is the spec of a ChaosHub resource, credentials are never part of it
+type ChaosHubSpec struct {
+	RepoURL    string  `json:"repoURL"`
+	RepoBranch string  `json:"repoBranch"`
+	IsPrivate  bool    `json:"isPrivate"`
+	AuthType   string  `json:"authType,omitempty"`
+	UserName   *string `json:"userName,omitempty"`
+}
+
+// ImageRegistrySpec is the spec of an ImageRegistry resource
+type ImageRegistrySpec struct {
+	RegistryName    string  `json:"registryName"`
+	RepoName        string  `json:"repoName"`
+	RegistryType    string  `json:"registryType"`
+	SecretName      *string `json:"secretName,omitempty"`
+	SecretNamespace *string `json:"secretNamespace,omitempty"`
+	IsDefault       bool    `json:"isDefault"`
+	EnableRegistry  *bool   `json:"enableRegistry,omitempty"`
+}
+
+// NewConfigResource builds a resource of the given kind with the spec serialized
+func NewConfigResource(kind, name, id string, spec interface{}) (ConfigResource, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return ConfigResource{}, err
+	}
+	return ConfigResource{
+		APIVersion: ConfigAPIVersion,
+		Kind:       kind,
+		Metadata:   ConfigMetadata{Name: name, ID: id},
+		Spec:       data,
+	}, nil
+}
+
+// ParseConfigResource parses a YAML or JSON document into a resource
+func ParseConfigResource(data []byte) (ConfigResource, error) {
+	var resource ConfigResource
+	data, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return resource, err
+	}
+	err = json.Unmarshal(data, &resource)
+	if err != nil {
+		return resource, err
+	}
+	if resource.Kind == "" || resource.Metadata.Name == "" {
+		return resource, errors.New("kind and metadata.name are required")
+	}
+	return resource, nil
+}
+
+// canonicalResource returns a normalized JSON form of the resource used for comparisons
+func canonicalResource(resource ConfigResource) ([]byte, error) {
+	var spec interface{}
+	if len(resource.Spec) > 0 {
+		if err := json.Unmarshal(resource.Spec, &spec); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(map[string]interface{}{
+		"apiVersion": resource.APIVersion,
+		"kind":       resource.Kind,
+		"metadata":   resource.Metadata,
+		"spec":       spec,
+	})
+}
+
+// configFileName returns the file name used for a named resource
+func configFileName(name string) string {
+	return strings.NewReplacer("/", "-", "\\", "-").Replace(name) + ".yaml"
+}
+
+// configDir returns the reserved config directory of the project relative to the repository root
+func (c GitConfig) configDir() string {
+	return ProjectDataPath + "/" + c.ProjectID + "/" + ConfigDataPath
+}
+
+// isConfigFile checks whether the repository file belongs to the reserved config directory
+func (c GitConfig) isConfigFile(file string) bool {
+	return strings.HasPrefix(file, c.configDir()+"/")
+}
+
+// readConfigDir reads all the resources in a config sub directory, mapped by their repository path
+func (c GitConfig) readConfigDir(dir string) (map[string]ConfigResource, error) {
+	resources := map[string]ConfigResource{}
+	files, err := ioutil.ReadDir(c.LocalPath + "/" + dir)
+	if os.IsNotExist(err) {
+		return resources, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".yaml") {
+			continue
+		}
+		data, err := ioutil.ReadFile(c.LocalPath + "/" + dir + "/" + file.Name())
+		if err != nil {
+			return nil, err
+		}
+		resource, err := ParseConfigResource(data)
+		if err != nil {
+			log.Print("Skipping invalid config file : " + dir + "/" + file.Name() + " | " + err.Error())
+			continue
+		}
+		resources[dir+"/"+file.Name()] = resource
+	}
+	return resources, nil
+}
+
+// writeConfigFile writes the data to the repository file if its content differs, returns true if the file was written
+func (c GitConfig) writeConfigFile(file string, data []byte) (bool, error) {
+	path := c.LocalPath + "/" + file
+	existing, err := ioutil.ReadFile(path)
+	if err == nil && bytes.Equal(existing, data) {
+		return false, nil
+	}
+	err = os.MkdirAll(path[:strings.LastIndex(path, "/")], 0755)
+	if err != nil {
+		return false, err
+	}
+	return true, ioutil.WriteFile(path, data, 0644)
+}
+
+// writeConfigResource writes the resource to the repository file unless the file already holds the same resource
+func (c GitConfig) writeConfigResource(file string, resource ConfigResource) (bool, error) {
+	desired, err := canonicalResource(resource)
+	if err != nil {
+		return false, err
+	}
+	if data, err := ioutil.ReadFile(c.LocalPath + "/" + file); err == nil {
+		if existing, err := ParseConfigResource(data); err == nil {
+			if current, err := canonicalResource(existing); err == nil && bytes.Equal(current, desired) {
+				return false, nil
+			}
+		}
+	}
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return false, err
+	}
+	data, err = yaml.JSONToYAML(data)
+	if err != nil {
+		return false, err
+	}
+	return c.writeConfigFile(file, data)
+}
+
+// removeConfigFile removes the repository file from the worktree and the index
+func (c GitConfig) removeConfigFile(file string) error {
+	_, w, err := c.getRepositoryWorktreeReference()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Remove(file); err != nil {
+		return os.Remove(c.LocalPath + "/" + file)
+	}
+	return nil
+}
+
+// importConfigFile applies a config file changed in git to the DB, deleted files remove the resource from the project
+func importConfigFile(ctx context.Context, file string, config GitConfig) error {
+	rel := strings.TrimPrefix(file, config.configDir()+"/")
+	exists, err := PathExists(config.LocalPath + "/" + file)
+	if err != nil {
+		return err
+	}
+
+	var resource ConfigResource
+	if exists {
+		data, err := ioutil.ReadFile(config.LocalPath + "/" + file)
+		if err != nil {
+			return err
+		}
+		resource, err = ParseConfigResource(data)
+		if err != nil {
+			return errors.New("invalid config file : " + err.Error())
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(rel, templatesDir+"/"):
+		if !exists {
+			return removeTemplate(ctx, file, config)
+		}
+		return importTemplate(ctx, resource, config)
+	case strings.HasPrefix(rel, chaosHubsDir+"/"):
+		if !exists {
+			return removeChaosHub(ctx, file, config)
+		}
+		return importChaosHub(ctx, resource, config)
+	case rel == imageRegistryFile:
+		if !exists {
+			log.Print("Image registry config removed from git, restoring it : " + config.ProjectID)
+			return nil
+		}
+		return importImageRegistry(ctx, resource, config)
+	}
+	return nil
+}
+
+// importTemplate creates or updates the workflow template described by the resource
+func importTemplate(ctx context.Context, resource ConfigResource, config GitConfig) error {
+	if resource.Kind != KindManifestTemplate {
+		return errors.New("expected kind " + KindManifestTemplate + ", found " + resource.Kind)
+	}
+	var spec TemplateSpec
+	if err := json.Unmarshal(resource.Spec, &spec); err != nil {
+		return errors.New("invalid template spec : " + err.Error())
+	}
+	templates, err := dbOperationsWorkflowTemplate.GetTemplatesByProjectID(ctx, config.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	for _, template := range templates {
+		if template.TemplateID != resource.Metadata.ID && (resource.Metadata.ID != "" || template.TemplateName != resource.Metadata.Name) {
+			continue
+		}
+		if template.TemplateName == resource.Metadata.Name && template.TemplateDescription == spec.Description &&
+			template.Manifest == spec.Manifest && template.IsCustomWorkflow == spec.IsCustomWorkflow {
+			return nil
+		}
+		query := bson.D{{"template_id", template.TemplateID}}
+		update := bson.D{{"$set", bson.D{
+			{"template_name", resource.Metadata.Name},
+			{"template_description", spec.Description},
+			{"manifest", spec.Manifest},
+			{"is_custom_workflow", spec.IsCustomWorkflow},
+		}}}
+		return dbOperationsWorkflowTemplate.UpdateTemplateManifest(ctx, query, update)
+	}
+
+	project, err := dbOperationsProject.GetProject(ctx, bson.D{{"_id", config.ProjectID}})
+	if err != nil {
+		return err
+	}
+	log.Print("New template pushed to git : " + resource.Metadata.Name)
+	return dbOperationsWorkflowTemplate.CreateWorkflowTemplate(ctx, &dbOperationsWorkflowTemplate.ManifestTemplate{
+		TemplateID:          uuid.New().String(),
+		ProjectID:           config.ProjectID,
+		Manifest:            spec.Manifest,
+		TemplateName:        resource.Metadata.Name,
+		TemplateDescription: spec.Description,
+		ProjectName:         project.Name,
+		CreatedAt:           strconv.FormatInt(time.Now().Unix(), 10),
+		IsCustomWorkflow:    spec.IsCustomWorkflow,
+	})
+}
+
+// removeTemplate removes the workflow template whose file was deleted from git
+func removeTemplate(ctx context.Context, file string, config GitConfig) error {
+	templates, err := dbOperationsWorkflowTemplate.GetTemplatesByProjectID(ctx, config.ProjectID)
+	if err != nil {
+		return err
+	}
+	for _, template := range templates {
+		if config.configDir()+"/"+templatesDir+"/"+configFileName(template.TemplateName) != file {
+			continue
+		}
+		log.Print("Template removed from git : " + template.TemplateName)
+		query := bson.D{{"template_id", template.TemplateID}}
+		update := bson.D{{"$set", bson.D{{"is_removed", true}}}}
+		return dbOperationsWorkflowTemplate.UpdateTemplateManifest(ctx, query, update)
+	}
+	return nil
+}
+
+// importChaosHub creates or updates the chaos hub described by the resource, the stored credentials are kept
+func importChaosHub(ctx context.Context, resource ConfigResource, config GitConfig) error {
+	if resource.Kind != KindChaosHub {
+		return errors.New("expected kind " + KindChaosHub + ", found " + resource.Kind)
+	}
+	var spec ChaosHubSpec
+	if err := json.Unmarshal(resource.Spec, &spec); err != nil {
+		return errors.New("invalid chaos hub spec : " + err.Error())
+	}
+	if spec.AuthType == "" {
+		spec.AuthType = string(model.AuthTypeNone)
+	}
+	if !model.AuthType(spec.AuthType).IsValid() {
+		return errors.New("invalid chaos hub auth type : " + spec.AuthType)
+	}
+	if spec.RepoURL == "" || spec.RepoBranch == "" {
+		return errors.New("chaos hub repoURL and repoBranch are required")
+	}
+	hubs, err := dbOperationsMyHub.GetMyHubByProjectID(ctx, config.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	currentTime := strconv.FormatInt(time.Now().Unix(), 10)
+	for _, hub := range hubs {
+		if hub.ID != resource.Metadata.ID && (resource.Metadata.ID != "" || hub.HubName != resource.Metadata.Name) {
+			continue
+		}
+		repoChanged := hub.RepoURL != spec.RepoURL || hub.RepoBranch != spec.RepoBranch || hub.IsPrivate != spec.IsPrivate || hub.AuthType != spec.AuthType
+		if !repoChanged && hub.HubName == resource.Metadata.Name && stringValue(hub.UserName) == stringValue(spec.UserName) {
+			return nil
+		}
+		query := bson.D{{"myhub_id", hub.ID}, {"IsRemoved", false}}
+		update := bson.D{{"$set", bson.D{{"repo_url", spec.RepoURL}, {"repo_branch", spec.RepoBranch},
+			{"hub_name", resource.Metadata.Name}, {"IsPrivate", spec.IsPrivate}, {"AuthType", spec.AuthType},
+			{"UserName", spec.UserName}, {"updated_at", currentTime}}}}
+		err = dbOperationsMyHub.UpdateMyHub(ctx, query, update)
+		if err != nil {
+			return err
+		}
+		if repoChanged || hub.HubName != resource.Metadata.Name {
+			hub.RepoURL, hub.RepoBranch, hub.HubName = spec.RepoURL, spec.RepoBranch, resource.Metadata.Name
+			hub.IsPrivate, hub.AuthType, hub.UserName = spec.IsPrivate, spec.AuthType, spec.UserName
+			cloneChaosHub(hub)
+		}
+		return nil
+	}
+
+	log.Print("New chaos hub pushed to git : " + resource.Metadata.Name)
+	hub := dbSchemaMyHub.MyHub{
+		ID:           uuid.New().String(),
+		ProjectID:    config.ProjectID,
+		RepoURL:      spec.RepoURL,
+		RepoBranch:   spec.RepoBranch,
+		HubName:      resource.Metadata.Name,
+		IsPrivate:    spec.IsPrivate,
+		AuthType:     spec.AuthType,
+		UserName:     spec.UserName,
+		CreatedAt:    currentTime,
+		UpdatedAt:    currentTime,
+		LastSyncedAt: currentTime,
+	}
+	err = dbOperationsMyHub.CreateMyHub(ctx, &hub)
+	if err != nil {
+		return err
+	}
+	cloneChaosHub(hub)
+	return nil
+}
+
+// cloneChaosHub clones the hub repository with the stored credentials, failures are only logged as
+// hubs created from git have no credentials until they are set from the portal
+func cloneChaosHub(hub dbSchemaMyHub.MyHub) {
+	cloneHub := model.CloningInput{
+		ProjectID:  hub.ProjectID,
+		RepoBranch: hub.RepoBranch,
+		RepoURL:    hub.RepoURL,
+		HubName:    hub.HubName,
+		IsPrivate:  hub.IsPrivate,
+		UserName:   hub.UserName,
+		AuthType:   model.AuthType(hub.AuthType),
+	}
+	var err error
+	if cloneHub.Token, err = secrets.DecryptPtr(hub.Token); err == nil {
+		if cloneHub.Password, err = secrets.DecryptPtr(hub.Password); err == nil {
+			cloneHub.SSHPrivateKey, err = secrets.DecryptPtr(hub.SSHPrivateKey)
+		}
+	}
+	if err == nil {
+		err = myHubOps.GitClone(cloneHub)
+	}
+	if err != nil {
+		log.Print("Failed to clone chaos hub : " + hub.HubName + " | " + err.Error())
+	}
+}
+
+// removeChaosHub removes the chaos hub whose file was deleted from git
+func removeChaosHub(ctx context.Context, file string, config GitConfig) error {
+	hubs, err := dbOperationsMyHub.GetMyHubByProjectID(ctx, config.ProjectID)
+	if err != nil {
+		return err
+	}
+	for _, hub := range hubs {
+		if config.configDir()+"/"+chaosHubsDir+"/"+configFileName(hub.HubName) != file {
+			continue
+		}
+		log.Print("Chaos hub removed from git : " + hub.HubName)
+		query := bson.D{{"myhub_id", hub.ID}}
+		update := bson.D{{"$set", bson.D{{"IsRemoved", true}, {"updated_at", strconv.FormatInt(time.Now().Unix(), 10)}}}}
+		return dbOperationsMyHub.UpdateMyHub(ctx, query, update)
+	}
+	return nil
+}
+
+// importImageRegistry updates the image registry of the project, or creates it if the project has none
+func importImageRegistry(ctx context.Context, resource ConfigResource, config GitConfig) error {
+	if resource.Kind != KindImageRegistry {
+		return errors.New("expected kind " + KindImageRegistry + ", found " + resource.Kind)
+	}
+	var spec ImageRegistrySpec
+	if err := json.Unmarshal(resource.Spec, &spec); err != nil {
+		return errors.New("invalid image registry spec : " + err.Error())
+	}
+	registries, err := dbOperationsImageRegistry.ListImageRegistries(ctx, bson.D{{"project_id", config.ProjectID}, {"is_removed", false}})
+	if err != nil {
+		return err
+	}
+
+	currentTime := strconv.FormatInt(time.Now().Unix(), 10)
+	if len(registries) == 0 {
+		return dbOperationsImageRegistry.InsertImageRegistry(ctx, dbOperationsImageRegistry.ImageRegistry{
+			ImageRegistryID:   uuid.New().String(),
+			ProjectID:         config.ProjectID,
+			ImageRegistryName: spec.RegistryName,
+			ImageRepoName:     spec.RepoName,
+			ImageRegistryType: spec.RegistryType,
+			SecretName:        spec.SecretName,
+			SecretNamespace:   spec.SecretNamespace,
+			IsDefault:         spec.IsDefault,
+			EnableRegistry:    spec.EnableRegistry,
+			CreatedAt:         &currentTime,
+			UpdatedAt:         currentTime,
+		})
+	}
+
+	registry := registries[0]
+	current, err := json.Marshal(imageRegistrySpec(registry))
+	if err != nil {
+		return err
+	}
+	desired, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(current, desired) {
+		return nil
+	}
+	query := bson.D{{"image_registry_id", registry.ImageRegistryID}, {"project_id", config.ProjectID}}
+	update := bson.D{{"$set", bson.D{
+		{"image_registry_name", spec.RegistryName},
+		{"image_repo_name", spec.RepoName},
+		{"image_registry_type", spec.RegistryType},
+		{"secret_name", spec.SecretName},
+		{"secret_namespace", spec.SecretNamespace},
+		{"enable_registry", spec.EnableRegistry},
+		{"is_default", spec.IsDefault},
+		{"updated_at", currentTime},
+	}}}
+	return dbOperationsImageRegistry.UpdateImageRegistry(ctx, query, update)
+}
+
+// imageRegistrySpec returns the spec of the stored image registry
+func imageRegistrySpec(registry dbOperationsImageRegistry.ImageRegistry) ImageRegistrySpec {
+	return ImageRegistrySpec{
+		RegistryName:    registry.ImageRegistryName,
+		RepoName:        registry.ImageRepoName,
+		RegistryType:    registry.ImageRegistryType,
+		SecretName:      registry.SecretName,
+		SecretNamespace: registry.SecretNamespace,
+		IsDefault:       registry.IsDefault,
+		EnableRegistry:  registry.EnableRegistry,
+	}
+}
+
+// exportConfig writes the project configuration stored in the DB to the reserved directory, returns true if the repo changed
+func (c GitConfig) exportConfig(ctx context.Context) (bool, error) {
+	changed, err := c.writeConfigFile(c.configDir()+"/README.md", []byte(configReadme))
+	if err != nil {
+		return false, err
+	}
+
+	// templates
+	templates, err := dbOperationsWorkflowTemplate.GetTemplatesByProjectID(ctx, c.ProjectID)
+	if err != nil {
+		return false, errors.New("Cannot get templates : " + err.Error())
+	}
+	resources := map[string]ConfigResource{}
+	for _, template := range templates {
+		resource, err := NewConfigResource(KindManifestTemplate, template.TemplateName, template.TemplateID, TemplateSpec{
+			Description:      template.TemplateDescription,
+			IsCustomWorkflow: template.IsCustomWorkflow,
+			Manifest:         template.Manifest,
+		})
+		if err != nil {
+			return false, err
+		}
+		resources[c.configDir()+"/"+templatesDir+"/"+configFileName(template.TemplateName)] = resource
+	}
+	dirChanged, err := c.exportConfigDir(c.configDir()+"/"+templatesDir, resources, func(id string) bool {
+		template, err := dbOperationsWorkflowTemplate.GetTemplateByTemplateID(ctx, id)
+		return err == nil && template.IsRemoved
+	})
+	if err != nil {
+		return false, err
+	}
+	changed = changed || dirChanged
+
+	// chaos hubs
+	hubs, err := dbOperationsMyHub.GetMyHubByProjectID(ctx, c.ProjectID)
+	if err != nil {
+		return false, errors.New("Cannot get chaos hubs : " + err.Error())
+	}
+	resources = map[string]ConfigResource{}
+	for _, hub := range hubs {
+		resource, err := NewConfigResource(KindChaosHub, hub.HubName, hub.ID, ChaosHubSpec{
+			RepoURL:    hub.RepoURL,
+			RepoBranch: hub.RepoBranch,
+			IsPrivate:  hub.IsPrivate,
+			AuthType:   hub.AuthType,
+			UserName:   hub.UserName,
+		})
+		if err != nil {
+			return false, err
+		}
+		resources[c.configDir()+"/"+chaosHubsDir+"/"+configFileName(hub.HubName)] = resource
+	}
+	dirChanged, err = c.exportConfigDir(c.configDir()+"/"+chaosHubsDir, resources, func(id string) bool {
+		hub, err := dbOperationsMyHub.GetHubByID(ctx, id)
+		return err == nil && hub.IsRemoved
+	})
+	if err != nil {
+		return false, err
+	}
+	changed = changed || dirChanged
+
+	// image registry
+	registries, err := dbOperationsImageRegistry.ListImageRegistries(ctx, bson.D{{"project_id", c.ProjectID}, {"is_removed", false}})
+	if err != nil {
+		return false, errors.New("Cannot get image registries : " + err.Error())
+	}
+	if len(registries) > 0 {
+		resource, err := NewConfigResource(KindImageRegistry, registries[0].ImageRegistryName, registries[0].ImageRegistryID, imageRegistrySpec(registries[0]))
+		if err != nil {
+			return false, err
+		}
+		written, err := c.writeConfigResource(c.configDir()+"/"+imageRegistryFile, resource)
+		if err != nil {
+			return false, err
+		}
+		changed = changed || written
+	}
+	return changed, nil
+}
+
+// exportConfigDir writes the resources of a config sub directory, files of resources that were renamed or
+// removed in the portal are deleted. Files of unknown resources are left untouched
+func (c GitConfig) exportConfigDir(dir string, resources map[string]ConfigResource, isRemoved func(id string) bool) (bool, error) {
+	existing, err := c.readConfigDir(dir)
+	if err != nil {
+		return false, err
+	}
+	ids := map[string]string{}
+	for file, resource := range resources {
+		ids[resource.Metadata.ID] = file
+	}
+
+	changed := false
+	for file, resource := range existing {
+		if _, ok := resources[file]; ok || resource.Metadata.ID == "" {
+			continue
+		}
+		if _, renamed := ids[resource.Metadata.ID]; renamed || isRemoved(resource.Metadata.ID) {
+			if err := c.removeConfigFile(file); err != nil {
+				return false, err
+			}
+			changed = true
+		}
+	}
+	for file, resource := range resources {
+		written, err := c.writeConfigResource(file, resource)
+		if err != nil {
+			return false, err
+		}
+		changed = changed || written
+	}
+	return changed, nil
+}
+
+// syncConfigToGit writes the project configuration to git, returns the new commit hash or an empty string if nothing changed
+func (c GitConfig) syncConfigToGit(ctx context.Context) (string, error) {
+	// read only repos are only synced from git to the portal
+	if c.AuthType == model.AuthTypeNone {
+		return "", nil
+	}
+	changed, err := c.exportConfig(ctx)
+	if err != nil || !changed {
+		return "", err
+	}
+	commit, err := c.GitCommit(GitUserFromContext(ctx), "Updated Project Configuration", nil)
+	if err != nil {
+		return "", errors.New("Cannot commit project configuration to git : " + err.Error())
+	}
+	err = c.GitPush()
+	if err != nil {
+		return "", errors.New("Cannot push project configuration to git : " + err.Error())
+	}
+	return commit, nil
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,406 @@
+package gitops
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/chaos-workflow/ops"
+	store "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/data-store"
+	dbOperationsWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+	dbSchemaWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+)
+
+// WorkflowFilePath returns the path of the workflow manifest relative to the repository root
+func (c GitConfig) WorkflowFilePath(workflowName string) string {
+	return ProjectDataPath + "/" + c.ProjectID + "/" + workflowName + ".yaml"
+}
+
+// readGitManifest reads the workflow manifest from the local repo as JSON, returns false if the file doesn't exist
+func (c GitConfig) readGitManifest(workflowName string) (string, bool, error) {
+	path := c.LocalPath + "/" + c.WorkflowFilePath(workflowName)
+	exists, err := PathExists(path)
+	if err != nil || !exists {
+		return "", false, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", false, err
+	}
+	data, err = yaml.YAMLToJSON(data)
+	if err != nil {
+		return "", false, err
+	}
+	return string(data), true, nil
+}
+
+// canonicalManifest processes the manifest the same way the portal does before storing it and
+// normalizes the JSON so that manifests coming from git and from the DB can be compared. If the
+// processing fails the normalized input is returned along with the error so that it can still be displayed.
+// Manifests stored in the DB are already processed, normalizeManifest is enough for them
+func canonicalManifest(manifest string, workflow dbSchemaWorkflow.ChaosWorkFlowInput) (string, error) {
+	workflowID := workflow.WorkflowID
+	input, _, err := ops.ProcessWorkflow(&model.ChaosWorkFlowInput{
+		WorkflowID:       &workflowID,
+		WorkflowManifest: manifest,
+		CronSyntax:       workflow.CronSyntax,
+		WorkflowName:     workflow.WorkflowName,
+		IsCustomWorkflow: workflow.IsCustomWorkflow,
+		ProjectID:        workflow.ProjectID,
+		ClusterID:        workflow.ClusterID,
+	})
+	if err == nil {
+		manifest = input.WorkflowManifest
+	}
+
+	normalized, er := normalizeManifest(manifest)
+	if er != nil {
+		return "", er
+	}
+	return normalized, err
+}
+
+// normalizeManifest normalizes the JSON of the manifest without processing it
+func normalizeManifest(manifest string) (string, error) {
+	var obj interface{}
+	if err := json.Unmarshal([]byte(manifest), &obj); err != nil {
+		return "", errors.New("failed to parse manifest : " + err.Error())
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// normalizedHash returns the hash of the normalized manifest
+func normalizedHash(manifest string) (string, error) {
+	normalized, err := normalizeManifest(manifest)
+	if err != nil {
+		return "", err
+	}
+	return manifestHash(normalized), nil
+}
+
+// manifestHash returns the hash of a canonical manifest
+func manifestHash(canonical string) string {
+	sum := sha256.Sum256([]byte(canonical))
+	return hex.EncodeToString(sum[:])
+}
+
+// setWorkflowSynced records that the DB and git manifests of the workflow are identical, gitHash is the hash of
+// the git manifest as read from the repo
+func setWorkflowSynced(workflow dbSchemaWorkflow.ChaosWorkFlowInput, hash string, gitHash string) error {
+	if workflow.GitOps != nil && workflow.GitOps.SyncedHash == hash && workflow.GitOps.GitHash == gitHash && workflow.GitOps.DriftStatus == model.DriftStatusInSync {
+		return nil
+	}
+	query := bson.D{{"workflow_id", workflow.WorkflowID}, {"project_id", workflow.ProjectID}}
+	update := bson.D{{"$set", bson.D{{"gitops", dbSchemaWorkflow.GitOpsState{
+		SyncedHash:  hash,
+		GitHash:     gitHash,
+		DriftStatus: model.DriftStatusInSync,
+	}}}}}
+	return dbOperationsWorkflow.UpdateChaosWorkflow(query, update)
+}
+
+// setWorkflowDrift records a drift of the workflow, the last synced hash is kept so that later syncs can still tell which side changed
+func setWorkflowDrift(workflow dbSchemaWorkflow.ChaosWorkFlowInput, status model.DriftStatus, gitManifest string) error {
+	state := dbSchemaWorkflow.GitOpsState{
+		DriftStatus: status,
+		GitManifest: gitManifest,
+		DetectedAt:  strconv.FormatInt(time.Now().Unix(), 10),
+	}
+	if workflow.GitOps != nil {
+		if workflow.GitOps.DriftStatus == status && workflow.GitOps.GitManifest == gitManifest {
+			return nil
+		}
+		state.SyncedHash, state.GitHash = workflow.GitOps.SyncedHash, workflow.GitOps.GitHash
+		if workflow.GitOps.DriftStatus == status && workflow.GitOps.DetectedAt != "" {
+			state.DetectedAt = workflow.GitOps.DetectedAt
+		}
+	}
+	log.Print("Workflow drift detected | wf_name: ", workflow.WorkflowName, " status: ", status)
+	query := bson.D{{"workflow_id", workflow.WorkflowID}, {"project_id", workflow.ProjectID}}
+	update := bson.D{{"$set", bson.D{{"gitops", state}}}}
+	return dbOperationsWorkflow.UpdateChaosWorkflow(query, update)
+}
+
+// MarkWorkflowSynced records that the manifest was just written to git by the portal
+func MarkWorkflowSynced(workflow *model.ChaosWorkFlowInput) error {
+	if workflow.WorkflowID == nil {
+		return nil
+	}
+	dbWorkflow, err := dbOperationsWorkflow.GetWorkflows(bson.D{{"workflow_id", *workflow.WorkflowID}, {"project_id", workflow.ProjectID}, {"isRemoved", false}})
+	if err != nil || len(dbWorkflow) == 0 {
+		// new workflows are inserted after the git push, the next sync records their state
+		return err
+	}
+	// the portal writes the processed manifest to git, so both sides have the same hash
+	hash, err := normalizedHash(workflow.WorkflowManifest)
+	if err != nil {
+		return err
+	}
+	dbWorkflow[0].GitOps = nil
+	return setWorkflowSynced(dbWorkflow[0], hash, hash)
+}
+
+// IsWorkflowDiverged checks whether the workflow has an unresolved drift with git
+func IsWorkflowDiverged(projectID, workflowID string) (bool, error) {
+	workflows, err := dbOperationsWorkflow.GetWorkflows(bson.D{{"workflow_id", workflowID}, {"project_id", projectID}, {"isRemoved", false}})
+	if err != nil {
+		return false, err
+	}
+	if len(workflows) == 0 || workflows[0].GitOps == nil {
+		return false, nil
+	}
+	return workflows[0].GitOps.DriftStatus == model.DriftStatusDiverged, nil
+}
+
+// WorkflowGitHash returns the hash of the git manifest the workflow was last synced with, empty when it never was
+func WorkflowGitHash(projectID, workflowID string) (string, error) {
+	workflows, err := dbOperationsWorkflow.GetWorkflows(bson.D{{"workflow_id", workflowID}, {"project_id", projectID}, {"isRemoved", false}})
+	if err != nil {
+		return "", err
+	}
+	if len(workflows) == 0 || workflows[0].GitOps == nil {
+		return "", nil
+	}
+	return workflows[0].GitOps.GitHash, nil
+}
+
+// DivergeIfGitMoved marks the edited workflow as diverged when its git manifest changed since the sync with
+// baseGitHash the edit is based on, so that the edit doesn't overwrite a concurrent change in git. Returns whether
+// the workflow diverged
+func (c GitConfig) DivergeIfGitMoved(workflow *model.ChaosWorkFlowInput, baseGitHash string) (bool, error) {
+	if workflow.WorkflowID == nil || baseGitHash == "" {
+		return false, nil
+	}
+	gitManifest, exists, err := c.readGitManifest(workflow.WorkflowName)
+	if err != nil || !exists {
+		return false, err
+	}
+	gitHash, err := normalizedHash(gitManifest)
+	if err != nil {
+		return false, err
+	}
+	if gitHash == baseGitHash {
+		return false, nil
+	}
+
+	workflows, err := dbOperationsWorkflow.GetWorkflows(bson.D{{"workflow_id", *workflow.WorkflowID}, {"project_id", workflow.ProjectID}, {"isRemoved", false}})
+	if err != nil || len(workflows) == 0 {
+		return false, err
+	}
+	editHash, err := normalizedHash(workflow.WorkflowManifest)
+	if err != nil {
+		return false, err
+	}
+	// git may have been changed to the same manifest as the edit
+	if gitCanonical, err := canonicalManifest(gitManifest, workflows[0]); err == nil && manifestHash(gitCanonical) == editHash {
+		return false, nil
+	}
+	return true, setWorkflowDrift(workflows[0], model.DriftStatusDiverged, gitManifest)
+}
+
+// applyGitWorkflowChange updates the DB with the workflow manifest changed in git, if the portal copy also changed
+// since the last sync the workflow is marked as diverged instead of silently overwriting either side
+func applyGitWorkflowChange(input *model.ChaosWorkFlowInput, wfType *dbSchemaWorkflow.ChaosWorkflowType, workflow dbSchemaWorkflow.ChaosWorkFlowInput, rawManifest string) error {
+	gitHash, err := normalizedHash(input.WorkflowManifest)
+	if err != nil {
+		return err
+	}
+	dbHash, err := normalizedHash(workflow.WorkflowManifest)
+	if err != nil {
+		return err
+	}
+	rawHash, err := normalizedHash(rawManifest)
+	if err != nil {
+		return err
+	}
+	if gitHash == dbHash {
+		return setWorkflowSynced(workflow, gitHash, rawHash)
+	}
+	if workflow.GitOps != nil && workflow.GitOps.SyncedHash != "" && workflow.GitOps.SyncedHash != dbHash {
+		return setWorkflowDrift(workflow, model.DriftStatusDiverged, rawManifest)
+	}
+
+	err = ops.ProcessWorkflowUpdate(input, wfType, store.Store)
+	if err != nil {
+		return err
+	}
+	workflow.GitOps = nil
+	return setWorkflowSynced(workflow, gitHash, rawHash)
+}
+
+// DetectDrift compares the DB manifest of every workflow in the project with its manifest in the local repo. The
+// hashes are compared with the ones of the last sync, a git manifest is only processed when it changed since then
+func DetectDrift(config GitConfig) error {
+	workflows, err := dbOperationsWorkflow.GetWorkflows(bson.D{{"project_id", config.ProjectID}, {"isRemoved", false}})
+	if err != nil {
+		return errors.New("Cannot get workflows : " + err.Error())
+	}
+	for _, workflow := range workflows {
+		gitManifest, exists, err := config.readGitManifest(workflow.WorkflowName)
+		if err != nil {
+			log.Print("Error reading workflow from git : " + workflow.WorkflowName + " | " + err.Error())
+			continue
+		}
+		if !exists {
+			err = setWorkflowDrift(workflow, model.DriftStatusMissingInGit, "")
+		} else {
+			err = detectWorkflowDrift(workflow, gitManifest)
+		}
+		if err != nil {
+			log.Print("Error updating workflow drift state : " + workflow.WorkflowName + " | " + err.Error())
+		}
+	}
+	return nil
+}
+
+// detectWorkflowDrift records whether the workflow is in sync with its git manifest
+func detectWorkflowDrift(workflow dbSchemaWorkflow.ChaosWorkFlowInput, gitManifest string) error {
+	gitHash, gitErr := normalizedHash(gitManifest)
+	dbHash, dbErr := normalizedHash(workflow.WorkflowManifest)
+	if gitErr != nil || dbErr != nil {
+		return setWorkflowDrift(workflow, model.DriftStatusDiverged, gitManifest)
+	}
+
+	if state := workflow.GitOps; state != nil && state.SyncedHash != "" && state.GitHash == gitHash {
+		// git didn't change since the last sync, so only a change in the portal diverges
+		if dbHash != state.SyncedHash {
+			return setWorkflowDrift(workflow, model.DriftStatusDiverged, gitManifest)
+		}
+		return setWorkflowSynced(workflow, dbHash, gitHash)
+	}
+	if gitHash == dbHash {
+		return setWorkflowSynced(workflow, dbHash, gitHash)
+	}
+
+	// git changed, e.g. it was edited without the labels set by the portal, the manifests are compared once processed
+	gitCanonical, err := canonicalManifest(gitManifest, workflow)
+	if err != nil || manifestHash(gitCanonical) != dbHash {
+		return setWorkflowDrift(workflow, model.DriftStatusDiverged, gitManifest)
+	}
+	return setWorkflowSynced(workflow, dbHash, gitHash)
+}
+
+// ManifestDiff returns a line diff between the portal and git manifests in YAML, removed lines are the portal
+// version and added lines the git version
+func ManifestDiff(portalManifest, gitManifest string, workflow dbSchemaWorkflow.ChaosWorkFlowInput) string {
+	toYAML := func(manifest string) string {
+		if manifest == "" {
+			return ""
+		}
+		canonical, _ := canonicalManifest(manifest, workflow)
+		if canonical == "" {
+			return manifest
+		}
+		data, err := yaml.JSONToYAML([]byte(canonical))
+		if err != nil {
+			return canonical
+		}
+		return string(data)
+	}
+
+	var out strings.Builder
+	out.WriteString("--- portal/" + workflow.WorkflowName + ".yaml\n")
+	out.WriteString("+++ git/" + workflow.WorkflowName + ".yaml\n")
+	for _, d := range diff.Do(toYAML(portalManifest), toYAML(gitManifest)) {
+		prefix := " "
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			prefix = "-"
+		case diffmatchpatch.DiffInsert:
+			prefix = "+"
+		}
+		for _, line := range strings.SplitAfter(d.Text, "\n") {
+			if line == "" {
+				continue
+			}
+			out.WriteString(prefix + line)
+			if !strings.HasSuffix(line, "\n") {
+				out.WriteString("\n")
+			}
+		}
+	}
+	return out.String()
+}
+
+// TakeGitVersion resolves a drift by replacing the portal workflow with its manifest from git,
+// a workflow that no longer exists in git is deleted from the portal
+func TakeGitVersion(config GitConfig, workflow dbSchemaWorkflow.ChaosWorkFlowInput) error {
+	gitManifest, exists, err := config.readGitManifest(workflow.WorkflowName)
+	if err != nil {
+		return errors.New("Cannot read workflow from git : " + err.Error())
+	}
+	if !exists {
+		query := bson.D{{"workflow_id", workflow.WorkflowID}, {"project_id", workflow.ProjectID}}
+		return ops.ProcessWorkflowDelete(query, workflow, store.Store)
+	}
+
+	input, wfType, err := ops.ProcessWorkflow(&model.ChaosWorkFlowInput{
+		WorkflowID:          &workflow.WorkflowID,
+		WorkflowManifest:    gitManifest,
+		CronSyntax:          workflow.CronSyntax,
+		WorkflowName:        workflow.WorkflowName,
+		WorkflowDescription: workflow.WorkflowDescription,
+		IsCustomWorkflow:    workflow.IsCustomWorkflow,
+		ProjectID:           workflow.ProjectID,
+		ClusterID:           workflow.ClusterID,
+	})
+	if err != nil {
+		return err
+	}
+	err = ops.ProcessWorkflowUpdate(input, wfType, store.Store)
+	if err != nil {
+		return err
+	}
+	hash, err := normalizedHash(input.WorkflowManifest)
+	if err != nil {
+		return err
+	}
+	gitHash, err := normalizedHash(gitManifest)
+	if err != nil {
+		return err
+	}
+	workflow.GitOps = nil
+	return setWorkflowSynced(workflow, hash, gitHash)
+}
+
+// TakePortalVersion resolves a drift by writing the portal workflow to git, returns the new commit hash
+func TakePortalVersion(user GitUser, config GitConfig, workflow dbSchemaWorkflow.ChaosWorkFlowInput) (string, error) {
+	data, err := yaml.JSONToYAML([]byte(workflow.WorkflowManifest))
+	if err != nil {
+		return "", errors.New("Cannot convert manifest to yaml : " + err.Error())
+	}
+	err = ioutil.WriteFile(config.LocalPath+"/"+config.WorkflowFilePath(workflow.WorkflowName), data, 0644)
+	if err != nil {
+		return "", errors.New("Cannot write workflow to git : " + err.Error())
+	}
+	commit, err := config.GitCommit(user, "Resolved Workflow Drift : "+workflow.WorkflowName, nil)
+	if err != nil {
+		return "", errors.New("Cannot commit workflow to git : " + err.Error())
+	}
+	err = config.GitPush()
+	if err != nil {
+		return "", errors.New("Cannot push workflow to git : " + err.Error())
+	}
+	hash, err := normalizedHash(workflow.WorkflowManifest)
+	if err != nil {
+		return "", err
+	}
+	workflow.GitOps = nil
+	return commit, setWorkflowSynced(workflow, hash, hash)
+}
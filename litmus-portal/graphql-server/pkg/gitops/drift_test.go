@@ -0,0 +1,84 @@
+package gitops
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizedHash(t *testing.T) {
+	const manifest = `{"apiVersion":"argoproj.io/v1alpha1","kind":"Workflow","metadata":{"name":"pod-delete","labels":{"a":"1","b":"2"}}}`
+
+	tests := []struct {
+		name     string
+		manifest string
+		same     bool
+		wantErr  bool
+	}{
+		{name: "identical", manifest: manifest, same: true},
+		{name: "whitespace", manifest: "{\n  \"apiVersion\": \"argoproj.io/v1alpha1\",\n  \"kind\": \"Workflow\",\n  \"metadata\": {\"name\": \"pod-delete\", \"labels\": {\"a\": \"1\", \"b\": \"2\"}}\n}", same: true},
+		{name: "key order", manifest: `{"metadata":{"labels":{"b":"2","a":"1"},"name":"pod-delete"},"kind":"Workflow","apiVersion":"argoproj.io/v1alpha1"}`, same: true},
+		{name: "changed value", manifest: `{"apiVersion":"argoproj.io/v1alpha1","kind":"Workflow","metadata":{"name":"pod-delete","labels":{"a":"1","b":"3"}}}`, same: false},
+		{name: "missing field", manifest: `{"apiVersion":"argoproj.io/v1alpha1","kind":"Workflow","metadata":{"name":"pod-delete"}}`, same: false},
+		{name: "invalid json", manifest: `{"kind":`, wantErr: true},
+	}
+
+	want, err := normalizedHash(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizedHash(tt.manifest)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (got == want) != tt.same {
+				t.Errorf("normalizedHash() = %s, base hash %s, want same = %v", got, want, tt.same)
+			}
+		})
+	}
+}
+
+func TestReadGitManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gitops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := GitConfig{ProjectID: "project-1", LocalPath: dir}
+	path := filepath.Join(dir, config.WorkflowFilePath("pod-delete"))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	yamlManifest := "kind: Workflow\nmetadata:\n  name: pod-delete\nspec:\n  entrypoint: main\n"
+	if err := ioutil.WriteFile(path, []byte(yamlManifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, exists, err := config.readGitManifest("pod-delete")
+	if err != nil || !exists {
+		t.Fatalf("readGitManifest() = %v, %v", exists, err)
+	}
+	// the git manifest in YAML hashes the same as the DB manifest in JSON
+	gitHash, err := normalizedHash(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbHash, _ := normalizedHash(`{"spec":{"entrypoint":"main"},"metadata":{"name":"pod-delete"},"kind":"Workflow"}`)
+	if gitHash != dbHash {
+		t.Errorf("hash of the git manifest %s differs from the DB manifest %s", gitHash, dbHash)
+	}
+
+	if _, exists, err := config.readGitManifest("missing"); err != nil || exists {
+		t.Errorf("readGitManifest() of a missing workflow = %v, %v", exists, err)
+	}
+}
@@ -33,6 +33,7 @@ import (
 	dbOperationsGitOps "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/gitops"
 	dbSchemaGitOps "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/gitops"
 	dbOperationsWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/secrets"
 )
 
 // GitConfig structure for the GitOps settings
@@ -84,8 +85,8 @@ func GitUserFromContext(ctx context.Context) GitUser {
 	}
 }
 
-// GetGitOpsConfig is used for constructing the GitConfig from dbSchemaGitOps.GitConfigDB
-func GetGitOpsConfig(repoData dbSchemaGitOps.GitConfigDB) GitConfig {
+// GetGitOpsConfig is used for constructing the GitConfig from dbSchemaGitOps.GitConfigDB, stored credentials are decrypted
+func GetGitOpsConfig(repoData dbSchemaGitOps.GitConfigDB) (GitConfig, error) {
 	gitConfig := GitConfig{
 		ProjectID:     repoData.ProjectID,
 		RepositoryURL: repoData.RepositoryURL,
@@ -94,13 +95,43 @@ func GetGitOpsConfig(repoData dbSchemaGitOps.GitConfigDB) GitConfig {
 		LocalPath:     DefaultPath + repoData.ProjectID,
 		LatestCommit:  repoData.LatestCommit,
 		UserName:      repoData.UserName,
-		Password:      repoData.Password,
 		AuthType:      repoData.AuthType,
-		Token:         repoData.Token,
-		SSHPrivateKey: repoData.SSHPrivateKey,
 	}
 
-	return gitConfig
+	var err error
+	if gitConfig.Password, err = secrets.DecryptPtr(repoData.Password); err != nil {
+		return GitConfig{}, err
+	}
+	if gitConfig.Token, err = secrets.DecryptPtr(repoData.Token); err != nil {
+		return GitConfig{}, err
+	}
+	if gitConfig.SSHPrivateKey, err = secrets.DecryptPtr(repoData.SSHPrivateKey); err != nil {
+		return GitConfig{}, err
+	}
+
+	return gitConfig, nil
+}
+
+// EncryptCredentials encrypts the credentials of the git config before it is persisted
+func EncryptCredentials(repoData *dbSchemaGitOps.GitConfigDB) error {
+	var err error
+	if repoData.Password, err = secrets.EncryptPtr(repoData.Password); err != nil {
+		return err
+	}
+	if repoData.Token, err = secrets.EncryptPtr(repoData.Token); err != nil {
+		return err
+	}
+	repoData.SSHPrivateKey, err = secrets.EncryptPtr(repoData.SSHPrivateKey)
+	return err
+}
+
+// CredentialsInfo returns the redacted view of the credentials stored in the git config
+func CredentialsInfo(repoData dbSchemaGitOps.GitConfigDB) []*model.SecretInfo {
+	return []*model.SecretInfo{
+		secrets.Describe("Token", repoData.Token),
+		secrets.Describe("Password", repoData.Password),
+		secrets.Describe("SSHPrivateKey", repoData.SSHPrivateKey),
+	}
 }
 
 // setupGitRepo helps clones and sets up the repo for gitops
@@ -488,8 +519,23 @@ func SyncDBToGit(ctx context.Context, config GitConfig) error {
 	if err != nil {
 		return errors.New("Error Getting File Changes : " + err.Error())
 	}
+
+	dbCtx := ctx
+	if dbCtx == nil {
+		var cancel context.CancelFunc
+		dbCtx, cancel = context.WithTimeout(context.Background(), time.Second*30)
+		defer cancel()
+	}
+
 	if latestCommit == config.LatestCommit {
-		return nil
+		configCommit, err := config.syncConfigToGit(dbCtx)
+		if err != nil {
+			log.Print("Error syncing project configuration to git : " + err.Error())
+		}
+		if configCommit == "" {
+			return DetectDrift(config)
+		}
+		return updateLatestCommit(dbCtx, config, configCommit)
 	}
 	log.Print(latestCommit, " ", config.LatestCommit, "File Changes: ", files)
 	newWorkflows := false
@@ -497,6 +543,13 @@ func SyncDBToGit(ctx context.Context, config GitConfig) error {
 		if !strings.HasSuffix(file, ".yaml") {
 			continue
 		}
+		if config.isConfigFile(file) {
+			err = importConfigFile(dbCtx, file, config)
+			if err != nil {
+				log.Print("Error while applying project configuration : " + file + " | " + err.Error())
+			}
+			continue
+		}
 		// check if file was deleted or not
 		exists, err := PathExists(config.LocalPath + "/" + file)
 		if err != nil {
@@ -559,21 +612,25 @@ func SyncDBToGit(ctx context.Context, config GitConfig) error {
 		}
 	}
 
-	query := bson.D{{"project_id", config.ProjectID}}
-	update := bson.D{{"$set", bson.D{{"latest_commit", latestCommit}}}}
-
-	if ctx == nil {
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-		defer cancel()
-		err = dbOperationsGitOps.UpdateGitConfig(ctx, query, update)
-	} else {
-		err = dbOperationsGitOps.UpdateGitConfig(ctx, query, update)
+	configCommit, err := config.syncConfigToGit(dbCtx)
+	if err != nil {
+		log.Print("Error syncing project configuration to git : " + err.Error())
+	}
+	if configCommit != "" {
+		latestCommit = configCommit
 	}
+	return updateLatestCommit(dbCtx, config, latestCommit)
+}
 
+// updateLatestCommit stores the latest synced commit of the project and refreshes the drift state of its workflows
+func updateLatestCommit(ctx context.Context, config GitConfig, latestCommit string) error {
+	query := bson.D{{"project_id", config.ProjectID}}
+	update := bson.D{{"$set", bson.D{{"latest_commit", latestCommit}}}}
+	err := dbOperationsGitOps.UpdateGitConfig(ctx, query, update)
 	if err != nil {
 		return errors.New("Failed to update git config : " + err.Error())
 	}
-	return nil
+	return DetectDrift(config)
 }
 
 // createWorkflow helps in creating a new workflow during the SyncDBToGit operation
@@ -666,8 +723,7 @@ func updateWorkflow(data, wfID, file string, config GitConfig) error {
 	if err != nil {
 		return err
 	}
-	return ops.ProcessWorkflowUpdate(input, wfType, store.Store)
-
+	return applyGitWorkflowChange(input, wfType, workflow[0], data)
 }
 
 // deleteWorkflow helps in deleting a workflow from DB during the SyncDBToGit operation
@@ -681,5 +737,13 @@ func deleteWorkflow(file string, config GitConfig) error {
 		return err
 	}
 
+	// keep workflows changed in the portal since the last sync, the deletion has to be resolved by the user
+	if workflow.GitOps != nil && workflow.GitOps.SyncedHash != "" {
+		hash, err := normalizedHash(workflow.WorkflowManifest)
+		if err != nil || hash != workflow.GitOps.SyncedHash {
+			return setWorkflowDrift(workflow, model.DriftStatusMissingInGit, "")
+		}
+	}
+
 	return ops.ProcessWorkflowDelete(query, workflow, store.Store)
 }
@@ -14,9 +14,11 @@ import (
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/chaos-workflow/ops"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/cluster"
 	store "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/data-store"
+	dbSchemaCluster "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/cluster"
 	dbSchemaGitOps "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/gitops"
 	dbOperationsProject "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/project"
 	dbOperationsWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+	dbSchemaWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
 
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
@@ -28,6 +30,7 @@ import (
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
 	dbOperationsGitOps "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/gitops"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/gitops"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/secrets"
 )
 
 const (
@@ -56,12 +59,21 @@ func EnableGitOpsHandler(ctx context.Context, config model.GitConfig) (bool, err
 	log.Print("Enabling Gitops")
 	gitDB := dbSchemaGitOps.GetGitConfigDB(config)
 
-	commit, err := gitops.SetupGitOps(gitops.GitUserFromContext(ctx), gitops.GetGitOpsConfig(gitDB))
+	gitConfig, err := gitops.GetGitOpsConfig(gitDB)
+	if err != nil {
+		return false, errors.New("Failed to setup GitOps : " + err.Error())
+	}
+	commit, err := gitops.SetupGitOps(gitops.GitUserFromContext(ctx), gitConfig)
 	if err != nil {
 		return false, errors.New("Failed to setup GitOps : " + err.Error())
 	}
 	gitDB.LatestCommit = commit
 
+	err = gitops.EncryptCredentials(&gitDB)
+	if err != nil {
+		return false, errors.New("Failed to encrypt git credentials : " + err.Error())
+	}
+
 	err = dbOperationsGitOps.AddGitConfig(ctx, &gitDB)
 	if err != nil {
 		return false, errors.New("Failed to enable GitOps in DB : " + err.Error())
@@ -89,7 +101,7 @@ func DisableGitOpsHandler(ctx context.Context, projectID string) (bool, error) {
 	return true, nil
 }
 
-// GetGitOpsDetailsHandler returns the current gitops config for the requested project
+// GetGitOpsDetailsHandler returns the current gitops config for the requested project, credentials are only described and never returned
 func GetGitOpsDetailsHandler(ctx context.Context, projectID string) (*model.GitConfigResponse, error) {
 	gitLock.Lock(projectID, nil)
 	defer gitLock.Unlock(projectID, nil)
@@ -109,18 +121,10 @@ func GetGitOpsDetailsHandler(ctx context.Context, projectID string) (*model.GitC
 		Branch:    &config.Branch,
 		RepoURL:   &config.RepositoryURL,
 		AuthType:  &config.AuthType,
+		Secrets:   gitops.CredentialsInfo(*config),
 	}
-	switch config.AuthType {
-
-	case model.AuthTypeToken:
-		resp.Token = config.Token
-
-	case model.AuthTypeBasic:
+	if config.AuthType == model.AuthTypeBasic {
 		resp.UserName = config.UserName
-		resp.Password = config.Password
-
-	case model.AuthTypeSSH:
-		resp.SSHPrivateKey = config.SSHPrivateKey
 	}
 	return &resp, nil
 }
@@ -144,7 +148,23 @@ func UpdateGitOpsDetailsHandler(ctx context.Context, config model.GitConfig) (bo
 	log.Print("Enabling Gitops")
 	gitDB := dbSchemaGitOps.GetGitConfigDB(config)
 
-	gitConfig := gitops.GetGitOpsConfig(gitDB)
+	// credentials are never sent back to the client, keep the stored ones if they were not changed
+	if config.AuthType == existingConfig.AuthType {
+		if gitDB.Token == nil {
+			gitDB.Token = existingConfig.Token
+		}
+		if gitDB.Password == nil {
+			gitDB.Password = existingConfig.Password
+		}
+		if gitDB.SSHPrivateKey == nil {
+			gitDB.SSHPrivateKey = existingConfig.SSHPrivateKey
+		}
+	}
+
+	gitConfig, err := gitops.GetGitOpsConfig(gitDB)
+	if err != nil {
+		return false, errors.New("Failed to setup GitOps : " + err.Error())
+	}
 	originalPath := gitConfig.LocalPath
 	gitConfig.LocalPath = tempPath + gitConfig.ProjectID
 	commit, err := gitops.SetupGitOps(gitops.GitUserFromContext(ctx), gitConfig)
@@ -153,6 +173,11 @@ func UpdateGitOpsDetailsHandler(ctx context.Context, config model.GitConfig) (bo
 	}
 	gitDB.LatestCommit = commit
 
+	err = gitops.EncryptCredentials(&gitDB)
+	if err != nil {
+		return false, errors.New("Failed to encrypt git credentials : " + err.Error())
+	}
+
 	err = dbOperationsGitOps.ReplaceGitConfig(ctx, bson.D{{"project_id", config.ProjectID}}, &gitDB)
 	if err != nil {
 		return false, errors.New("Failed to enable GitOps in DB : " + err.Error())
@@ -177,27 +202,38 @@ func GitOpsNotificationHandler(ctx context.Context, clusterInfo model.ClusterIde
 		log.Print("Validation failed : ", clusterInfo.ClusterID)
 		return "Validation failed", err
 	}
-	gitLock.Lock(cInfo.ProjectID, nil)
-	defer gitLock.Unlock(cInfo.ProjectID, nil)
-	config, err := dbOperationsGitOps.GetGitConfig(ctx, cInfo.ProjectID)
+	status, err := triggerWorkflow(ctx, cInfo, workflowID)
 	if err != nil {
-		return "", errors.New("Cannot get Git Config from DB : " + err.Error())
+		return "", err
 	}
-	if config == nil {
+	if status == TriggerStatusGitOpsDisabled {
 		return "Gitops Disabled", nil
 	}
-	query := bson.D{{"cluster_id", clusterInfo.ClusterID}, {"workflow_id", workflowID}, {"isRemoved", false}}
+	return "Request Acknowledged for workflowID: " + workflowID, nil
+}
+
+// triggerWorkflow accepts a run request of the workflow, the run is sent to the cluster in the background unless the
+// steady state gates of the workflow fail. Schedules are not triggered
+func triggerWorkflow(ctx context.Context, cInfo *dbSchemaCluster.Cluster, workflowID string) (string, error) {
+	enabled, err := gitOpsEnabled(ctx, cInfo.ProjectID)
+	if err != nil {
+		return "", err
+	}
+	if !enabled {
+		return TriggerStatusGitOpsDisabled, nil
+	}
+	query := bson.D{{"cluster_id", cInfo.ClusterID}, {"workflow_id", workflowID}, {"isRemoved", false}}
 	workflows, err := dbOperationsWorkflow.GetWorkflows(query)
 	if err != nil {
 		log.Print("Could not get workflow :", err)
-		return "could not get workflow", err
+		return "", errors.New("could not get workflow : " + err.Error())
 	}
 	if len(workflows) == 0 {
-		return "", errors.New("no such workflow found")
+		return "", errWorkflowNotFound
 	}
-	resKind := gjson.Get(workflows[0].WorkflowManifest, "kind").String()
-	if strings.ToLower(resKind) == "cronworkflow" { // no op
-		return "Request Acknowledged for workflowID: " + workflowID, nil
+	resKind := strings.ToLower(gjson.Get(workflows[0].WorkflowManifest, "kind").String())
+	if resKind == "cronworkflow" || resKind == "chaosschedule" { // no op
+		return TriggerStatusSkipped, nil
 	}
 
 	workflows[0].WorkflowManifest, err = sjson.Set(workflows[0].WorkflowManifest, "metadata.name", workflows[0].WorkflowName+"-"+strconv.FormatInt(time.Now().Unix(), 10))
@@ -206,13 +242,33 @@ func GitOpsNotificationHandler(ctx context.Context, clusterInfo model.ClusterIde
 		return "", errors.New("Failed to updated workflow name " + err.Error())
 	}
 
-	ops.SendWorkflowToSubscriber(&model.ChaosWorkFlowInput{
-		WorkflowManifest: workflows[0].WorkflowManifest,
-		ProjectID:        workflows[0].ProjectID,
-		ClusterID:        workflows[0].ClusterID,
-	}, nil, "create", store.Store)
+	// the steady state gates may take longer to evaluate than the caller waits for a response, so the run is
+	// dispatched after the trigger is acknowledged
+	go dispatchTriggeredRun(workflows[0])
 
-	return "Request Acknowledged for workflowID: " + workflowID, nil
+	return TriggerStatusAccepted, nil
+}
+
+// dispatchTriggeredRun sends the triggered run to the cluster once its steady state gates pass
+func dispatchTriggeredRun(workflow dbSchemaWorkflow.ChaosWorkFlowInput) {
+	triggered, err := ops.DispatchWorkflowRun(workflow, store.Store)
+	if err != nil {
+		log.Print("Could not dispatch the triggered run of workflow ", workflow.WorkflowID, " : ", err)
+	} else if !triggered {
+		log.Print("Skipped the triggered run of workflow ", workflow.WorkflowID)
+	}
+}
+
+// gitOpsEnabled reports whether GitOps is configured for the project, the lock of the project is only held while
+// reading the config so that the gates of triggered runs are evaluated without it
+func gitOpsEnabled(ctx context.Context, projectID string) (bool, error) {
+	gitLock.Lock(projectID, nil)
+	defer gitLock.Unlock(projectID, nil)
+	config, err := dbOperationsGitOps.GetGitConfig(ctx, projectID)
+	if err != nil {
+		return false, errors.New("Cannot get Git Config from DB : " + err.Error())
+	}
+	return config != nil, nil
 }
 
 // UpsertWorkflowToGit adds/updates workflow to git
@@ -229,14 +285,46 @@ func UpsertWorkflowToGit(ctx context.Context, workflow *model.ChaosWorkFlowInput
 	gitLock.Lock(config.RepositoryURL, &config.Branch)
 	defer gitLock.Unlock(config.RepositoryURL, &config.Branch)
 
-	gitConfig := gitops.GetGitOpsConfig(*config)
+	gitConfig, err := gitops.GetGitOpsConfig(*config)
+	if err != nil {
+		return errors.New("Cannot get Git Config : " + err.Error())
+	}
+
+	// the sync pulls changes of git into the DB, so the git hash the edit is based on is read before it
+	var baseGitHash string
+	if workflow.WorkflowID != nil {
+		baseGitHash, err = gitops.WorkflowGitHash(workflow.ProjectID, *workflow.WorkflowID)
+		if err != nil {
+			return errors.New("Cannot get workflow sync state : " + err.Error())
+		}
+	}
 
 	err = gitops.SyncDBToGit(ctx, gitConfig)
 	if err != nil {
 		return errors.New("Sync Error | " + err.Error())
 	}
 
-	workflowPath := gitConfig.LocalPath + "/" + gitops.ProjectDataPath + "/" + gitConfig.ProjectID + "/" + workflow.WorkflowName + ".yaml"
+	if workflow.WorkflowID != nil {
+		diverged, err := gitops.IsWorkflowDiverged(workflow.ProjectID, *workflow.WorkflowID)
+		if err != nil {
+			return errors.New("Cannot check workflow drift : " + err.Error())
+		}
+		if diverged {
+			return errors.New("workflow has diverged from git, resolve the drift before updating it")
+		}
+
+		// the edit is kept in the portal and the workflow is marked as diverged instead of overwriting git
+		moved, err := gitConfig.DivergeIfGitMoved(workflow, baseGitHash)
+		if err != nil {
+			return errors.New("Cannot check workflow drift : " + err.Error())
+		}
+		if moved {
+			log.Print("Workflow changed in git while it was edited, not updating git : ", workflow.WorkflowName)
+			return nil
+		}
+	}
+
+	workflowPath := gitConfig.LocalPath + "/" + gitConfig.WorkflowFilePath(workflow.WorkflowName)
 
 	data, err := yaml.JSONToYAML([]byte(workflow.WorkflowManifest))
 	if err != nil {
@@ -265,6 +353,11 @@ func UpsertWorkflowToGit(ctx context.Context, workflow *model.ChaosWorkFlowInput
 		return errors.New("Failed to update git config : " + err.Error())
 	}
 
+	err = gitops.MarkWorkflowSynced(workflow)
+	if err != nil {
+		log.Print("Failed to update workflow sync state : ", err)
+	}
+
 	return nil
 }
 
@@ -284,7 +377,10 @@ func DeleteWorkflowFromGit(ctx context.Context, workflow *model.ChaosWorkFlowInp
 	gitLock.Lock(config.RepositoryURL, &config.Branch)
 	defer gitLock.Unlock(config.RepositoryURL, &config.Branch)
 
-	gitConfig := gitops.GetGitOpsConfig(*config)
+	gitConfig, err := gitops.GetGitOpsConfig(*config)
+	if err != nil {
+		return errors.New("Cannot get Git Config : " + err.Error())
+	}
 
 	err = gitops.SyncDBToGit(ctx, gitConfig)
 	if err != nil {
@@ -350,7 +446,11 @@ func GitSyncHelper(config dbSchemaGitOps.GitConfigDB, wg *sync.WaitGroup) {
 		return
 	}
 
-	gitConfig := gitops.GetGitOpsConfig(*conf)
+	gitConfig, err := gitops.GetGitOpsConfig(*conf)
+	if err != nil {
+		log.Print("Repo Sync ERROR: ", conf.ProjectID, err.Error())
+		return
+	}
 
 	err = gitops.SyncDBToGit(nil, gitConfig)
 	if err != nil {
@@ -395,3 +495,155 @@ func GitOpsSyncHandler(singleRun bool) {
 		time.Sleep(syncInterval)
 	}
 }
+
+// MigrateSecrets encrypts git credentials still stored in plaintext and re-encrypts the ones written with a rotated key
+func MigrateSecrets(ctx context.Context) error {
+	configs, err := dbOperationsGitOps.GetAllGitConfig(ctx)
+	if err != nil {
+		return errors.New("Failed to get git configs from db : " + err.Error())
+	}
+	for _, config := range configs {
+		update := bson.D{}
+		for field, value := range map[string]*string{"password": config.Password, "token": config.Token, "ssh_private_key": config.SSHPrivateKey} {
+			migrate, err := secrets.NeedsMigration(value)
+			if err != nil {
+				return err
+			}
+			if !migrate {
+				continue
+			}
+			encrypted, err := secrets.Reencrypt(value)
+			if err != nil {
+				log.Print("Failed to migrate git credential ", field, " for project ", config.ProjectID, " : ", err)
+				continue
+			}
+			update = append(update, bson.E{Key: field, Value: encrypted})
+		}
+		if len(update) == 0 {
+			continue
+		}
+		err = dbOperationsGitOps.UpdateGitConfig(ctx, bson.D{{"project_id", config.ProjectID}}, bson.D{{"$set", update}})
+		if err != nil {
+			return errors.New("Failed to update git config : " + err.Error())
+		}
+		log.Print("Migrated git credentials for project : ", config.ProjectID)
+	}
+	return nil
+}
+
+// GetWorkflowDriftHandler returns the drifted workflows of the project, or the drift state of a single workflow
+func GetWorkflowDriftHandler(ctx context.Context, projectID string, workflowID *string) ([]*model.WorkflowDrift, error) {
+	config, err := dbOperationsGitOps.GetGitConfig(ctx, projectID)
+	if err != nil {
+		return nil, errors.New("Cannot get Git Config from DB : " + err.Error())
+	}
+	if config == nil {
+		return nil, errors.New("GitOps Disabled ")
+	}
+
+	query := bson.D{{"project_id", projectID}, {"isRemoved", false}}
+	if workflowID != nil {
+		query = append(query, bson.E{Key: "workflow_id", Value: *workflowID})
+	} else {
+		query = append(query, bson.E{Key: "gitops.drift_status", Value: bson.D{{"$in", bson.A{model.DriftStatusDiverged, model.DriftStatusMissingInGit}}}})
+	}
+	workflows, err := dbOperationsWorkflow.GetWorkflows(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*model.WorkflowDrift
+	for _, workflow := range workflows {
+		drift := &model.WorkflowDrift{
+			WorkflowID:     workflow.WorkflowID,
+			WorkflowName:   workflow.WorkflowName,
+			DriftStatus:    model.DriftStatusInSync,
+			PortalManifest: workflow.WorkflowManifest,
+		}
+		if workflow.GitOps != nil && workflow.GitOps.DriftStatus != model.DriftStatusInSync {
+			drift.DriftStatus = workflow.GitOps.DriftStatus
+			detectedAt := workflow.GitOps.DetectedAt
+			drift.DetectedAt = &detectedAt
+			if workflow.GitOps.GitManifest != "" {
+				gitManifest := workflow.GitOps.GitManifest
+				drift.GitManifest = &gitManifest
+			}
+			drift.Diff = gitops.ManifestDiff(workflow.WorkflowManifest, workflow.GitOps.GitManifest, workflow)
+		}
+		result = append(result, drift)
+	}
+	return result, nil
+}
+
+// ResolveWorkflowDriftHandler resolves the drift of a workflow by keeping either the git or the portal version
+func ResolveWorkflowDriftHandler(ctx context.Context, projectID string, workflowID string, resolution model.DriftResolution) (bool, error) {
+	gitLock.Lock(projectID, nil)
+	defer gitLock.Unlock(projectID, nil)
+
+	config, err := dbOperationsGitOps.GetGitConfig(ctx, projectID)
+	if err != nil {
+		return false, errors.New("Cannot get Git Config from DB : " + err.Error())
+	}
+	if config == nil {
+		return false, errors.New("GitOps Disabled ")
+	}
+	gitLock.Lock(config.RepositoryURL, &config.Branch)
+	defer gitLock.Unlock(config.RepositoryURL, &config.Branch)
+
+	gitConfig, err := gitops.GetGitOpsConfig(*config)
+	if err != nil {
+		return false, errors.New("Cannot get Git Config : " + err.Error())
+	}
+
+	err = gitops.SyncDBToGit(ctx, gitConfig)
+	if err != nil {
+		return false, errors.New("Sync Error | " + err.Error())
+	}
+
+	workflow, err := dbOperationsWorkflow.GetWorkflow(bson.D{{"workflow_id", workflowID}, {"project_id", projectID}, {"isRemoved", false}})
+	if err != nil {
+		return false, errors.New("Cannot get workflow : " + err.Error())
+	}
+	if workflow.GitOps == nil || workflow.GitOps.DriftStatus == model.DriftStatusInSync {
+		return true, nil
+	}
+
+	switch resolution {
+	case model.DriftResolutionTakeGit:
+		err = gitops.TakeGitVersion(gitConfig, workflow)
+		if err != nil {
+			return false, errors.New("Failed to apply git version : " + err.Error())
+		}
+
+	case model.DriftResolutionTakePortal:
+		commit, err := gitops.TakePortalVersion(gitops.GitUserFromContext(ctx), gitConfig, workflow)
+		if err != nil {
+			return false, errors.New("Failed to apply portal version : " + err.Error())
+		}
+		query := bson.D{{"project_id", projectID}}
+		update := bson.D{{"$set", bson.D{{"latest_commit", commit}}}}
+		err = dbOperationsGitOps.UpdateGitConfig(ctx, query, update)
+		if err != nil {
+			return false, errors.New("Failed to update git config : " + err.Error())
+		}
+
+	default:
+		return false, errors.New("unknown drift resolution : " + resolution.String())
+	}
+
+	return true, nil
+}
+
+// SyncProjectConfig starts a sync of the project with git so that configuration changed in the portal
+// (templates, chaos hubs, image registries) is written to the repo without waiting for the next sync cycle
+func SyncProjectConfig(ctx context.Context, projectID string) {
+	config, err := dbOperationsGitOps.GetGitConfig(ctx, projectID)
+	if err != nil {
+		log.Print("Cannot get Git Config from DB : ", err)
+		return
+	}
+	if config == nil {
+		return
+	}
+	go GitSyncHelper(*config, nil)
+}
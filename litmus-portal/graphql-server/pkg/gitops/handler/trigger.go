@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/cluster"
+)
+
+// Statuses returned by the gitops trigger endpoint
+const (
+	TriggerStatusAccepted       = "accepted"
+	TriggerStatusSkipped        = "skipped"
+	TriggerStatusGitOpsDisabled = "gitops_disabled"
+
+	maxTriggerRequestSize = 1 << 10
+)
+
+var errWorkflowNotFound = errors.New("no such workflow found")
+
+// TriggerRequest is the body of a gitops trigger request
+type TriggerRequest struct {
+	WorkflowID string `json:"workflow_id"`
+}
+
+// TriggerResponse is the body of a gitops trigger response
+type TriggerResponse struct {
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// GitOpsTriggerHandler runs a workflow on the cluster of the agent on behalf of the event-tracker. Requests are
+// authenticated with the notifier token of the agent, which only allows triggering the workflows of its cluster
+func GitOpsTriggerHandler(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if token == "" {
+		writeTriggerResponse(w, http.StatusUnauthorized, TriggerResponse{Error: "missing notifier token"})
+		return
+	}
+	cInfo, err := cluster.VerifyNotifierToken(token)
+	if err != nil {
+		writeTriggerResponse(w, http.StatusUnauthorized, TriggerResponse{Error: err.Error()})
+		return
+	}
+
+	var request TriggerRequest
+	err = json.NewDecoder(io.LimitReader(r.Body, maxTriggerRequestSize)).Decode(&request)
+	if err != nil || request.WorkflowID == "" {
+		writeTriggerResponse(w, http.StatusBadRequest, TriggerResponse{Error: "request body must contain a workflow_id"})
+		return
+	}
+
+	status, err := triggerWorkflow(r.Context(), cInfo, request.WorkflowID)
+	if err == errWorkflowNotFound {
+		writeTriggerResponse(w, http.StatusNotFound, TriggerResponse{Error: err.Error()})
+		return
+	} else if err != nil {
+		log.WithError(err).Error("gitops trigger failed for cluster ", cInfo.ClusterID)
+		writeTriggerResponse(w, http.StatusInternalServerError, TriggerResponse{Error: err.Error()})
+		return
+	}
+
+	log.Info("gitops trigger for workflow ", request.WorkflowID, " on cluster ", cInfo.ClusterID, " : ", status)
+	code := http.StatusOK
+	if status == TriggerStatusAccepted {
+		code = http.StatusAccepted
+	}
+	writeTriggerResponse(w, code, TriggerResponse{
+		Status:  status,
+		Message: "Request Acknowledged for workflowID: " + request.WorkflowID,
+	})
+}
+
+func writeTriggerResponse(w http.ResponseWriter, code int, response TriggerResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.WithError(err).Error("failed to write gitops trigger response")
+	}
+}
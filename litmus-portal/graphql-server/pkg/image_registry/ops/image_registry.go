@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/image_registry"
+	gitOpsHandler "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/gitops/handler"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
@@ -36,6 +37,7 @@ func CreateImageRegistry(ctx context.Context, projectID string, imageRegistryInf
 	if err != nil {
 		return nil, err
 	}
+	gitOpsHandler.SyncProjectConfig(ctx, projectID)
 
 	return &model.ImageRegistryResponse{
 		ImageRegistryID: id,
@@ -78,6 +80,7 @@ func UpdateImageRegistry(ctx context.Context, imageRegistryID string, projectID
 	if err != nil {
 		return nil, err
 	}
+	gitOpsHandler.SyncProjectConfig(ctx, projectID)
 
 	return &model.ImageRegistryResponse{
 		ImageRegistryID: imageRegistryID,
@@ -104,6 +107,7 @@ func DeleteImageRegistry(ctx context.Context, imageRegistryID string, projectID
 	if err != nil {
 		return "", err
 	}
+	gitOpsHandler.SyncProjectConfig(ctx, projectID)
 
 	return "image registry deleted", nil
 }
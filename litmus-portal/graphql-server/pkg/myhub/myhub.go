@@ -20,8 +20,10 @@ import (
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
 	dbOperationsMyHub "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/myhub"
 	dbSchemaMyHub "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/myhub"
+	gitOpsHandler "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/gitops/handler"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/myhub/handler"
 	myHubOps "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/myhub/ops"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/secrets"
 )
 
 const (
@@ -73,6 +75,11 @@ func AddMyHub(ctx context.Context, myhub model.CreateMyHub, projectID string) (*
 		LastSyncedAt:  strconv.FormatInt(time.Now().Unix(), 10),
 	}
 
+	err = encryptCredentials(newHub)
+	if err != nil {
+		return nil, err
+	}
+
 	// Adding the new hub into database with the given username.
 	err = dbOperationsMyHub.CreateMyHub(ctx, newHub)
 	if err != nil {
@@ -85,6 +92,7 @@ func AddMyHub(ctx context.Context, myhub model.CreateMyHub, projectID string) (*
 	if err != nil {
 		log.Print("Error", err)
 	}
+	gitOpsHandler.SyncProjectConfig(ctx, projectID)
 
 	return newHub.GetOutputMyHub(), nil
 }
@@ -121,12 +129,18 @@ func SaveMyHub(ctx context.Context, myhub model.CreateMyHub, projectID string) (
 		LastSyncedAt:  strconv.FormatInt(time.Now().Unix(), 10),
 	}
 
+	err = encryptCredentials(newHub)
+	if err != nil {
+		return nil, err
+	}
+
 	// Adding the new hub into database with the given username without cloning.
 	err = dbOperationsMyHub.CreateMyHub(ctx, newHub)
 	if err != nil {
 		log.Print("ERROR", err)
 		return nil, err
 	}
+	gitOpsHandler.SyncProjectConfig(ctx, projectID)
 
 	return newHub.GetOutputMyHub(), nil
 }
@@ -161,21 +175,19 @@ func HubStatus(ctx context.Context, projectID string) ([]*model.MyHubStatus, err
 			}
 		}
 		hubDetail = &model.MyHubStatus{
-			IsAvailable:   isConfirmed,
-			ID:            hub.ID,
-			RepoURL:       hub.RepoURL,
-			HubName:       hub.HubName,
-			RepoBranch:    hub.RepoBranch,
-			IsPrivate:     hub.IsPrivate,
-			AuthType:      model.AuthType(hub.AuthType),
-			Token:         hub.Token,
-			UserName:      hub.UserName,
-			Password:      hub.Password,
-			SSHPrivateKey: hub.SSHPrivateKey,
-			SSHPublicKey:  hub.SSHPublicKey,
-			IsRemoved:     hub.IsRemoved,
-			LastSyncedAt:  hub.LastSyncedAt,
-			TotalExp:      strconv.Itoa(sum),
+			IsAvailable:  isConfirmed,
+			ID:           hub.ID,
+			RepoURL:      hub.RepoURL,
+			HubName:      hub.HubName,
+			RepoBranch:   hub.RepoBranch,
+			IsPrivate:    hub.IsPrivate,
+			AuthType:     model.AuthType(hub.AuthType),
+			UserName:     hub.UserName,
+			SSHPublicKey: hub.SSHPublicKey,
+			Secrets:      hub.GetSecretsInfo(),
+			IsRemoved:    hub.IsRemoved,
+			LastSyncedAt: hub.LastSyncedAt,
+			TotalExp:     strconv.Itoa(sum),
 		}
 		hubDetails = append(hubDetails, hubDetail)
 	}
@@ -252,17 +264,9 @@ func SyncHub(ctx context.Context, hubID string) ([]*model.MyHubStatus, error) {
 		return nil, err
 	}
 
-	syncHubInput := model.CloningInput{
-		HubName:       myhub.HubName,
-		ProjectID:     myhub.ProjectID,
-		RepoURL:       myhub.RepoURL,
-		RepoBranch:    myhub.RepoBranch,
-		IsPrivate:     myhub.IsPrivate,
-		UserName:      myhub.UserName,
-		Password:      myhub.Password,
-		AuthType:      model.AuthType(myhub.AuthType),
-		Token:         myhub.Token,
-		SSHPrivateKey: myhub.SSHPrivateKey,
+	syncHubInput, err := getCloningInput(myhub)
+	if err != nil {
+		return nil, err
 	}
 
 	time := strconv.FormatInt(time.Now().Unix(), 10)
@@ -320,6 +324,27 @@ func GetAllHubs(ctx context.Context) ([]*model.MyHub, error) {
 
 func UpdateMyHub(ctx context.Context, myhub model.UpdateMyHub, projectID string) (*model.MyHub, error) {
 
+	prevMyHub, err := dbOperationsMyHub.GetHubByID(ctx, myhub.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// credentials are never sent back to the client, keep the stored ones if they were not changed
+	if prevMyHub.AuthType == myhub.AuthType.String() {
+		if myhub.Token == nil {
+			myhub.Token, err = secrets.DecryptPtr(prevMyHub.Token)
+		}
+		if err == nil && myhub.Password == nil {
+			myhub.Password, err = secrets.DecryptPtr(prevMyHub.Password)
+		}
+		if err == nil && myhub.SSHPrivateKey == nil {
+			myhub.SSHPrivateKey, err = secrets.DecryptPtr(prevMyHub.SSHPrivateKey)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	cloneHub := model.CloningInput{
 		ProjectID:     projectID,
 		RepoBranch:    myhub.RepoBranch,
@@ -333,10 +358,6 @@ func UpdateMyHub(ctx context.Context, myhub model.UpdateMyHub, projectID string)
 		SSHPrivateKey: myhub.SSHPrivateKey,
 	}
 
-	prevMyHub, err := dbOperationsMyHub.GetHubByID(ctx, myhub.ID)
-	if err != nil {
-		return nil, err
-	}
 	if prevMyHub.HubName != myhub.HubName {
 		IsExist, err := IsMyHubAvailable(ctx, myhub.HubName, projectID)
 		if err != nil {
@@ -362,11 +383,21 @@ func UpdateMyHub(ctx context.Context, myhub model.UpdateMyHub, projectID string)
 
 	time := strconv.FormatInt(time.Now().Unix(), 10)
 
+	storedHub := &dbSchemaMyHub.MyHub{
+		Token:         myhub.Token,
+		Password:      myhub.Password,
+		SSHPrivateKey: myhub.SSHPrivateKey,
+	}
+	err = encryptCredentials(storedHub)
+	if err != nil {
+		return nil, err
+	}
+
 	query := bson.D{{"myhub_id", myhub.ID}, {"IsRemoved", false}}
 	update := bson.D{{"$set", bson.D{{"repo_url", myhub.RepoURL}, {"repo_branch", myhub.RepoBranch},
 		{"hub_name", myhub.HubName}, {"IsPrivate", myhub.IsPrivate}, {"AuthType", myhub.AuthType},
-		{"Token", myhub.Token}, {"UserName", myhub.UserName}, {"Password", myhub.Password},
-		{"SSHPrivateKey", myhub.SSHPrivateKey}, {"SSHPublicKey", myhub.SSHPublicKey}, {"updated_at", time}}}}
+		{"Token", storedHub.Token}, {"UserName", myhub.UserName}, {"Password", storedHub.Password},
+		{"SSHPrivateKey", storedHub.SSHPrivateKey}, {"SSHPublicKey", myhub.SSHPublicKey}, {"updated_at", time}}}}
 
 	// Updating the new hub into database with the given username.
 	err = dbOperationsMyHub.UpdateMyHub(ctx, query, update)
@@ -378,7 +409,10 @@ func UpdateMyHub(ctx context.Context, myhub model.UpdateMyHub, projectID string)
 	var newMyhub model.MyHub
 	copier.Copy(&newMyhub, &myhub)
 
+	newMyhub.Token, newMyhub.Password, newMyhub.SSHPrivateKey = nil, nil, nil
+	newMyhub.Secrets = storedHub.GetSecretsInfo()
 	newMyhub.UpdatedAt = time
+	gitOpsHandler.SyncProjectConfig(ctx, projectID)
 
 	return &newMyhub, nil
 }
@@ -392,6 +426,9 @@ func DeleteMyHub(ctx context.Context, hubID string) (bool, error) {
 		log.Print("ERROR", err)
 		return false, err
 	}
+	if hub, err := dbOperationsMyHub.GetHubByID(ctx, hubID); err == nil {
+		gitOpsHandler.SyncProjectConfig(ctx, hub.ProjectID)
+	}
 	return true, nil
 }
 
@@ -427,21 +464,14 @@ var GetIconHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Reques
 func RecurringHubSync() {
 	for {
 		// Started Syncing of hubs
-		myhubs, _ := GetAllHubs(nil)
+		myhubs, _ := dbOperationsMyHub.GetHubs(nil)
 
 		for _, myhub := range myhubs {
 
-			chartsInput := model.CloningInput{
-				HubName:       myhub.HubName,
-				ProjectID:     myhub.ProjectID,
-				RepoURL:       myhub.RepoURL,
-				RepoBranch:    myhub.RepoBranch,
-				IsPrivate:     myhub.IsPrivate,
-				AuthType:      myhub.AuthType,
-				Token:         myhub.Token,
-				UserName:      myhub.UserName,
-				Password:      myhub.Password,
-				SSHPrivateKey: myhub.SSHPrivateKey,
+			chartsInput, err := getCloningInput(myhub)
+			if err != nil {
+				log.Print("Error decrypting credentials for hub ", myhub.ID, " : ", err)
+				continue
 			}
 
 			myHubOps.GitSyncHandlerForProjects(chartsInput)
@@ -459,3 +489,76 @@ func GetPredefinedWorkflowList(hubname string, projectID string) ([]string, erro
 	}
 	return expList, nil
 }
+
+// encryptCredentials encrypts the hub credentials before they are persisted
+func encryptCredentials(hub *dbSchemaMyHub.MyHub) error {
+	var err error
+	if hub.Token, err = secrets.EncryptPtr(hub.Token); err != nil {
+		return err
+	}
+	if hub.Password, err = secrets.EncryptPtr(hub.Password); err != nil {
+		return err
+	}
+	hub.SSHPrivateKey, err = secrets.EncryptPtr(hub.SSHPrivateKey)
+	return err
+}
+
+// getCloningInput constructs the cloning input for a stored hub with its credentials decrypted
+func getCloningInput(hub dbSchemaMyHub.MyHub) (model.CloningInput, error) {
+	cloneInput := model.CloningInput{
+		HubName:    hub.HubName,
+		ProjectID:  hub.ProjectID,
+		RepoURL:    hub.RepoURL,
+		RepoBranch: hub.RepoBranch,
+		IsPrivate:  hub.IsPrivate,
+		UserName:   hub.UserName,
+		AuthType:   model.AuthType(hub.AuthType),
+	}
+
+	var err error
+	if cloneInput.Token, err = secrets.DecryptPtr(hub.Token); err != nil {
+		return model.CloningInput{}, err
+	}
+	if cloneInput.Password, err = secrets.DecryptPtr(hub.Password); err != nil {
+		return model.CloningInput{}, err
+	}
+	if cloneInput.SSHPrivateKey, err = secrets.DecryptPtr(hub.SSHPrivateKey); err != nil {
+		return model.CloningInput{}, err
+	}
+	return cloneInput, nil
+}
+
+// MigrateSecrets encrypts hub credentials still stored in plaintext and re-encrypts the ones written with a rotated key
+func MigrateSecrets(ctx context.Context) error {
+	myhubs, err := dbOperationsMyHub.GetHubs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, hub := range myhubs {
+		update := bson.D{}
+		for field, value := range map[string]*string{"Token": hub.Token, "Password": hub.Password, "SSHPrivateKey": hub.SSHPrivateKey} {
+			migrate, err := secrets.NeedsMigration(value)
+			if err != nil {
+				return err
+			}
+			if !migrate {
+				continue
+			}
+			encrypted, err := secrets.Reencrypt(value)
+			if err != nil {
+				log.Print("Failed to migrate hub credential ", field, " for hub ", hub.ID, " : ", err)
+				continue
+			}
+			update = append(update, bson.E{Key: field, Value: encrypted})
+		}
+		if len(update) == 0 {
+			continue
+		}
+		err = dbOperationsMyHub.UpdateMyHub(ctx, bson.D{{"myhub_id", hub.ID}}, bson.D{{"$set", update}})
+		if err != nil {
+			return err
+		}
+		log.Print("Migrated credentials for hub : ", hub.ID)
+	}
+	return nil
+}
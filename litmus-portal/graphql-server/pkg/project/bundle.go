@@ -0,0 +1,264 @@
+package project
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	dbSchemaAnalytics "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/analytics"
+	dbSchemaGitOps "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/gitops"
+	dbSchemaImageRegistry "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/image_registry"
+	dbSchemaMyHub "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/myhub"
+	dbSchemaWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+	dbSchemaWorkflowTemplate "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflowtemplate"
+)
+
+// A project bundle is a tar.gz archive holding one JSON document per resource, the documents use the
+// field names of the DB schema:
+//
+//	bundle.json                     bundle metadata, see BundleInfo
+//	workflows/<workflow_id>.json
+//	templates/<template_id>.json
+//	chaoshubs/<hub_id>.json
+//	datasources/<ds_id>.json
+//	dashboards/<db_id>.json         dashboard along with its panels
+//	image-registries/<id>.json
+//	gitops.json
+//
+// Secrets (hub, data source and git credentials) are only present when the bundle was exported with them.
+const (
+	// BundleVersion is the version of the bundle format written by ExportProject
+	BundleVersion = 1
+
+	bundleInfoFile       = "bundle.json"
+	bundleWorkflowsDir   = "workflows"
+	bundleTemplatesDir   = "templates"
+	bundleHubsDir        = "chaoshubs"
+	bundleDataSourcesDir = "datasources"
+	bundleDashboardsDir  = "dashboards"
+	bundleRegistriesDir  = "image-registries"
+	bundleGitOpsFile     = "gitops.json"
+
+	maxBundleFileSize = 16 << 20
+	maxBundleSize     = 128 << 20
+)
+
+// BundleInfo describes the exported project
+type BundleInfo struct {
+	Version        int             `bson:"version"`
+	ProjectID      string          `bson:"project_id"`
+	ProjectName    string          `bson:"project_name"`
+	ExportedAt     string          `bson:"exported_at"`
+	IncludeSecrets bool            `bson:"include_secrets"`
+	Clusters       []BundleCluster `bson:"clusters"`
+}
+
+// BundleCluster identifies a cluster referenced by the bundle so that it can be mapped on import
+type BundleCluster struct {
+	ClusterID   string `bson:"cluster_id"`
+	ClusterName string `bson:"cluster_name"`
+}
+
+// BundleDashboard is a dashboard along with the panels of its panel groups
+type BundleDashboard struct {
+	Dashboard dbSchemaAnalytics.DashBoard `bson:"dashboard"`
+	Panels    []*dbSchemaAnalytics.Panel  `bson:"panels"`
+}
+
+// Bundle is the in-memory form of a project bundle
+type Bundle struct {
+	Info            BundleInfo
+	Workflows       []dbSchemaWorkflow.ChaosWorkFlowInput
+	Templates       []dbSchemaWorkflowTemplate.ManifestTemplate
+	Hubs            []dbSchemaMyHub.MyHub
+	DataSources     []dbSchemaAnalytics.DataSource
+	Dashboards      []BundleDashboard
+	ImageRegistries []dbSchemaImageRegistry.ImageRegistry
+	GitOps          *dbSchemaGitOps.GitConfigDB
+}
+
+// bundleFile is a single document of the archive
+type bundleFile struct {
+	name string
+	doc  interface{}
+}
+
+// files returns the documents of the bundle in a stable order
+func (b *Bundle) files() []bundleFile {
+	files := []bundleFile{{bundleInfoFile, b.Info}}
+	for _, workflow := range b.Workflows {
+		files = append(files, bundleFile{bundleWorkflowsDir + "/" + workflow.WorkflowID + ".json", workflow})
+	}
+	for _, template := range b.Templates {
+		files = append(files, bundleFile{bundleTemplatesDir + "/" + template.TemplateID + ".json", template})
+	}
+	for _, hub := range b.Hubs {
+		files = append(files, bundleFile{bundleHubsDir + "/" + hub.ID + ".json", hub})
+	}
+	for _, ds := range b.DataSources {
+		files = append(files, bundleFile{bundleDataSourcesDir + "/" + ds.DsID + ".json", ds})
+	}
+	for _, dashboard := range b.Dashboards {
+		files = append(files, bundleFile{bundleDashboardsDir + "/" + dashboard.Dashboard.DbID + ".json", dashboard})
+	}
+	for _, registry := range b.ImageRegistries {
+		files = append(files, bundleFile{bundleRegistriesDir + "/" + registry.ImageRegistryID + ".json", registry})
+	}
+	if b.GitOps != nil {
+		files = append(files, bundleFile{bundleGitOpsFile, b.GitOps})
+	}
+	return files
+}
+
+// Archive writes the bundle as a tar.gz archive
+func (b *Bundle) Archive() ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	modTime := time.Now()
+	for _, file := range b.files() {
+		doc, err := bson.MarshalExtJSON(file.doc, false, false)
+		if err != nil {
+			return nil, errors.New("failed to serialize " + file.name + " : " + err.Error())
+		}
+		var data bytes.Buffer
+		if err = json.Indent(&data, doc, "", "  "); err != nil {
+			return nil, err
+		}
+		err = tw.WriteHeader(&tar.Header{
+			Name:     file.name,
+			Mode:     0644,
+			Size:     int64(data.Len()),
+			ModTime:  modTime,
+			Typeflag: tar.TypeReg,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err = tw.Write(data.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ParseBundle reads a tar.gz archive produced by Archive, unknown files are ignored
+func ParseBundle(data []byte) (*Bundle, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("bundle is not a gzip archive : " + err.Error())
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	files := map[string][]byte{}
+	total := int64(0)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.New("invalid bundle archive : " + err.Error())
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		if path.IsAbs(name) || strings.HasPrefix(name, "..") {
+			return nil, errors.New("invalid file path in bundle : " + header.Name)
+		}
+		if header.Size > maxBundleFileSize {
+			return nil, errors.New("bundle file too large : " + name)
+		}
+		total += header.Size
+		if total > maxBundleSize {
+			return nil, errors.New("bundle too large")
+		}
+		content, err := ioutil.ReadAll(io.LimitReader(tr, maxBundleFileSize))
+		if err != nil {
+			return nil, err
+		}
+		files[name] = content
+	}
+
+	info, ok := files[bundleInfoFile]
+	if !ok {
+		return nil, errors.New("bundle is missing " + bundleInfoFile)
+	}
+	bundle := &Bundle{}
+	if err := bson.UnmarshalExtJSON(info, false, &bundle.Info); err != nil {
+		return nil, errors.New("invalid " + bundleInfoFile + " : " + err.Error())
+	}
+	if bundle.Info.Version < 1 || bundle.Info.Version > BundleVersion {
+		return nil, errors.New("unsupported bundle version")
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		content := files[name]
+		dir, _ := path.Split(name)
+		dir = strings.TrimSuffix(dir, "/")
+		var err error
+		switch {
+		case name == bundleGitOpsFile:
+			bundle.GitOps = &dbSchemaGitOps.GitConfigDB{}
+			err = bson.UnmarshalExtJSON(content, false, bundle.GitOps)
+		case dir == bundleWorkflowsDir:
+			var workflow dbSchemaWorkflow.ChaosWorkFlowInput
+			if err = bson.UnmarshalExtJSON(content, false, &workflow); err == nil {
+				bundle.Workflows = append(bundle.Workflows, workflow)
+			}
+		case dir == bundleTemplatesDir:
+			var template dbSchemaWorkflowTemplate.ManifestTemplate
+			if err = bson.UnmarshalExtJSON(content, false, &template); err == nil {
+				bundle.Templates = append(bundle.Templates, template)
+			}
+		case dir == bundleHubsDir:
+			var hub dbSchemaMyHub.MyHub
+			if err = bson.UnmarshalExtJSON(content, false, &hub); err == nil {
+				bundle.Hubs = append(bundle.Hubs, hub)
+			}
+		case dir == bundleDataSourcesDir:
+			var ds dbSchemaAnalytics.DataSource
+			if err = bson.UnmarshalExtJSON(content, false, &ds); err == nil {
+				bundle.DataSources = append(bundle.DataSources, ds)
+			}
+		case dir == bundleDashboardsDir:
+			var dashboard BundleDashboard
+			if err = bson.UnmarshalExtJSON(content, false, &dashboard); err == nil {
+				bundle.Dashboards = append(bundle.Dashboards, dashboard)
+			}
+		case dir == bundleRegistriesDir:
+			var registry dbSchemaImageRegistry.ImageRegistry
+			if err = bson.UnmarshalExtJSON(content, false, &registry); err == nil {
+				bundle.ImageRegistries = append(bundle.ImageRegistries, registry)
+			}
+		}
+		if err != nil {
+			return nil, errors.New("invalid bundle file " + name + " : " + err.Error())
+		}
+	}
+	return bundle, nil
+}
@@ -0,0 +1,142 @@
+package project
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+
+	dbSchemaAnalytics "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/analytics"
+	dbSchemaGitOps "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/gitops"
+	dbSchemaWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+)
+
+// tarball builds a tar.gz archive of the files in order
+func tarball(t *testing.T, files ...[2]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, file := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: file[0], Mode: 0644, Size: int64(len(file[1])), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(file[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestBundleArchive(t *testing.T) {
+	bundle := &Bundle{
+		Info: BundleInfo{
+			Version:     BundleVersion,
+			ProjectID:   "project-1",
+			ProjectName: "demo",
+			ExportedAt:  "1600000000",
+			Clusters:    []BundleCluster{{ClusterID: "cluster-1", ClusterName: "self-agent"}},
+		},
+		Workflows: []dbSchemaWorkflow.ChaosWorkFlowInput{
+			{WorkflowID: "wf-1", WorkflowName: "pod-delete", WorkflowManifest: `{"kind":"Workflow"}`, ClusterID: "cluster-1"},
+			{WorkflowID: "wf-2", WorkflowName: "pod-cpu-hog", ClusterID: "cluster-1"},
+		},
+		DataSources: []dbSchemaAnalytics.DataSource{{DsID: "ds-1", DsName: "prometheus", DsURL: "http://prometheus:9090"}},
+		Dashboards: []BundleDashboard{{
+			Dashboard: dbSchemaAnalytics.DashBoard{DbID: "db-1", DsID: "ds-1", DbName: "overview"},
+			Panels:    []*dbSchemaAnalytics.Panel{{PanelID: "panel-1", PanelName: "cpu"}},
+		}},
+		GitOps: &dbSchemaGitOps.GitConfigDB{ProjectID: "project-1", RepositoryURL: "https://example.com/repo.git", Branch: "main"},
+	}
+
+	archive, err := bundle.Archive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseBundle(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.Info.ProjectID != "project-1" || parsed.Info.ProjectName != "demo" || len(parsed.Info.Clusters) != 1 {
+		t.Errorf("unexpected bundle info %+v", parsed.Info)
+	}
+	if len(parsed.Workflows) != 2 || parsed.Workflows[0].WorkflowID != "wf-1" || parsed.Workflows[0].WorkflowManifest != `{"kind":"Workflow"}` {
+		t.Errorf("unexpected workflows %+v", parsed.Workflows)
+	}
+	if len(parsed.DataSources) != 1 || parsed.DataSources[0].DsURL != "http://prometheus:9090" {
+		t.Errorf("unexpected data sources %+v", parsed.DataSources)
+	}
+	if len(parsed.Dashboards) != 1 || len(parsed.Dashboards[0].Panels) != 1 || parsed.Dashboards[0].Panels[0].PanelID != "panel-1" {
+		t.Errorf("unexpected dashboards %+v", parsed.Dashboards)
+	}
+	if parsed.GitOps == nil || parsed.GitOps.Branch != "main" {
+		t.Errorf("unexpected gitops config %+v", parsed.GitOps)
+	}
+	if len(parsed.Templates) != 0 || len(parsed.Hubs) != 0 || len(parsed.ImageRegistries) != 0 {
+		t.Error("parsed resources which weren't exported")
+	}
+}
+
+func TestParseBundle(t *testing.T) {
+	info := `{"version": 1, "project_id": "project-1"}`
+	workflow := `{"workflow_id": "wf-1"}`
+
+	tests := []struct {
+		name      string
+		data      []byte
+		workflows int
+		wantErr   string
+	}{
+		{name: "valid", data: tarball(t, [2]string{"bundle.json", info}, [2]string{"workflows/wf-1.json", workflow}), workflows: 1},
+		{name: "relative names", data: tarball(t, [2]string{"./bundle.json", info}, [2]string{"./workflows/wf-1.json", workflow}), workflows: 1},
+		{name: "unknown files are ignored", data: tarball(t, [2]string{"bundle.json", info}, [2]string{"README.md", "bundle"}), workflows: 0},
+		{name: "parent directory", data: tarball(t, [2]string{"bundle.json", info}, [2]string{"../workflows/wf-1.json", workflow}), wantErr: "invalid file path"},
+		{name: "nested parent directory", data: tarball(t, [2]string{"bundle.json", info}, [2]string{"workflows/../../etc/passwd", "root"}), wantErr: "invalid file path"},
+		{name: "absolute path", data: tarball(t, [2]string{"bundle.json", info}, [2]string{"/etc/passwd", "root"}), wantErr: "invalid file path"},
+		{name: "missing info", data: tarball(t, [2]string{"workflows/wf-1.json", workflow}), wantErr: "missing bundle.json"},
+		{name: "unsupported version", data: tarball(t, [2]string{"bundle.json", `{"version": 2}`}), wantErr: "unsupported bundle version"},
+		{name: "invalid document", data: tarball(t, [2]string{"bundle.json", info}, [2]string{"workflows/wf-1.json", "{"}), wantErr: "invalid bundle file"},
+		{name: "not gzip", data: []byte("bundle"), wantErr: "not a gzip archive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundle, err := ParseBundle(tt.data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseBundle() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(bundle.Workflows) != tt.workflows {
+				t.Errorf("got %d workflows, want %d", len(bundle.Workflows), tt.workflows)
+			}
+		})
+	}
+}
+
+func TestBundleFileName(t *testing.T) {
+	tests := []struct {
+		projectName string
+		want        string
+	}{
+		{projectName: "demo", want: "demo-1600000000.tar.gz"},
+		{projectName: "my project/../x", want: "my-project----x-1600000000.tar.gz"},
+		{projectName: "team_a-b", want: "team_a-b-1600000000.tar.gz"},
+	}
+	for _, tt := range tests {
+		if got := bundleFileName(tt.projectName, "1600000000"); got != tt.want {
+			t.Errorf("bundleFileName(%q) = %q, want %q", tt.projectName, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package project
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	analyticsOps "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics/ops"
+	dbOperationsAnalytics "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/analytics"
+	dbOperationsCluster "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/cluster"
+	dbOperationsGitOps "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/gitops"
+	dbOperationsImageRegistry "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/image_registry"
+	dbOperationsMyHub "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/myhub"
+	dbOperationsProject "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/project"
+	dbOperationsWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+	dbOperationsWorkflowTemplate "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflowtemplate"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/secrets"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/utils"
+)
+
+// ExportProject builds a bundle of the project configuration, credentials are only included if requested
+func ExportProject(ctx context.Context, projectID string, includeSecrets bool) (*model.ProjectBundle, error) {
+	project, err := dbOperationsProject.GetProject(ctx, bson.D{{"_id", projectID}})
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &Bundle{
+		Info: BundleInfo{
+			Version:        BundleVersion,
+			ProjectID:      projectID,
+			ProjectName:    project.Name,
+			ExportedAt:     strconv.FormatInt(time.Now().Unix(), 10),
+			IncludeSecrets: includeSecrets,
+		},
+	}
+
+	clusters, err := dbOperationsCluster.GetClusterWithProjectID(projectID, nil)
+	if err != nil {
+		return nil, errors.New("failed to get clusters : " + err.Error())
+	}
+	for _, cluster := range clusters {
+		bundle.Info.Clusters = append(bundle.Info.Clusters, BundleCluster{ClusterID: cluster.ClusterID, ClusterName: cluster.ClusterName})
+	}
+
+	workflows, err := dbOperationsWorkflow.GetWorkflows(bson.D{{"project_id", projectID}, {"isRemoved", false}})
+	if err != nil {
+		return nil, errors.New("failed to get workflows : " + err.Error())
+	}
+	for _, workflow := range workflows {
+		workflow.WorkflowRuns = nil
+		workflow.GitOps = nil
+		bundle.Workflows = append(bundle.Workflows, workflow)
+	}
+
+	bundle.Templates, err = dbOperationsWorkflowTemplate.GetTemplatesByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, errors.New("failed to get templates : " + err.Error())
+	}
+
+	hubs, err := dbOperationsMyHub.GetMyHubByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, errors.New("failed to get chaos hubs : " + err.Error())
+	}
+	for _, hub := range hubs {
+		hub.Token, hub.Password, hub.SSHPrivateKey, err = exportSecrets(includeSecrets, hub.Token, hub.Password, hub.SSHPrivateKey)
+		if err != nil {
+			return nil, errors.New("failed to export credentials of chaos hub " + hub.HubName + " : " + err.Error())
+		}
+		bundle.Hubs = append(bundle.Hubs, hub)
+	}
+
+	dataSources, err := dbOperationsAnalytics.ListDataSource(bson.D{{"project_id", projectID}, {"is_removed", false}})
+	if err != nil {
+		return nil, errors.New("failed to get data sources : " + err.Error())
+	}
+	for _, ds := range dataSources {
+		if !includeSecrets {
+			analyticsOps.RemoveDataSourceSecrets(ds)
+		} else if err := analyticsOps.DecryptDataSourceSecrets(ds); err != nil {
+			return nil, errors.New("failed to export credentials of data source " + ds.DsName + " : " + err.Error())
+		}
+		bundle.DataSources = append(bundle.DataSources, *ds)
+	}
+
+	dashboards, err := dbOperationsAnalytics.ListDashboard(bson.D{{"project_id", projectID}, {"is_removed", false}})
+	if err != nil {
+		return nil, errors.New("failed to get dashboards : " + err.Error())
+	}
+	for _, dashboard := range dashboards {
+		var panelGroupIDs bson.A
+		for _, panelGroup := range dashboard.PanelGroups {
+			panelGroupIDs = append(panelGroupIDs, panelGroup.PanelGroupID)
+		}
+		panels, err := dbOperationsAnalytics.ListPanel(bson.D{{"panel_group_id", bson.D{{"$in", panelGroupIDs}}}, {"is_removed", false}})
+		if err != nil {
+			return nil, errors.New("failed to get panels of dashboard " + dashboard.DbName + " : " + err.Error())
+		}
+		bundle.Dashboards = append(bundle.Dashboards, BundleDashboard{Dashboard: *dashboard, Panels: panels})
+	}
+
+	bundle.ImageRegistries, err = dbOperationsImageRegistry.ListImageRegistries(ctx, bson.D{{"project_id", projectID}, {"is_removed", false}})
+	if err != nil {
+		return nil, errors.New("failed to get image registries : " + err.Error())
+	}
+
+	gitConfig, err := dbOperationsGitOps.GetGitConfig(ctx, projectID)
+	if err != nil {
+		return nil, errors.New("failed to get gitops config : " + err.Error())
+	}
+	if gitConfig != nil {
+		gitConfig.LatestCommit = ""
+		gitConfig.Token, gitConfig.Password, gitConfig.SSHPrivateKey, err = exportSecrets(includeSecrets, gitConfig.Token, gitConfig.Password, gitConfig.SSHPrivateKey)
+		if err != nil {
+			return nil, errors.New("failed to export gitops credentials : " + err.Error())
+		}
+		bundle.GitOps = gitConfig
+	}
+
+	archive, err := bundle.Archive()
+	if err != nil {
+		return nil, err
+	}
+	return &model.ProjectBundle{
+		FileName: bundleFileName(project.Name, bundle.Info.ExportedAt),
+		Version:  BundleVersion,
+		Content:  base64.StdEncoding.EncodeToString(archive),
+	}, nil
+}
+
+// exportSecrets decrypts the stored credentials for the bundle, or drops them if secrets are excluded
+func exportSecrets(includeSecrets bool, token, password, sshPrivateKey *string) (*string, *string, *string, error) {
+	if !includeSecrets {
+		return nil, nil, nil, nil
+	}
+	var err error
+	if token, err = secrets.DecryptPtr(token); err != nil {
+		return nil, nil, nil, err
+	}
+	if password, err = secrets.DecryptPtr(password); err != nil {
+		return nil, nil, nil, err
+	}
+	sshPrivateKey, err = secrets.DecryptPtr(sshPrivateKey)
+	return token, password, sshPrivateKey, err
+}
+
+// bundleFileName returns the file name of the archive for the project
+func bundleFileName(projectName, exportedAt string) string {
+	return utils.SanitizeFileName(projectName) + "-" + exportedAt + ".tar.gz"
+}
@@ -0,0 +1,693 @@
+package project
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+	"github.com/tidwall/sjson"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	analyticsHandler "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics/handler"
+	analyticsOps "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics/ops"
+	wfHandler "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/chaos-workflow/handler"
+	store "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/data-store"
+	dbOperationsAnalytics "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/analytics"
+	dbSchemaAnalytics "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/analytics"
+	dbOperationsCluster "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/cluster"
+	dbOperationsGitOps "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/gitops"
+	dbSchemaGitOps "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/gitops"
+	dbOperationsImageRegistry "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/image_registry"
+	dbSchemaImageRegistry "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/image_registry"
+	dbOperationsMyHub "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/myhub"
+	dbSchemaMyHub "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/myhub"
+	dbOperationsProject "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/project"
+	dbOperationsWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+	dbSchemaWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+	dbOperationsWorkflowTemplate "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflowtemplate"
+	dbSchemaWorkflowTemplate "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflowtemplate"
+	gitOpsHandler "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/gitops/handler"
+	imageRegistryOps "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/image_registry/ops"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/myhub"
+)
+
+// importer holds the state of a single project import, ids of the bundle are remapped to newly created resources
+type importer struct {
+	ctx         context.Context
+	projectID   string
+	projectName string
+	policy      model.ImportConflictPolicy
+	store       *store.StateData
+	clusters    map[string]string
+	dataSources map[string]string
+	dashboards  map[string]string
+	panels      map[string]string
+	resources   []*model.ImportedResource
+}
+
+// ImportProject imports a bundle produced by ExportProject into the project. Resources are matched by name,
+// conflicts are resolved with the conflict policy and clusters are mapped with the given mapping or by name
+func ImportProject(ctx context.Context, input model.ImportProjectInput, r *store.StateData) (*model.ImportProjectResponse, error) {
+	data, err := base64.StdEncoding.DecodeString(input.Bundle)
+	if err != nil {
+		return nil, errors.New("bundle is not base64 encoded : " + err.Error())
+	}
+	bundle, err := ParseBundle(data)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := dbOperationsProject.GetProject(ctx, bson.D{{"_id", input.ProjectID}})
+	if err != nil {
+		return nil, err
+	}
+
+	i := &importer{
+		ctx:         ctx,
+		projectID:   input.ProjectID,
+		projectName: project.Name,
+		policy:      model.ImportConflictPolicySkip,
+		store:       r,
+		dataSources: map[string]string{},
+		dashboards:  map[string]string{},
+		panels:      map[string]string{},
+	}
+	if input.ConflictPolicy != nil {
+		if !input.ConflictPolicy.IsValid() {
+			return nil, errors.New("invalid conflict policy : " + input.ConflictPolicy.String())
+		}
+		i.policy = *input.ConflictPolicy
+	}
+	i.clusters, err = mapClusters(input.ProjectID, bundle.Info.Clusters, input.ClusterMapping)
+	if err != nil {
+		return nil, err
+	}
+
+	// GitOps is set up first so that the imported workflows and configuration are written to the repo
+	if bundle.GitOps != nil {
+		i.importGitOps(*bundle.GitOps)
+	}
+	for _, registry := range bundle.ImageRegistries {
+		i.importImageRegistry(registry)
+	}
+	for _, hub := range bundle.Hubs {
+		i.importHub(hub)
+	}
+	for _, template := range bundle.Templates {
+		i.importTemplate(template)
+	}
+	for _, ds := range bundle.DataSources {
+		i.importDataSource(ds)
+	}
+	for _, dashboard := range bundle.Dashboards {
+		i.importDashboard(dashboard)
+	}
+	for _, workflow := range bundle.Workflows {
+		i.importWorkflow(workflow)
+	}
+	gitOpsHandler.SyncProjectConfig(ctx, input.ProjectID)
+
+	return &model.ImportProjectResponse{
+		Version:   bundle.Info.Version,
+		Resources: i.resources,
+	}, nil
+}
+
+// mapClusters maps the clusters of the bundle to the clusters of the project, explicit mappings take precedence
+// over clusters matched by name
+func mapClusters(projectID string, bundleClusters []BundleCluster, mappings []*model.ClusterMapping) (map[string]string, error) {
+	clusters, err := dbOperationsCluster.GetClusterWithProjectID(projectID, nil)
+	if err != nil {
+		return nil, errors.New("failed to get clusters : " + err.Error())
+	}
+	clusterIDs := map[string]bool{}
+	clusterNames := map[string]string{}
+	for _, cluster := range clusters {
+		clusterIDs[cluster.ClusterID] = true
+		clusterNames[cluster.ClusterName] = cluster.ClusterID
+	}
+
+	result := map[string]string{}
+	for _, cluster := range bundleClusters {
+		if id, ok := clusterNames[cluster.ClusterName]; ok {
+			result[cluster.ClusterID] = id
+		}
+	}
+	for _, mapping := range mappings {
+		if !clusterIDs[mapping.TargetClusterID] {
+			return nil, errors.New("cluster " + mapping.TargetClusterID + " doesn't belong to this project")
+		}
+		result[mapping.SourceClusterID] = mapping.TargetClusterID
+	}
+	return result, nil
+}
+
+// record adds the outcome of importing a resource to the response
+func (i *importer) record(kind, name, sourceID string, targetID *string, status model.ImportStatus, err error) {
+	resource := &model.ImportedResource{
+		Kind:     kind,
+		Name:     name,
+		SourceID: sourceID,
+		TargetID: targetID,
+		Status:   status,
+	}
+	if err != nil {
+		message := err.Error()
+		resource.Message = &message
+	}
+	i.resources = append(i.resources, resource)
+}
+
+// resolveConflict returns the status and name to import a resource with, an empty name means the resource is skipped
+func (i *importer) resolveConflict(name string, taken map[string]bool) (model.ImportStatus, string) {
+	if !taken[name] {
+		return model.ImportStatusCreated, name
+	}
+	switch i.policy {
+	case model.ImportConflictPolicyOverwrite:
+		return model.ImportStatusOverwritten, name
+	case model.ImportConflictPolicyRename:
+		newName := name + "-imported"
+		for n := 2; taken[newName]; n++ {
+			newName = name + "-imported-" + strconv.Itoa(n)
+		}
+		return model.ImportStatusRenamed, newName
+	}
+	return model.ImportStatusSkipped, ""
+}
+
+func (i *importer) importGitOps(config dbSchemaGitOps.GitConfigDB) {
+	const kind = "GitOps"
+	existing, err := dbOperationsGitOps.GetGitConfig(i.ctx, i.projectID)
+	if err != nil {
+		i.record(kind, config.RepositoryURL, config.ProjectID, nil, model.ImportStatusFailed, err)
+		return
+	}
+	if config.AuthType != model.AuthTypeNone && config.Token == nil && config.Password == nil && config.SSHPrivateKey == nil {
+		i.record(kind, config.RepositoryURL, config.ProjectID, nil, model.ImportStatusSkipped, errors.New("credentials are not part of the bundle, enable GitOps from the portal"))
+		return
+	}
+	if existing != nil && i.policy != model.ImportConflictPolicyOverwrite {
+		i.record(kind, config.RepositoryURL, config.ProjectID, nil, model.ImportStatusSkipped, errors.New("GitOps is already enabled for the project"))
+		return
+	}
+
+	gitConfig := model.GitConfig{
+		ProjectID:     i.projectID,
+		Branch:        config.Branch,
+		RepoURL:       config.RepositoryURL,
+		AuthType:      config.AuthType,
+		Token:         config.Token,
+		UserName:      config.UserName,
+		Password:      config.Password,
+		SSHPrivateKey: config.SSHPrivateKey,
+	}
+	status := model.ImportStatusCreated
+	if existing == nil {
+		_, err = gitOpsHandler.EnableGitOpsHandler(i.ctx, gitConfig)
+	} else {
+		status = model.ImportStatusOverwritten
+		_, err = gitOpsHandler.UpdateGitOpsDetailsHandler(i.ctx, gitConfig)
+	}
+	if err != nil {
+		status = model.ImportStatusFailed
+	}
+	i.record(kind, config.RepositoryURL, config.ProjectID, &i.projectID, status, err)
+}
+
+func (i *importer) importImageRegistry(registry dbSchemaImageRegistry.ImageRegistry) {
+	const kind = "ImageRegistry"
+	existing, err := dbOperationsImageRegistry.ListImageRegistries(i.ctx, bson.D{{"project_id", i.projectID}, {"is_removed", false}})
+	if err != nil {
+		i.record(kind, registry.ImageRegistryName, registry.ImageRegistryID, nil, model.ImportStatusFailed, err)
+		return
+	}
+	if len(existing) > 0 && i.policy != model.ImportConflictPolicyOverwrite {
+		i.record(kind, registry.ImageRegistryName, registry.ImageRegistryID, &existing[0].ImageRegistryID, model.ImportStatusSkipped, errors.New("the project already has an image registry"))
+		return
+	}
+
+	input := model.ImageRegistryInput{
+		IsDefault:         registry.IsDefault,
+		ImageRegistryName: registry.ImageRegistryName,
+		ImageRepoName:     registry.ImageRepoName,
+		ImageRegistryType: registry.ImageRegistryType,
+		SecretName:        registry.SecretName,
+		SecretNamespace:   registry.SecretNamespace,
+		EnableRegistry:    registry.EnableRegistry,
+	}
+	var response *model.ImageRegistryResponse
+	status := model.ImportStatusCreated
+	if len(existing) == 0 {
+		response, err = imageRegistryOps.CreateImageRegistry(i.ctx, i.projectID, input)
+	} else {
+		status = model.ImportStatusOverwritten
+		response, err = imageRegistryOps.UpdateImageRegistry(i.ctx, existing[0].ImageRegistryID, i.projectID, input)
+	}
+	if err != nil {
+		i.record(kind, registry.ImageRegistryName, registry.ImageRegistryID, nil, model.ImportStatusFailed, err)
+		return
+	}
+	i.record(kind, registry.ImageRegistryName, registry.ImageRegistryID, &response.ImageRegistryID, status, nil)
+}
+
+func (i *importer) importHub(hub dbSchemaMyHub.MyHub) {
+	const kind = "ChaosHub"
+	existing, err := dbOperationsMyHub.GetMyHubByProjectID(i.ctx, i.projectID)
+	if err != nil {
+		i.record(kind, hub.HubName, hub.ID, nil, model.ImportStatusFailed, err)
+		return
+	}
+	taken := map[string]bool{}
+	existingIDs := map[string]string{}
+	for _, h := range existing {
+		taken[h.HubName] = true
+		existingIDs[h.HubName] = h.ID
+	}
+
+	status, name := i.resolveConflict(hub.HubName, taken)
+	var result *model.MyHub
+	switch status {
+	case model.ImportStatusSkipped:
+		targetID := existingIDs[hub.HubName]
+		i.record(kind, hub.HubName, hub.ID, &targetID, status, errors.New("a chaos hub with the same name already exists"))
+		return
+	case model.ImportStatusOverwritten:
+		result, err = myhub.UpdateMyHub(i.ctx, model.UpdateMyHub{
+			ID:            existingIDs[hub.HubName],
+			HubName:       name,
+			RepoURL:       hub.RepoURL,
+			RepoBranch:    hub.RepoBranch,
+			IsPrivate:     hub.IsPrivate,
+			AuthType:      model.AuthType(hub.AuthType),
+			Token:         hub.Token,
+			UserName:      hub.UserName,
+			Password:      hub.Password,
+			SSHPrivateKey: hub.SSHPrivateKey,
+			SSHPublicKey:  hub.SSHPublicKey,
+		}, i.projectID)
+	default:
+		result, err = myhub.AddMyHub(i.ctx, model.CreateMyHub{
+			HubName:       name,
+			RepoURL:       hub.RepoURL,
+			RepoBranch:    hub.RepoBranch,
+			IsPrivate:     hub.IsPrivate,
+			AuthType:      model.AuthType(hub.AuthType),
+			Token:         hub.Token,
+			UserName:      hub.UserName,
+			Password:      hub.Password,
+			SSHPrivateKey: hub.SSHPrivateKey,
+			SSHPublicKey:  hub.SSHPublicKey,
+		}, i.projectID)
+	}
+	if err != nil {
+		i.record(kind, hub.HubName, hub.ID, nil, model.ImportStatusFailed, err)
+		return
+	}
+	if hub.IsPrivate && hub.Token == nil && hub.Password == nil && hub.SSHPrivateKey == nil {
+		err = errors.New("credentials are not part of the bundle, set them from the portal")
+	}
+	i.record(kind, name, hub.ID, &result.ID, status, err)
+}
+
+func (i *importer) importTemplate(template dbSchemaWorkflowTemplate.ManifestTemplate) {
+	const kind = "ManifestTemplate"
+	existing, err := dbOperationsWorkflowTemplate.GetTemplatesByProjectID(i.ctx, i.projectID)
+	if err != nil {
+		i.record(kind, template.TemplateName, template.TemplateID, nil, model.ImportStatusFailed, err)
+		return
+	}
+	taken := map[string]bool{}
+	existingIDs := map[string]string{}
+	for _, t := range existing {
+		taken[t.TemplateName] = true
+		existingIDs[t.TemplateName] = t.TemplateID
+	}
+
+	status, name := i.resolveConflict(template.TemplateName, taken)
+	targetID := existingIDs[template.TemplateName]
+	switch status {
+	case model.ImportStatusSkipped:
+		i.record(kind, template.TemplateName, template.TemplateID, &targetID, status, errors.New("a template with the same name already exists"))
+		return
+	case model.ImportStatusOverwritten:
+		query := bson.D{{"template_id", targetID}}
+		update := bson.D{{"$set", bson.D{
+			{"template_description", template.TemplateDescription},
+			{"manifest", template.Manifest},
+			{"is_custom_workflow", template.IsCustomWorkflow},
+		}}}
+		err = dbOperationsWorkflowTemplate.UpdateTemplateManifest(i.ctx, query, update)
+	default:
+		targetID = uuid.New().String()
+		err = dbOperationsWorkflowTemplate.CreateWorkflowTemplate(i.ctx, &dbSchemaWorkflowTemplate.ManifestTemplate{
+			TemplateID:          targetID,
+			ProjectID:           i.projectID,
+			Manifest:            template.Manifest,
+			TemplateName:        name,
+			TemplateDescription: template.TemplateDescription,
+			ProjectName:         i.projectName,
+			CreatedAt:           strconv.FormatInt(time.Now().Unix(), 10),
+			IsCustomWorkflow:    template.IsCustomWorkflow,
+		})
+	}
+	if err != nil {
+		i.record(kind, template.TemplateName, template.TemplateID, nil, model.ImportStatusFailed, err)
+		return
+	}
+	i.record(kind, name, template.TemplateID, &targetID, status, nil)
+}
+
+// importDataSource imports the data source without a health check, so that bundles can be imported before the
+// data source is reachable from the portal
+func (i *importer) importDataSource(ds dbSchemaAnalytics.DataSource) {
+	const kind = "DataSource"
+	existing, err := dbOperationsAnalytics.ListDataSource(bson.D{{"project_id", i.projectID}, {"is_removed", false}})
+	if err != nil {
+		i.record(kind, ds.DsName, ds.DsID, nil, model.ImportStatusFailed, err)
+		return
+	}
+	taken := map[string]bool{}
+	existingDS := map[string]*dbSchemaAnalytics.DataSource{}
+	for _, d := range existing {
+		taken[d.DsName] = true
+		existingDS[d.DsName] = d
+	}
+
+	if err := analyticsOps.EncryptDataSourceSecrets(&ds); err != nil {
+		i.record(kind, ds.DsName, ds.DsID, nil, model.ImportStatusFailed, err)
+		return
+	}
+
+	status, name := i.resolveConflict(ds.DsName, taken)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	var targetID string
+	switch status {
+	case model.ImportStatusSkipped:
+		// dashboards of the bundle are attached to the existing data source
+		targetID = existingDS[ds.DsName].DsID
+		i.dataSources[ds.DsID] = targetID
+		i.record(kind, ds.DsName, ds.DsID, &targetID, status, errors.New("a data source with the same name already exists"))
+		return
+	case model.ImportStatusOverwritten:
+		current := existingDS[ds.DsName]
+		targetID = current.DsID
+		// credentials which were removed from the bundle are kept
+		password, bearerToken, clientKey := ds.BasicAuthPassword, ds.BearerToken, ds.ClientKey
+		if password == nil {
+			password = current.BasicAuthPassword
+		}
+		if bearerToken == nil {
+			bearerToken = current.BearerToken
+		}
+		if clientKey == nil {
+			clientKey = current.ClientKey
+		}
+		currentHeaders := map[string]string{}
+		for _, header := range current.CustomHeaders {
+			currentHeaders[header.Name] = header.Value
+		}
+		headers := make([]dbSchemaAnalytics.DataSourceHeader, len(ds.CustomHeaders))
+		for index, header := range ds.CustomHeaders {
+			if header.Value == "" {
+				header.Value = currentHeaders[header.Name]
+			}
+			headers[index] = header
+		}
+		query := bson.D{{"ds_id", targetID}}
+		update := bson.D{{"$set", bson.D{
+			{"ds_type", ds.DsType}, {"ds_url", ds.DsURL}, {"access_type", ds.AccessType},
+			{"auth_type", ds.AuthType}, {"basic_auth_username", ds.BasicAuthUsername},
+			{"basic_auth_password", password}, {"bearer_token", bearerToken},
+			{"custom_headers", headers}, {"ca_cert", ds.CACert},
+			{"client_cert", ds.ClientCert}, {"client_key", clientKey},
+			{"insecure_skip_verify", ds.InsecureSkipVerify}, {"tenant_id", ds.TenantID},
+			{"tenant_header", ds.TenantHeader}, {"scrape_interval", ds.ScrapeInterval},
+			{"query_timeout", ds.QueryTimeout}, {"http_method", ds.HTTPMethod},
+			{"updated_at", timestamp},
+		}}}
+		err = dbOperationsAnalytics.UpdateDataSource(query, update)
+	default:
+		targetID = uuid.New().String()
+		newDS := ds
+		newDS.DsID, newDS.DsName, newDS.ProjectID = targetID, name, i.projectID
+		newDS.CreatedAt, newDS.UpdatedAt, newDS.IsRemoved = timestamp, timestamp, false
+		err = dbOperationsAnalytics.InsertDataSource(newDS)
+	}
+	if err != nil {
+		i.record(kind, ds.DsName, ds.DsID, nil, model.ImportStatusFailed, err)
+		return
+	}
+	i.dataSources[ds.DsID] = targetID
+	i.record(kind, name, ds.DsID, &targetID, status, nil)
+}
+
+func (i *importer) importDashboard(bundleDashboard BundleDashboard) {
+	const kind = "Dashboard"
+	dashboard := bundleDashboard.Dashboard
+	dsID, ok := i.dataSources[dashboard.DsID]
+	if !ok {
+		i.record(kind, dashboard.DbName, dashboard.DbID, nil, model.ImportStatusFailed, errors.New("the data source of the dashboard was not imported"))
+		return
+	}
+	clusterID, ok := i.clusters[dashboard.ClusterID]
+	if !ok {
+		i.record(kind, dashboard.DbName, dashboard.DbID, nil, model.ImportStatusFailed, errors.New("the cluster of the dashboard is not mapped to a cluster of the project"))
+		return
+	}
+	existing, err := dbOperationsAnalytics.ListDashboard(bson.D{{"project_id", i.projectID}, {"is_removed", false}})
+	if err != nil {
+		i.record(kind, dashboard.DbName, dashboard.DbID, nil, model.ImportStatusFailed, err)
+		return
+	}
+	taken := map[string]bool{}
+	existingDashboards := map[string]*dbSchemaAnalytics.DashBoard{}
+	for _, db := range existing {
+		taken[db.DbName] = true
+		existingDashboards[db.DbName] = db
+	}
+
+	status, name := i.resolveConflict(dashboard.DbName, taken)
+	switch status {
+	case model.ImportStatusSkipped:
+		target := existingDashboards[dashboard.DbName]
+		// workflows of the bundle analyze the impact on the existing dashboard, its panels are matched by name
+		i.dashboards[dashboard.DbID] = target.DbID
+		if err := i.mapExistingPanels(target, bundleDashboard.Panels); err != nil {
+			log.Print("Could not map the panels of dashboard ", target.DbName, " : ", err)
+		}
+		i.record(kind, dashboard.DbName, dashboard.DbID, &target.DbID, status, errors.New("a dashboard with the same name already exists"))
+		return
+	case model.ImportStatusOverwritten:
+		// dashboards are replaced as a whole along with their panels
+		existingID := existingDashboards[dashboard.DbName].DbID
+		if _, err := analyticsHandler.DeleteDashboard(&existingID); err != nil {
+			i.record(kind, dashboard.DbName, dashboard.DbID, nil, model.ImportStatusFailed, err)
+			return
+		}
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	newDashboard := dashboard
+	newDashboard.DbID, newDashboard.DbName, newDashboard.DsID = uuid.New().String(), name, dsID
+	newDashboard.ClusterID, newDashboard.ProjectID = clusterID, i.projectID
+	newDashboard.CreatedAt, newDashboard.UpdatedAt, newDashboard.ViewedAt = timestamp, timestamp, ""
+	newDashboard.IsRemoved = false
+
+	panelGroupIDs := map[string]string{}
+	newDashboard.PanelGroups = make([]dbSchemaAnalytics.PanelGroup, len(dashboard.PanelGroups))
+	for j, panelGroup := range dashboard.PanelGroups {
+		panelGroupIDs[panelGroup.PanelGroupID] = uuid.New().String()
+		newDashboard.PanelGroups[j] = dbSchemaAnalytics.PanelGroup{
+			PanelGroupName: panelGroup.PanelGroupName,
+			PanelGroupID:   panelGroupIDs[panelGroup.PanelGroupID],
+		}
+	}
+	var newPanels []*dbSchemaAnalytics.Panel
+	for _, panel := range bundleDashboard.Panels {
+		groupID, ok := panelGroupIDs[panel.PanelGroupID]
+		if !ok {
+			continue
+		}
+		newPanel := *panel
+		newPanel.PanelID, newPanel.PanelGroupID = uuid.New().String(), groupID
+		i.panels[panel.PanelID] = newPanel.PanelID
+		newPanel.CreatedAt, newPanel.UpdatedAt, newPanel.IsRemoved = timestamp, timestamp, false
+		newPanels = append(newPanels, &newPanel)
+	}
+
+	if len(newPanels) > 0 {
+		err = dbOperationsAnalytics.InsertPanel(newPanels)
+	}
+	if err == nil {
+		err = dbOperationsAnalytics.InsertDashBoard(newDashboard)
+	}
+	if err != nil {
+		i.record(kind, dashboard.DbName, dashboard.DbID, nil, model.ImportStatusFailed, err)
+		return
+	}
+	i.dashboards[dashboard.DbID] = newDashboard.DbID
+	i.record(kind, name, dashboard.DbID, &newDashboard.DbID, status, nil)
+}
+
+// mapExistingPanels maps the panels of a bundle dashboard to the panels of the existing dashboard with the same names
+func (i *importer) mapExistingPanels(dashboard *dbSchemaAnalytics.DashBoard, bundlePanels []*dbSchemaAnalytics.Panel) error {
+	var groupIDs bson.A
+	for _, panelGroup := range dashboard.PanelGroups {
+		groupIDs = append(groupIDs, panelGroup.PanelGroupID)
+	}
+	panels, err := dbOperationsAnalytics.ListPanel(bson.D{{"panel_group_id", bson.D{{"$in", groupIDs}}}, {"is_removed", false}})
+	if err != nil {
+		return err
+	}
+	panelIDs := map[string]string{}
+	for _, panel := range panels {
+		panelIDs[panel.PanelName] = panel.PanelID
+	}
+	for _, panel := range bundlePanels {
+		if panelID, ok := panelIDs[panel.PanelName]; ok {
+			i.panels[panel.PanelID] = panelID
+		}
+	}
+	return nil
+}
+
+// importWorkflow creates the workflow through the regular workflow handlers, so that it is written to git when
+// GitOps is enabled. Imported workflows don't run, only schedules are deployed to the mapped cluster
+func (i *importer) importWorkflow(workflow dbSchemaWorkflow.ChaosWorkFlowInput) {
+	const kind = "ChaosWorkflow"
+	clusterID, ok := i.clusters[workflow.ClusterID]
+	if !ok {
+		i.record(kind, workflow.WorkflowName, workflow.WorkflowID, nil, model.ImportStatusFailed, errors.New("cluster "+workflow.ClusterName+" is not mapped to a cluster of the project"))
+		return
+	}
+	existing, err := dbOperationsWorkflow.GetWorkflows(bson.D{{"project_id", i.projectID}, {"isRemoved", false}})
+	if err != nil {
+		i.record(kind, workflow.WorkflowName, workflow.WorkflowID, nil, model.ImportStatusFailed, err)
+		return
+	}
+	taken := map[string]bool{}
+	existingWorkflows := map[string]dbSchemaWorkflow.ChaosWorkFlowInput{}
+	for _, wf := range existing {
+		taken[wf.WorkflowName] = true
+		existingWorkflows[wf.WorkflowName] = wf
+	}
+
+	input := &model.ChaosWorkFlowInput{
+		WorkflowManifest:    workflow.WorkflowManifest,
+		CronSyntax:          workflow.CronSyntax,
+		WorkflowName:        workflow.WorkflowName,
+		WorkflowDescription: workflow.WorkflowDescription,
+		IsCustomWorkflow:    workflow.IsCustomWorkflow,
+		ProjectID:           i.projectID,
+		ClusterID:           clusterID,
+	}
+	copier.Copy(&input.Weightages, &workflow.Weightages)
+	if err := i.mapWorkflowChecks(workflow, input); err != nil {
+		i.record(kind, workflow.WorkflowName, workflow.WorkflowID, nil, model.ImportStatusFailed, err)
+		return
+	}
+
+	status, name := i.resolveConflict(workflow.WorkflowName, taken)
+	var response *model.ChaosWorkFlowResponse
+	switch status {
+	case model.ImportStatusSkipped:
+		targetID := existingWorkflows[workflow.WorkflowName].WorkflowID
+		i.record(kind, workflow.WorkflowName, workflow.WorkflowID, &targetID, status, errors.New("a workflow with the same name already exists"))
+		return
+	case model.ImportStatusOverwritten:
+		target := existingWorkflows[workflow.WorkflowName]
+		if target.ClusterID != clusterID {
+			i.record(kind, workflow.WorkflowName, workflow.WorkflowID, nil, model.ImportStatusFailed, errors.New("the existing workflow runs on a different cluster"))
+			return
+		}
+		input.WorkflowID = &target.WorkflowID
+		response, err = wfHandler.UpdateWorkflow(i.ctx, input, i.store)
+	default:
+		if name != workflow.WorkflowName {
+			input.WorkflowName = name
+			input.WorkflowManifest, err = sjson.Set(input.WorkflowManifest, "metadata.name", name)
+		}
+		if err == nil {
+			response, err = wfHandler.ImportChaosWorkflow(i.ctx, input, i.store)
+		}
+	}
+	if err != nil {
+		i.record(kind, workflow.WorkflowName, workflow.WorkflowID, nil, model.ImportStatusFailed, err)
+		return
+	}
+	i.record(kind, name, workflow.WorkflowID, &response.WorkflowID, status, nil)
+}
+
+// mapWorkflowChecks sets the steady state gates, guardrails and impact analysis of the bundle workflow on the input,
+// with the ids of the imported data sources, dashboards and panels. The existing checks are replaced on overwrites
+func (i *importer) mapWorkflowChecks(workflow dbSchemaWorkflow.ChaosWorkFlowInput, input *model.ChaosWorkFlowInput) error {
+	input.SteadyStateGates = []*model.SteadyStateGateInput{}
+	for _, gate := range workflow.SteadyStateGates {
+		newGate := &model.SteadyStateGateInput{
+			Name:          gate.Name,
+			Type:          gate.Type,
+			Query:         gate.Query,
+			Operator:      gate.Operator,
+			Threshold:     gate.Threshold,
+			Kind:          gate.Kind,
+			Namespace:     gate.Namespace,
+			ResourceName:  gate.ResourceName,
+			LabelSelector: gate.LabelSelector,
+		}
+		if gate.DataSourceID != nil {
+			dsID, ok := i.dataSources[*gate.DataSourceID]
+			if !ok {
+				return errors.New("the data source of steady state gate " + gate.Name + " was not imported")
+			}
+			newGate.DataSourceID = &dsID
+		}
+		input.SteadyStateGates = append(input.SteadyStateGates, newGate)
+	}
+
+	input.Guardrails = []*model.GuardrailInput{}
+	for _, guardrail := range workflow.Guardrails {
+		dsID, ok := i.dataSources[guardrail.DataSourceID]
+		if !ok {
+			return errors.New("the data source of guardrail " + guardrail.Name + " was not imported")
+		}
+		input.Guardrails = append(input.Guardrails, &model.GuardrailInput{
+			Name:         guardrail.Name,
+			DataSourceID: dsID,
+			Query:        guardrail.Query,
+			Operator:     guardrail.Operator,
+			Threshold:    guardrail.Threshold,
+			Interval:     guardrail.Interval,
+			BreachCount:  guardrail.BreachCount,
+		})
+	}
+
+	if impactAnalysis := workflow.ImpactAnalysis; impactAnalysis != nil {
+		dbID, ok := i.dashboards[impactAnalysis.DbID]
+		if !ok {
+			return errors.New("the dashboard of the impact analysis was not imported")
+		}
+		var panelIDs []string
+		for _, panelID := range impactAnalysis.PanelIDs {
+			newPanelID, ok := i.panels[panelID]
+			if !ok {
+				return errors.New("panel " + panelID + " of the impact analysis was not imported")
+			}
+			panelIDs = append(panelIDs, newPanelID)
+		}
+		input.ImpactAnalysis = &model.ImpactAnalysisInput{
+			DbID:               dbID,
+			PanelIds:           panelIDs,
+			BaselineWindow:     impactAnalysis.BaselineWindow,
+			RecoveryWindow:     impactAnalysis.RecoveryWindow,
+			DeviationThreshold: impactAnalysis.DeviationThreshold,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package report
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	dbReport "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/report"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/utils"
+)
+
+// SMTP server the reports are mailed with, reports are only stored when SMTP_HOST isn't set
+const (
+	SMTPHostEnv     = "SMTP_HOST"
+	SMTPPortEnv     = "SMTP_PORT"
+	SMTPUsernameEnv = "SMTP_USERNAME"
+	SMTPPasswordEnv = "SMTP_PASSWORD"
+	SMTPFromEnv     = "SMTP_FROM"
+
+	defaultSMTPPort = "587"
+	defaultSMTPFrom = "litmus@localhost"
+)
+
+// DeliveryEnabled reports whether an SMTP server is configured
+func DeliveryEnabled() bool {
+	return os.Getenv(SMTPHostEnv) != ""
+}
+
+// ValidateRecipients checks the addresses of the recipients
+func ValidateRecipients(recipients []string) error {
+	for _, recipient := range recipients {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil || address.Address != recipient {
+			return errors.New("invalid recipient " + recipient)
+		}
+	}
+	return nil
+}
+
+// Deliver mails the report to its recipients, the HTML document is the body of the mail and the PDF document is attached
+func Deliver(report dbReport.Report, data *Data) error {
+	if !DeliveryEnabled() {
+		return errors.New("no SMTP server is configured, the report is only stored")
+	}
+
+	host := os.Getenv(SMTPHostEnv)
+	port := os.Getenv(SMTPPortEnv)
+	if port == "" {
+		port = defaultSMTPPort
+	}
+	from := os.Getenv(SMTPFromEnv)
+	if from == "" {
+		from = defaultSMTPFrom
+	}
+
+	var auth smtp.Auth
+	if username := os.Getenv(SMTPUsernameEnv); username != "" {
+		auth = smtp.PlainAuth("", username, os.Getenv(SMTPPasswordEnv), host)
+	}
+
+	message, err := buildMessage(report, data, from)
+	if err != nil {
+		return err
+	}
+
+	return smtp.SendMail(net.JoinHostPort(host, port), auth, from, report.Recipients, message)
+}
+
+// buildMessage writes the MIME message of the report
+func buildMessage(report dbReport.Report, data *Data, from string) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	htmlBody := report.HTML
+	if !containsFormat(report.Formats, model.ReportFormatHTML) {
+		htmlBody = "<p>The resiliency report of " + html.EscapeString(data.ProjectName) + " from " + data.PeriodStart.Format("2006-01-02") +
+			" to " + data.PeriodEnd.Format("2006-01-02") + " is attached.</p>"
+	}
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/html; charset=UTF-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	writeBase64(part, []byte(htmlBody))
+
+	if len(report.PDF) > 0 {
+		part, err = writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {"application/pdf"},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {`attachment; filename="` + fileName(data) + `.pdf"`},
+		})
+		if err != nil {
+			return nil, err
+		}
+		writeBase64(part, report.PDF)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	var message bytes.Buffer
+	headers := [][2]string{
+		{"From", from},
+		{"To", strings.Join(report.Recipients, ", ")},
+		{"Subject", mime.QEncoding.Encode("UTF-8", "Resiliency report - "+data.ProjectName)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", "<" + uuid.New().String() + "@litmus>"},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/mixed; boundary=" + writer.Boundary()},
+	}
+	for _, header := range headers {
+		message.WriteString(header[0] + ": " + header[1] + "\r\n")
+	}
+	message.WriteString("\r\n")
+	message.Write(body.Bytes())
+
+	return message.Bytes(), nil
+}
+
+// writeBase64 writes the content in base64 lines of 76 characters
+func writeBase64(writer io.Writer, content []byte) {
+	encoded := base64.StdEncoding.EncodeToString(content)
+	for len(encoded) > 76 {
+		writer.Write([]byte(encoded[:76] + "\r\n"))
+		encoded = encoded[76:]
+	}
+	writer.Write([]byte(encoded + "\r\n"))
+}
+
+func fileName(data *Data) string {
+	return "resiliency-report-" + utils.SanitizeFileName(data.ProjectName) + "-" + data.PeriodEnd.Format("2006-01-02")
+}
+
+func containsFormat(formats []string, format model.ReportFormat) bool {
+	for _, f := range formats {
+		if f == string(format) {
+			return true
+		}
+	}
+	return false
+}
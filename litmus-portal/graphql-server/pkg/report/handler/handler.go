@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	dbReport "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/report"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/report"
+)
+
+// SaveSchedule creates or replaces the report schedule of the project
+func SaveSchedule(ctx context.Context, input model.ReportScheduleInput) (*model.ReportSchedule, error) {
+	if !input.Frequency.IsValid() {
+		return nil, errors.New("invalid frequency " + string(input.Frequency))
+	}
+	if err := validateFormats(input.Formats); err != nil {
+		return nil, err
+	}
+	if err := report.ValidateRecipients(input.Recipients); err != nil {
+		return nil, err
+	}
+
+	hour := 0
+	if input.Hour != nil {
+		hour = *input.Hour
+	}
+	if hour < 0 || hour > 23 {
+		return nil, errors.New("hour must be between 0 and 23")
+	}
+
+	day := 0
+	switch input.Frequency {
+	case model.ReportFrequencyWeekly:
+		day = 1
+		if input.Day != nil {
+			day = *input.Day
+		}
+		if day < 0 || day > 6 {
+			return nil, errors.New("day of weekly reports must be between 0 (sunday) and 6")
+		}
+	case model.ReportFrequencyMonthly:
+		day = 1
+		if input.Day != nil {
+			day = *input.Day
+		}
+		if day < 1 || day > 28 {
+			return nil, errors.New("day of monthly reports must be between 1 and 28")
+		}
+	}
+
+	periodDays := report.DefaultPeriod(input.Frequency)
+	if input.PeriodDays != nil {
+		periodDays = *input.PeriodDays
+	}
+	if periodDays < 1 || periodDays > report.MaxPeriodDays {
+		return nil, errors.New("period must be between 1 and " + strconv.Itoa(report.MaxPeriodDays) + " days")
+	}
+
+	var formats []string
+	for _, format := range input.Formats {
+		formats = append(formats, string(format))
+	}
+	recipients := input.Recipients
+	if recipients == nil {
+		recipients = []string{}
+	}
+
+	now := time.Now()
+	schedule := dbReport.ReportSchedule{
+		ProjectID:  input.ProjectID,
+		Enabled:    input.Enabled,
+		Frequency:  string(input.Frequency),
+		Day:        day,
+		Hour:       hour,
+		Formats:    formats,
+		Recipients: recipients,
+		PeriodDays: periodDays,
+	}
+	nextRunAt := ""
+	if input.Enabled {
+		nextRunAt = strconv.FormatInt(report.NextRun(schedule, now).Unix(), 10)
+	}
+
+	update := bson.D{
+		{"$set", bson.D{
+			{"enabled", schedule.Enabled},
+			{"frequency", schedule.Frequency},
+			{"day", schedule.Day},
+			{"hour", schedule.Hour},
+			{"formats", schedule.Formats},
+			{"recipients", schedule.Recipients},
+			{"period_days", schedule.PeriodDays},
+			{"next_run_at", nextRunAt},
+			{"updated_at", strconv.FormatInt(now.Unix(), 10)},
+		}},
+		{"$setOnInsert", bson.D{
+			{"last_run_at", ""},
+			{"last_error", ""},
+			{"created_at", strconv.FormatInt(now.Unix(), 10)},
+		}},
+	}
+	_, err := dbReport.UpdateSchedule(ctx, bson.D{{"project_id", input.ProjectID}}, update, true)
+	if err != nil {
+		return nil, errors.New("failed to save the report schedule : " + err.Error())
+	}
+
+	return GetSchedule(ctx, input.ProjectID)
+}
+
+// GetSchedule returns the report schedule of the project, nil when the project has none
+func GetSchedule(ctx context.Context, projectID string) (*model.ReportSchedule, error) {
+	schedule, err := dbReport.GetSchedule(ctx, bson.D{{"project_id", projectID}})
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return scheduleToModel(schedule), nil
+}
+
+// DeleteSchedule removes the report schedule of the project, the stored reports are kept
+func DeleteSchedule(ctx context.Context, projectID string) (bool, error) {
+	err := dbReport.DeleteSchedule(ctx, projectID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GenerateReport generates a report of the project on demand
+func GenerateReport(ctx context.Context, input model.GenerateReportInput) (*model.Report, error) {
+	if err := validateFormats(input.Formats); err != nil {
+		return nil, err
+	}
+	if err := report.ValidateRecipients(input.Recipients); err != nil {
+		return nil, err
+	}
+
+	periodDays := report.DefaultPeriodDays
+	if input.PeriodDays != nil {
+		periodDays = *input.PeriodDays
+	}
+	if periodDays < 1 || periodDays > report.MaxPeriodDays {
+		return nil, errors.New("period must be between 1 and " + strconv.Itoa(report.MaxPeriodDays) + " days")
+	}
+
+	newReport, err := report.Generate(ctx, report.Options{
+		ProjectID:  input.ProjectID,
+		Formats:    input.Formats,
+		PeriodDays: periodDays,
+		Recipients: input.Recipients,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reportToModel(*newReport), nil
+}
+
+// ListReports returns the stored reports of the project, newest first
+func ListReports(ctx context.Context, projectID string) ([]*model.Report, error) {
+	reports, err := dbReport.ListReports(ctx, bson.D{{"project_id", projectID}})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].GeneratedAt > reports[j].GeneratedAt
+	})
+
+	result := []*model.Report{}
+	for _, storedReport := range reports {
+		result = append(result, reportToModel(storedReport))
+	}
+	return result, nil
+}
+
+// DownloadReport returns the HTML document or the base64 encoded PDF document of a stored report
+func DownloadReport(ctx context.Context, projectID string, reportID string, format model.ReportFormat) (string, error) {
+	storedReport, err := dbReport.GetReport(ctx, bson.D{{"report_id", reportID}, {"project_id", projectID}})
+	if err != nil {
+		return "", errors.New("report not found : " + err.Error())
+	}
+
+	switch format {
+	case model.ReportFormatHTML:
+		if storedReport.HTML != "" {
+			return storedReport.HTML, nil
+		}
+	case model.ReportFormatPdf:
+		if len(storedReport.PDF) > 0 {
+			return base64.StdEncoding.EncodeToString(storedReport.PDF), nil
+		}
+	}
+	return "", errors.New("the report wasn't generated as " + string(format))
+}
+
+func validateFormats(formats []model.ReportFormat) error {
+	if len(formats) == 0 {
+		return errors.New("at least one report format is required")
+	}
+	for _, format := range formats {
+		if !format.IsValid() {
+			return errors.New("invalid report format " + string(format))
+		}
+	}
+	return nil
+}
+
+func scheduleToModel(schedule dbReport.ReportSchedule) *model.ReportSchedule {
+	newSchedule := &model.ReportSchedule{
+		ProjectID:  schedule.ProjectID,
+		Enabled:    schedule.Enabled,
+		Frequency:  model.ReportFrequency(schedule.Frequency),
+		Day:        schedule.Day,
+		Hour:       schedule.Hour,
+		Recipients: schedule.Recipients,
+		PeriodDays: schedule.PeriodDays,
+		NextRunAt:  optionalString(schedule.NextRunAt),
+		LastRunAt:  optionalString(schedule.LastRunAt),
+		LastError:  optionalString(schedule.LastError),
+		CreatedAt:  schedule.CreatedAt,
+		UpdatedAt:  schedule.UpdatedAt,
+	}
+	for _, format := range schedule.Formats {
+		newSchedule.Formats = append(newSchedule.Formats, model.ReportFormat(format))
+	}
+	if newSchedule.Recipients == nil {
+		newSchedule.Recipients = []string{}
+	}
+	return newSchedule
+}
+
+func reportToModel(storedReport dbReport.Report) *model.Report {
+	newReport := &model.Report{
+		ReportID:      storedReport.ReportID,
+		ProjectID:     storedReport.ProjectID,
+		PeriodStart:   storedReport.PeriodStart,
+		PeriodEnd:     storedReport.PeriodEnd,
+		GeneratedAt:   storedReport.GeneratedAt,
+		Scheduled:     storedReport.Scheduled,
+		Recipients:    storedReport.Recipients,
+		Delivered:     storedReport.Delivered,
+		DeliveryError: optionalString(storedReport.DeliveryError),
+	}
+	for _, format := range storedReport.Formats {
+		newReport.Formats = append(newReport.Formats, model.ReportFormat(format))
+	}
+	if newReport.Recipients == nil {
+		newReport.Recipients = []string{}
+	}
+	return newReport
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,289 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+)
+
+const (
+	chartWidth   = 720
+	chartHeight  = 200
+	chartPadding = 30
+)
+
+// chart is the geometry of an inline SVG chart
+type chart struct {
+	Width      int
+	Height     int
+	Bars       []chartBar
+	Line       string
+	Points     []chartPoint
+	StartLabel string
+	EndLabel   string
+	MaxLabel   string
+}
+
+type chartBar struct {
+	X, Y, Width, Height float64
+	Title               string
+}
+
+type chartPoint struct {
+	X, Y  float64
+	Title string
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"score":   formatScore,
+	"percent": formatPercent,
+	"date":    func(t time.Time) string { return t.Format("2006-01-02") },
+	"day":     func(t time.Time) string { return t.Format("Mon 2006-01-02") },
+	"heat":    heatColor,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Resiliency report - {{.Data.ProjectName}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; color: #1c1c1c; margin: 32px; }
+h1 { color: #5b44ba; margin-bottom: 4px; }
+h2 { border-bottom: 1px solid #d8d8d8; padding-bottom: 4px; margin-top: 32px; }
+.period { color: #666; }
+.cards { display: flex; flex-wrap: wrap; gap: 12px; }
+.card { border: 1px solid #d8d8d8; border-radius: 4px; padding: 12px 16px; min-width: 120px; }
+.card .value { font-size: 24px; font-weight: bold; }
+.card .label { color: #666; font-size: 12px; }
+table { border-collapse: collapse; }
+th, td { text-align: left; padding: 6px 12px; border-bottom: 1px solid #eee; font-size: 14px; }
+.ok { color: #109b67; }
+.bad { color: #ca2c2c; }
+.heatmap td { width: 18px; height: 18px; padding: 0; border: 2px solid #fff; }
+svg text { font-size: 11px; fill: #666; }
+.empty { color: #666; font-style: italic; }
+</style>
+</head>
+<body>
+<h1>Resiliency report</h1>
+<div><b>{{.Data.ProjectName}}</b></div>
+<div class="period">{{date .Data.PeriodStart}} to {{date .Data.PeriodEnd}} (UTC), generated {{.Data.GeneratedAt.Format "2006-01-02 15:04 UTC"}}</div>
+
+<h2>Summary</h2>
+<div class="cards">
+<div class="card"><div class="value">{{.Data.Summary.Runs}}</div><div class="label">Workflow runs</div></div>
+<div class="card"><div class="value ok">{{.Data.Summary.Succeeded}}</div><div class="label">Succeeded</div></div>
+<div class="card"><div class="value bad">{{.Data.Summary.Failed}}</div><div class="label">Failed</div></div>
+<div class="card"><div class="value">{{score .Data.Summary.AverageResiliencyScore}}</div><div class="label">Average resiliency score</div></div>
+<div class="card"><div class="value">{{.Data.Summary.ExperimentsPassed}} / {{.Data.Summary.ExperimentsFailed}}</div><div class="label">Experiments passed / failed</div></div>
+</div>
+{{with .Data.AllTime}}
+<p class="period">All time: {{.TotalWorkflowRuns}} runs, {{percent .WorkflowRunSucceededPercentage}} succeeded, average resiliency score {{printf "%.1f" .AverageResiliencyScore}}, {{percent .PassedPercentage}} of {{.TotalExperiments}} experiments passed.</p>
+{{end}}
+
+<h2>Resiliency score trend</h2>
+{{if .Trend.Points}}
+<svg width="{{.Trend.Width}}" height="{{.Trend.Height}}" xmlns="http://www.w3.org/2000/svg">
+<line x1="30" y1="170" x2="{{.Trend.Width}}" y2="170" stroke="#d8d8d8"/>
+<line x1="30" y1="10" x2="30" y2="170" stroke="#d8d8d8"/>
+<text x="0" y="14">100</text><text x="12" y="170">0</text>
+<polyline points="{{.Trend.Line}}" fill="none" stroke="#5b44ba" stroke-width="2"/>
+{{range .Trend.Points}}<circle cx="{{.X}}" cy="{{.Y}}" r="3" fill="#5b44ba"><title>{{.Title}}</title></circle>
+{{end}}
+<text x="30" y="190">{{.Trend.StartLabel}}</text>
+<text x="{{.Trend.Width}}" y="190" text-anchor="end">{{.Trend.EndLabel}}</text>
+</svg>
+{{else}}
+<p class="empty">No completed runs during the period.</p>
+{{end}}
+
+<h2>Workflow runs ({{.FrequencyLabel}})</h2>
+{{if .Frequency.Bars}}
+<svg width="{{.Frequency.Width}}" height="{{.Frequency.Height}}" xmlns="http://www.w3.org/2000/svg">
+<line x1="30" y1="170" x2="{{.Frequency.Width}}" y2="170" stroke="#d8d8d8"/>
+<text x="0" y="14">{{.Frequency.MaxLabel}}</text>
+{{range .Frequency.Bars}}<rect x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="{{.Height}}" fill="#858cdd"><title>{{.Title}}</title></rect>
+{{end}}
+<text x="30" y="190">{{.Frequency.StartLabel}}</text>
+<text x="{{.Frequency.Width}}" y="190" text-anchor="end">{{.Frequency.EndLabel}}</text>
+</svg>
+{{else}}
+<p class="empty">No workflow runs.</p>
+{{end}}
+
+<h2>Run heatmap</h2>
+<table class="heatmap">
+<tr><th></th>{{range .Weekdays}}<th>{{.}}</th>{{end}}</tr>
+{{range .Data.Heatmap}}<tr><th>{{date (index . 0).Date}}</th>{{range .}}<td style="background-color: {{heat .}}">{{if .InPeriod}}<span title="{{day .Date}}: {{.Runs}} runs, resiliency score {{score .AverageResiliencyScore}}">&nbsp;</span>{{end}}</td>{{end}}</tr>
+{{end}}
+</table>
+
+<h2>Top failing experiments</h2>
+{{if .Data.TopFailingExperiments}}
+<table>
+<tr><th>Experiment</th><th>Failed</th><th>Runs</th><th>Failure rate</th></tr>
+{{range .Data.TopFailingExperiments}}<tr><td>{{.Name}}</td><td class="bad">{{.Failed}}</td><td>{{.Runs}}</td><td>{{percent .FailureRate}}</td></tr>
+{{end}}
+</table>
+{{else}}
+<p class="empty">No experiment failed during the period.</p>
+{{end}}
+
+<h2>Agent health</h2>
+{{if .Data.Agents}}
+<table>
+<tr><th>Agent</th><th>Type</th><th>Platform</th><th>Status</th><th>Last updated</th></tr>
+{{range .Data.Agents}}<tr><td>{{.Name}}</td><td>{{.Type}}</td><td>{{.Platform}}</td><td>{{if not .Confirmed}}<span class="bad">Pending</span>{{else if .Active}}<span class="ok">Active</span>{{else}}<span class="bad">Inactive</span>{{end}}</td><td>{{.LastUpdated}}</td></tr>
+{{end}}
+</table>
+{{else}}
+<p class="empty">The project has no agents.</p>
+{{end}}
+</body>
+</html>
+`))
+
+// RenderHTML renders the report as a self-contained HTML document, the charts are inline SVG
+func RenderHTML(data *Data) (string, error) {
+	var buffer bytes.Buffer
+	err := htmlTemplate.Execute(&buffer, struct {
+		Data           *Data
+		Trend          chart
+		Frequency      chart
+		FrequencyLabel string
+		Weekdays       []string
+	}{
+		Data:           data,
+		Trend:          trendChart(data.ResiliencyTrend),
+		Frequency:      frequencyChart(data.RunFrequency, data.RunFrequencyFilter),
+		FrequencyLabel: frequencyLabel(data.RunFrequencyFilter),
+		Weekdays:       []string{"S", "M", "T", "W", "T", "F", "S"},
+	})
+	if err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+// trendChart plots the daily average resiliency scores on a 0 to 100 scale, days without completed runs are skipped
+func trendChart(trend []DailyStats) chart {
+	c := chart{Width: chartWidth, Height: chartHeight}
+	if len(trend) == 0 {
+		return c
+	}
+
+	plotWidth := float64(chartWidth - chartPadding - 10)
+	plotHeight := float64(chartHeight - chartPadding - 10)
+	step := plotWidth
+	if len(trend) > 1 {
+		step = plotWidth / float64(len(trend)-1)
+	}
+
+	var line []string
+	for i, day := range trend {
+		if day.AverageResiliencyScore == nil {
+			continue
+		}
+		x := float64(chartPadding) + float64(i)*step
+		y := 10 + plotHeight*(1-*day.AverageResiliencyScore/100)
+		c.Points = append(c.Points, chartPoint{X: round(x), Y: round(y), Title: fmt.Sprintf("%s: %.1f", day.Date.Format("2006-01-02"), *day.AverageResiliencyScore)})
+		line = append(line, fmt.Sprintf("%.1f,%.1f", x, y))
+	}
+	c.Line = strings.Join(line, " ")
+	c.StartLabel = trend[0].Date.Format("2006-01-02")
+	c.EndLabel = trend[len(trend)-1].Date.Format("2006-01-02")
+	return c
+}
+
+// frequencyChart draws a bar per interval of the workflow stats
+func frequencyChart(stats []*model.WorkflowStats, filter model.TimeFrequency) chart {
+	c := chart{Width: chartWidth, Height: chartHeight}
+	maxValue := 0
+	for _, stat := range stats {
+		if stat.Value > maxValue {
+			maxValue = stat.Value
+		}
+	}
+	if len(stats) == 0 || maxValue == 0 {
+		return c
+	}
+
+	plotWidth := float64(chartWidth - chartPadding - 10)
+	plotHeight := float64(chartHeight - chartPadding - 10)
+	slot := plotWidth / float64(len(stats))
+	for i, stat := range stats {
+		height := plotHeight * float64(stat.Value) / float64(maxValue)
+		c.Bars = append(c.Bars, chartBar{
+			X:      round(float64(chartPadding) + float64(i)*slot + slot*0.1),
+			Y:      round(10 + plotHeight - height),
+			Width:  round(slot * 0.8),
+			Height: round(height),
+			Title:  formatStatDate(stat.Date, filter) + ": " + strconv.Itoa(stat.Value) + " runs",
+		})
+	}
+	c.MaxLabel = strconv.Itoa(maxValue)
+	c.StartLabel = formatStatDate(stats[0].Date, filter)
+	c.EndLabel = formatStatDate(stats[len(stats)-1].Date, filter)
+	return c
+}
+
+func frequencyLabel(filter model.TimeFrequency) string {
+	switch filter {
+	case model.TimeFrequencyHourly:
+		return "last 48 hours"
+	case model.TimeFrequencyDaily:
+		return "last 28 days"
+	default:
+		return "last 6 months"
+	}
+}
+
+// formatStatDate formats the dates of the workflow stats, which are in milliseconds
+func formatStatDate(date float64, filter model.TimeFrequency) string {
+	t := time.Unix(int64(date/1000), 0).UTC()
+	switch filter {
+	case model.TimeFrequencyHourly:
+		return t.Format("01-02 15:00")
+	case model.TimeFrequencyDaily:
+		return t.Format("2006-01-02")
+	default:
+		return t.Format("2006-01")
+	}
+}
+
+// heatColor shades the days by their average resiliency score, days with runs but no score are grey
+func heatColor(day HeatmapDay) string {
+	switch {
+	case !day.InPeriod:
+		return "#ffffff"
+	case day.Runs == 0:
+		return "#f0f0f0"
+	case day.AverageResiliencyScore == nil:
+		return "#c4c4c4"
+	case *day.AverageResiliencyScore >= 75:
+		return "#109b67"
+	case *day.AverageResiliencyScore >= 50:
+		return "#f6b92b"
+	default:
+		return "#ca2c2c"
+	}
+}
+
+func formatScore(score *float64) string {
+	if score == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*score, 'f', 1, 64)
+}
+
+func formatPercent(value float64) string {
+	return strconv.FormatFloat(value, 'f', 1, 64) + "%"
+}
+
+func round(value float64) float64 {
+	return float64(int64(value*10+0.5)) / 10
+}
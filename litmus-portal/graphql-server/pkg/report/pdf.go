@@ -0,0 +1,290 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A4 page in points
+const (
+	pageWidth  = 595.0
+	pageHeight = 842.0
+	pageMargin = 50.0
+)
+
+// pdfDocument writes a minimal PDF with the standard Helvetica fonts, which every viewer has, so that the
+// documents don't embed fonts or need a rendering engine
+type pdfDocument struct {
+	pages   []*bytes.Buffer
+	content *bytes.Buffer
+	y       float64
+}
+
+func newPDFDocument() *pdfDocument {
+	document := &pdfDocument{}
+	document.newPage()
+	return document
+}
+
+func (d *pdfDocument) newPage() {
+	d.content = &bytes.Buffer{}
+	d.pages = append(d.pages, d.content)
+	d.y = pageHeight - pageMargin
+}
+
+// space moves to a new page when less than the height is left on the current one
+func (d *pdfDocument) space(height float64) {
+	if d.y-height < pageMargin {
+		d.newPage()
+	}
+}
+
+// text writes the text at the current line, the line isn't advanced
+func (d *pdfDocument) text(x float64, size float64, bold bool, text string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, d.y-size, escapePDFText(text))
+}
+
+// line writes a wrapped line of text and advances below it
+func (d *pdfDocument) line(size float64, bold bool, text string) {
+	// Helvetica characters are about half as wide as they are high
+	maxChars := int((pageWidth - 2*pageMargin) / (size * 0.5))
+	for _, wrapped := range wrapText(text, maxChars) {
+		d.space(size * 1.5)
+		d.text(pageMargin, size, bold, wrapped)
+		d.y -= size * 1.5
+	}
+}
+
+// row writes the cells of a table row at the x positions of the columns
+func (d *pdfDocument) row(columns []float64, bold bool, cells ...string) {
+	d.space(15)
+	for i, cell := range cells {
+		if i < len(columns) {
+			d.text(columns[i], 10, bold, cell)
+		}
+	}
+	d.y -= 15
+}
+
+// rect fills a rectangle with a hex color, y is the bottom of the rectangle
+func (d *pdfDocument) rect(x, y, width, height float64, color string) {
+	r, g, b := hexColor(color)
+	fmt.Fprintf(d.content, "%.3f %.3f %.3f rg %.2f %.2f %.2f %.2f re f\n", r, g, b, x, y, width, height)
+}
+
+func (d *pdfDocument) heading(text string) {
+	d.space(60)
+	d.y -= 10
+	d.line(14, true, text)
+	d.y -= 4
+}
+
+// bytes lays out the objects of the document, its fonts and pages, with the cross-reference table
+func (d *pdfDocument) bytes() []byte {
+	var output bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, output.Len())
+		fmt.Fprintf(&output, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	output.WriteString("%PDF-1.4\n")
+
+	var kids []string
+	for i := range d.pages {
+		kids = append(kids, strconv.Itoa(5+2*i)+" 0 R")
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object("<< /Type /Pages /Kids [" + strings.Join(kids, " ") + "] /Count " + strconv.Itoa(len(d.pages)) + " >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", pageWidth, pageHeight, 6+2*i))
+		object("<< /Length " + strconv.Itoa(page.Len()) + " >>\nstream\n" + page.String() + "endstream")
+	}
+
+	xref := output.Len()
+	fmt.Fprintf(&output, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&output, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&output, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return output.Bytes()
+}
+
+// RenderPDF renders the report as a PDF document with the same sections as the HTML document
+func RenderPDF(data *Data) ([]byte, error) {
+	d := newPDFDocument()
+
+	d.line(20, true, "Resiliency report")
+	d.line(12, true, data.ProjectName)
+	d.line(10, false, data.PeriodStart.Format("2006-01-02")+" to "+data.PeriodEnd.Format("2006-01-02")+" (UTC), generated "+data.GeneratedAt.Format("2006-01-02 15:04 UTC"))
+
+	d.heading("Summary")
+	d.line(10, false, fmt.Sprintf("Workflow runs: %d (%d succeeded, %d failed, %d running)", data.Summary.Runs, data.Summary.Succeeded, data.Summary.Failed, data.Summary.Running))
+	d.line(10, false, "Average resiliency score: "+formatScore(data.Summary.AverageResiliencyScore))
+	d.line(10, false, fmt.Sprintf("Experiments passed: %d, failed: %d", data.Summary.ExperimentsPassed, data.Summary.ExperimentsFailed))
+	if data.AllTime != nil {
+		d.line(10, false, fmt.Sprintf("All time: %d runs, %s succeeded, average resiliency score %.1f, %s of %d experiments passed.",
+			data.AllTime.TotalWorkflowRuns, formatPercent(data.AllTime.WorkflowRunSucceededPercentage), data.AllTime.AverageResiliencyScore,
+			formatPercent(data.AllTime.PassedPercentage), data.AllTime.TotalExperiments))
+	}
+
+	d.heading("Resiliency score trend")
+	if len(data.ResiliencyTrend) == 0 {
+		d.line(10, false, "No completed runs during the period.")
+	} else {
+		bars := make([]pdfBar, 0, len(data.ResiliencyTrend))
+		for _, day := range data.ResiliencyTrend {
+			value := 0.0
+			if day.AverageResiliencyScore != nil {
+				value = *day.AverageResiliencyScore
+			}
+			bars = append(bars, pdfBar{value: value, color: heatColor(HeatmapDay{DailyStats: day, InPeriod: true})})
+		}
+		d.barChart(bars, 100, "100", data.ResiliencyTrend[0].Date.Format("2006-01-02"), data.ResiliencyTrend[len(data.ResiliencyTrend)-1].Date.Format("2006-01-02"))
+	}
+
+	d.heading("Workflow runs (" + frequencyLabel(data.RunFrequencyFilter) + ")")
+	maxRuns := 0
+	for _, stat := range data.RunFrequency {
+		if stat.Value > maxRuns {
+			maxRuns = stat.Value
+		}
+	}
+	if maxRuns == 0 {
+		d.line(10, false, "No workflow runs.")
+	} else {
+		bars := make([]pdfBar, 0, len(data.RunFrequency))
+		for _, stat := range data.RunFrequency {
+			bars = append(bars, pdfBar{value: float64(stat.Value), color: "#858cdd"})
+		}
+		d.barChart(bars, float64(maxRuns), strconv.Itoa(maxRuns),
+			formatStatDate(data.RunFrequency[0].Date, data.RunFrequencyFilter), formatStatDate(data.RunFrequency[len(data.RunFrequency)-1].Date, data.RunFrequencyFilter))
+	}
+
+	d.heading("Run heatmap")
+	for _, week := range data.Heatmap {
+		d.space(14)
+		d.text(pageMargin, 8, false, week[0].Date.Format("2006-01-02"))
+		for i, day := range week {
+			d.rect(pageMargin+60+float64(i)*14, d.y-12, 12, 12, heatColor(day))
+		}
+		d.y -= 14
+	}
+
+	d.heading("Top failing experiments")
+	if len(data.TopFailingExperiments) == 0 {
+		d.line(10, false, "No experiment failed during the period.")
+	} else {
+		columns := []float64{pageMargin, 330, 390, 440}
+		d.row(columns, true, "Experiment", "Failed", "Runs", "Failure rate")
+		for _, experiment := range data.TopFailingExperiments {
+			d.row(columns, false, experiment.Name, strconv.Itoa(experiment.Failed), strconv.Itoa(experiment.Runs), formatPercent(experiment.FailureRate))
+		}
+	}
+
+	d.heading("Agent health")
+	if len(data.Agents) == 0 {
+		d.line(10, false, "The project has no agents.")
+	} else {
+		columns := []float64{pageMargin, 220, 290, 360, 420}
+		d.row(columns, true, "Agent", "Type", "Platform", "Status", "Last updated")
+		for _, agent := range data.Agents {
+			status := "Inactive"
+			if !agent.Confirmed {
+				status = "Pending"
+			} else if agent.Active {
+				status = "Active"
+			}
+			d.row(columns, false, agent.Name, agent.Type, agent.Platform, status, agent.LastUpdated)
+		}
+	}
+
+	return d.bytes(), nil
+}
+
+type pdfBar struct {
+	value float64
+	color string
+}
+
+// barChart draws the bars scaled to the max value with the labels of the first and last bar below them
+func (d *pdfDocument) barChart(bars []pdfBar, maxValue float64, maxLabel, startLabel, endLabel string) {
+	const height = 120.0
+	d.space(height + 30)
+
+	left := pageMargin + 30
+	width := pageWidth - pageMargin - left
+	bottom := d.y - height
+	slot := width / float64(len(bars))
+
+	d.text(pageMargin, 8, false, maxLabel)
+	d.rect(left, bottom, width, 0.5, "#d8d8d8")
+	for i, bar := range bars {
+		barHeight := height * bar.value / maxValue
+		d.rect(left+float64(i)*slot+slot*0.1, bottom, slot*0.8, barHeight, bar.color)
+	}
+
+	d.y = bottom - 4
+	d.text(left, 8, false, startLabel)
+	d.text(pageWidth-pageMargin-float64(len(endLabel))*4.5, 8, false, endLabel)
+	d.y -= 16
+}
+
+// escapePDFText escapes a PDF string, characters outside of latin-1 are replaced as the fonts don't have them
+func escapePDFText(text string) string {
+	var builder strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			builder.WriteRune('\\')
+			builder.WriteRune(r)
+		case r < 32 || r > 255:
+			builder.WriteRune('?')
+		case r > 126:
+			fmt.Fprintf(&builder, "\\%03o", r)
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+func wrapText(text string, maxChars int) []string {
+	if maxChars <= 0 || len(text) <= maxChars {
+		return []string{text}
+	}
+
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > maxChars {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func hexColor(color string) (float64, float64, float64) {
+	value, err := strconv.ParseUint(strings.TrimPrefix(color, "#"), 16, 32)
+	if err != nil {
+		return 0, 0, 0
+	}
+	return float64(value>>16&0xff) / 255, float64(value>>8&0xff) / 255, float64(value&0xff) / 255
+}
@@ -0,0 +1,395 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	analyticsHandler "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics/handler"
+	types "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/chaos-workflow"
+	dbOperationsCluster "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/cluster"
+	dbOperationsProject "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/project"
+	dbReport "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/report"
+	dbOperationsWorkflow "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/workflow"
+)
+
+const (
+	// DefaultPeriodDays is the period of the reports generated on demand
+	DefaultPeriodDays = 7
+	// MaxPeriodDays is the longest period a report covers
+	MaxPeriodDays = 366
+	// reports of a project beyond this count are removed, oldest first
+	maxStoredReports = 30
+	// experiments listed in the top failing experiments
+	maxFailingExperiments = 10
+)
+
+// Data is the content of a resiliency report
+type Data struct {
+	ProjectID   string
+	ProjectName string
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	GeneratedAt time.Time
+
+	Summary Summary
+	// AllTime are the stats of every run of the project
+	AllTime *model.WorkflowRunStatsResponse
+	// RunFrequency is the number of runs from GetWorkflowStats over the last 48 hours, 28 days or 6 months
+	RunFrequency       []*model.WorkflowStats
+	RunFrequencyFilter model.TimeFrequency
+
+	ResiliencyTrend       []DailyStats
+	TopFailingExperiments []ExperimentFailures
+	Agents                []AgentHealth
+	// Heatmap has a row per week of the period, starting on sunday
+	Heatmap [][]HeatmapDay
+}
+
+// Summary are the stats of the runs which ended during the period
+type Summary struct {
+	Runs                   int
+	Succeeded              int
+	Failed                 int
+	Running                int
+	ExperimentsPassed      int
+	ExperimentsFailed      int
+	AverageResiliencyScore *float64
+}
+
+// DailyStats are the runs of a day of the period
+type DailyStats struct {
+	Date                   time.Time
+	Runs                   int
+	AverageResiliencyScore *float64
+}
+
+// HeatmapDay is a day of the heatmap, the days of the first and last weeks outside the period are empty
+type HeatmapDay struct {
+	DailyStats
+	InPeriod bool
+}
+
+// ExperimentFailures are the failed runs of an experiment during the period
+type ExperimentFailures struct {
+	Name        string
+	Runs        int
+	Failed      int
+	FailureRate float64
+}
+
+// AgentHealth is the state of an agent of the project
+type AgentHealth struct {
+	Name        string
+	Type        string
+	Platform    string
+	Active      bool
+	Confirmed   bool
+	LastUpdated string
+}
+
+// Options are the options of a report
+type Options struct {
+	ProjectID  string
+	Formats    []model.ReportFormat
+	PeriodDays int
+	Recipients []string
+	Scheduled  bool
+}
+
+// Generate renders the report of a project in the requested formats, mails it to the recipients and stores it
+func Generate(ctx context.Context, opts Options) (*dbReport.Report, error) {
+	if opts.PeriodDays <= 0 {
+		opts.PeriodDays = DefaultPeriodDays
+	}
+	if opts.PeriodDays > MaxPeriodDays {
+		return nil, errors.New("reports cover at most " + strconv.Itoa(MaxPeriodDays) + " days")
+	}
+	if len(opts.Formats) == 0 {
+		opts.Formats = []model.ReportFormat{model.ReportFormatHTML}
+	}
+
+	end := time.Now().UTC()
+	data, err := CollectData(ctx, opts.ProjectID, end.AddDate(0, 0, -opts.PeriodDays), end)
+	if err != nil {
+		return nil, err
+	}
+
+	report := dbReport.Report{
+		ReportID:    uuid.New().String(),
+		ProjectID:   opts.ProjectID,
+		PeriodStart: strconv.FormatInt(data.PeriodStart.Unix(), 10),
+		PeriodEnd:   strconv.FormatInt(data.PeriodEnd.Unix(), 10),
+		GeneratedAt: strconv.FormatInt(data.GeneratedAt.Unix(), 10),
+		Scheduled:   opts.Scheduled,
+		Recipients:  opts.Recipients,
+	}
+	for _, format := range opts.Formats {
+		report.Formats = append(report.Formats, string(format))
+		switch format {
+		case model.ReportFormatHTML:
+			report.HTML, err = RenderHTML(data)
+		case model.ReportFormatPdf:
+			report.PDF, err = RenderPDF(data)
+		}
+		if err != nil {
+			return nil, errors.New("failed to render the " + string(format) + " report : " + err.Error())
+		}
+	}
+
+	if len(opts.Recipients) > 0 {
+		err = Deliver(report, data)
+		if err != nil {
+			log.Print("Could not deliver the report of project ", opts.ProjectID, " : ", err)
+			report.DeliveryError = err.Error()
+		} else {
+			report.Delivered = true
+		}
+	}
+
+	err = dbReport.InsertReport(ctx, report)
+	if err != nil {
+		return nil, errors.New("failed to store the report : " + err.Error())
+	}
+	pruneReports(ctx, opts.ProjectID)
+
+	return &report, nil
+}
+
+// CollectData gathers the content of the report of a project, the runs are assigned to the period by their last update
+func CollectData(ctx context.Context, projectID string, start, end time.Time) (*Data, error) {
+	project, err := dbOperationsProject.GetProject(ctx, bson.D{{"_id", projectID}})
+	if err != nil {
+		return nil, errors.New("project not found : " + err.Error())
+	}
+
+	data := &Data{
+		ProjectID:   projectID,
+		ProjectName: project.Name,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	data.AllTime, err = analyticsHandler.GetWorkflowRunStats(model.WorkflowRunStatsRequest{ProjectID: projectID})
+	if err != nil {
+		return nil, errors.New("failed to get the workflow run stats : " + err.Error())
+	}
+
+	data.RunFrequencyFilter = runFrequencyFilter(end.Sub(start))
+	data.RunFrequency, err = analyticsHandler.GetWorkflowStats(projectID, data.RunFrequencyFilter, true)
+	if err != nil {
+		return nil, errors.New("failed to get the workflow stats : " + err.Error())
+	}
+
+	workflows, err := dbOperationsWorkflow.GetWorkflows(bson.D{{"project_id", projectID}, {"isRemoved", false}})
+	if err != nil {
+		return nil, errors.New("failed to get the workflows : " + err.Error())
+	}
+
+	days := make(map[string]*dailyAccumulator)
+	experiments := make(map[string]*ExperimentFailures)
+	var scoreSum float64
+	var scoreCount int
+	for _, workflow := range workflows {
+		for _, run := range workflow.WorkflowRuns {
+			if run == nil || (run.IsRemoved != nil && *run.IsRemoved) {
+				continue
+			}
+			lastUpdated, err := strconv.ParseInt(run.LastUpdated, 10, 64)
+			if err != nil || lastUpdated < start.Unix() || lastUpdated > end.Unix() {
+				continue
+			}
+
+			data.Summary.Runs++
+			switch run.Phase {
+			case string(model.WorkflowRunStatusSucceeded):
+				data.Summary.Succeeded++
+			case string(model.WorkflowRunStatusFailed):
+				data.Summary.Failed++
+			case string(model.WorkflowRunStatusRunning):
+				data.Summary.Running++
+			}
+			if run.ExperimentsPassed != nil {
+				data.Summary.ExperimentsPassed += *run.ExperimentsPassed
+			}
+			if run.ExperimentsFailed != nil {
+				data.Summary.ExperimentsFailed += *run.ExperimentsFailed
+			}
+
+			key := dayKey(time.Unix(lastUpdated, 0))
+			if days[key] == nil {
+				days[key] = &dailyAccumulator{}
+			}
+			days[key].runs++
+			if run.Completed && run.ResiliencyScore != nil {
+				days[key].scoreSum += *run.ResiliencyScore
+				days[key].scoreCount++
+				scoreSum += *run.ResiliencyScore
+				scoreCount++
+			}
+
+			if run.Completed {
+				countExperiments(run.ExecutionData, experiments)
+			}
+		}
+	}
+	if scoreCount > 0 {
+		average := scoreSum / float64(scoreCount)
+		data.Summary.AverageResiliencyScore = &average
+	}
+
+	data.ResiliencyTrend, data.Heatmap = dailyStats(days, start, end)
+	data.TopFailingExperiments = topFailingExperiments(experiments)
+
+	clusters, err := dbOperationsCluster.GetClusterWithProjectID(projectID, nil)
+	if err != nil {
+		return nil, errors.New("failed to get the agents : " + err.Error())
+	}
+	for _, cluster := range clusters {
+		data.Agents = append(data.Agents, AgentHealth{
+			Name:        cluster.ClusterName,
+			Type:        cluster.ClusterType,
+			Platform:    cluster.PlatformName,
+			Active:      cluster.IsActive,
+			Confirmed:   cluster.IsClusterConfirmed,
+			LastUpdated: formatTimestamp(cluster.UpdatedAt),
+		})
+	}
+	sort.SliceStable(data.Agents, func(i, j int) bool { return data.Agents[i].Name < data.Agents[j].Name })
+
+	return data, nil
+}
+
+type dailyAccumulator struct {
+	runs       int
+	scoreSum   float64
+	scoreCount int
+}
+
+// dailyStats returns the stats of every day of the period and the same days arranged in weeks
+func dailyStats(days map[string]*dailyAccumulator, start, end time.Time) ([]DailyStats, [][]HeatmapDay) {
+	var trend []DailyStats
+	var heatmap [][]HeatmapDay
+
+	firstDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	lastDay := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	weekStart := firstDay.AddDate(0, 0, -int(firstDay.Weekday()))
+
+	var week []HeatmapDay
+	for day := weekStart; !day.After(lastDay) || len(week) > 0; day = day.AddDate(0, 0, 1) {
+		stats := DailyStats{Date: day}
+		inPeriod := !day.Before(firstDay) && !day.After(lastDay)
+		if accumulator := days[dayKey(day)]; accumulator != nil && inPeriod {
+			stats.Runs = accumulator.runs
+			if accumulator.scoreCount > 0 {
+				average := accumulator.scoreSum / float64(accumulator.scoreCount)
+				stats.AverageResiliencyScore = &average
+			}
+		}
+		if inPeriod {
+			trend = append(trend, stats)
+		}
+
+		week = append(week, HeatmapDay{DailyStats: stats, InPeriod: inPeriod})
+		if len(week) == 7 {
+			heatmap = append(heatmap, week)
+			week = nil
+		}
+	}
+
+	return trend, heatmap
+}
+
+// countExperiments adds the verdicts of the experiments of a completed run
+func countExperiments(executionData string, experiments map[string]*ExperimentFailures) {
+	var data types.ExecutionData
+	if err := json.Unmarshal([]byte(executionData), &data); err != nil {
+		return
+	}
+
+	for _, node := range data.Nodes {
+		if node.Type != "ChaosEngine" || node.ChaosExp == nil || node.ChaosExp.ExperimentName == "" {
+			continue
+		}
+		name := node.ChaosExp.ExperimentName
+		if experiments[name] == nil {
+			experiments[name] = &ExperimentFailures{Name: name}
+		}
+		experiments[name].Runs++
+		if node.ChaosExp.ExperimentVerdict == "Fail" {
+			experiments[name].Failed++
+		}
+	}
+}
+
+func topFailingExperiments(experiments map[string]*ExperimentFailures) []ExperimentFailures {
+	var failing []ExperimentFailures
+	for _, experiment := range experiments {
+		if experiment.Failed == 0 {
+			continue
+		}
+		experiment.FailureRate = float64(experiment.Failed) / float64(experiment.Runs) * 100
+		failing = append(failing, *experiment)
+	}
+
+	sort.SliceStable(failing, func(i, j int) bool {
+		if failing[i].Failed != failing[j].Failed {
+			return failing[i].Failed > failing[j].Failed
+		}
+		return failing[i].Name < failing[j].Name
+	})
+	if len(failing) > maxFailingExperiments {
+		failing = failing[:maxFailingExperiments]
+	}
+	return failing
+}
+
+// runFrequencyFilter picks the GetWorkflowStats filter closest to the period
+func runFrequencyFilter(period time.Duration) model.TimeFrequency {
+	switch {
+	case period <= 48*time.Hour:
+		return model.TimeFrequencyHourly
+	case period <= 28*24*time.Hour:
+		return model.TimeFrequencyDaily
+	default:
+		return model.TimeFrequencyMonthly
+	}
+}
+
+// pruneReports removes the oldest reports of the project beyond the stored report limit
+func pruneReports(ctx context.Context, projectID string) {
+	reports, err := dbReport.ListReports(ctx, bson.D{{"project_id", projectID}})
+	if err != nil || len(reports) <= maxStoredReports {
+		return
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].GeneratedAt > reports[j].GeneratedAt
+	})
+	for _, report := range reports[maxStoredReports:] {
+		if err := dbReport.DeleteReport(ctx, report.ReportID); err != nil {
+			log.Print("Could not remove the report ", report.ReportID, " : ", err)
+		}
+	}
+}
+
+func dayKey(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+func formatTimestamp(timestamp string) string {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return timestamp
+	}
+	return time.Unix(seconds, 0).UTC().Format("2006-01-02 15:04 UTC")
+}
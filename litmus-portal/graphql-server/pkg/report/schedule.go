@@ -0,0 +1,111 @@
+package report
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+	dbReport "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb/report"
+)
+
+// interval between the checks for due schedules
+const scheduleInterval = time.Minute
+
+// RecurringReports generates the reports of the schedules which are due
+func RecurringReports() {
+	for {
+		runDueReports(time.Now().UTC())
+		time.Sleep(scheduleInterval)
+	}
+}
+
+func runDueReports(now time.Time) {
+	ctx := context.Background()
+	schedules, err := dbReport.ListSchedules(ctx, bson.D{{"enabled", true}})
+	if err != nil {
+		log.Print("Could not get the report schedules : ", err)
+		return
+	}
+
+	for _, schedule := range schedules {
+		nextRun, err := strconv.ParseInt(schedule.NextRunAt, 10, 64)
+		if err != nil || nextRun > now.Unix() {
+			continue
+		}
+
+		// the next run is moved before the report is generated, only one server claims it
+		claimed, err := dbReport.UpdateSchedule(ctx,
+			bson.D{{"project_id", schedule.ProjectID}, {"next_run_at", schedule.NextRunAt}},
+			bson.D{{"$set", bson.D{{"next_run_at", strconv.FormatInt(NextRun(schedule, now).Unix(), 10)}}}},
+			false)
+		if err != nil || !claimed {
+			continue
+		}
+
+		var formats []model.ReportFormat
+		for _, format := range schedule.Formats {
+			formats = append(formats, model.ReportFormat(format))
+		}
+		lastError := ""
+		report, err := Generate(ctx, Options{
+			ProjectID:  schedule.ProjectID,
+			Formats:    formats,
+			PeriodDays: schedule.PeriodDays,
+			Recipients: schedule.Recipients,
+			Scheduled:  true,
+		})
+		if err != nil {
+			log.Print("Could not generate the scheduled report of project ", schedule.ProjectID, " : ", err)
+			lastError = err.Error()
+		} else if report.DeliveryError != "" {
+			lastError = report.DeliveryError
+		}
+
+		_, err = dbReport.UpdateSchedule(ctx, bson.D{{"project_id", schedule.ProjectID}},
+			bson.D{{"$set", bson.D{{"last_run_at", strconv.FormatInt(now.Unix(), 10)}, {"last_error", lastError}}}}, false)
+		if err != nil {
+			log.Print("Could not update the report schedule of project ", schedule.ProjectID, " : ", err)
+		}
+	}
+}
+
+// NextRun returns the first time after the given time the schedule is due, in UTC
+func NextRun(schedule dbReport.ReportSchedule, after time.Time) time.Time {
+	after = after.UTC()
+	next := time.Date(after.Year(), after.Month(), after.Day(), schedule.Hour, 0, 0, 0, time.UTC)
+
+	switch model.ReportFrequency(schedule.Frequency) {
+	case model.ReportFrequencyWeekly:
+		next = next.AddDate(0, 0, (schedule.Day-int(next.Weekday())+7)%7)
+		if !next.After(after) {
+			next = next.AddDate(0, 0, 7)
+		}
+	case model.ReportFrequencyMonthly:
+		next = time.Date(after.Year(), after.Month(), schedule.Day, schedule.Hour, 0, 0, 0, time.UTC)
+		if !next.After(after) {
+			next = next.AddDate(0, 1, 0)
+		}
+	default:
+		if !next.After(after) {
+			next = next.AddDate(0, 0, 1)
+		}
+	}
+
+	return next
+}
+
+// DefaultPeriod returns the days covered by the reports of a frequency
+func DefaultPeriod(frequency model.ReportFrequency) int {
+	switch frequency {
+	case model.ReportFrequencyDaily:
+		return 1
+	case model.ReportFrequencyMonthly:
+		return 30
+	default:
+		return DefaultPeriodDays
+	}
+}
@@ -0,0 +1,183 @@
+package secrets
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// KeyFileEnv points to a mounted file holding the encryption key ring
+	KeyFileEnv = "SECRETS_KEY_FILE"
+	// KeyEnv holds the encryption key ring inline, used when no key file is mounted
+	KeyEnv = "SECRETS_ENCRYPTION_KEY"
+
+	defaultKeyID  = "default"
+	fallbackKeyID = "jwt"
+)
+
+// keyRing holds the encryption keys known to the server, the primary key is used
+// for all new encryptions and the remaining keys are only used for decryption
+type keyRing struct {
+	primary string
+	keys    map[string][]byte
+}
+
+var (
+	ringLock    sync.Mutex
+	currentRing *keyRing
+	ringSource  string
+	ringModTime time.Time
+)
+
+// parseKeyRing parses a key ring definition of the form "id=base64key" separated by
+// newlines or commas. The first entry is the primary key, a bare base64 key is accepted as the only entry
+func parseKeyRing(data string) (*keyRing, error) {
+	ring := &keyRing{keys: map[string][]byte{}}
+	entries := strings.FieldsFunc(data, func(r rune) bool {
+		return r == '\n' || r == ','
+	})
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		id, encodedKey := defaultKeyID, entry
+		// '=' only appears as trailing padding in base64, so anything after the first '=' that is
+		// not padding means the entry carries an explicit key id
+		if i := strings.Index(entry, "="); i > 0 && strings.Trim(entry[i+1:], "=") != "" {
+			id, encodedKey = strings.TrimSpace(entry[:i]), strings.TrimSpace(entry[i+1:])
+		}
+		if strings.Contains(id, ":") {
+			return nil, errors.New("key id must not contain ':' : " + id)
+		}
+		key, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil {
+			return nil, errors.New("invalid base64 key for key id " + id + " : " + err.Error())
+		}
+		if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+			return nil, errors.New("key " + id + " must be 16, 24 or 32 bytes long")
+		}
+		if _, ok := ring.keys[id]; ok {
+			return nil, errors.New("duplicate key id " + id)
+		}
+		if ring.primary == "" {
+			ring.primary = id
+		}
+		ring.keys[id] = key
+	}
+	if ring.primary == "" {
+		return nil, errors.New("no encryption keys found")
+	}
+	return ring, nil
+}
+
+// fallbackKeyRing derives a key from the JWT secret for installations without a dedicated key
+func fallbackKeyRing() (*keyRing, error) {
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" {
+		return nil, errors.New("no secrets encryption key configured")
+	}
+	key := sha256.Sum256([]byte("litmus-secrets:" + jwtSecret))
+	return &keyRing{
+		primary: fallbackKeyID,
+		keys:    map[string][]byte{fallbackKeyID: key[:]},
+	}, nil
+}
+
+// withFallback keeps the JWT derived key available for decryption so that values written
+// before a dedicated key was configured can still be read and migrated
+func withFallback(ring *keyRing) *keyRing {
+	if _, ok := ring.keys[fallbackKeyID]; ok {
+		return ring
+	}
+	if fallback, err := fallbackKeyRing(); err == nil {
+		ring.keys[fallbackKeyID] = fallback.keys[fallbackKeyID]
+	}
+	return ring
+}
+
+// loadKeyRing returns the current key ring, the key file is re-read whenever it changes on disk
+// so that mounted secrets can be rotated without restarting the server
+func loadKeyRing() (*keyRing, error) {
+	ringLock.Lock()
+	defer ringLock.Unlock()
+
+	if path := os.Getenv(KeyFileEnv); path != "" {
+		info, err := os.Stat(path)
+		if err != nil {
+			if currentRing != nil && ringSource == path {
+				log.Print("Failed to stat secrets key file, using cached keys : ", err)
+				return currentRing, nil
+			}
+			return nil, errors.New("cannot read secrets key file : " + err.Error())
+		}
+		if currentRing != nil && ringSource == path && info.ModTime().Equal(ringModTime) {
+			return currentRing, nil
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.New("cannot read secrets key file : " + err.Error())
+		}
+		ring, err := parseKeyRing(string(data))
+		if err != nil {
+			return nil, errors.New("invalid secrets key file : " + err.Error())
+		}
+		if currentRing != nil && currentRing.primary != ring.primary {
+			log.Print("Secrets primary key rotated to : ", ring.primary)
+		}
+		currentRing, ringSource, ringModTime = withFallback(ring), path, info.ModTime()
+		return currentRing, nil
+	}
+
+	if currentRing != nil && ringSource == KeyEnv {
+		return currentRing, nil
+	}
+
+	var (
+		ring *keyRing
+		err  error
+	)
+	if keys := os.Getenv(KeyEnv); keys != "" {
+		ring, err = parseKeyRing(keys)
+		if err != nil {
+			return nil, errors.New("invalid " + KeyEnv + " : " + err.Error())
+		}
+		ring = withFallback(ring)
+	} else {
+		log.Print("No secrets encryption key configured, deriving key from JWT_SECRET")
+		ring, err = fallbackKeyRing()
+		if err != nil {
+			return nil, err
+		}
+	}
+	currentRing, ringSource = ring, KeyEnv
+	return currentRing, nil
+}
+
+// PrimaryKeyID returns the id of the key currently used for encryption
+func PrimaryKeyID() (string, error) {
+	ring, err := loadKeyRing()
+	if err != nil {
+		return "", err
+	}
+	return ring.primary, nil
+}
+
+// fingerprintKey returns the HMAC key of the secret fingerprints, it's derived from the primary key so that
+// fingerprints can't be computed without access to the key ring. Fingerprints change when the primary key rotates
+func fingerprintKey() ([]byte, error) {
+	ring, err := loadKeyRing()
+	if err != nil {
+		return nil, err
+	}
+	key := sha256.Sum256(append([]byte("litmus-secrets-fingerprint:"), ring.keys[ring.primary]...))
+	return key[:], nil
+}
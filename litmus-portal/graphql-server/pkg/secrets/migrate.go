@@ -0,0 +1,47 @@
+package secrets
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const migrationTimeout = time.Minute
+
+// MigrationFunc encrypts or re-encrypts the stored secrets of one collection with the primary key
+type MigrationFunc func(ctx context.Context) error
+
+// Migrate runs all the migrations, failures are logged so that one collection can't block the others
+func Migrate(migrations ...MigrationFunc) {
+	for _, migrate := range migrations {
+		ctx, cancel := context.WithTimeout(context.Background(), migrationTimeout)
+		if err := migrate(ctx); err != nil {
+			log.Print("Secrets migration failed : ", err)
+		}
+		cancel()
+	}
+}
+
+// WatchKeyRotation checks the key ring on every interval and re-runs the migrations whenever the
+// primary key changes, so that rotated keys can be removed once all secrets use the new key
+func WatchKeyRotation(interval time.Duration, migrations ...MigrationFunc) {
+	primary, err := PrimaryKeyID()
+	if err != nil {
+		log.Print("Failed to load secrets key ring : ", err)
+	}
+	for {
+		time.Sleep(interval)
+		current, err := PrimaryKeyID()
+		if err != nil {
+			log.Print("Failed to load secrets key ring : ", err)
+			continue
+		}
+		if current == primary {
+			continue
+		}
+		log.Print("Secrets primary key changed, re-encrypting stored secrets")
+		Migrate(migrations...)
+		primary = current
+	}
+}
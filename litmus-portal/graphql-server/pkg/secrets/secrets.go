@@ -0,0 +1,190 @@
+// Package secrets encrypts credentials stored by the server (git tokens, passwords, ssh keys)
+// and provides the redacted view of them that is returned through the GraphQL API
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/model"
+)
+
+// encryptedPrefix marks values encrypted by this package, format: enc:v1:<key id>:<base64(nonce|ciphertext)>
+const encryptedPrefix = "enc:v1:"
+
+// IsEncrypted checks whether the value was produced by Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
+
+// keyID returns the id of the key used to encrypt the value
+func keyID(value string) string {
+	rest := strings.TrimPrefix(value, encryptedPrefix)
+	if i := strings.Index(rest, ":"); i >= 0 {
+		return rest[:i]
+	}
+	return ""
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt encrypts the plaintext with the primary key using AES-GCM, the key id is bound
+// to the ciphertext as additional data
+func Encrypt(plaintext string) (string, error) {
+	ring, err := loadKeyRing()
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(ring.keys[ring.primary])
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), []byte(ring.primary))
+	return encryptedPrefix + ring.primary + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the plaintext for a value produced by Encrypt, values without the
+// encryption prefix are legacy plaintext and returned unchanged
+func Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	id := keyID(value)
+	ring, err := loadKeyRing()
+	if err != nil {
+		return "", err
+	}
+	key, ok := ring.keys[id]
+	if !ok {
+		return "", errors.New("unknown secrets encryption key : " + id)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix+id+":"))
+	if err != nil {
+		return "", errors.New("malformed encrypted secret : " + err.Error())
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("malformed encrypted secret")
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(id))
+	if err != nil {
+		return "", errors.New("failed to decrypt secret : " + err.Error())
+	}
+	return string(plaintext), nil
+}
+
+// EncryptPtr encrypts an optional value, nil and empty values are kept as they are
+func EncryptPtr(value *string) (*string, error) {
+	if value == nil || *value == "" || IsEncrypted(*value) {
+		return value, nil
+	}
+	encrypted, err := Encrypt(*value)
+	if err != nil {
+		return nil, err
+	}
+	return &encrypted, nil
+}
+
+// DecryptPtr decrypts an optional value, nil values are kept as they are
+func DecryptPtr(value *string) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	plaintext, err := Decrypt(*value)
+	if err != nil {
+		return nil, err
+	}
+	return &plaintext, nil
+}
+
+// NeedsMigration checks whether a stored value is plaintext or encrypted with a key other than the primary key
+func NeedsMigration(value *string) (bool, error) {
+	if value == nil || *value == "" {
+		return false, nil
+	}
+	if !IsEncrypted(*value) {
+		return true, nil
+	}
+	primary, err := PrimaryKeyID()
+	if err != nil {
+		return false, err
+	}
+	return keyID(*value) != primary, nil
+}
+
+// Reencrypt decrypts the value with whichever key it was written with and encrypts it with the primary key
+func Reencrypt(value *string) (*string, error) {
+	plaintext, err := DecryptPtr(value)
+	if err != nil {
+		return nil, err
+	}
+	if plaintext == nil || *plaintext == "" {
+		return plaintext, nil
+	}
+	encrypted, err := Encrypt(*plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return &encrypted, nil
+}
+
+// Fingerprint returns a non reversible identifier for the secret, an HMAC keyed with a server side key so that
+// guessable secrets can't be recovered from it. Ssh private keys are identified by the SHA256 fingerprint of their
+// public key so that they can be matched with deploy keys
+func Fingerprint(plaintext string) (string, error) {
+	if signer, err := ssh.ParsePrivateKey([]byte(plaintext)); err == nil {
+		return ssh.FingerprintSHA256(signer.PublicKey()), nil
+	}
+	key, err := fingerprintKey()
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(plaintext))
+	return "HMAC-SHA256:" + hex.EncodeToString(mac.Sum(nil)[:16]), nil
+}
+
+// Describe returns the redacted view of a stored secret, the secret value itself is never part of it
+func Describe(name string, value *string) *model.SecretInfo {
+	info := &model.SecretInfo{
+		Name:   name,
+		Status: model.SecretStatusUnset,
+	}
+	if value == nil || *value == "" {
+		return info
+	}
+	info.Status = model.SecretStatusSet
+	plaintext, err := Decrypt(*value)
+	if err != nil {
+		return info
+	}
+	fingerprint, err := Fingerprint(plaintext)
+	if err != nil {
+		return info
+	}
+	info.Fingerprint = &fingerprint
+	return info
+}
@@ -0,0 +1,191 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"os"
+	"strings"
+	"testing"
+)
+
+var (
+	testKey16 = base64.StdEncoding.EncodeToString([]byte("0123456789abcdef"))
+	testKey32 = base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+)
+
+// useKeyRing configures the key ring through the environment and drops the cached one
+func useKeyRing(t *testing.T, keys string) {
+	t.Helper()
+	for _, env := range []string{KeyEnv, KeyFileEnv, "JWT_SECRET"} {
+		value, ok := os.LookupEnv(env)
+		env := env
+		t.Cleanup(func() {
+			if ok {
+				os.Setenv(env, value)
+			} else {
+				os.Unsetenv(env)
+			}
+		})
+	}
+	os.Unsetenv(KeyFileEnv)
+	os.Unsetenv("JWT_SECRET")
+	os.Setenv(KeyEnv, keys)
+
+	ringLock.Lock()
+	currentRing, ringSource = nil, ""
+	ringLock.Unlock()
+	t.Cleanup(func() {
+		ringLock.Lock()
+		currentRing, ringSource = nil, ""
+		ringLock.Unlock()
+	})
+}
+
+func TestParseKeyRing(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		primary string
+		keys    []string
+		wantErr bool
+	}{
+		{name: "bare key", data: testKey16, primary: defaultKeyID, keys: []string{defaultKeyID}},
+		{name: "bare key with padding", data: testKey32 + "\n", primary: defaultKeyID, keys: []string{defaultKeyID}},
+		{name: "key ids", data: "new=" + testKey32 + "\nold=" + testKey16, primary: "new", keys: []string{"new", "old"}},
+		{name: "comma separated", data: "a=" + testKey16 + ", b=" + testKey32, primary: "a", keys: []string{"a", "b"}},
+		{name: "comments", data: "# rotated\nb=" + testKey16, primary: "b", keys: []string{"b"}},
+		{name: "empty", data: "\n# nothing\n", wantErr: true},
+		{name: "invalid base64", data: "a=not base64!", wantErr: true},
+		{name: "invalid length", data: "a=" + base64.StdEncoding.EncodeToString([]byte("short")), wantErr: true},
+		{name: "duplicate id", data: "a=" + testKey16 + "\na=" + testKey32, wantErr: true},
+		{name: "colon in id", data: "a:b=" + testKey16, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring, err := parseKeyRing(tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ring.primary != tt.primary {
+				t.Errorf("primary = %q, want %q", ring.primary, tt.primary)
+			}
+			if len(ring.keys) != len(tt.keys) {
+				t.Errorf("got %d keys, want %d", len(ring.keys), len(tt.keys))
+			}
+			for _, id := range tt.keys {
+				if _, ok := ring.keys[id]; !ok {
+					t.Errorf("missing key %q", id)
+				}
+			}
+		})
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	useKeyRing(t, "k1="+testKey32)
+
+	for _, plaintext := range []string{"", "token", "multi\nline ssh key", strings.Repeat("x", 4096)} {
+		encrypted, err := Encrypt(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !IsEncrypted(encrypted) || keyID(encrypted) != "k1" {
+			t.Fatalf("unexpected encrypted value %q", encrypted)
+		}
+		if plaintext != "" && strings.Contains(encrypted, plaintext) {
+			t.Fatalf("encrypted value contains the plaintext")
+		}
+		decrypted, err := Decrypt(encrypted)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decrypted != plaintext {
+			t.Errorf("Decrypt = %q, want %q", decrypted, plaintext)
+		}
+	}
+
+	decrypted, err := Decrypt("legacy plaintext")
+	if err != nil || decrypted != "legacy plaintext" {
+		t.Errorf("legacy plaintext = %q, %v", decrypted, err)
+	}
+
+	encrypted, _ := Encrypt("token")
+	for name, value := range map[string]string{
+		"unknown key":      strings.Replace(encrypted, "enc:v1:k1:", "enc:v1:k2:", 1),
+		"malformed base64": "enc:v1:k1:%%%",
+		"too short":        "enc:v1:k1:" + base64.StdEncoding.EncodeToString([]byte("abc")),
+		"tampered":         encrypted[:len(encrypted)-4] + "AAA=",
+	} {
+		if _, err := Decrypt(value); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	useKeyRing(t, "old="+testKey16)
+	value, err := Encrypt("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migrate, err := NeedsMigration(&value); err != nil || migrate {
+		t.Fatalf("NeedsMigration before rotation = %v, %v", migrate, err)
+	}
+
+	useKeyRing(t, "new="+testKey32+"\nold="+testKey16)
+	if migrate, err := NeedsMigration(&value); err != nil || !migrate {
+		t.Fatalf("NeedsMigration after rotation = %v, %v", migrate, err)
+	}
+	reencrypted, err := Reencrypt(&value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyID(*reencrypted) != "new" {
+		t.Errorf("re-encrypted with %q, want new", keyID(*reencrypted))
+	}
+	if plaintext, err := Decrypt(*reencrypted); err != nil || plaintext != "token" {
+		t.Errorf("Decrypt = %q, %v", plaintext, err)
+	}
+	if migrate, _ := NeedsMigration(reencrypted); migrate {
+		t.Error("re-encrypted value still needs migration")
+	}
+
+	plaintext := "plaintext"
+	if migrate, _ := NeedsMigration(&plaintext); !migrate {
+		t.Error("plaintext value doesn't need migration")
+	}
+
+	// values of removed keys can't be read anymore
+	useKeyRing(t, "new="+testKey32)
+	if _, err := Decrypt(value); err == nil {
+		t.Error("expected an error for a removed key")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	useKeyRing(t, "k1="+testKey32)
+	first, err := Fingerprint("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(first, "HMAC-SHA256:") || strings.Contains(first, "token") {
+		t.Errorf("unexpected fingerprint %q", first)
+	}
+	if again, _ := Fingerprint("token"); again != first {
+		t.Errorf("fingerprint isn't stable, %q != %q", again, first)
+	}
+	if other, _ := Fingerprint("other"); other == first {
+		t.Error("different secrets have the same fingerprint")
+	}
+
+	// the fingerprint depends on the key ring
+	useKeyRing(t, "k2="+testKey16)
+	if rotated, _ := Fingerprint("token"); rotated == first {
+		t.Error("fingerprint doesn't depend on the key ring")
+	}
+}
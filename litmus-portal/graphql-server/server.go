@@ -16,11 +16,18 @@ import (
 
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/graph/generated"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/alerts"
+	analyticsHandler "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/analytics/handler"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/authorization"
+	chaosWorkflowOps "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/chaos-workflow/ops"
+	data_store "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/data-store"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/database/mongodb"
+	eventTrackerHandler "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/eventtracker/handler"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/file_handlers"
 	gitOpsHandler "github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/gitops/handler"
 	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/myhub"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/report"
+	"github.com/litmuschaos/litmus/litmus-portal/graphql-server/pkg/secrets"
 	"github.com/rs/cors"
 )
 
@@ -66,14 +73,25 @@ func main() {
 		AllowCredentials: true,
 	}).Handler)
 
+	// encrypt credentials stored before secrets encryption was enabled or with a rotated key
+	secrets.Migrate(gitOpsHandler.MigrateSecrets, myhub.MigrateSecrets, analyticsHandler.MigrateSecrets)
+	go secrets.WatchKeyRotation(time.Minute, gitOpsHandler.MigrateSecrets, myhub.MigrateSecrets, analyticsHandler.MigrateSecrets)
+
 	gitOpsHandler.GitOpsSyncHandler(true) // sync all previous existing repos before start
 
-	go myhub.RecurringHubSync()               // go routine for syncing hubs for all users
-	go gitOpsHandler.GitOpsSyncHandler(false) // routine to sync git repos for gitOps
+	go myhub.RecurringHubSync()                                  // go routine for syncing hubs for all users
+	go gitOpsHandler.GitOpsSyncHandler(false)                    // routine to sync git repos for gitOps
+	go chaosWorkflowOps.ResumeImpactReports()                    // routine to generate the impact reports pending before a restart
+	go report.RecurringReports()                                 // routine to generate the scheduled resiliency reports
+	go analyticsHandler.MonitorDataSourceHealth()                // routine to check the health of the data sources
+	go alerts.RecurringEvaluation(data_store.Store)              // routine to evaluate the alert rules of the projects
+	go chaosWorkflowOps.RecurringScheduledRuns(data_store.Store) // routine to dispatch the scheduled runs of workflows with steady state gates
 
 	router.Handle("/", playground.Handler("GraphQL playground", "/query"))
 	router.Handle("/query", authorization.Middleware(srv))
 	router.HandleFunc("/file/{key}{path:.yaml}", file_handlers.FileHandler)
+	router.HandleFunc("/gitops/trigger", gitOpsHandler.GitOpsTriggerHandler).Methods("POST")
+	router.HandleFunc("/eventtracker/status", eventTrackerHandler.PolicyStatusHandler).Methods("POST")
 	router.Handle("/icon/{ProjectID}/{HubName}/{ChartName}/{IconName}", authorization.RestMiddlewareWithRole(myhub.GetIconHandler, nil)).Methods("GET")
 	logrus.Printf("connect to http://localhost:%s/ for GraphQL playground", port)
 	logrus.Fatal(http.ListenAndServe(":"+port, router))
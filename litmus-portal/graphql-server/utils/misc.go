@@ -161,6 +161,16 @@ func ContainsString(s []string, str string) bool {
 	return false
 }
 
+// SanitizeFileName replaces the characters of the name which aren't safe in file names with dashes
+func SanitizeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, name)
+}
+
 // Truncate a float to two levels of precision
 func Truncate(num float64) float64 {
 	return float64(int(num*100)) / 100